@@ -0,0 +1,128 @@
+// Package batcher накапливает порции элементов в батчи: батч отправляется, когда следующая порция
+// не помещается по числу элементов или байт либо по решению Config.Flush, или когда первый элемент ждёт дольше MaxAge.
+// Не поместившаяся порция не дробится и начинает следующий батч. Для каждой порции запоминается cookie,
+// чтобы после обработки батча подтвердить их источнику по порядку.
+package batcher
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config — условия отправки батча. Нулевое значение условия — условие выключено.
+type Config[T any] struct {
+	MaxItems int                            // не больше стольких элементов; порция длиннее лимита уходит отдельным батчем
+	MaxBytes int                            // не больше стольких байт по Size
+	MaxAge   time.Duration                  // не дольше стольких держать первую порцию батча
+	Flush    func(cur, incoming State) bool // true — отправить непустой буфер cur, прежде чем добавить порцию incoming
+	Size     func(T) int                    // размер элемента; обязателен при MaxBytes > 0
+	Now      func() time.Time               // источник времени для MaxAge и State.Age; nil — time.Now
+}
+
+// State — размер буфера или порции, передаваемый в Config.Flush.
+type State struct {
+	Items int           // число элементов
+	Bytes int           // суммарный размер по Size; 0 без Size
+	Age   time.Duration // сколько ждёт первый элемент буфера; у порции 0
+}
+
+// Batch — накопленный батч.
+type Batch[T any] struct {
+	Items   []T
+	Cookies []int     // cookies порций в порядке Add
+	Starts  []int     // Starts[i] — индекс в Items первого элемента порции Cookies[i]
+	Bytes   int       // суммарный размер по Size (0, если Size не задан)
+	Started time.Time // когда в батч попал первый элемент; по Config.Now
+}
+
+// Len возвращает число элементов батча.
+func (b Batch[T]) Len() int {
+	return len(b.Items)
+}
+
+// Batcher накапливает порции в батч. Не безопасен для конкурентного использования.
+type Batcher[T any] struct {
+	cfg Config[T]
+	cur Batch[T]
+}
+
+// New проверяет конфигурацию и создаёт Batcher.
+func New[T any](cfg Config[T]) (*Batcher[T], error) {
+	if cfg.MaxItems < 0 || cfg.MaxBytes < 0 || cfg.MaxAge < 0 {
+		return nil, fmt.Errorf("batcher: limits must not be negative: items %d, bytes %d, age %v",
+			cfg.MaxItems, cfg.MaxBytes, cfg.MaxAge)
+	}
+	if cfg.MaxBytes > 0 && cfg.Size == nil {
+		return nil, errors.New("batcher: size func is required with max bytes")
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Batcher[T]{cfg: cfg}, nil
+}
+
+// Add добавляет порцию items с cookie. Если порция не помещается в непустой буфер, накопленный батч
+// возвращается с ok == true, а порция начинает новый буфер. Пустой буфер принимает порцию любого размера.
+// Cookie порции без элементов остаётся в буфере и уходит вместе со следующим непустым батчем.
+func (b *Batcher[T]) Add(items []T, cookie int) (full Batch[T], ok bool) {
+	size := 0
+	if b.cfg.Size != nil {
+		for _, it := range items {
+			size += b.cfg.Size(it)
+		}
+	}
+	if len(b.cur.Items) > 0 && !b.fits(len(items), size) {
+		full, ok = b.Flush(), true
+	}
+
+	if len(b.cur.Items) == 0 {
+		b.cur.Started = b.cfg.Now()
+	}
+	b.cur.Starts = append(b.cur.Starts, len(b.cur.Items))
+	b.cur.Items = append(b.cur.Items, items...)
+	b.cur.Cookies = append(b.cur.Cookies, cookie)
+	b.cur.Bytes += size
+	return full, ok
+}
+
+// fits сообщает, помещается ли порция из n элементов размером size в текущий буфер.
+func (b *Batcher[T]) fits(n, size int) bool {
+	if b.cfg.MaxItems > 0 && len(b.cur.Items)+n > b.cfg.MaxItems {
+		return false
+	}
+	if b.cfg.Flush != nil && b.cfg.Flush(State{Items: len(b.cur.Items), Bytes: b.cur.Bytes, Age: b.Age()}, State{Items: n, Bytes: size}) {
+		return false
+	}
+	return b.cfg.MaxBytes <= 0 || b.cur.Bytes+size <= b.cfg.MaxBytes
+}
+
+// Due сообщает, что в буфере есть элементы и первый из них ждёт MaxAge или дольше.
+func (b *Batcher[T]) Due() bool {
+	return b.cfg.MaxAge > 0 && len(b.cur.Items) > 0 && b.cfg.Now().Sub(b.cur.Started) >= b.cfg.MaxAge
+}
+
+// Age возвращает, сколько ждёт первый элемент буфера; 0 — буфер пуст.
+func (b *Batcher[T]) Age() time.Duration {
+	if len(b.cur.Items) == 0 {
+		return 0
+	}
+	return b.cfg.Now().Sub(b.cur.Started)
+}
+
+// Flush забирает накопленный батч (возможно, пустой) и начинает новый. Срезы батча больше не используются Batcher.
+func (b *Batcher[T]) Flush() Batch[T] {
+	full := b.cur
+	b.cur = Batch[T]{}
+	return full
+}
+
+// Len возвращает число накопленных элементов.
+func (b *Batcher[T]) Len() int {
+	return len(b.cur.Items)
+}
+
+// Pending возвращает число накопленных порций.
+func (b *Batcher[T]) Pending() int {
+	return len(b.cur.Cookies)
+}
@@ -0,0 +1,136 @@
+package batcher
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestBatcher[T any](t *testing.T, cfg Config[T]) *Batcher[T] {
+	t.Helper()
+	b, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return b
+}
+
+func TestNew_Validates(t *testing.T) {
+	for _, cfg := range []Config[string]{{MaxItems: -1}, {MaxAge: -time.Second}, {MaxBytes: 10}} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v): ожидалась ошибка", cfg)
+		}
+	}
+}
+
+func TestBatcher_CarriesOverflowingPortion(t *testing.T) {
+	b := newTestBatcher(t, Config[int]{MaxItems: 5})
+	if _, ok := b.Add([]int{1, 2, 3}, 10); ok {
+		t.Fatalf("первая порция не должна отправлять батч")
+	}
+	if _, ok := b.Add([]int{4, 5}, 11); ok {
+		t.Fatalf("порция помещается ровно в MaxItems")
+	}
+	full, ok := b.Add([]int{6}, 12)
+	if !ok {
+		t.Fatalf("порция сверх MaxItems должна отправить накопленное")
+	}
+	if !slices.Equal(full.Items, []int{1, 2, 3, 4, 5}) || !slices.Equal(full.Cookies, []int{10, 11}) ||
+		!slices.Equal(full.Starts, []int{0, 3}) {
+		t.Fatalf("батч: %+v", full)
+	}
+
+	// Не поместившаяся порция начала новый буфер; порция длиннее лимита не дробится
+	full, ok = b.Add([]int{7, 8, 9, 10, 11, 12}, 13)
+	if !ok || !slices.Equal(full.Items, []int{6}) || !slices.Equal(full.Cookies, []int{12}) {
+		t.Fatalf("батч: %+v, %v", full, ok)
+	}
+	rest := b.Flush()
+	if rest.Len() != 6 || !slices.Equal(rest.Cookies, []int{13}) || b.Len() != 0 || b.Pending() != 0 {
+		t.Fatalf("Flush: %+v, осталось %d элементов", rest, b.Len())
+	}
+}
+
+func TestBatcher_MaxBytes(t *testing.T) {
+	b := newTestBatcher(t, Config[string]{MaxBytes: 6, Size: func(s string) int { return len(s) }})
+	b.Add([]string{"ab", "cd"}, 1)
+	b.Add([]string{"ef"}, 2)
+	full, ok := b.Add([]string{"g"}, 3)
+	if !ok || full.Bytes != 6 || !slices.Equal(full.Items, []string{"ab", "cd", "ef"}) {
+		t.Fatalf("батч: %+v, %v", full, ok)
+	}
+	if rest := b.Flush(); rest.Bytes != 1 {
+		t.Fatalf("размер нового буфера: %d", rest.Bytes)
+	}
+}
+
+func TestBatcher_MaxAge(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTestBatcher(t, Config[int]{MaxAge: time.Second, Now: func() time.Time { return now }})
+	if b.Due() {
+		t.Fatalf("пустой буфер не может быть просрочен")
+	}
+	b.Add([]int{1}, 1)
+	now = now.Add(600 * time.Millisecond)
+	b.Add([]int{2}, 2) // Возраст считается от первой порции
+	if b.Due() {
+		t.Fatalf("буфер просрочен раньше MaxAge")
+	}
+	now = now.Add(400 * time.Millisecond)
+	if got := b.Age(); got != time.Second {
+		t.Fatalf("возраст буфера %v, ожидалась 1s", got)
+	}
+	if !b.Due() {
+		t.Fatalf("буфер не просрочен через MaxAge после первой порции")
+	}
+	if full := b.Flush(); !full.Started.Equal(time.Unix(0, 0)) {
+		t.Fatalf("батч начат в %v, ожидалось время первой порции", full.Started)
+	}
+	if got := b.Age(); got != 0 {
+		t.Fatalf("возраст пустого буфера %v", got)
+	}
+	b.Add([]int{3}, 3)
+	if b.Due() {
+		t.Fatalf("возраст нового буфера должен отсчитываться заново")
+	}
+}
+
+// TestBatcher_EmptyPortionRidesAlong: cookie порции без элементов уходит со следующим непустым батчем.
+func TestBatcher_EmptyPortionRidesAlong(t *testing.T) {
+	b := newTestBatcher(t, Config[int]{MaxItems: 2})
+	b.Add(nil, 1)
+	if _, ok := b.Add([]int{1, 2, 3}, 2); ok {
+		t.Fatalf("буфер без элементов не отправляется")
+	}
+	full, ok := b.Add([]int{4}, 3)
+	if !ok || !slices.Equal(full.Cookies, []int{1, 2}) || !slices.Equal(full.Starts, []int{0, 0}) {
+		t.Fatalf("батч: %+v, %v", full, ok)
+	}
+}
+
+// TestBatcher_FlushCallback: Flush видит состояние буфера и порции и может отправить буфер раньше лимитов.
+func TestBatcher_FlushCallback(t *testing.T) {
+	now := time.Unix(0, 0)
+	var calls []State
+	b := newTestBatcher(t, Config[string]{
+		Size: func(s string) int { return len(s) },
+		Now:  func() time.Time { return now },
+		Flush: func(cur, incoming State) bool {
+			calls = append(calls, cur, incoming)
+			return cur.Bytes+incoming.Bytes > 4
+		},
+	})
+	b.Add([]string{"ab"}, 1) // Пустой буфер принимает порцию без вопросов
+	now = now.Add(time.Second)
+	if _, ok := b.Add([]string{"c", "d"}, 2); ok {
+		t.Fatalf("буфер отправлен до порога")
+	}
+	full, ok := b.Add([]string{"efg"}, 3)
+	if !ok || !slices.Equal(full.Items, []string{"ab", "c", "d"}) {
+		t.Fatalf("батч: %+v, %v", full, ok)
+	}
+	want := []State{{Items: 1, Bytes: 2, Age: time.Second}, {Items: 2, Bytes: 2}, {Items: 3, Bytes: 4, Age: time.Second}, {Items: 1, Bytes: 3}}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("состояния: %+v, ожидались %+v", calls, want)
+	}
+}
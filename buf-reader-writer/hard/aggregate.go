@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Aggregate — результат свёртки элементов одного ключа в одном временном окне.
+type Aggregate struct {
+	Key   string
+	Start time.Time // начало окна (включительно)
+	End   time.Time // конец окна (исключительно)
+	Count int       // число свёрнутых элементов
+	Value any       // результат Reduce
+}
+
+// AggregateConfig — настройки AggregatingConsumer.
+type AggregateConfig struct {
+	Window time.Duration                      // длина tumbling-окна; обязательно
+	Key    func(item any) (string, time.Time) // ключ группировки и время события элемента; обязательно
+	Reduce func(acc any, item any) any        // свёртка; acc равен nil для первого элемента окна; не должна менять acc на месте; обязательно
+}
+
+// windowKey идентифицирует открытое окно.
+type windowKey struct {
+	key   string
+	start time.Time
+}
+
+// openWindow — накапливаемое окно.
+type openWindow struct {
+	agg      Aggregate
+	firstSeq int // номер первого батча, внёсшего элементы в окно
+}
+
+// pendingCommit — cookie, коммит которого отложен до закрытия окон с его элементами.
+type pendingCommit struct {
+	cookie int
+	seq    int // номер последнего обработанного батча на момент коммита
+}
+
+// AggregatingConsumer группирует элементы по ключу и tumbling-окну времени события и отправляет готовые
+// агрегаты в downstream Consumer, когда окно закрывается. Окно закрывается, как только время события
+// (watermark — максимум среди увиденных элементов) достигает его конца; опоздавшие элементы открывают окно заново.
+//
+// Коммит cookie откладывается до тех пор, пока не отправлены все окна с его элементами: для этого источник
+// оборачивается через WrapProducer. После завершения Pipe нужно вызвать Close, чтобы отправить оставшиеся окна
+// и закоммитить отложенные cookies.
+type AggregatingConsumer struct {
+	next Consumer
+	cfg  AggregateConfig
+
+	mu        sync.Mutex
+	p         Producer // источник из WrapProducer; nil — коммиты не отслеживаются
+	windows   map[windowKey]*openWindow
+	watermark time.Time
+	seq       int // число обработанных батчей
+	pending   []pendingCommit
+}
+
+// Проверка, что AggregatingConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*AggregatingConsumer)(nil)
+
+// NewAggregatingConsumer создаёт агрегирующий Consumer поверх next.
+func NewAggregatingConsumer(next Consumer, cfg AggregateConfig) (*AggregatingConsumer, error) {
+	if cfg.Window <= 0 || cfg.Key == nil || cfg.Reduce == nil {
+		return nil, errors.New("aggregating consumer: Window, Key and Reduce are required")
+	}
+	return &AggregatingConsumer{
+		next:    next,
+		cfg:     cfg,
+		windows: make(map[windowKey]*openWindow),
+	}, nil
+}
+
+// WrapProducer возвращает обёртку над p, чьи коммиты откладываются до отправки соответствующих окон.
+// Её нужно передать в Pipe вместо p.
+func (ac *AggregatingConsumer) WrapProducer(p Producer) Producer {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.p = p
+	return aggregateProducer{Producer: p, ac: ac}
+}
+
+// Process добавляет элементы в окна и отправляет закрывшиеся окна в downstream. Элементы сворачиваются
+// в копию окон, которая заменяет текущее состояние только после успешной отправки: при ошибке downstream
+// батч не остаётся учтённым, и его повтор (или отправка в dead letter) не искажает агрегаты.
+func (ac *AggregatingConsumer) Process(items []any) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	seq := ac.seq + 1
+	watermark := ac.watermark
+	windows := make(map[windowKey]*openWindow, len(ac.windows))
+	for wk, w := range ac.windows {
+		windows[wk] = w
+	}
+	for _, item := range items {
+		key, ts := ac.cfg.Key(item)
+		wk := windowKey{key: key, start: ts.Truncate(ac.cfg.Window)}
+		w, ok := windows[wk]
+		switch {
+		case !ok:
+			w = &openWindow{
+				agg:      Aggregate{Key: key, Start: wk.start, End: wk.start.Add(ac.cfg.Window)},
+				firstSeq: seq,
+			}
+			windows[wk] = w
+		case w == ac.windows[wk]: // Окно из текущего состояния меняем только в копии
+			copied := *w
+			w = &copied
+			windows[wk] = w
+		}
+		w.agg.Value = ac.cfg.Reduce(w.agg.Value, item)
+		w.agg.Count++
+		if ts.After(watermark) {
+			watermark = ts
+		}
+	}
+
+	err := ac.emit(windows, watermark, false)
+	if err != nil {
+		return err
+	}
+	ac.windows, ac.watermark, ac.seq = windows, watermark, seq
+
+	// Ошибка коммита не возвращается: батч уже учтён, и повтор Process посчитал бы его дважды.
+	// Cookie остаётся в очереди, а ошибку вернёт ближайший Commit
+	_ = ac.releaseCommits()
+	return nil
+}
+
+// Close отправляет все открытые окна и коммитит отложенные cookies.
+func (ac *AggregatingConsumer) Close() error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	err := ac.emit(ac.windows, ac.watermark, true)
+	if err != nil {
+		return err
+	}
+	return ac.releaseCommits()
+}
+
+// emit отправляет окна из windows, закрытые watermark (или все при all), и удаляет их из windows.
+// Окна удаляются только после успешной отправки, поэтому при ошибке downstream повтор отправит их снова.
+func (ac *AggregatingConsumer) emit(windows map[windowKey]*openWindow, watermark time.Time, all bool) error {
+	var ready []windowKey
+	for wk, w := range windows {
+		if all || !w.agg.End.After(watermark) {
+			ready = append(ready, wk)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		if !ready[i].start.Equal(ready[j].start) {
+			return ready[i].start.Before(ready[j].start)
+		}
+		return ready[i].key < ready[j].key
+	})
+
+	out := make([]any, len(ready))
+	for i, wk := range ready {
+		out[i] = windows[wk].agg
+	}
+	err := ac.next.Process(out)
+	if err != nil {
+		return fmt.Errorf("emit aggregates: %w", err)
+	}
+	for _, wk := range ready {
+		delete(windows, wk)
+	}
+	return nil
+}
+
+// releaseCommits коммитит отложенные cookies по порядку, пока их элементы не попадают в открытые окна.
+func (ac *AggregatingConsumer) releaseCommits() error {
+	minOpen := ac.seq + 1
+	for _, w := range ac.windows {
+		minOpen = min(minOpen, w.firstSeq)
+	}
+
+	for len(ac.pending) > 0 && ac.pending[0].seq < minOpen {
+		pc := ac.pending[0]
+		err := ac.p.Commit(pc.cookie)
+		if err != nil {
+			return fmt.Errorf("error commiting cookie %d: %w", pc.cookie, err)
+		}
+		ac.pending = ac.pending[1:]
+	}
+	return nil
+}
+
+// aggregateProducer перехватывает Commit, откладывая его в AggregatingConsumer.
+type aggregateProducer struct {
+	Producer
+	ac *AggregatingConsumer
+}
+
+func (ap aggregateProducer) Commit(cookie int) error {
+	ap.ac.mu.Lock()
+	defer ap.ac.mu.Unlock()
+	ap.ac.pending = append(ap.ac.pending, pendingCommit{cookie: cookie, seq: ap.ac.seq})
+	return ap.ac.releaseCommits()
+}
+
+// Nack пробрасывается в исходный источник, если он поддерживает Nacker.
+func (ap aggregateProducer) Nack(cookie int) error {
+	if n, ok := ap.Producer.(Nacker); ok {
+		return n.Nack(cookie)
+	}
+	return nil
+}
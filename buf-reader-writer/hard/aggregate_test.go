@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type aggEvent struct {
+	key string
+	ts  time.Time
+	val int
+}
+
+func newSumAggregator(t *testing.T, next Consumer) *AggregatingConsumer {
+	ac, err := NewAggregatingConsumer(next, AggregateConfig{
+		Window: time.Minute,
+		Key: func(item any) (string, time.Time) {
+			ev := item.(aggEvent)
+			return ev.key, ev.ts
+		},
+		Reduce: func(acc any, item any) any {
+			sum, _ := acc.(int)
+			return sum + item.(aggEvent).val
+		},
+	})
+	require.NoError(t, err)
+	return ac
+}
+
+func at(sec int) time.Time { return time.Unix(int64(sec), 0).UTC() }
+
+func TestAggregatingConsumer_EmitsClosedWindows(t *testing.T) {
+	out := &mockConsumer{}
+	ac := newSumAggregator(t, out)
+
+	require.NoError(t, ac.Process([]any{
+		aggEvent{"a", at(1), 1}, aggEvent{"b", at(2), 10}, aggEvent{"a", at(59), 2},
+	}))
+	assert.Empty(t, out.processed, "окно ещё не закрыто")
+
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(61), 5}}))
+	require.Len(t, out.processed, 1)
+	assert.Equal(t, []any{
+		Aggregate{Key: "a", Start: at(0), End: at(60), Count: 2, Value: 3},
+		Aggregate{Key: "b", Start: at(0), End: at(60), Count: 1, Value: 10},
+	}, out.processed[0])
+
+	require.NoError(t, ac.Close())
+	assert.Equal(t, []any{Aggregate{Key: "a", Start: at(60), End: at(120), Count: 1, Value: 5}}, out.processed[1])
+}
+
+func TestAggregatingConsumer_CommitsAfterWindowFlushed(t *testing.T) {
+	p := &mockProducer{}
+	ac := newSumAggregator(t, &mockConsumer{})
+	wp := ac.WrapProducer(p)
+
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(10), 1}}))
+	require.NoError(t, wp.Commit(1))
+	assert.Empty(t, p.committed, "окно с элементами cookie 1 ещё открыто")
+
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(20), 1}}))
+	require.NoError(t, wp.Commit(2))
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(70), 1}}))
+	assert.Equal(t, []int{1, 2}, p.committed, "после закрытия окна коммитятся оба cookie")
+
+	require.NoError(t, wp.Commit(3))
+	assert.Equal(t, []int{1, 2}, p.committed)
+
+	require.NoError(t, ac.Close())
+	assert.Equal(t, []int{1, 2, 3}, p.committed)
+}
+
+func TestAggregatingConsumer_Pipe(t *testing.T) {
+	out := &mockConsumer{}
+	ac := newSumAggregator(t, out)
+	p := &mockProducer{
+		batches: [][]any{{aggEvent{"a", at(1), 1}}, {aggEvent{"a", at(2), 2}}},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(ac.WrapProducer(p), ac)
+	require.Equal(t, io.EOF, err)
+	assert.Empty(t, p.committed)
+
+	require.NoError(t, ac.Close())
+	assert.Equal(t, [][]any{{Aggregate{Key: "a", Start: at(0), End: at(60), Count: 2, Value: 3}}}, out.processed)
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestAggregatingConsumer_DownstreamErrorKeepsWindows(t *testing.T) {
+	out := &mockConsumer{procErr: errors.New("sink down")}
+	ac := newSumAggregator(t, out)
+
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(1), 1}}))
+	require.ErrorIs(t, ac.Close(), out.procErr)
+
+	out.procErr = nil
+	require.NoError(t, ac.Close())
+	assert.Len(t, out.processed, 2, "окно отправляется повторно после ошибки")
+}
+
+func TestAggregatingConsumer_RetryAfterEmitErrorCountsOnce(t *testing.T) {
+	out := &mockConsumer{}
+	ac := newSumAggregator(t, out)
+
+	require.NoError(t, ac.Process([]any{aggEvent{"a", at(1), 1}}))
+	batch := []any{aggEvent{"a", at(2), 2}, aggEvent{"b", at(3), 10}, aggEvent{"a", at(61), 5}}
+	out.procErr = errors.New("sink down")
+	require.ErrorIs(t, ac.Process(batch), out.procErr)
+
+	out.procErr = nil
+	require.NoError(t, ac.Process(batch), "повтор того же батча")
+	require.NoError(t, ac.Close())
+	assert.Equal(t, []any{
+		Aggregate{Key: "a", Start: at(0), End: at(60), Count: 2, Value: 3},
+		Aggregate{Key: "b", Start: at(0), End: at(60), Count: 1, Value: 10},
+	}, out.processed[1], "неудачная попытка не должна учитываться в окнах")
+	assert.Equal(t, []any{Aggregate{Key: "a", Start: at(60), End: at(120), Count: 1, Value: 5}}, out.processed[2])
+}
+
+func TestNewAggregatingConsumer_Validation(t *testing.T) {
+	_, err := NewAggregatingConsumer(&mockConsumer{}, AggregateConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// pipeAllocsPerItem — бюджет аллокаций Pipe на элемент сверх фиксированных затрат на запуск.
+// Рост buf при накоплении амортизирован, поэтому на элемент приходятся сотые доли аллокации.
+const pipeAllocsPerItem = 0.01
+
+// staticProducer отдаёт заранее построенные батчи и ничего не записывает, чтобы не добавлять собственных аллокаций к замеру.
+type staticProducer struct {
+	batches [][]any
+	next    int
+}
+
+func newStaticProducer(batches, size int) *staticProducer {
+	p := &staticProducer{batches: make([][]any, batches)}
+	for i := range p.batches {
+		p.batches[i] = make([]any, size)
+		for j := range p.batches[i] {
+			p.batches[i][j] = true // Непустой интерфейс без боксинга
+		}
+	}
+	return p
+}
+
+func (p *staticProducer) Next() (items []any, cookie int, err error) {
+	if p.next == len(p.batches) {
+		return nil, 0, io.EOF
+	}
+	p.next++
+	return p.batches[p.next-1], p.next, nil
+}
+
+func (p *staticProducer) Commit(int) error { return nil }
+
+// rewind готовит источник к повторному прогону.
+func (p *staticProducer) rewind() { p.next = 0 }
+
+type discardConsumer struct{}
+
+func (discardConsumer) Process([]any) error { return nil }
+
+// TestPipe_Allocs_AccumulationPerItem проверяет, что накопление и коммит не выделяют память на каждый элемент или батч:
+// разница аллокаций между коротким и длинным прогоном, делённая на разницу в элементах, укладывается в бюджет.
+func TestPipe_Allocs_AccumulationPerItem(t *testing.T) {
+	if testutil.RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	for _, size := range []int{1, 16} {
+		run := func(batches int) float64 {
+			p := newStaticProducer(batches, size)
+			return testing.AllocsPerRun(5, func() {
+				p.rewind()
+				if err := Pipe(p, discardConsumer{}); err != io.EOF {
+					t.Fatal(err)
+				}
+			})
+		}
+		short, long := 1000, 16000
+		perItem := (run(long) - run(short)) / float64((long-short)*size)
+		if perItem > pipeAllocsPerItem {
+			t.Errorf("батчи по %d: %.4f аллокаций на элемент, бюджет %v", size, perItem, pipeAllocsPerItem)
+		}
+	}
+}
+
+// BenchmarkPipe_Accumulation — полный прогон Pipe по одноэлементным батчам. Падает, если бюджет на элемент превышен.
+// Запуск: go test -run XXX -bench Pipe_Accumulation
+func BenchmarkPipe_Accumulation(b *testing.B) {
+	const items = 4 * MaxItems
+	p := newStaticProducer(items, 1)
+	b.ResetTimer()
+	defer testutil.BenchAllocBudget(b, 100+pipeAllocsPerItem*items)()
+
+	for range b.N {
+		p.rewind()
+		if err := Pipe(p, discardConsumer{}); err != io.EOF {
+			b.Fatal(err)
+		}
+	}
+}
+
+// intProducer — staticProducer для PipeOf[int].
+type intProducer struct {
+	batches [][]int
+	next    int
+}
+
+func (p *intProducer) Next() (items []int, cookie int, err error) {
+	if p.next == len(p.batches) {
+		return nil, 0, io.EOF
+	}
+	p.next++
+	return p.batches[p.next-1], p.next, nil
+}
+
+func (p *intProducer) Commit(int) error { return nil }
+
+type discardInts struct{}
+
+func (discardInts) Process([]int) error { return nil }
+
+// TestPipeOf_Allocs_AccumulationPerItem — тот же бюджет для типизированного Pipe: элементы int
+// не упаковываются в any ни при накоплении, ни при передаче в Process.
+func TestPipeOf_Allocs_AccumulationPerItem(t *testing.T) {
+	if testutil.RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	run := func(batches int) float64 {
+		p := &intProducer{batches: make([][]int, batches)}
+		for i := range p.batches {
+			p.batches[i] = []int{1000 + i} // Вне кэша малых чисел: упаковка в any выделила бы память
+		}
+		return testing.AllocsPerRun(5, func() {
+			p.next = 0
+			if err := PipeOf[int](p, discardInts{}); err != io.EOF {
+				t.Fatal(err)
+			}
+		})
+	}
+	short, long := 1000, 16000
+	perItem := (run(long) - run(short)) / float64(long-short)
+	if perItem > pipeAllocsPerItem {
+		t.Errorf("%.4f аллокаций на элемент, бюджет %v", perItem, pipeAllocsPerItem)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+// Pausable — опциональное расширение Producer для pull-источников с внутренним префетчем (например, Kafka-клиентов).
+// Pipe вызывает Pause, когда очередь воркера заполнена и очередной батч не может быть передан сразу,
+// и Resume, как только батч принят воркером, — чтобы источник перестал выбирать данные в память впрок.
+type Pausable interface {
+	// Pause asks the producer to stop fetching new data in the background
+	Pause()
+	// Resume allows the producer to continue background fetching
+	Resume()
+}
+
+// pauseProducer приостанавливает источник p, если он поддерживает Pausable, и возвращает функцию возобновления.
+func pauseProducer(p any) (resume func()) {
+	pp, ok := p.(Pausable)
+	if !ok {
+		return func() {}
+	}
+	pp.Pause()
+	return pp.Resume
+}
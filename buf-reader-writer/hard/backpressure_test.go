@@ -0,0 +1,180 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/pipetest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+type pausableProducer struct {
+	mockProducer
+
+	onPause func()
+	paused  int
+	resumed int
+}
+
+func (m *pausableProducer) Pause() {
+	m.paused++
+	if m.onPause != nil {
+		m.onPause()
+	}
+}
+
+func (m *pausableProducer) Resume() {
+	m.resumed++
+}
+
+// blockingConsumer блокирует Process до закрытия release.
+type blockingConsumer struct {
+	mockConsumer
+	release chan struct{}
+}
+
+func (m *blockingConsumer) Process(items []any) error {
+	<-m.release
+	return m.mockConsumer.Process(items)
+}
+
+func TestPipe_Backpressure_PausesProducer(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	c := &blockingConsumer{release: make(chan struct{})}
+	var once sync.Once
+	p := &pausableProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{
+				makeItems(0, MaxItems),
+				makeItems(MaxItems, MaxItems),
+				makeItems(2*MaxItems, MaxItems),
+				makeItems(3*MaxItems, MaxItems),
+			},
+			cookies: []int{1, 2, 3, 4},
+			readErr: io.EOF,
+		},
+		// Отпускаем потребителя только после сигнала backpressure
+		onPause: func() { once.Do(func() { close(c.release) }) },
+	}
+
+	err := Pipe(p, c)
+	require.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, p.paused, 1, "ожидался хотя бы один вызов Pause")
+	assert.Equal(t, p.paused, p.resumed, "каждый Pause должен сопровождаться Resume")
+	assert.Equal(t, []int{1, 2, 3, 4}, p.committed, "нарушен порядок коммитов")
+}
+
+// lagProducer считает выданные элементы и наибольшее отставание медленного потребителя от источника.
+type lagProducer struct {
+	*pipetest.Producer
+	sc *pipetest.SlowConsumer
+
+	mu              sync.Mutex
+	fetched         int
+	maxLag          int
+	paused          bool
+	pauses, resumes int
+	nextWhilePaused int
+}
+
+func (m *lagProducer) Next() (items []any, cookie int, err error) {
+	items, cookie, err = m.Producer.Next()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused {
+		m.nextWhilePaused++
+	}
+	m.fetched += len(items)
+	m.maxLag = max(m.maxLag, m.fetched-m.sc.Processed())
+	return items, cookie, err
+}
+
+func (m *lagProducer) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+	m.pauses++
+}
+
+func (m *lagProducer) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+	m.resumes++
+}
+
+func (m *lagProducer) Fetched() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fetched
+}
+
+// newLagProducer создаёт источник из n батчей по size элементов.
+func newLagProducer(sc *pipetest.SlowConsumer, n, size int) *lagProducer {
+	batches := make([]pipetest.Batch, n)
+	for i := range batches {
+		batches[i] = pipetest.Batch{Items: makeItems(i*size, size), Cookie: i}
+	}
+	return &lagProducer{Producer: pipetest.NewProducer(batches...), sc: sc}
+}
+
+// maxBufferedItems — сколько элементов Pipe может держать сверх обработанных: батч в работе у воркера,
+// батч в очереди воркера, накапливаемый буфер и только что полученный из Next батч.
+func maxBufferedItems(batchSize int) int {
+	return 3*MaxItems + batchSize
+}
+
+func TestPipe_SlowConsumer_BufferingBounded(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	const batches, size = 40, MaxItems/2 + 1
+	sc := pipetest.NewSlowConsumer(nil, pipetest.SlowConfig{Delay: time.Millisecond, StallEvery: 4, Stall: 10 * time.Millisecond})
+	p := newLagProducer(sc, batches, size)
+
+	err := Pipe(p, sc)
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, batches*size, sc.Processed())
+	assert.LessOrEqual(t, p.maxLag, maxBufferedItems(size), "Pipe держит в памяти больше батчей, чем допускает очередь воркера")
+	assert.Greater(t, p.maxLag, MaxItems, "источник должен опережать медленного потребителя в пределах очереди")
+	assert.GreaterOrEqual(t, p.pauses, 1, "при заполненной очереди источник должен получать Pause")
+	assert.Equal(t, p.pauses, p.resumes)
+	assert.Zero(t, p.nextWhilePaused, "Next не должен вызываться, пока источник на паузе")
+}
+
+func TestPipe_SlowConsumer_ProducerBlocksDuringStall(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	const batches, size = 30, MaxItems
+	release := make(chan struct{})
+	sc := pipetest.NewSlowConsumer(nil, pipetest.SlowConfig{
+		StallEvery: 2,
+		Stall:      time.Hour, // Не ждём реально: Sleep ниже держит второй батч до release
+		Sleep: func(d time.Duration) {
+			if d >= time.Hour {
+				<-release
+			}
+		},
+	})
+	p := newLagProducer(sc, batches, size)
+
+	done := make(chan error, 1)
+	go func() { done <- Pipe(p, sc) }()
+
+	// Дожидаемся, пока потребитель завис, а источник перестал выдавать батчи
+	require.Eventually(t, func() bool {
+		before := p.Fetched()
+		time.Sleep(5 * time.Millisecond)
+		return sc.Calls() == 2 && sc.Busy() && p.Fetched() == before
+	}, 2*time.Second, time.Millisecond)
+
+	fetched := p.Fetched()
+	assert.Less(t, fetched, batches*size, "источник не должен быть вычитан целиком, пока потребитель завис")
+	assert.LessOrEqual(t, fetched-sc.Processed(), maxBufferedItems(size))
+
+	close(release)
+	require.ErrorIs(t, <-done, io.EOF)
+	assert.Equal(t, batches*size, sc.Processed())
+}
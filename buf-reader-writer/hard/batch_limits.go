@@ -0,0 +1,104 @@
+package main
+
+import (
+	"time"
+
+	"github.com/zlatoivan/go-advanced/batcher"
+)
+
+// BatchLimit — условие, по которому Pipe отправляет накопленный буфер в Process, не дожидаясь MaxItems.
+// Несколько условий действуют совместно: буфер уходит при срабатывании любого.
+type BatchLimit func(*batchLimits)
+
+// batchLimits — итоговые условия накопления батча; нулевые значения — условие выключено.
+type batchLimits struct {
+	items int           // не больше стольких элементов (в пределах MaxItems)
+	bytes int           // не больше стольких байт по itemSize
+	age   time.Duration // не дольше стольких держать первый элемент буфера
+}
+
+// ByCount ограничивает батч n элементами. Батч одного Next, который сам длиннее n, не дробится.
+func ByCount(n int) BatchLimit {
+	return func(l *batchLimits) {
+		l.items = n
+	}
+}
+
+// ByBytes ограничивает суммарный размер батча n байтами. Размер известен для []byte, string
+// и элементов с методом Size() int; остальные элементы считаются нулевого размера.
+func ByBytes(n int) BatchLimit {
+	return func(l *batchLimits) {
+		l.bytes = n
+	}
+}
+
+// ByAge отправляет буфер, как только его первый элемент ждёт d и дольше. Возраст проверяется
+// после каждого Next по Clock Pipe: заблокированный Next буфер не отправит (для этого есть WithMaxDelay).
+func ByAge(d time.Duration) BatchLimit {
+	return func(l *batchLimits) {
+		l.age = d
+	}
+}
+
+// WithBatchLimits задаёт условия отправки накопленного буфера в дополнение к MaxItems.
+func WithBatchLimits(limits ...BatchLimit) Option {
+	return func(o *options) {
+		for _, limit := range limits {
+			limit(&o.batch)
+		}
+	}
+}
+
+// newAccumulator создаёт накопитель Pipe: MaxItems (или политика WithBatchPolicy) и условия WithBatchLimits.
+func newAccumulator[T any](o options) (*batcher.Batcher[T], error) {
+	maxItems := MaxItems
+	var flush func(cur, incoming batcher.State) bool
+	if o.policy != nil {
+		maxItems = 0
+		flush = o.policy.ShouldFlush
+	}
+	if o.batch.items > 0 && (maxItems == 0 || o.batch.items < maxItems) {
+		maxItems = o.batch.items
+	}
+	var size func(T) int
+	if o.batch.bytes > 0 || o.policy != nil { // Размер нужен только ByBytes и политике: без них элементы не упаковываются в any
+		size = func(it T) int { return itemSize(it) }
+	}
+	return batcher.New(batcher.Config[T]{
+		MaxItems: maxItems,
+		MaxBytes: o.batch.bytes,
+		MaxAge:   o.batch.age,
+		Flush:    flush,
+		Size:     size,
+		Now:      o.clock.Now,
+	})
+}
+
+// metaSpans строит спаны метаданных для порций батча b; metas[i] относится к порции i, nil — без метаданных.
+func metaSpans[T any](b batcher.Batch[T], metas []Meta) []MetaSpan {
+	var spans []MetaSpan
+	for i, meta := range metas {
+		if meta == nil {
+			continue
+		}
+		end := b.Len()
+		if i+1 < len(b.Starts) {
+			end = b.Starts[i+1]
+		}
+		spans = append(spans, MetaSpan{Start: b.Starts[i], End: end, Meta: meta})
+	}
+	return spans
+}
+
+// itemSize возвращает размер элемента для ByBytes.
+func itemSize(it any) int {
+	switch v := it.(type) {
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	case interface{ Size() int }:
+		return v.Size()
+	}
+	return 0
+}
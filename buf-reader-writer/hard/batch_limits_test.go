@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchSizes возвращает длины батчей, переданных в Process.
+func batchSizes(c *mockConsumer) []int {
+	sizes := make([]int, 0, len(c.processed))
+	for _, b := range c.processed {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestPipe_BatchLimits_ByCount(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 3), makeItems(3, 3), makeItems(6, 3), makeItems(9, 10)},
+		cookies: []int{1, 2, 3, 4},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithBatchLimits(ByCount(7)))
+	require.ErrorIs(t, err, io.EOF)
+	// Третий батч уже не помещается в 7; батч длиннее лимита уходит целиком
+	assert.Equal(t, []int{6, 3, 10}, batchSizes(c))
+	assert.Equal(t, []int{1, 2, 3, 4}, p.committed)
+}
+
+func TestPipe_BatchLimits_ByBytes(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{{"aaaa", []byte("bb")}, {"cccc"}, {"dd"}, {42}}, // 42 — размер неизвестен, считается нулевым
+		cookies: []int{1, 2, 3, 4},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithBatchLimits(ByBytes(10)))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, [][]any{{"aaaa", []byte("bb"), "cccc"}, {"dd", 42}}, c.processed)
+}
+
+// tickingProducer сдвигает часы на step при каждом Next — источник, отдающий батч раз в step.
+type tickingProducer struct {
+	*mockProducer
+	clock fakeClock
+	step  time.Duration
+}
+
+func (tp *tickingProducer) Next() ([]any, int, error) {
+	tp.clock.Advance(tp.step)
+	return tp.mockProducer.Next()
+}
+
+func TestPipe_BatchLimits_ByAge(t *testing.T) {
+	mp := &mockProducer{readErr: io.EOF}
+	for i := range 7 {
+		mp.batches = append(mp.batches, makeItems(i, 1))
+		mp.cookies = append(mp.cookies, i)
+	}
+	clock := newFakeClock()
+	p := &tickingProducer{mockProducer: mp, clock: clock, step: time.Second}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithClock(clock), WithBatchLimits(ByAge(2*time.Second), ByCount(100)))
+	require.ErrorIs(t, err, io.EOF)
+	// Буфер начат после первого Next и отправляется, когда ему исполняется 2с — на третьем батче
+	assert.Equal(t, []int{3, 3, 1}, batchSizes(c))
+	assert.Equal(t, mp.cookies, mp.committed)
+}
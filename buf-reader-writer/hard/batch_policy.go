@@ -0,0 +1,65 @@
+package main
+
+import (
+	"time"
+
+	"github.com/zlatoivan/go-advanced/batcher"
+)
+
+// BatchState — размер накопленного буфера или очередной порции: элементы, байты по itemSize
+// (см. ByBytes) и, для буфера, сколько ждёт его первый элемент по Clock Pipe.
+type BatchState = batcher.State
+
+// BatchPolicy решает, когда отправлять накопленный буфер в Process. Pipe спрашивает её перед добавлением
+// каждой порции в непустой буфер: true — буфер уходит, порция начинает новый.
+// Условия WithBatchLimits действуют вместе с политикой.
+type BatchPolicy interface {
+	ShouldFlush(cur, incoming BatchState) bool
+}
+
+// BatchPolicyFunc адаптирует функцию к BatchPolicy.
+type BatchPolicyFunc func(cur, incoming BatchState) bool
+
+func (f BatchPolicyFunc) ShouldFlush(cur, incoming BatchState) bool { return f(cur, incoming) }
+
+// WithBatchPolicy заменяет ограничение MaxItems политикой p: буфер может быть и больше MaxItems,
+// если политика это допускает. nil — политика по умолчанию (CountPolicy(MaxItems)).
+func WithBatchPolicy(p BatchPolicy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// CountPolicy отправляет буфер, если с порцией он превысит n элементов.
+func CountPolicy(n int) BatchPolicy {
+	return BatchPolicyFunc(func(cur, incoming BatchState) bool {
+		return cur.Items+incoming.Items > n
+	})
+}
+
+// BytesPolicy отправляет буфер, если с порцией он превысит n байт по itemSize.
+func BytesPolicy(n int) BatchPolicy {
+	return BatchPolicyFunc(func(cur, incoming BatchState) bool {
+		return cur.Bytes+incoming.Bytes > n
+	})
+}
+
+// AgePolicy отправляет буфер, первый элемент которого ждёт d и дольше, прежде чем добавить к нему порцию.
+// Пока Pipe ждёт в Next, политику не спрашивают: для этого есть WithMaxDelay.
+func AgePolicy(d time.Duration) BatchPolicy {
+	return BatchPolicyFunc(func(cur, _ BatchState) bool {
+		return cur.Age >= d
+	})
+}
+
+// AnyPolicy отправляет буфер, если этого требует хотя бы одна из политик.
+func AnyPolicy(policies ...BatchPolicy) BatchPolicy {
+	return BatchPolicyFunc(func(cur, incoming BatchState) bool {
+		for _, p := range policies {
+			if p.ShouldFlush(cur, incoming) {
+				return true
+			}
+		}
+		return false
+	})
+}
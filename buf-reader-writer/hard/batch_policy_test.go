@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipe_BatchPolicy_ReplacesMaxItems(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 6000), makeItems(6000, 6000), makeItems(12000, 6000)},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithBatchPolicy(CountPolicy(15000)))
+	require.ErrorIs(t, err, io.EOF)
+	// Без политики каждый батч ушёл бы отдельно: 12000 > MaxItems
+	assert.Equal(t, []int{12000, 6000}, batchSizes(c))
+	assert.Equal(t, []int{1, 2, 3}, p.committed)
+}
+
+func TestPipe_BatchPolicy_Bytes(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{{"aaaa", []byte("bb")}, {"cccc"}, {"dd"}, {42}},
+		cookies: []int{1, 2, 3, 4},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithBatchPolicy(BytesPolicy(10)))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, [][]any{{"aaaa", []byte("bb"), "cccc"}, {"dd", 42}}, c.processed)
+}
+
+func TestPipe_BatchPolicy_Age(t *testing.T) {
+	mp := &mockProducer{readErr: io.EOF}
+	for i := range 7 {
+		mp.batches = append(mp.batches, makeItems(i, 1))
+		mp.cookies = append(mp.cookies, i)
+	}
+	clock := newFakeClock()
+	p := &tickingProducer{mockProducer: mp, clock: clock, step: time.Second}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithClock(clock), WithBatchPolicy(AgePolicy(2*time.Second)))
+	require.ErrorIs(t, err, io.EOF)
+	// В отличие от ByAge, политика решает до добавления порции: буфер возрастом 2с уходит без неё
+	assert.Equal(t, []int{2, 2, 2, 1}, batchSizes(c))
+	assert.Equal(t, mp.cookies, mp.committed)
+}
+
+func TestPipe_BatchPolicy_AnyAndLimits(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{{"aaaaaa"}, {"b"}, {"c"}, {"d"}, {"eeeeeeeeee"}},
+		cookies: []int{1, 2, 3, 4, 5},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c,
+		WithBatchPolicy(AnyPolicy(BytesPolicy(8), CountPolicy(10))),
+		WithBatchLimits(ByCount(2)), // Условия WithBatchLimits действуют вместе с политикой
+	)
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, [][]any{{"aaaaaa", "b"}, {"c", "d"}, {"eeeeeeeeee"}}, c.processed)
+}
+
+func TestPipe_BatchPolicy_Func(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 1), makeItems(1, 1), makeItems(2, 1)},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+	var seen []BatchState
+
+	err := Pipe(p, c, WithClock(newFakeClock()), WithBatchPolicy(BatchPolicyFunc(func(cur, incoming BatchState) bool {
+		seen = append(seen, cur, incoming)
+		return false
+	})))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []int{3}, batchSizes(c))
+	assert.Equal(t, []BatchState{{Items: 1}, {Items: 1}, {Items: 2}, {Items: 1}}, seen)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// BlockProducer нарезает байтовый поток (файл, MultiReader, сетевое соединение) на блоки фиксированного размера.
+// Каждый вызов Next возвращает один блок []byte как единственный элемент, cookie — порядковый номер блока.
+// Последний блок может быть короче blockSize.
+type BlockProducer struct {
+	r         io.Reader
+	blockSize int
+	next      int          // номер следующего блока
+	committed atomic.Int64 // число подтверждённых блоков
+}
+
+// Проверка, что BlockProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*BlockProducer)(nil)
+
+// NewBlockProducer создаёт Producer, читающий r блоками по blockSize байт. blockSize должен быть положительным:
+// при нулевом Next возвращал бы пустые блоки бесконечно, не доходя до io.EOF.
+func NewBlockProducer(r io.Reader, blockSize int) (*BlockProducer, error) {
+	if blockSize <= 0 {
+		return nil, fmt.Errorf("block producer: block size must be positive, got %d", blockSize)
+	}
+	return &BlockProducer{r: r, blockSize: blockSize}, nil
+}
+
+// Next читает очередной блок. По окончании потока возвращает io.EOF.
+func (bp *BlockProducer) Next() (items []any, cookie int, err error) {
+	block := make([]byte, bp.blockSize)
+	n, err := io.ReadFull(bp.r, block)
+	switch {
+	case errors.Is(err, io.EOF): // Поток закончился ровно на границе блока
+		return nil, 0, io.EOF
+	case errors.Is(err, io.ErrUnexpectedEOF): // Последний неполный блок
+	case err != nil:
+		return nil, 0, fmt.Errorf("read block %d: %w", bp.next, err)
+	}
+
+	cookie = bp.next
+	bp.next++
+	return []any{block[:n]}, cookie, nil
+}
+
+// Commit подтверждает блок. Pipe коммитит cookies строго по порядку, поэтому номер должен совпадать с ожидаемым.
+// Повторный Commit уже подтверждённого номера (повторная доставка) ничего не делает.
+func (bp *BlockProducer) Commit(cookie int) error {
+	expected := bp.committed.Load()
+	if int64(cookie) < expected {
+		return nil
+	}
+	if int64(cookie) != expected {
+		return fmt.Errorf("commit out of order: got block %d, expected %d", cookie, expected)
+	}
+	bp.committed.Add(1)
+	return nil
+}
+
+// Committed возвращает число подтверждённых блоков. Позиция для возобновления — Committed()*blockSize.
+func (bp *BlockProducer) Committed() int {
+	return int(bp.committed.Load())
+}
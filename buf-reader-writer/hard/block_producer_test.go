@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockProducer_SplitsStream(t *testing.T) {
+	bp, err := NewBlockProducer(strings.NewReader("abcdefgh"), 3)
+	require.NoError(t, err)
+	c := &mockConsumer{}
+
+	err = Pipe(bp, c)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, c.processed, 1)
+
+	var got []string
+	for _, item := range c.processed[0] {
+		got = append(got, string(item.([]byte)))
+	}
+	assert.Equal(t, []string{"abc", "def", "gh"}, got)
+	assert.Equal(t, 3, bp.Committed())
+}
+
+func TestBlockProducer_ExactMultiple(t *testing.T) {
+	bp, err := NewBlockProducer(bytes.NewReader([]byte("abcdef")), 3)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		items, cookie, err := bp.Next()
+		require.NoError(t, err)
+		assert.Equal(t, i, cookie)
+		assert.Len(t, items[0], 3)
+	}
+	_, _, err = bp.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestBlockProducer_Errors(t *testing.T) {
+	readErr := errors.New("disk failure")
+	bp, err := NewBlockProducer(iotest.ErrReader(readErr), 3)
+	require.NoError(t, err)
+	_, _, err = bp.Next()
+	require.ErrorIs(t, err, readErr)
+
+	require.Error(t, bp.Commit(5), "коммит не по порядку должен давать ошибку")
+}
+
+func TestBlockProducer_RepeatedCommitIsNoop(t *testing.T) {
+	bp, err := NewBlockProducer(strings.NewReader("abcdef"), 3)
+	require.NoError(t, err)
+	require.NoError(t, bp.Commit(0))
+	require.NoError(t, bp.Commit(0), "повторный коммит уже подтверждённого блока")
+	require.NoError(t, bp.Commit(1))
+	assert.Equal(t, 2, bp.Committed())
+}
+
+func TestNewBlockProducer_RejectsNonPositiveSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		_, err := NewBlockProducer(strings.NewReader("abc"), size)
+		assert.Error(t, err, "размер блока %d", size)
+	}
+}
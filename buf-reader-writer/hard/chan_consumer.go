@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedItemType возвращается адаптерами, если элемент батча не приводится к ожидаемому типу.
+var ErrUnexpectedItemType = errors.New("unexpected item type")
+
+// ChanConsumer — Consumer, передающий элементы в канал, чтобы продолжить обработку в обычном горутинном пайплайне.
+// Работает в одном из двух режимов: весь батч одним срезом []T или поэлементная отправка (fan-out по T).
+// Отправка блокируется, пока читатель не заберёт данные или не будет отменён ctx.
+type ChanConsumer[T any] struct {
+	ctx     context.Context
+	batches chan<- []T // режим батчей; nil — поэлементный режим
+	items   chan<- T   // поэлементный режим
+}
+
+// NewChanConsumer создаёт ChanConsumer, отправляющий каждый батч в out целиком (новым срезом []T).
+func NewChanConsumer[T any](ctx context.Context, out chan<- []T) *ChanConsumer[T] {
+	return &ChanConsumer[T]{ctx: ctx, batches: out}
+}
+
+// NewItemChanConsumer создаёт ChanConsumer, отправляющий элементы в out по одному.
+func NewItemChanConsumer[T any](ctx context.Context, out chan<- T) *ChanConsumer[T] {
+	return &ChanConsumer[T]{ctx: ctx, items: out}
+}
+
+// Process приводит элементы к T и отправляет их в канал. Исходный срез items не сохраняется.
+// Если ctx отменён посреди поэлементной отправки, часть элементов может быть уже доставлена.
+func (cc *ChanConsumer[T]) Process(items []any) error {
+	typed := make([]T, len(items))
+	for i, item := range items {
+		v, ok := item.(T)
+		if !ok {
+			return fmt.Errorf("%w: item %d is %T", ErrUnexpectedItemType, i, item)
+		}
+		typed[i] = v
+	}
+
+	if cc.batches != nil {
+		return send(cc.ctx, cc.batches, typed)
+	}
+	for _, v := range typed {
+		err := send(cc.ctx, cc.items, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send отправляет v в канал ch с учётом отмены ctx.
+func send[V any](ctx context.Context, ch chan<- V, v V) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case ch <- v:
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanConsumer_Batches(t *testing.T) {
+	out := make(chan []int, 2)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 2), makeItems(2, MaxItems)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(p, NewChanConsumer(context.Background(), out))
+	require.Equal(t, io.EOF, err)
+	close(out)
+
+	var got [][]int
+	for b := range out {
+		got = append(got, b)
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, []int{0, 1}, got[0])
+	assert.Len(t, got[1], MaxItems)
+}
+
+func TestChanConsumer_Items(t *testing.T) {
+	out := make(chan int, 3)
+	c := NewItemChanConsumer(context.Background(), out)
+
+	require.NoError(t, c.Process(makeItems(5, 3)))
+	close(out)
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{5, 6, 7}, got)
+}
+
+func TestChanConsumer_WrongType(t *testing.T) {
+	c := NewItemChanConsumer(context.Background(), make(chan string, 1))
+
+	err := c.Process([]any{"ok", 42})
+	require.ErrorIs(t, err, ErrUnexpectedItemType)
+}
+
+func TestChanConsumer_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := NewChanConsumer(ctx, make(chan []int)) // никто не читает
+
+	err := c.Process(makeItems(0, 1))
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Checkpointer сохраняет подтверждённую позицию источника между перезапусками процесса.
+type Checkpointer interface {
+	// Load возвращает сохранённую позицию; если её ещё нет — 0 и nil.
+	Load() (int64, error)
+	// Save атомарно сохраняет позицию.
+	Save(pos int64) error
+}
+
+// FileCheckpointer хранит позицию в текстовом файле. Запись атомарна: временный файл + rename.
+type FileCheckpointer struct {
+	path string
+}
+
+// Проверка, что FileCheckpointer удовлетворяет интерфейсу Checkpointer
+var _ Checkpointer = (*FileCheckpointer)(nil)
+
+// NewFileCheckpointer создаёт Checkpointer поверх файла path.
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{path: path}
+}
+
+func (fc *FileCheckpointer) Load() (int64, error) {
+	data, err := os.ReadFile(fc.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read checkpoint: %w", err)
+	}
+	pos, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse checkpoint: %w", err)
+	}
+	return pos, nil
+}
+
+func (fc *FileCheckpointer) Save(pos int64) error {
+	tmp := fc.path + ".tmp"
+	err := os.WriteFile(tmp, []byte(strconv.FormatInt(pos, 10)), 0o644)
+	if err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	err = os.Rename(tmp, fc.path)
+	if err != nil {
+		return fmt.Errorf("rename checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+)
+
+// ChunkProducer выдаёт чанки objstore.Chunker как элементы Pipe: каждый вызов Next возвращает один objstore.Chunk,
+// cookie — номер чанка. Вместе с потребителем, пишущим в ChunkStore, даёт дедуплицирующий бэкап через Pipe.
+type ChunkProducer struct {
+	chunker   *objstore.Chunker
+	committed atomic.Int64 // число подтверждённых чанков
+}
+
+// Проверка, что ChunkProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*ChunkProducer)(nil)
+
+// NewChunkProducer создаёт Producer поверх нарезчика chunker.
+func NewChunkProducer(chunker *objstore.Chunker) *ChunkProducer {
+	return &ChunkProducer{chunker: chunker}
+}
+
+// Next возвращает очередной чанк. По окончании потока возвращает io.EOF.
+func (cp *ChunkProducer) Next() (items []any, cookie int, err error) {
+	chunk, err := cp.chunker.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return []any{chunk}, chunk.Index, nil
+}
+
+// Commit подтверждает чанк. Как и у BlockProducer, номера коммитятся строго по порядку, повторный коммит ничего не делает.
+func (cp *ChunkProducer) Commit(cookie int) error {
+	expected := cp.committed.Load()
+	if int64(cookie) < expected {
+		return nil
+	}
+	if int64(cookie) != expected {
+		return fmt.Errorf("commit out of order: got chunk %d, expected %d", cookie, expected)
+	}
+	cp.committed.Add(1)
+	return nil
+}
+
+// Committed возвращает число подтверждённых чанков.
+func (cp *ChunkProducer) Committed() int {
+	return int(cp.committed.Load())
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore/objstoretest"
+)
+
+// chunkStoreConsumer сохраняет чанки из Pipe в ChunkStore и запоминает их порядок.
+type chunkStoreConsumer struct {
+	store objstore.ChunkStore
+	refs  []objstore.ChunkRef
+}
+
+func (c *chunkStoreConsumer) Process(items []any) error {
+	for _, item := range items {
+		chunk := item.(objstore.Chunk)
+		err := c.store.PutChunk(context.Background(), chunk.Digest, chunk.Data)
+		if err != nil {
+			return err
+		}
+		c.refs = append(c.refs, objstore.ChunkRef{Digest: chunk.Digest, Size: int64(len(chunk.Data))})
+	}
+	return nil
+}
+
+func TestChunkProducer_BackupThroughPipe(t *testing.T) {
+	data := strings.Repeat("backup-block", 100)
+	chunker, err := objstore.NewChunker(strings.NewReader(data), objstore.ChunkerConfig{Size: 64})
+	require.NoError(t, err)
+	cp := NewChunkProducer(chunker)
+	store := objstoretest.New(objstoretest.Config{Digest: objstore.SHA256Digest})
+	c := &chunkStoreConsumer{store: store}
+
+	require.Equal(t, io.EOF, Pipe(cp, c))
+	assert.Equal(t, len(c.refs), cp.Committed())
+
+	var restored bytes.Buffer
+	for _, ref := range c.refs {
+		chunk, err := store.GetChunk(context.Background(), ref.Digest)
+		require.NoError(t, err)
+		restored.Write(chunk)
+	}
+	assert.Equal(t, data, restored.String())
+}
+
+func TestChunkProducer_Commit(t *testing.T) {
+	chunker, err := objstore.NewChunker(strings.NewReader("abcdef"), objstore.ChunkerConfig{Size: 3})
+	require.NoError(t, err)
+	cp := NewChunkProducer(chunker)
+
+	items, cookie, err := cp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 0, cookie)
+	assert.Equal(t, "abc", string(items[0].(objstore.Chunk).Data))
+
+	require.Error(t, cp.Commit(1), "коммит не по порядку должен давать ошибку")
+	require.NoError(t, cp.Commit(0))
+	require.NoError(t, cp.Commit(0), "повторный коммит уже подтверждённого чанка")
+	assert.Equal(t, 1, cp.Committed())
+}
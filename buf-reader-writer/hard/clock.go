@@ -0,0 +1,60 @@
+package main
+
+import "time"
+
+// Clock — источник времени для всех time-based механизмов Pipe (флеш по таймеру, таймауты, backoff).
+// В тестах подменяется фейковой реализацией, чтобы не ждать реальное время.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer — минимальный интерфейс таймера, совместимый с *time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// WithClock задаёт источник времени для Pipe. По умолчанию используется системное время.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		if clock == nil {
+			clock = systemClock{}
+		}
+		o.clock = clock
+	}
+}
+
+// systemClock — реализация Clock поверх пакета time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+// systemTimer адаптирует *time.Timer к интерфейсу Timer.
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (s systemTimer) C() <-chan time.Time { return s.t.C }
+
+func (s systemTimer) Stop() bool { return s.t.Stop() }
+
+func (s systemTimer) Reset(d time.Duration) bool { return s.t.Reset(d) }
+
+// sleep ждёт d по часам clock. Возвращает false, если ожидание прервано закрытием done.
+func sleep(clock Clock, d time.Duration, done <-chan struct{}) bool {
+	if d <= 0 {
+		return true
+	}
+	t := clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/testclock"
+)
+
+// fakeClock адаптирует *testclock.Clock к интерфейсу Clock.
+type fakeClock struct {
+	*testclock.Clock
+}
+
+func (c fakeClock) NewTimer(d time.Duration) Timer { return c.Clock.NewTimer(d) }
+
+// newFakeClock создаёт часы, которые двигает только тест.
+func newFakeClock() fakeClock {
+	return fakeClock{testclock.New(time.Unix(0, 0))}
+}
+
+// newAutoClock создаёт часы, чьи таймеры срабатывают сразу, сдвигая время на свою длительность.
+func newAutoClock() fakeClock {
+	return fakeClock{testclock.NewAuto(time.Unix(0, 0))}
+}
+
+func TestSleep_FakeClock(t *testing.T) {
+	clock := newFakeClock()
+	done := make(chan struct{})
+	res := make(chan bool, 1)
+	go func() { res <- sleep(clock, time.Hour, done) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.True(t, <-res, "ожидание завершается по таймеру")
+
+	go func() { res <- sleep(clock, time.Hour, done) }()
+	clock.BlockUntil(1)
+	close(done)
+	assert.False(t, <-res, "ожидание прерывается закрытием done")
+	assert.Zero(t, clock.Pending(), "прерванный sleep останавливает таймер")
+	assert.True(t, sleep(clock, 0, done), "нулевая пауза не заводит таймер")
+}
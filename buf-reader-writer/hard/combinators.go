@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// cookieRoute — исходный источник и его собственный cookie.
+type cookieRoute struct {
+	src    int
+	cookie int
+}
+
+// cookieRouter выдаёт составные cookies и помнит, к какому источнику они относятся.
+type cookieRouter struct {
+	mu     sync.Mutex
+	next   int
+	routes map[int]cookieRoute
+}
+
+func newCookieRouter() *cookieRouter {
+	return &cookieRouter{routes: make(map[int]cookieRoute)}
+}
+
+// add регистрирует cookie источника src и возвращает составной cookie.
+func (r *cookieRouter) add(src, cookie int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.next
+	r.next++
+	r.routes[id] = cookieRoute{src: src, cookie: cookie}
+	return id
+}
+
+// take возвращает маршрут составного cookie и забывает его.
+func (r *cookieRouter) take(cookie int) (cookieRoute, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rt, ok := r.routes[cookie]
+	if !ok {
+		return cookieRoute{}, fmt.Errorf("unknown cookie %d", cookie)
+	}
+	delete(r.routes, cookie)
+	return rt, nil
+}
+
+// routeCommit коммитит cookie в исходном источнике.
+func routeCommit(r *cookieRouter, sources []Producer, cookie int) error {
+	rt, err := r.take(cookie)
+	if err != nil {
+		return err
+	}
+	return sources[rt.src].Commit(rt.cookie)
+}
+
+// routeNack передаёт Nack исходному источнику, если он поддерживает Nacker.
+func routeNack(r *cookieRouter, sources []Producer, cookie int) error {
+	rt, err := r.take(cookie)
+	if err != nil {
+		return err
+	}
+	if n, ok := sources[rt.src].(Nacker); ok {
+		return n.Nack(rt.cookie)
+	}
+	return nil
+}
+
+// ChainProducer читает источники по очереди: первый до io.EOF, затем второй и т.д.
+type ChainProducer struct {
+	sources []Producer
+	cur     int
+	router  *cookieRouter
+}
+
+// Проверка, что ChainProducer удовлетворяет интерфейсам Producer и Nacker
+var (
+	_ Producer = (*ChainProducer)(nil)
+	_ Nacker   = (*ChainProducer)(nil)
+)
+
+// Chain объединяет источники последовательно. Commit и Nack маршрутизируются в исходный источник.
+func Chain(sources ...Producer) *ChainProducer {
+	return &ChainProducer{sources: sources, router: newCookieRouter()}
+}
+
+func (cp *ChainProducer) Next() (items []any, cookie int, err error) {
+	for cp.cur < len(cp.sources) {
+		items, cookie, err = cp.sources[cp.cur].Next()
+		if errors.Is(err, io.EOF) {
+			cp.cur++
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("source %d: %w", cp.cur, err)
+		}
+		return items, cp.router.add(cp.cur, cookie), nil
+	}
+	return nil, 0, io.EOF
+}
+
+func (cp *ChainProducer) Commit(cookie int) error {
+	return routeCommit(cp.router, cp.sources, cookie)
+}
+
+func (cp *ChainProducer) Nack(cookie int) error {
+	return routeNack(cp.router, cp.sources, cookie)
+}
+
+// mergeResult — результат Next одного из источников Merge.
+type mergeResult struct {
+	src    int
+	items  []any
+	cookie int
+	err    error
+}
+
+// MergeProducer читает все источники конкурентно и чередует их пачки.
+// Каждый источник опрашивается своей горутиной, которая ждёт, пока её пачку заберут;
+// ожидающие отправители обслуживаются каналом в порядке очереди, поэтому быстрый источник не вытесняет медленные.
+type MergeProducer struct {
+	sources []Producer
+	router  *cookieRouter
+	resCh   chan mergeResult
+	stopCh  chan struct{}
+	once    sync.Once
+	active  int // число источников, ещё не вернувших ошибку или io.EOF
+}
+
+// Проверка, что MergeProducer удовлетворяет интерфейсам Producer и Nacker
+var (
+	_ Producer = (*MergeProducer)(nil)
+	_ Nacker   = (*MergeProducer)(nil)
+)
+
+// Merge объединяет источники конкурентно. Горутины опроса стартуют сразу; остановить их досрочно — Close.
+func Merge(sources ...Producer) *MergeProducer {
+	mp := &MergeProducer{
+		sources: sources,
+		router:  newCookieRouter(),
+		resCh:   make(chan mergeResult),
+		stopCh:  make(chan struct{}),
+		active:  len(sources),
+	}
+	for i, src := range sources {
+		go mp.poll(i, src)
+	}
+	return mp
+}
+
+// poll — горутина опроса одного источника. Завершается после ошибки/EOF источника или Close.
+func (mp *MergeProducer) poll(idx int, src Producer) {
+	for {
+		items, cookie, err := src.Next()
+		select {
+		case <-mp.stopCh:
+			return
+		case mp.resCh <- mergeResult{src: idx, items: items, cookie: cookie, err: err}:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Next возвращает пачку любого готового источника. io.EOF — когда все источники исчерпаны;
+// другая ошибка источника возвращается сразу, а сам он считается завершённым. После Close — io.ErrClosedPipe.
+func (mp *MergeProducer) Next() (items []any, cookie int, err error) {
+	for mp.active > 0 {
+		var res mergeResult
+		select {
+		case <-mp.stopCh:
+			return nil, 0, io.ErrClosedPipe
+		case res = <-mp.resCh:
+		}
+		if res.err != nil { // Горутина опроса источника завершилась
+			mp.active--
+		}
+		if errors.Is(res.err, io.EOF) {
+			continue
+		}
+		if res.err != nil {
+			return nil, 0, fmt.Errorf("source %d: %w", res.src, res.err)
+		}
+		return res.items, mp.router.add(res.src, res.cookie), nil
+	}
+	return nil, 0, io.EOF
+}
+
+func (mp *MergeProducer) Commit(cookie int) error {
+	return routeCommit(mp.router, mp.sources, cookie)
+}
+
+func (mp *MergeProducer) Nack(cookie int) error {
+	return routeNack(mp.router, mp.sources, cookie)
+}
+
+// Close останавливает горутины опроса. Горутина, заблокированная внутри Next источника, завершится после его возврата.
+func (mp *MergeProducer) Close() error {
+	mp.once.Do(func() { close(mp.stopCh) })
+	return nil
+}
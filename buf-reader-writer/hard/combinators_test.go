@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedProducer делает mockProducer безопасным для вызова Next и Commit из разных горутин.
+type lockedProducer struct {
+	mu sync.Mutex
+	mockProducer
+}
+
+func (m *lockedProducer) Next() ([]any, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockProducer.Next()
+}
+
+func (m *lockedProducer) Commit(cookie int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockProducer.Commit(cookie)
+}
+
+func TestChain_ConsumesSequentiallyAndRoutesCommits(t *testing.T) {
+	p1 := &mockProducer{batches: [][]any{{"a1"}, {"a2"}}, cookies: []int{10, 11}, readErr: io.EOF}
+	p2 := &mockProducer{batches: [][]any{{"b1"}}, cookies: []int{10}, readErr: io.EOF}
+	c := &mockConsumer{}
+
+	err := Pipe(Chain(p1, p2), c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{{"a1", "a2", "b1"}}, c.processed)
+	assert.Equal(t, []int{10, 11}, p1.committed)
+	assert.Equal(t, []int{10}, p2.committed)
+}
+
+func TestChain_SourceError(t *testing.T) {
+	readErr := errors.New("broken")
+	p1 := &mockProducer{readErr: io.EOF}
+	p2 := &mockProducer{readErr: readErr}
+
+	_, _, err := Chain(p1, p2).Next()
+	require.ErrorIs(t, err, readErr)
+	assert.Contains(t, err.Error(), "source 1")
+}
+
+func TestMerge_InterleavesAndRoutesCommits(t *testing.T) {
+	p1 := &lockedProducer{mockProducer: mockProducer{batches: [][]any{{"a1"}, {"a2"}}, cookies: []int{1, 2}, readErr: io.EOF}}
+	p2 := &lockedProducer{mockProducer: mockProducer{batches: [][]any{{"b1"}, {"b2"}}, cookies: []int{1, 2}, readErr: io.EOF}}
+	c := &mockConsumer{}
+	mp := Merge(p1, p2)
+	defer mp.Close()
+
+	err := Pipe(mp, c)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, c.processed, 1)
+
+	got := make([]string, 0, 4)
+	for _, item := range c.processed[0] {
+		got = append(got, item.(string))
+	}
+	sort.Strings(got)
+	assert.Equal(t, []string{"a1", "a2", "b1", "b2"}, got)
+	assert.Equal(t, []int{1, 2}, p1.committed, "порядок коммитов внутри источника сохраняется")
+	assert.Equal(t, []int{1, 2}, p2.committed)
+}
+
+func TestMerge_NackRouted(t *testing.T) {
+	p1 := &nackProducer{mockProducer: mockProducer{batches: [][]any{{"a"}}, cookies: []int{7}, readErr: io.EOF}}
+	mp := Merge(p1)
+	defer mp.Close()
+
+	err := Pipe(mp, &mockConsumer{procErr: errors.New("process failed")})
+	require.Error(t, err)
+	assert.Equal(t, []int{7}, p1.nacked)
+}
+
+func TestMerge_SourceErrorDoesNotHang(t *testing.T) {
+	readErr := errors.New("broken")
+	p1 := &lockedProducer{mockProducer: mockProducer{readErr: readErr}}
+	p2 := &lockedProducer{mockProducer: mockProducer{batches: [][]any{{"b1"}}, cookies: []int{1}, readErr: io.EOF}}
+	mp := Merge(p1, p2)
+	defer mp.Close()
+
+	var errs []error
+	for {
+		_, _, err := mp.Next()
+		if err == nil {
+			continue
+		}
+		errs = append(errs, err)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	require.Len(t, errs, 2, "ошибка источника и затем io.EOF, когда остальные исчерпаны")
+	assert.ErrorIs(t, errs[0], readErr)
+}
+
+func TestMerge_NextAfterClose(t *testing.T) {
+	blocked := &blockingProducer{release: make(chan struct{})}
+	defer close(blocked.release)
+	mp := Merge(blocked)
+	require.NoError(t, mp.Close())
+
+	_, _, err := mp.Next()
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+// blockingProducer блокирует Next, пока не закрыт release.
+type blockingProducer struct {
+	mockProducer
+	release chan struct{}
+}
+
+func (b *blockingProducer) Next() ([]any, int, error) {
+	<-b.release
+	return nil, 0, io.EOF
+}
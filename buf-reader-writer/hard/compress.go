@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compressor сжимает и распаковывает самостоятельные блоки данных.
+// Реализация по умолчанию — GzipCompressor; zstd и другие алгоритмы подключаются своей реализацией интерфейса.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor сжимает каждый блок отдельным gzip-потоком.
+type GzipCompressor struct {
+	Level int // уровень сжатия gzip; 0 — gzip.DefaultCompression
+}
+
+func (g GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = zw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// CompressingConsumer — стадия сжатия между EncodingConsumer и терминальным ByteConsumer:
+// каждый чанк сжимается независимо, поэтому приёмнику не нужно знать об алгоритме сжатия.
+type CompressingConsumer struct {
+	comp Compressor
+	next ByteConsumer
+}
+
+// Проверка, что CompressingConsumer удовлетворяет интерфейсу ByteConsumer
+var _ ByteConsumer = (*CompressingConsumer)(nil)
+
+// NewCompressingConsumer создаёт стадию сжатия поверх next.
+func NewCompressingConsumer(comp Compressor, next ByteConsumer) *CompressingConsumer {
+	return &CompressingConsumer{comp: comp, next: next}
+}
+
+// ProcessChunk сжимает chunk и передаёт результат дальше. Сжатые данные — новый срез, chunk не сохраняется.
+func (cc *CompressingConsumer) ProcessChunk(chunk []byte) error {
+	data, err := cc.comp.Compress(chunk)
+	if err != nil {
+		return fmt.Errorf("compress chunk: %w", err)
+	}
+	return cc.next.ProcessChunk(data)
+}
+
+// decompressingProducer распаковывает элементы источника, записанные через CompressingConsumer.
+// Каждый элемент должен быть []byte со сжатым блоком. Наружу отдаётся через withProducerExtensions.
+type decompressingProducer struct {
+	p    Producer
+	comp Compressor
+}
+
+// NewDecompressingProducer создаёт распаковывающую обёртку над p. Возвращаемый Producer реализует те же
+// Nacker, Pausable и MetaProducer, что и p; Commit, Nack и Pause/Resume пробрасываются без изменений.
+func NewDecompressingProducer(p Producer, comp Compressor) Producer {
+	return withProducerExtensions(&decompressingProducer{p: p, comp: comp}, p)
+}
+
+func (dp *decompressingProducer) Next() (items []any, cookie int, err error) {
+	items, cookie, err = dp.p.Next()
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err = dp.decompress(items, cookie)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, cookie, nil
+}
+
+func (dp *decompressingProducer) NextWithMeta() (items []any, cookie int, meta Meta, err error) {
+	items, cookie, meta, err = nextWithMeta(dp.p)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	items, err = dp.decompress(items, cookie)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return items, cookie, meta, nil
+}
+
+// decompress распаковывает сжатые блоки батча cookie в новый срез.
+func (dp *decompressingProducer) decompress(items []any, cookie int) ([]any, error) {
+	res := make([]any, len(items))
+	for i, item := range items {
+		data, ok := item.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d is %T", ErrUnexpectedItemType, i, item)
+		}
+		var err error
+		res[i], err = dp.comp.Decompress(data)
+		if err != nil {
+			return nil, fmt.Errorf("decompress item %d of cookie %d: %w", i, cookie, err)
+		}
+	}
+	return res, nil
+}
+
+func (dp *decompressingProducer) Commit(cookie int) error {
+	return dp.p.Commit(cookie)
+}
+
+func (dp *decompressingProducer) Nack(cookie int) error {
+	return dp.p.(Nacker).Nack(cookie)
+}
+
+func (dp *decompressingProducer) Pause()  { dp.p.(Pausable).Pause() }
+func (dp *decompressingProducer) Resume() { dp.p.(Pausable).Resume() }
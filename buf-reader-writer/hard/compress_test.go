@@ -0,0 +1,99 @@
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompression_RoundTrip(t *testing.T) {
+	archive := &mockByteConsumer{}
+	c := NewEncodingConsumer(JSONEncoder{}, NewCompressingConsumer(GzipCompressor{Level: gzip.BestSpeed}, archive), 8)
+
+	err := Pipe(&mockProducer{batches: [][]any{{1, 22, 333}}, cookies: []int{1}, readErr: io.EOF}, c)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, archive.chunks, 2)
+
+	// Читаем архив обратно: каждый сжатый чанк — отдельный элемент
+	var compressed []any
+	for _, chunk := range archive.chunks {
+		compressed = append(compressed, []byte(chunk))
+	}
+	src := &mockProducer{batches: [][]any{compressed}, cookies: []int{7}, readErr: io.EOF}
+	out := &mockConsumer{}
+
+	err = Pipe(NewDecompressingProducer(src, GzipCompressor{}), out)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{{[]byte("1\n22\n"), []byte("333\n")}}, out.processed)
+	assert.Equal(t, []int{7}, src.committed)
+}
+
+func TestCompressingConsumer_ShrinksRepetitiveData(t *testing.T) {
+	bc := &mockByteConsumer{}
+	chunk := []byte(strings.Repeat("abc", 1000))
+
+	require.NoError(t, NewCompressingConsumer(GzipCompressor{}, bc).ProcessChunk(chunk))
+	require.Len(t, bc.chunks, 1)
+	assert.Less(t, len(bc.chunks[0]), len(chunk)/10)
+}
+
+func TestDecompressingProducer_Errors(t *testing.T) {
+	p := &mockProducer{batches: [][]any{{"not bytes"}}, cookies: []int{1}}
+	_, _, err := NewDecompressingProducer(p, GzipCompressor{}).Next()
+	assert.ErrorIs(t, err, ErrUnexpectedItemType)
+
+	p = &mockProducer{batches: [][]any{{[]byte("definitely not gzip data")}}, cookies: []int{1}}
+	_, _, err = NewDecompressingProducer(p, GzipCompressor{}).Next()
+	assert.ErrorIs(t, err, gzip.ErrHeader)
+}
+
+func gzipItem(t *testing.T, s string) []byte {
+	data, err := GzipCompressor{}.Compress([]byte(s))
+	require.NoError(t, err)
+	return data
+}
+
+func TestDecompressingProducer_ExposesOnlyInnerExtensions(t *testing.T) {
+	plain := NewDecompressingProducer(&mockProducer{}, GzipCompressor{})
+	_, isNacker := plain.(Nacker)
+	_, isPausable := plain.(Pausable)
+	_, isMeta := plain.(MetaProducer)
+	assert.False(t, isNacker || isPausable || isMeta, "у обычного источника обёртка не должна добавлять расширений")
+
+	pp := &pausableProducer{}
+	paused := NewDecompressingProducer(pp, GzipCompressor{})
+	require.Implements(t, (*Pausable)(nil), paused)
+	paused.(Pausable).Pause()
+	paused.(Pausable).Resume()
+	assert.Equal(t, 1, pp.paused)
+	assert.Equal(t, 1, pp.resumed)
+}
+
+func TestDecompressingProducer_ForwardsNack(t *testing.T) {
+	p := &nackProducer{mockProducer: mockProducer{batches: [][]any{{gzipItem(t, "a")}}, cookies: []int{1}, readErr: io.EOF}}
+	dp := NewDecompressingProducer(p, GzipCompressor{})
+	require.Implements(t, (*Nacker)(nil), dp)
+
+	err := Pipe(dp, &mockConsumer{procErr: errors.New("boom")})
+	require.Error(t, err)
+	assert.Equal(t, []int{1}, p.nacked)
+}
+
+func TestDecompressingProducer_ForwardsMeta(t *testing.T) {
+	tenant := Meta{"tenant": "a"}
+	p := &metaProducer{
+		mockProducer: mockProducer{batches: [][]any{{gzipItem(t, "a"), gzipItem(t, "b")}}, cookies: []int{1}, readErr: io.EOF},
+		metas:        []Meta{tenant},
+	}
+	c := &metaConsumer{}
+
+	err := Pipe(NewDecompressingProducer(p, GzipCompressor{}), c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]MetaSpan{{{Start: 0, End: 2, Meta: tenant}}}, c.spans, "метаданные должны проходить через обёртку")
+	assert.Equal(t, [][]any{{[]byte("a"), []byte("b")}}, c.processed)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/pipetest"
+)
+
+// conformanceBatches — содержимое сценарных источников в проверках соответствия.
+func conformanceBatches() []pipetest.Batch {
+	return []pipetest.Batch{
+		{Items: []any{"a", "b"}, Cookie: 0},
+		{Items: []any{"c"}, Cookie: 1},
+		{Items: []any{"d", "e", "f"}, Cookie: 2},
+	}
+}
+
+func TestConformance_Producers(t *testing.T) {
+	producers := map[string]func(t *testing.T) pipetest.PipeProducer{
+		"BlockProducer": func(t *testing.T) pipetest.PipeProducer {
+			bp, err := NewBlockProducer(strings.NewReader("abcdefghij"), 3)
+			require.NoError(t, err)
+			return bp
+		},
+		"SnapshotProducer": func(t *testing.T) pipetest.PipeProducer {
+			path := filepath.Join(t.TempDir(), "snapshot.bin")
+			sc, err := NewSnapshotConsumer(path, SnapshotConfig{Sync: SyncOnClose})
+			require.NoError(t, err)
+			for _, b := range conformanceBatches() {
+				require.NoError(t, sc.Process(b.Items))
+			}
+			require.NoError(t, sc.Close())
+			sp, err := NewSnapshotProducer(path, nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = sp.Close() })
+			return sp
+		},
+		"ReplayProducer": func(t *testing.T) pipetest.PipeProducer {
+			var rec bytes.Buffer
+			src := NewRecordingProducer(pipetest.NewProducer(conformanceBatches()...), &rec)
+			var err error
+			for err == nil {
+				_, _, err = src.Next()
+			}
+			rp, err := NewReplayProducer(&rec)
+			require.NoError(t, err)
+			return rp
+		},
+		"RecordingProducer": func(*testing.T) pipetest.PipeProducer {
+			return NewRecordingProducer(pipetest.NewProducer(conformanceBatches()...), io.Discard)
+		},
+		"ThrottledProducer": func(*testing.T) pipetest.PipeProducer {
+			return NewThrottledProducer(pipetest.NewProducer(conformanceBatches()...), ThrottleConfig{})
+		},
+	}
+	for name, newProducer := range producers {
+		t.Run(name, func(t *testing.T) {
+			pipetest.RunProducerConformance(t, pipetest.ProducerConformance{New: newProducer})
+		})
+	}
+}
+
+func TestConformance_ChanConsumer(t *testing.T) {
+	chans := map[pipetest.PipeConsumer]chan []int{}
+	pipetest.RunConsumerConformance(t, pipetest.ConsumerConformance{
+		New: func(*testing.T) pipetest.PipeConsumer {
+			ch := make(chan []int, 16)
+			c := NewChanConsumer(context.Background(), ch)
+			chans[c] = ch
+			return c
+		},
+		Received: func(_ *testing.T, c pipetest.PipeConsumer) []any {
+			ch := chans[c]
+			close(ch)
+			var res []any
+			for batch := range ch {
+				for _, v := range batch {
+					res = append(res, v)
+				}
+			}
+			return res
+		},
+	})
+}
+
+func TestConformance_SnapshotConsumer(t *testing.T) {
+	paths := map[pipetest.PipeConsumer]string{}
+	pipetest.RunConsumerConformance(t, pipetest.ConsumerConformance{
+		New: func(t *testing.T) pipetest.PipeConsumer {
+			path := filepath.Join(t.TempDir(), "snapshot.bin")
+			sc, err := NewSnapshotConsumer(path, SnapshotConfig{Sync: SyncOnClose})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = sc.Close() })
+			paths[sc] = path
+			return sc
+		},
+		Item: func(i int) any { return float64(i) }, // JSON восстанавливает числа как float64
+		Received: func(t *testing.T, c pipetest.PipeConsumer) []any {
+			require.NoError(t, c.(*SnapshotConsumer).f.Sync())
+			sp, err := NewSnapshotProducer(paths[c], nil)
+			require.NoError(t, err)
+			defer sp.Close()
+			var res []any
+			for {
+				items, _, err := sp.Next()
+				if errors.Is(err, io.EOF) {
+					return res
+				}
+				require.NoError(t, err)
+				res = append(res, items...)
+			}
+		},
+	})
+}
+
+func TestConformance_WriterConsumer(t *testing.T) {
+	pipetest.RunConsumerConformance(t, pipetest.ConsumerConformance{
+		New:  func(*testing.T) pipetest.PipeConsumer { return NewWriterConsumer(io.Discard) },
+		Item: func(i int) any { return []byte{byte(i)} },
+	})
+}
@@ -0,0 +1,27 @@
+package main
+
+// DeadLetterOf принимает батч, который Process так и не смог обработать (после перезапусков супервизора,
+// если он задан). Если Handle вернул nil, батч считается обработанным: его cookies коммитятся,
+// и Pipe продолжает работу. Ошибка Handle завершает Pipe, как и без DeadLetter.
+type DeadLetterOf[T any] interface {
+	// Handle получает необработанные элементы батча (без под-срезов, уже прошедших Process с WithMaxProcessItems),
+	// cookies всего батча и ошибку Process.
+	Handle(items []T, cookies []int, err error) error
+}
+
+// DeadLetter — DeadLetterOf для нетипизированных элементов Pipe.
+type DeadLetter = DeadLetterOf[any]
+
+// WithDeadLetter направляет батчи, на которых упал Process, в dl вместо завершения Pipe:
+// один «ядовитый» батч больше не останавливает обработку. Тип элементов dl должен совпадать с типом Pipe.
+func WithDeadLetter[T any](dl DeadLetterOf[T]) Option {
+	return func(o *options) {
+		o.deadLetter = dl
+	}
+}
+
+// deadLetterOf возвращает DeadLetter из опций, если он принимает элементы типа T.
+func deadLetterOf[T any](o options) DeadLetterOf[T] {
+	dl, _ := o.deadLetter.(DeadLetterOf[T])
+	return dl
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// poisonConsumer не может обработать батч, в котором есть элемент "bad".
+type poisonConsumer struct {
+	mockConsumer
+}
+
+var errPoison = errors.New("malformed record")
+
+func (m *poisonConsumer) Process(items []any) error {
+	if slices.Contains(items, any("bad")) {
+		return errPoison
+	}
+	return m.mockConsumer.Process(items)
+}
+
+// recordingDeadLetter запоминает переданные ему батчи и возвращает err.
+type recordingDeadLetter struct {
+	items   [][]any
+	cookies [][]int
+	errs    []error
+	err     error
+}
+
+func (d *recordingDeadLetter) Handle(items []any, cookies []int, err error) error {
+	d.items = append(d.items, append([]any(nil), items...))
+	d.cookies = append(d.cookies, cookies)
+	d.errs = append(d.errs, err)
+	return d.err
+}
+
+func TestPipe_DeadLetter_ContinuesAfterPoisonBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{{"a"}, {"bad"}, {"c"}},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	c := &poisonConsumer{}
+	dl := &recordingDeadLetter{}
+	var summary Summary
+
+	err := Pipe(p, c, WithBatchLimits(ByCount(1)), WithDeadLetter[any](dl), WithSummary(&summary))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, [][]any{{"a"}, {"c"}}, c.processed)
+	assert.Equal(t, [][]any{{"bad"}}, dl.items)
+	assert.Equal(t, [][]int{{2}}, dl.cookies)
+	require.Len(t, dl.errs, 1)
+	assert.ErrorIs(t, dl.errs[0], errPoison)
+	assert.Equal(t, []int{1, 2, 3}, p.committed, "батч, принятый DeadLetter, коммитится")
+	assert.Equal(t, 1, summary.DeadLettered)
+	assert.Equal(t, 2, summary.Batches)
+}
+
+func TestPipe_DeadLetter_OnlyUnprocessedItems(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{batches: [][]any{{"a", "b", "bad", "d"}}, cookies: []int{1}, readErr: io.EOF}
+	dl := &recordingDeadLetter{}
+
+	err := Pipe(p, &poisonConsumer{}, WithMaxProcessItems(2), WithDeadLetter[any](dl))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, [][]any{{"bad", "d"}}, dl.items, "под-срез {a, b} уже обработан")
+	assert.Equal(t, []int{1}, p.committed)
+}
+
+func TestPipe_DeadLetter_HandleError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &nackProducer{mockProducer: mockProducer{batches: [][]any{{"bad"}}, cookies: []int{1}, readErr: io.EOF}}
+	dl := &recordingDeadLetter{err: errors.New("queue unavailable")}
+
+	err := Pipe(p, &poisonConsumer{}, WithDeadLetter[any](dl))
+	require.ErrorIs(t, err, errPoison)
+	require.ErrorIs(t, err, dl.err)
+	assert.Empty(t, p.committed)
+	assert.Equal(t, []int{1}, p.nacked, "непринятый батч возвращается источнику")
+}
+
+func TestPipeOf_DeadLetterTypeMismatch(t *testing.T) {
+	err := PipeOf[int](&typedProducer[int]{}, &typedConsumer[int]{}, WithDeadLetter[any](&recordingDeadLetter{}))
+	require.ErrorContains(t, err, "does not accept items")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateCookie возвращается Pipe, если источник повторно выдал cookie при политике DuplicateFail.
+var ErrDuplicateCookie = errors.New("duplicate cookie")
+
+// DuplicatePolicy определяет реакцию Pipe на повторно доставленный cookie.
+type DuplicatePolicy int
+
+const (
+	// DuplicateWarn — сообщает о дубле в Logger (EventDuplicateFound) и обрабатывает батч как обычно.
+	DuplicateWarn DuplicatePolicy = iota
+	// DuplicateSkip — отбрасывает элементы батча: они не попадают в Process. Cookie коммитится повторно в общем
+	// порядке, иначе источник, ожидающий подтверждения передоставки (очередь), держал бы его вечно.
+	DuplicateSkip
+	// DuplicateFail — останавливает Pipe с ошибкой ErrDuplicateCookie.
+	DuplicateFail
+)
+
+// WithDuplicateDetection включает отслеживание последних window cookies и реакцию на дубли по policy.
+// Защищает от повторной обработки батча, который источник передоставил после переподключения.
+func WithDuplicateDetection(window int, policy DuplicatePolicy) Option {
+	return func(o *options) {
+		if window <= 0 {
+			o.dedup = nil
+			return
+		}
+		o.dedup = &cookieWindow{
+			policy: policy,
+			ring:   make([]int, 0, window),
+			seen:   make(map[int]struct{}, window),
+		}
+	}
+}
+
+// cookieWindow — скользящее окно последних увиденных cookies (кольцевой буфер + множество).
+type cookieWindow struct {
+	policy DuplicatePolicy
+	ring   []int            // cookies в порядке поступления, не более cap(ring)
+	pos    int              // позиция самого старого cookie после заполнения окна
+	seen   map[int]struct{} // множество cookies из ring
+}
+
+// check запоминает cookie и сообщает, нужно ли пропустить батч. Для DuplicateFail возвращает ошибку.
+// О каждом дубле сообщается в logger, если он задан.
+func (w *cookieWindow) check(cookie int, logger Logger) (skip bool, err error) {
+	if _, ok := w.seen[cookie]; ok {
+		logEvent(logger, EventDuplicateFound, map[string]any{"cookie": cookie, "policy": w.policy})
+		switch w.policy {
+		case DuplicateSkip:
+			return true, nil
+		case DuplicateFail:
+			return false, fmt.Errorf("%w: %d", ErrDuplicateCookie, cookie)
+		default:
+			return false, nil
+		}
+	}
+
+	if len(w.ring) < cap(w.ring) {
+		w.ring = append(w.ring, cookie)
+	} else { // Окно заполнено: вытесняем самый старый cookie
+		delete(w.seen, w.ring[w.pos])
+		w.ring[w.pos] = cookie
+		w.pos = (w.pos + 1) % len(w.ring)
+	}
+	w.seen[cookie] = struct{}{}
+
+	return false, nil
+}
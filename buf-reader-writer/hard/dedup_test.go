@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func newDuplicatingProducer() *mockProducer {
+	return &mockProducer{
+		batches: [][]any{makeItems(0, 2), makeItems(2, 2), makeItems(0, 2), makeItems(4, 2)},
+		cookies: []int{1, 2, 1, 3},
+		readErr: io.EOF,
+	}
+}
+
+func TestPipe_Duplicate_Skip(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newDuplicatingProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithDuplicateDetection(10, DuplicateSkip))
+	require.ErrorIs(t, err, io.EOF)
+	require.Len(t, c.processed, 1)
+	assert.Equal(t, concat(makeItems(0, 4), makeItems(4, 2)), c.processed[0], "дубль не должен попадать в Process")
+	assert.Equal(t, []int{1, 2, 1, 3}, p.committed, "cookie дубля коммитится в общем порядке, чтобы источник его не ждал")
+}
+
+func TestPipe_Duplicate_SkipLastBeforeEOF(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{batches: [][]any{makeItems(0, MaxItems), makeItems(0, MaxItems)}, cookies: []int{1, 1}, readErr: io.EOF}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithDuplicateDetection(10, DuplicateSkip))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Len(t, c.processed, 1)
+	assert.Equal(t, []int{1, 1}, p.committed, "дубль в пустом буфере на io.EOF тоже коммитится")
+}
+
+func TestPipe_Duplicate_Warn(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newDuplicatingProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithDuplicateDetection(10, DuplicateWarn))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []int{1, 2, 1, 3}, p.committed, "при DuplicateWarn батч обрабатывается как обычно")
+}
+
+func TestPipe_Duplicate_Fail(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newDuplicatingProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithDuplicateDetection(10, DuplicateFail))
+	require.ErrorIs(t, err, ErrDuplicateCookie)
+	assert.Len(t, c.processed, 0, "не должно быть вызовов Process")
+}
+
+func TestPipe_Duplicate_OutsideWindow(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newDuplicatingProducer()
+	c := &mockConsumer{}
+
+	// Окно из одного cookie: к моменту повтора cookie 1 уже вытеснен cookie 2
+	err := Pipe(p, c, WithDuplicateDetection(1, DuplicateFail))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []int{1, 2, 1, 3}, p.committed)
+}
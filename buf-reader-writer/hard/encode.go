@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zlatoivan/go-advanced/bufpool"
+)
+
+// ErrItemTooLarge возвращается, если закодированный элемент сам по себе превышает максимальный размер чанка.
+var ErrItemTooLarge = errors.New("encoded item exceeds max chunk size")
+
+// Encoder сериализует один элемент батча. Результат должен быть самоограниченным (framed),
+// чтобы конкатенацию нескольких элементов в одном чанке можно было разобрать обратно.
+type Encoder interface {
+	Encode(item any) ([]byte, error)
+}
+
+// ByteConsumer — байт-ориентированный приёмник (сеть, файл, объектное хранилище).
+// ProcessChunk не должен сохранять chunk после возврата: буфер переиспользуется.
+type ByteConsumer interface {
+	ProcessChunk(chunk []byte) error
+}
+
+// EncodingConsumer — стадия кодирования перед Process: сериализует накопленный батч через Encoder
+// и передаёт его в ByteConsumer чанками не больше maxChunkSize байт (элементы не разрезаются).
+type EncodingConsumer struct {
+	enc          Encoder
+	next         ByteConsumer
+	maxChunkSize int // максимальный размер чанка; <= 0 — весь батч одним чанком
+}
+
+// Проверка, что EncodingConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*EncodingConsumer)(nil)
+
+// NewEncodingConsumer создаёт стадию кодирования поверх байтового приёмника next.
+func NewEncodingConsumer(enc Encoder, next ByteConsumer, maxChunkSize int) *EncodingConsumer {
+	return &EncodingConsumer{
+		enc:          enc,
+		next:         next,
+		maxChunkSize: maxChunkSize,
+	}
+}
+
+// Process кодирует элементы и отправляет их в ByteConsumer, разбивая по maxChunkSize.
+func (e *EncodingConsumer) Process(items []any) error {
+	chunk := bufpool.Get(e.maxChunkSize)[:0] // Без лимита буфер растёт через append и возвращается в пул выросшим
+	defer func() { bufpool.Put(chunk) }()
+
+	for i, item := range items {
+		data, err := e.enc.Encode(item)
+		if err != nil {
+			return fmt.Errorf("encode item %d: %w", i, err)
+		}
+		if e.maxChunkSize > 0 && len(data) > e.maxChunkSize {
+			return fmt.Errorf("%w: item %d is %d bytes, limit %d", ErrItemTooLarge, i, len(data), e.maxChunkSize)
+		}
+		if e.maxChunkSize > 0 && len(chunk)+len(data) > e.maxChunkSize { // Элемент не влезает: отдаём накопленный чанк
+			err = e.next.ProcessChunk(chunk)
+			if err != nil {
+				return fmt.Errorf("process chunk: %w", err)
+			}
+			chunk = chunk[:0]
+		}
+		chunk = append(chunk, data...)
+	}
+
+	if len(chunk) == 0 {
+		return nil
+	}
+	err := e.next.ProcessChunk(chunk)
+	if err != nil {
+		return fmt.Errorf("process chunk: %w", err)
+	}
+
+	return nil
+}
+
+// JSONEncoder кодирует элемент как одну строку JSON Lines (с завершающим '\n').
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(item any) ([]byte, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// GobEncoder кодирует элемент через encoding/gob и добавляет префикс длины (uvarint).
+// Конкретные типы элементов должны быть зарегистрированы через gob.Register.
+type GobEncoder struct{}
+
+func (GobEncoder) Encode(item any) ([]byte, error) {
+	var body bytes.Buffer
+	err := gob.NewEncoder(&body).Encode(&item)
+	if err != nil {
+		return nil, err
+	}
+	data := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+body.Len()), uint64(body.Len()))
+	return append(data, body.Bytes()...), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockByteConsumer struct {
+	chunks  []string
+	procErr error
+}
+
+func (m *mockByteConsumer) ProcessChunk(chunk []byte) error {
+	m.chunks = append(m.chunks, string(chunk))
+	return m.procErr
+}
+
+func TestEncodingConsumer_JSONSplitsByMaxChunkSize(t *testing.T) {
+	bc := &mockByteConsumer{}
+	c := NewEncodingConsumer(JSONEncoder{}, bc, 8)
+
+	err := c.Process([]any{1, 22, 333, "abcde"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\n22\n", "333\n", "\"abcde\"\n"}, bc.chunks)
+}
+
+func TestEncodingConsumer_NoLimitSingleChunk(t *testing.T) {
+	bc := &mockByteConsumer{}
+	c := NewEncodingConsumer(JSONEncoder{}, bc, 0)
+
+	require.NoError(t, c.Process(makeItems(0, 3)))
+	require.NoError(t, c.Process(nil))
+	assert.Equal(t, []string{"0\n1\n2\n"}, bc.chunks, "пустой батч не должен порождать чанк")
+}
+
+func TestEncodingConsumer_ItemTooLarge(t *testing.T) {
+	bc := &mockByteConsumer{}
+	c := NewEncodingConsumer(JSONEncoder{}, bc, 4)
+
+	err := c.Process([]any{"too long"})
+	require.ErrorIs(t, err, ErrItemTooLarge)
+	assert.Len(t, bc.chunks, 0)
+}
+
+func TestEncodingConsumer_ChunkError(t *testing.T) {
+	bc := &mockByteConsumer{procErr: errors.New("send failed")}
+	c := NewEncodingConsumer(JSONEncoder{}, bc, 0)
+
+	err := c.Process([]any{1})
+	require.ErrorIs(t, err, bc.procErr)
+}
+
+func TestGobEncoder_LengthPrefixed(t *testing.T) {
+	data, err := GobEncoder{}.Encode(42)
+	require.NoError(t, err)
+
+	size, n := binary.Uvarint(data)
+	require.Positive(t, n)
+	require.Equal(t, int(size), len(data)-n, "префикс длины должен совпадать с телом")
+
+	var decoded any
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data[n:])).Decode(&decoded))
+	assert.Equal(t, 42, decoded)
+}
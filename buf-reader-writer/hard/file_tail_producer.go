@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/bufpool"
+)
+
+// TailConfig — настройки TailProducer.
+type TailConfig struct {
+	MaxLines     int           // максимум строк в одной пачке; <= 0 — MaxItems
+	PollInterval time.Duration // пауза между проверками файла, когда новых строк нет
+	Checkpointer Checkpointer  // где хранить подтверждённый offset; nil — всегда читать с начала
+	Clock        Clock         // источник времени; nil — системное время
+}
+
+// TailProducer следит за дописываемым файлом (как tail -F) и отдаёт пачки полных строк.
+// Cookie — монотонная байтовая позиция в «склеенном» потоке всех поколений файла (с учётом ротаций).
+// Commit сохраняет через Checkpointer offset внутри текущего файла, поэтому после рестарта чтение продолжается с него.
+// Ротация (файл по пути заменён) и усечение (размер стал меньше offset) определяются, когда новых строк нет;
+// недописанная последняя строка старого файла при этом выдаётся отдельной пачкой, а не теряется.
+type TailProducer struct {
+	path string
+	cfg  TailConfig
+
+	fileMu  sync.Mutex // защищает файл и позицию от конкурентного Close; Next держит его на время чтения
+	file    *os.File
+	info    os.FileInfo // описание открытого файла для os.SameFile
+	gen     int         // поколение файла: увеличивается при каждой ротации/усечении
+	base    int64       // позиция начала текущего поколения в склеенном потоке
+	offset  int64       // offset в текущем файле после последней выданной полной строки
+	partial []byte      // прочитанный хвост без '\n'
+
+	mu       sync.Mutex         // защищает pending, savedGen и запись в Checkpointer: Commit вызывается из горутины воркера
+	pending  map[int]tailCommit // выданные cookies → позиция для Checkpointer
+	savedGen int                // поколение, к которому относится сохранённый в Checkpointer offset
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// tailCommit — позиция, которую нужно сохранить при Commit cookie.
+type tailCommit struct {
+	gen    int
+	offset int64
+}
+
+// Проверка, что TailProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*TailProducer)(nil)
+
+// NewTailProducer открывает файл и позиционируется на сохранённый в Checkpointer offset.
+func NewTailProducer(path string, cfg TailConfig) (*TailProducer, error) {
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = MaxItems
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	tp := &TailProducer{
+		path:    path,
+		cfg:     cfg,
+		pending: make(map[int]tailCommit),
+		stopCh:  make(chan struct{}),
+	}
+
+	var start int64
+	if cfg.Checkpointer != nil {
+		pos, err := cfg.Checkpointer.Load()
+		if err != nil {
+			return nil, err
+		}
+		start = pos
+	}
+	err := tp.open(start)
+	if err != nil {
+		return nil, err
+	}
+
+	return tp, nil
+}
+
+// Next блокируется до появления хотя бы одной полной строки и возвращает до MaxLines строк.
+// После Close возвращает io.EOF.
+func (tp *TailProducer) Next() (items []any, cookie int, err error) {
+	for {
+		items, cookie, err = tp.poll()
+		if err != nil || items != nil {
+			return items, cookie, err
+		}
+		if !sleep(tp.cfg.Clock, tp.cfg.PollInterval, tp.stopCh) {
+			return nil, 0, io.EOF
+		}
+	}
+}
+
+// poll делает одну попытку прочитать строки; при их отсутствии проверяет ротацию и возвращает nil items.
+func (tp *TailProducer) poll() (items []any, cookie int, err error) {
+	tp.fileMu.Lock()
+	defer tp.fileMu.Unlock()
+	select {
+	case <-tp.stopCh:
+		return nil, 0, io.EOF
+	default:
+	}
+
+	lines, err := tp.readLines()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(lines) == 0 {
+		rotated, err := tp.rotated()
+		if err != nil || !rotated {
+			return nil, 0, err
+		}
+		if len(tp.partial) == 0 {
+			return nil, 0, tp.reopen()
+		}
+		// Старый файл больше не дописывается: его недописанная строка выдаётся как есть, а файл
+		// переоткрывается при следующем опросе
+		lines = []string{string(tp.partial)}
+		tp.offset += int64(len(tp.partial))
+		tp.partial = nil
+	}
+
+	items = make([]any, len(lines))
+	for i, l := range lines {
+		items[i] = l
+	}
+	cookie = int(tp.base + tp.offset)
+	tp.mu.Lock()
+	tp.pending[cookie] = tailCommit{gen: tp.gen, offset: tp.offset}
+	tp.mu.Unlock()
+
+	return items, cookie, nil
+}
+
+// Commit сохраняет offset пачки. Позиции из уже ротированного поколения не имеют смысла для нового файла:
+// вместо первой из них сохраняется начало текущего файла, чтобы после рестарта он читался с начала,
+// а не с offset старого.
+func (tp *TailProducer) Commit(cookie int) error {
+	tp.mu.Lock() // Save под замком: сохранение начала нового файла в reopen не должно обогнать старый offset
+	defer tp.mu.Unlock()
+	pos, ok := tp.pending[cookie]
+	if !ok {
+		return fmt.Errorf("unknown cookie %d", cookie)
+	}
+	delete(tp.pending, cookie)
+	switch {
+	case pos.gen == tp.gen:
+		tp.savedGen = tp.gen
+		return tp.save(pos.offset)
+	case tp.savedGen < tp.gen:
+		tp.savedGen = tp.gen
+		return tp.save(0)
+	}
+	return nil
+}
+
+// save сохраняет offset в Checkpointer, если он задан. Вызывается под tp.mu.
+func (tp *TailProducer) save(offset int64) error {
+	if tp.cfg.Checkpointer == nil {
+		return nil
+	}
+	return tp.cfg.Checkpointer.Save(offset)
+}
+
+// Close прерывает ожидание в Next (Pipe получит io.EOF) и закрывает файл. Безопасно вызывать из другой горутины.
+func (tp *TailProducer) Close() error {
+	var err error
+	tp.once.Do(func() {
+		close(tp.stopCh)
+		tp.fileMu.Lock()
+		defer tp.fileMu.Unlock()
+		err = tp.file.Close()
+	})
+	return err
+}
+
+// readLines дочитывает доступные данные и выделяет из них до MaxLines полных строк.
+func (tp *TailProducer) readLines() ([]string, error) {
+	var lines []string
+	chunk := bufpool.Get(32 * 1024)
+	defer bufpool.Put(chunk)
+	for len(lines) < tp.cfg.MaxLines {
+		// Сначала выдаём строки из уже прочитанного хвоста
+		idx := bytes.IndexByte(tp.partial, '\n')
+		if idx >= 0 {
+			lines = append(lines, string(tp.partial[:idx]))
+			tp.partial = tp.partial[idx+1:]
+			tp.offset += int64(idx + 1)
+			continue
+		}
+		n, err := tp.file.Read(chunk)
+		tp.partial = append(tp.partial, chunk[:n]...)
+		if errors.Is(err, io.EOF) || (err == nil && n == 0) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", tp.path, err)
+		}
+	}
+	return lines, nil
+}
+
+// rotated сообщает, что по пути лежит другой файл или текущий был усечён.
+func (tp *TailProducer) rotated() (bool, error) {
+	info, err := os.Stat(tp.path)
+	if errors.Is(err, os.ErrNotExist) { // Ротация в процессе: новый файл ещё не создан
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat %s: %w", tp.path, err)
+	}
+	return !os.SameFile(info, tp.info) || info.Size() < tp.offset+int64(len(tp.partial)), nil
+}
+
+// reopen переходит к новому поколению файла после ротации или усечения. Если всё выданное из старого файла
+// уже закоммичено, сразу сохраняет начало нового; иначе это сделает Commit первой из оставшихся пачек.
+func (tp *TailProducer) reopen() error {
+	_ = tp.file.Close()
+	tp.base += tp.offset
+	err := tp.open(0)
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	tp.gen++
+	if len(tp.pending) == 0 && tp.savedGen < tp.gen {
+		tp.savedGen = tp.gen
+		err = errors.Join(err, tp.save(0))
+	}
+	return err
+}
+
+// open открывает файл по пути и позиционируется на offset.
+func (tp *TailProducer) open(offset int64) error {
+	f, err := os.Open(tp.path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", tp.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat %s: %w", tp.path, err)
+	}
+	if offset > info.Size() { // Сохранённая позиция от другого файла: начинаем сначала
+		offset = 0
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("seek %s: %w", tp.path, err)
+	}
+
+	tp.file = f
+	tp.info = info
+	tp.offset = offset
+	tp.partial = nil
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncConsumer — потокобезопасный Consumer для чтения результатов во время работы Pipe.
+type syncConsumer struct {
+	mu    sync.Mutex
+	items []any
+}
+
+func (m *syncConsumer) Process(items []any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = append(m.items, items...)
+	return nil
+}
+
+func (m *syncConsumer) snapshot() []any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]any(nil), m.items...)
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+// wakeAfterPoll дожидается, пока TailProducer уснёт между опросами, выполняет fn и будит его.
+func wakeAfterPoll(clock fakeClock, fn func()) {
+	go func() {
+		clock.BlockUntil(1)
+		fn()
+		clock.Advance(time.Second)
+	}()
+}
+
+func TestTailProducer_FollowsAppendsAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "a\nb\npart")
+
+	cp := NewFileCheckpointer(filepath.Join(dir, "app.offset"))
+	clock := newFakeClock()
+	tp, err := NewTailProducer(path, TailConfig{MaxLines: 1, PollInterval: time.Second, Checkpointer: cp, Clock: clock})
+	require.NoError(t, err)
+	defer tp.Close()
+
+	var got []any
+	var cookies []int
+	next := func() {
+		items, cookie, err := tp.Next()
+		require.NoError(t, err)
+		got = append(got, items...)
+		cookies = append(cookies, cookie)
+	}
+	next()
+	next()
+
+	// Неполная строка не выдаётся, пока не будет дописана
+	wakeAfterPoll(clock, func() { appendFile(t, path, "ial\n") })
+	next()
+
+	// Ротация: старый файл переименован, по пути создан новый. Новый файл читается после следующего опроса
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "c\n")
+	wakeAfterPoll(clock, func() {})
+	next()
+
+	assert.Equal(t, []any{"a", "b", "partial", "c"}, got)
+	assert.Equal(t, []int{2, 4, 12, 14}, cookies, "cookie — монотонная позиция с учётом ротации")
+
+	for _, ck := range cookies {
+		require.NoError(t, tp.Commit(ck))
+	}
+	pos, err := cp.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pos, "сохраняется offset внутри нового файла")
+}
+
+func TestTailProducer_CloseStopsPipe(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "a\nb\n")
+
+	clock := newFakeClock()
+	tp, err := NewTailProducer(path, TailConfig{PollInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+
+	c := &syncConsumer{}
+	done := make(chan error, 1)
+	go func() { done <- Pipe(tp, c) }()
+
+	clock.BlockUntil(1) // Строки прочитаны, источник ждёт новых данных
+	require.NoError(t, tp.Close())
+	require.Equal(t, io.EOF, <-done)
+	assert.Equal(t, []any{"a", "b"}, c.snapshot(), "накопленные строки сбрасываются при остановке")
+}
+
+func TestTailProducer_ResumesFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "first\nsecond\n")
+	cp := NewFileCheckpointer(filepath.Join(dir, "app.offset"))
+	require.NoError(t, cp.Save(int64(len("first\n"))))
+
+	tp, err := NewTailProducer(path, TailConfig{PollInterval: time.Second, Checkpointer: cp, Clock: newAutoClock()})
+	require.NoError(t, err)
+	defer tp.Close()
+
+	items, cookie, err := tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"second"}, items)
+	require.NoError(t, tp.Commit(cookie))
+
+	pos, err := cp.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("first\nsecond\n")), pos)
+}
+
+func TestTailProducer_Truncation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "long line\n")
+
+	tp, err := NewTailProducer(path, TailConfig{PollInterval: time.Second, Clock: newAutoClock()})
+	require.NoError(t, err)
+	defer tp.Close()
+
+	items, _, err := tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"long line"}, items)
+
+	require.NoError(t, os.Truncate(path, 0))
+	appendFile(t, path, "x\n")
+	items, _, err = tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"x"}, items, "после усечения чтение начинается с начала файла")
+}
+
+func TestTailProducer_RotationKeepsPartialLineAndResetsCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "a\nunfinished")
+
+	cp := NewFileCheckpointer(filepath.Join(dir, "app.offset"))
+	clock := newFakeClock()
+	tp, err := NewTailProducer(path, TailConfig{PollInterval: time.Second, Checkpointer: cp, Clock: clock})
+	require.NoError(t, err)
+	defer tp.Close()
+
+	items, first, err := tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a"}, items)
+
+	require.NoError(t, os.Rename(path, path+".1"))
+	appendFile(t, path, "new file line\n")
+	wakeAfterPoll(clock, func() {})
+	items, last, err := tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"unfinished"}, items, "недописанная строка старого файла не должна теряться")
+
+	require.NoError(t, tp.Commit(first))
+	require.NoError(t, tp.Commit(last))
+	items, _, err = tp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"new file line"}, items)
+
+	pos, err := cp.Load()
+	require.NoError(t, err)
+	assert.Zero(t, pos, "после ротации рестарт должен читать новый файл с начала, а не с offset старого")
+}
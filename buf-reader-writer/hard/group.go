@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// PipeGroup запускает много пар (Producer, Consumer) с общим ограниченным пулом для Process.
+// Вызовы Process всех пайплайнов выполняются не более чем workers горутинами,
+// очереди пайплайнов обслуживаются по кругу (round-robin), чтобы ни один не голодал.
+//
+// Пул ограничивает только Process: чтение источника, накопление и Commit каждого пайплайна по-прежнему
+// идут в его собственных горутинах Pipe (горутина Run и воркер Pipe, плюс горутины WithMaxDelay
+// и WithWorkers, если они заданы). Пайплайны долгоживущие, поэтому занимать ими горутины пула целиком
+// нельзя: пайплайны сверх workers никогда бы не запустились. Число горутин группы — около
+// 2*N + workers для N пайплайнов; ограничено число одновременно выполняющихся Process, а не их.
+type PipeGroup struct {
+	workers int
+	pipes   []groupPipe
+}
+
+// groupPipe — один пайплайн группы.
+type groupPipe struct {
+	p    Producer
+	c    Consumer
+	opts []Option
+}
+
+// NewPipeGroup создаёт группу с пулом из workers горутин; при workers <= 0 используется один воркер.
+func NewPipeGroup(workers int) *PipeGroup {
+	return &PipeGroup{workers: max(workers, 1)}
+}
+
+// Add регистрирует пайплайн в группе. Вызывать до Run.
+func (g *PipeGroup) Add(p Producer, c Consumer, opts ...Option) {
+	g.pipes = append(g.pipes, groupPipe{p: p, c: c, opts: opts})
+}
+
+// Run запускает все пайплайны, каждый в своей горутине, и ждёт их завершения.
+// Штатное завершение пайплайна (io.EOF) ошибкой не считается; остальные ошибки агрегируются через errors.Join
+// с указанием номера пайплайна. Ошибка одного пайплайна не останавливает остальные.
+func (g *PipeGroup) Run() error {
+	pool := newFairPool(len(g.pipes))
+	var poolWg sync.WaitGroup
+	for range g.workers {
+		poolWg.Add(1)
+		go func() {
+			defer poolWg.Done()
+			pool.work()
+		}()
+	}
+
+	errs := make([]error, len(g.pipes))
+	var wg sync.WaitGroup
+	for i, gp := range g.pipes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := &pooledConsumer{pool: pool, id: i, c: gp.c}
+			err := Pipe(gp.p, c, gp.opts...)
+			if err != nil && !errors.Is(err, io.EOF) {
+				errs[i] = fmt.Errorf("pipe %d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	pool.close()
+	poolWg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// pooledConsumer выполняет Process исходного Consumer на общем пуле группы.
+type pooledConsumer struct {
+	pool *fairPool
+	id   int
+	c    Consumer
+}
+
+func (pc *pooledConsumer) Process(items []any) error {
+	return pc.pool.submit(pc.id, func() error { return pc.c.Process(items) })
+}
+
+// ProcessWithMeta пробрасывает метаданные, если исходный Consumer их поддерживает.
+func (pc *pooledConsumer) ProcessWithMeta(items []any, spans []MetaSpan) error {
+	return pc.pool.submit(pc.id, func() error { return process(pc.c, items, spans) })
+}
+
+// poolJob — задача пула и канал для её результата.
+type poolJob struct {
+	fn  func() error
+	res chan error
+}
+
+// fairPool — пул с отдельной FIFO-очередью на каждый пайплайн и круговым выбором очереди.
+type fairPool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queues [][]poolJob // очереди задач по номерам пайплайнов
+	next   int         // очередь, с которой начнётся следующий поиск задачи
+	closed bool
+}
+
+func newFairPool(n int) *fairPool {
+	fp := &fairPool{queues: make([][]poolJob, n)}
+	fp.cond = sync.NewCond(&fp.mu)
+	return fp
+}
+
+// submit ставит задачу в очередь пайплайна id и ждёт её выполнения.
+func (fp *fairPool) submit(id int, fn func() error) error {
+	job := poolJob{fn: fn, res: make(chan error, 1)}
+	fp.mu.Lock()
+	fp.queues[id] = append(fp.queues[id], job)
+	fp.mu.Unlock()
+	fp.cond.Signal()
+	return <-job.res
+}
+
+// work — цикл воркера: берёт задачи по кругу, пока пул не закрыт и очереди не пусты.
+func (fp *fairPool) work() {
+	for {
+		fp.mu.Lock()
+		job, ok := fp.pick()
+		for !ok && !fp.closed {
+			fp.cond.Wait()
+			job, ok = fp.pick()
+		}
+		fp.mu.Unlock()
+		if !ok {
+			return
+		}
+		job.res <- job.fn()
+	}
+}
+
+// pick извлекает задачу из первой непустой очереди начиная с next. Вызывается под mu.
+func (fp *fairPool) pick() (poolJob, bool) {
+	for i := range fp.queues {
+		idx := (fp.next + i) % len(fp.queues)
+		if len(fp.queues[idx]) == 0 {
+			continue
+		}
+		job := fp.queues[idx][0]
+		fp.queues[idx] = fp.queues[idx][1:]
+		fp.next = (idx + 1) % len(fp.queues)
+		return job, true
+	}
+	return poolJob{}, false
+}
+
+// close будит воркеров и завершает их после опустошения очередей.
+func (fp *fairPool) close() {
+	fp.mu.Lock()
+	fp.closed = true
+	fp.mu.Unlock()
+	fp.cond.Broadcast()
+}
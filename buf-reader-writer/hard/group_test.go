@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// concurrencyConsumer считает максимальное число одновременных вызовов Process среди всех экземпляров.
+type concurrencyConsumer struct {
+	mockConsumer
+	mu      sync.Mutex
+	active  *atomic.Int32
+	maxSeen *atomic.Int32
+}
+
+func (m *concurrencyConsumer) Process(items []any) error {
+	cur := m.active.Add(1)
+	defer m.active.Add(-1)
+	for {
+		seen := m.maxSeen.Load()
+		if cur <= seen || m.maxSeen.CompareAndSwap(seen, cur) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockConsumer.Process(items)
+}
+
+func TestPipeGroup_BoundedWorkersAndAggregatedErrors(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	const workers = 2
+	var active, maxSeen atomic.Int32
+
+	g := NewPipeGroup(workers)
+	producers := make([]*mockProducer, 4)
+	for i := range producers {
+		producers[i] = &mockProducer{
+			batches: [][]any{makeItems(0, MaxItems), makeItems(1, MaxItems), makeItems(2, MaxItems)},
+			cookies: []int{1, 2, 3},
+			readErr: io.EOF,
+		}
+		g.Add(producers[i], &concurrencyConsumer{active: &active, maxSeen: &maxSeen})
+	}
+	failing := &mockConsumer{procErr: errors.New("process failed")}
+	g.Add(&mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF}, failing)
+
+	err := g.Run()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, failing.procErr)
+	assert.Contains(t, err.Error(), "pipe 4")
+	assert.LessOrEqual(t, maxSeen.Load(), int32(workers), "превышен размер общего пула")
+	for i, p := range producers {
+		assert.Equal(t, []int{1, 2, 3}, p.committed, "пайплайн %d должен завершиться независимо от ошибки соседа", i)
+	}
+}
+
+func TestPipeGroup_AllSucceed(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	g := NewPipeGroup(0)
+	p := &mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF}
+	g.Add(p, &mockConsumer{})
+
+	require.NoError(t, g.Run())
+	assert.Equal(t, []int{1}, p.committed)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FetchFunc опрашивает источник один раз. Пустой результат — данных пока нет; io.EOF — источник исчерпан.
+type FetchFunc func() ([]any, error)
+
+// Schedule — расписание опроса: Next возвращает ближайший момент запуска строго после t.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc адаптирует функцию к интерфейсу Schedule (например, для cron-подобных расписаний).
+type ScheduleFunc func(t time.Time) time.Time
+
+func (f ScheduleFunc) Next(t time.Time) time.Time { return f(t) }
+
+// Every — расписание с фиксированным интервалом d.
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time { return t.Add(d) })
+}
+
+// EmptyPolicy определяет, что делает IntervalProducer, если опрос не вернул данных.
+type EmptyPolicy int
+
+const (
+	EmptySkip EmptyPolicy = iota // ждать следующего тика, не возвращаясь из Next (по умолчанию)
+	EmptyEmit                    // вернуть пустой батч: Pipe закоммитит cookie тика
+)
+
+// IntervalConfig — настройки IntervalProducer.
+type IntervalConfig struct {
+	Schedule Schedule // расписание опроса; обязательно
+	Empty    EmptyPolicy
+	Clock    Clock // источник времени; nil — системное время
+}
+
+// IntervalProducer вызывает fetch по расписанию и превращает опрашиваемый источник в Producer.
+// Cookie — номер тика. Пропущенные из-за долгого fetch тики не догоняются.
+// Close прерывает ожидание, после чего Next возвращает io.EOF.
+type IntervalProducer struct {
+	fetch     FetchFunc
+	cfg       IntervalConfig
+	last      time.Time // время предыдущего тика
+	tick      int
+	committed atomic.Int64 // число подтверждённых тиков
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Проверка, что IntervalProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*IntervalProducer)(nil)
+
+// NewIntervalProducer создаёт Producer, опрашивающий fetch по cfg.Schedule. Первый опрос — на первом тике расписания.
+func NewIntervalProducer(fetch FetchFunc, cfg IntervalConfig) (*IntervalProducer, error) {
+	if cfg.Schedule == nil {
+		return nil, errors.New("interval producer: schedule is required")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	return &IntervalProducer{
+		fetch: fetch,
+		cfg:   cfg,
+		last:  cfg.Clock.Now(),
+		done:  make(chan struct{}),
+	}, nil
+}
+
+// Next дожидается следующего тика и опрашивает источник.
+func (ip *IntervalProducer) Next() (items []any, cookie int, err error) {
+	for {
+		now := ip.cfg.Clock.Now()
+		at := ip.cfg.Schedule.Next(ip.last)
+		if at.Before(now) { // Отстали от расписания — пропускаем упущенные тики
+			at = ip.cfg.Schedule.Next(now)
+		}
+		if !sleep(ip.cfg.Clock, at.Sub(now), ip.done) {
+			return nil, 0, io.EOF
+		}
+		ip.last = at
+
+		cookie = ip.tick
+		ip.tick++
+		items, err = ip.fetch()
+		if errors.Is(err, io.EOF) {
+			return nil, 0, io.EOF
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("fetch tick %d: %w", cookie, err)
+		}
+		if len(items) == 0 && ip.cfg.Empty == EmptySkip {
+			continue
+		}
+		return items, cookie, nil
+	}
+}
+
+// Commit фиксирует подтверждённый тик. Тики, пропущенные по EmptySkip, не коммитятся.
+func (ip *IntervalProducer) Commit(cookie int) error {
+	ip.committed.Add(1)
+	return nil
+}
+
+// Committed возвращает число подтверждённых тиков.
+func (ip *IntervalProducer) Committed() int {
+	return int(ip.committed.Load())
+}
+
+// Close останавливает опрос: текущий и последующие вызовы Next возвращают io.EOF.
+func (ip *IntervalProducer) Close() error {
+	ip.closeOnce.Do(func() { close(ip.done) })
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedFetch возвращает результаты по очереди, затем io.EOF.
+func scriptedFetch(results ...[]any) FetchFunc {
+	return func() ([]any, error) {
+		if len(results) == 0 {
+			return nil, io.EOF
+		}
+		res := results[0]
+		results = results[1:]
+		return res, nil
+	}
+}
+
+func TestIntervalProducer_SkipsEmptyPolls(t *testing.T) {
+	clock := newAutoClock()
+	ip, err := NewIntervalProducer(scriptedFetch([]any{1}, nil, nil, []any{2}), IntervalConfig{
+		Schedule: Every(time.Second),
+		Clock:    clock,
+	})
+	require.NoError(t, err)
+
+	c := &mockConsumer{}
+	err = Pipe(ip, c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{{1, 2}}, c.processed)
+	assert.Equal(t, 2, ip.Committed(), "пустые тики не коммитятся")
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second}, clock.Sleeps())
+}
+
+func TestIntervalProducer_EmitsEmptyBatches(t *testing.T) {
+	clock := newAutoClock()
+	ip, err := NewIntervalProducer(scriptedFetch(nil, []any{1}), IntervalConfig{
+		Schedule: Every(time.Minute),
+		Empty:    EmptyEmit,
+		Clock:    clock,
+	})
+	require.NoError(t, err)
+
+	items, cookie, err := ip.Next()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, 0, cookie)
+
+	items, cookie, err = ip.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{1}, items)
+	assert.Equal(t, 1, cookie)
+}
+
+func TestIntervalProducer_FetchError(t *testing.T) {
+	fetchErr := errors.New("source unavailable")
+	ip, err := NewIntervalProducer(func() ([]any, error) { return nil, fetchErr }, IntervalConfig{
+		Schedule: Every(time.Second),
+		Clock:    newAutoClock(),
+	})
+	require.NoError(t, err)
+
+	err = Pipe(ip, &mockConsumer{})
+	assert.ErrorIs(t, err, fetchErr)
+}
+
+func TestIntervalProducer_CloseStopsWaiting(t *testing.T) {
+	clock := newFakeClock()
+	ip, err := NewIntervalProducer(scriptedFetch([]any{1}), IntervalConfig{Schedule: Every(time.Hour), Clock: clock})
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := ip.Next()
+		errCh <- err
+	}()
+	clock.BlockUntil(1)
+	require.NoError(t, ip.Close())
+
+	select {
+	case err = <-errCh:
+		assert.Equal(t, io.EOF, err)
+	case <-time.After(time.Second):
+		t.Fatal("Next не вернулся после Close")
+	}
+}
+
+func TestIntervalProducer_WakesOnTick(t *testing.T) {
+	clock := newFakeClock()
+	ip, err := NewIntervalProducer(scriptedFetch([]any{1}), IntervalConfig{Schedule: Every(time.Hour), Clock: clock})
+	require.NoError(t, err)
+
+	type result struct {
+		items []any
+		err   error
+	}
+	res := make(chan result, 1)
+	go func() {
+		items, _, err := ip.Next()
+		res <- result{items, err}
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(59 * time.Minute)
+	select {
+	case <-res:
+		t.Fatal("Next вернулся до тика")
+	default:
+	}
+
+	clock.Advance(time.Minute)
+	r := <-res
+	require.NoError(t, r.err)
+	assert.Equal(t, []any{1}, r.items)
+}
+
+func TestNewIntervalProducer_RequiresSchedule(t *testing.T) {
+	_, err := NewIntervalProducer(scriptedFetch(), IntervalConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSONLinesConfig — настройки JSONLinesConsumer.
+type JSONLinesConfig struct {
+	Gzip          bool          // сжимать вывод gzip
+	FlushInterval time.Duration // как часто завершать блок gzip; 0 — после каждого Process. Без Gzip не используется
+	Clock         Clock         // источник времени; nil — системное время
+}
+
+// JSONLinesConsumer пишет каждый элемент отдельной строкой JSON в io.Writer через буфер (и, опционально, gzip).
+// Буфер сбрасывается до возврата из Process, поэтому Pipe коммитит только данные, уже переданные в w.
+// С Gzip и FlushInterval > 0 блок gzip завершается не чаще раза в FlushInterval (ради степени сжатия),
+// и хвост успешно обработанных батчей до этого остаётся внутри компрессора: при падении процесса он теряется.
+type JSONLinesConsumer struct {
+	cfg       JSONLinesConfig
+	bw        *bufio.Writer
+	gz        *gzip.Writer // nil без сжатия
+	enc       JSONEncoder
+	lastFlush time.Time
+}
+
+// Проверка, что JSONLinesConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*JSONLinesConsumer)(nil)
+
+// NewJSONLinesConsumer создаёт Consumer, пишущий JSON Lines в w. После завершения Pipe нужно вызвать Close.
+func NewJSONLinesConsumer(w io.Writer, cfg JSONLinesConfig) *JSONLinesConsumer {
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	jc := &JSONLinesConsumer{cfg: cfg}
+	if cfg.Gzip {
+		jc.gz = gzip.NewWriter(w)
+		w = jc.gz
+	}
+	jc.bw = bufio.NewWriter(w)
+	jc.lastFlush = cfg.Clock.Now()
+	return jc
+}
+
+// Process записывает элементы батча и сбрасывает буфер. Батч сначала целиком кодируется, поэтому при
+// ошибке кодирования в буфер не попадает ни одна его строка, и повтор не запишет их дважды.
+func (jc *JSONLinesConsumer) Process(items []any) error {
+	var lines []byte
+	for i, item := range items {
+		line, err := jc.enc.Encode(item)
+		if err != nil {
+			return fmt.Errorf("encode item %d: %w", i, err)
+		}
+		lines = append(lines, line...)
+	}
+	_, err := jc.bw.Write(lines)
+	if err != nil {
+		return fmt.Errorf("write items: %w", err)
+	}
+	err = jc.bw.Flush()
+	if err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	if jc.gz == nil {
+		return nil
+	}
+	now := jc.cfg.Clock.Now()
+	if now.Sub(jc.lastFlush) < jc.cfg.FlushInterval {
+		return nil
+	}
+	jc.lastFlush = now
+	err = jc.gz.Flush()
+	if err != nil {
+		return fmt.Errorf("flush gzip: %w", err)
+	}
+	return nil
+}
+
+// Flush сбрасывает буфер (и блок gzip) в исходный Writer.
+func (jc *JSONLinesConsumer) Flush() error {
+	err := jc.bw.Flush()
+	if err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if jc.gz != nil {
+		err = jc.gz.Flush()
+		if err != nil {
+			return fmt.Errorf("flush gzip: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close сбрасывает остаток данных и завершает gzip-поток. Исходный Writer не закрывается.
+func (jc *JSONLinesConsumer) Close() error {
+	err := jc.bw.Flush()
+	if err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	if jc.gz != nil {
+		err = jc.gz.Close()
+		if err != nil {
+			return fmt.Errorf("close gzip: %w", err)
+		}
+	}
+	return nil
+}
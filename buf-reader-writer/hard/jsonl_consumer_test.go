@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLinesConsumer_WritesLines(t *testing.T) {
+	var out bytes.Buffer
+	c := NewJSONLinesConsumer(&out, JSONLinesConfig{})
+
+	require.NoError(t, c.Process([]any{1, "two", map[string]int{"x": 3}}))
+	assert.Equal(t, "1\n\"two\"\n{\"x\":3}\n", out.String(), "без FlushInterval данные сбрасываются после каждого Process")
+	require.NoError(t, c.Close())
+}
+
+func TestJSONLinesConsumer_FlushesEveryBatch(t *testing.T) {
+	var out bytes.Buffer
+	c := NewJSONLinesConsumer(&out, JSONLinesConfig{FlushInterval: time.Hour, Clock: newFakeClock()})
+
+	require.NoError(t, c.Process([]any{1}))
+	assert.Equal(t, "1\n", out.String(), "без Gzip FlushInterval не задерживает данные в буфере")
+	require.NoError(t, c.Close())
+}
+
+// gunzipPrefix распаковывает уже записанную часть незавершённого gzip-потока.
+func gunzipPrefix(t *testing.T, data []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err == io.EOF {
+		return ""
+	}
+	require.NoError(t, err)
+	res, err := io.ReadAll(zr)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	return string(res)
+}
+
+func TestJSONLinesConsumer_GzipBlockInterval(t *testing.T) {
+	var out bytes.Buffer
+	clock := newFakeClock()
+	c := NewJSONLinesConsumer(&out, JSONLinesConfig{Gzip: true, FlushInterval: time.Hour, Clock: clock})
+
+	require.NoError(t, c.Process([]any{1}))
+	assert.Empty(t, gunzipPrefix(t, out.Bytes()), "до истечения интервала блок gzip не завершается")
+
+	clock.Advance(time.Hour)
+	require.NoError(t, c.Process([]any{2}))
+	assert.Equal(t, "1\n2\n", gunzipPrefix(t, out.Bytes()), "по истечении интервала блок gzip завершается")
+	require.NoError(t, c.Close())
+}
+
+func TestJSONLinesConsumer_Gzip(t *testing.T) {
+	var out bytes.Buffer
+	c := NewJSONLinesConsumer(&out, JSONLinesConfig{Gzip: true})
+
+	p := &mockProducer{batches: [][]any{makeItems(0, 3)}, cookies: []int{1}, readErr: io.EOF}
+	require.Equal(t, io.EOF, Pipe(p, c))
+	require.NoError(t, c.Close())
+
+	zr, err := gzip.NewReader(&out)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "0\n1\n2\n", string(data))
+}
+
+func TestJSONLinesConsumer_EncodeErrorWritesNothing(t *testing.T) {
+	var out bytes.Buffer
+	c := NewJSONLinesConsumer(&out, JSONLinesConfig{})
+
+	require.Error(t, c.Process([]any{1, make(chan int)}))
+	assert.Empty(t, out.String(), "при ошибке кодирования батч не пишется частично")
+
+	require.NoError(t, c.Process([]any{1}))
+	require.NoError(t, c.Close())
+	assert.Equal(t, "1\n", out.String(), "повтор не дублирует строки")
+}
@@ -0,0 +1,33 @@
+package main
+
+// Logger — хук структурного логирования жизненного цикла Pipe.
+// Вызывается синхронно из горутин Pipe, поэтому реализация должна быть потокобезопасной и быстрой.
+type Logger interface {
+	Log(event string, attrs map[string]any)
+}
+
+// События, которые Pipe передаёт в Logger.
+const (
+	EventBatchFlushed   = "batch_flushed"    // батч передан воркеру: items, cookies
+	EventCommitDone     = "commit_done"      // cookie успешно закоммичен: cookie
+	EventRetry          = "retry"            // перезапуск воркера после ошибки: attempt, delay, error
+	EventBatchNacked    = "batch_nacked"     // батч окончательно не обработан, вызван Nack: cookies, error
+	EventDuplicateFound = "duplicate_cookie" // источник повторно выдал cookie: cookie, policy
+	EventCallRetry      = "call_retry"       // повтор упавшего Next или Commit: call, attempt, delay, error
+	EventDeadLettered   = "dead_lettered"    // необработанный батч принят DeadLetter: items, cookies, error
+)
+
+// WithLogger подключает структурный логгер событий Pipe.
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// logEvent передаёт событие в logger, если он задан.
+func logEvent(logger Logger, event string, attrs map[string]any) {
+	if logger == nil {
+		return
+	}
+	logger.Log(event, attrs)
+}
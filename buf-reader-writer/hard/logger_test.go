@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+type logRecord struct {
+	event string
+	attrs map[string]any
+}
+
+// recordingLogger запоминает события; потокобезопасен, т.к. вызывается из разных горутин Pipe.
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) Log(event string, attrs map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, logRecord{event: event, attrs: attrs})
+}
+
+func (l *recordingLogger) events(event string) []map[string]any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var res []map[string]any
+	for _, r := range l.records {
+		if r.event == event {
+			res = append(res, r.attrs)
+		}
+	}
+	return res
+}
+
+func TestPipe_Logger_LifecycleEvents(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, MaxItems), makeItems(MaxItems, 1), makeItems(MaxItems+1, 1)},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	c := &flakyConsumer{failErr: errors.New("temporary"), failures: 1}
+	logger := &recordingLogger{}
+
+	err := Pipe(p, c, WithLogger(logger), WithSupervisor(SupervisorPolicy{MaxRestarts: 1, Backoff: time.Microsecond}))
+	require.ErrorIs(t, err, io.EOF)
+
+	flushed := logger.events(EventBatchFlushed)
+	require.Len(t, flushed, 2)
+	assert.Equal(t, MaxItems, flushed[0]["items"])
+	assert.Equal(t, []int{2, 3}, flushed[1]["cookies"])
+
+	var committed []any
+	for _, attrs := range logger.events(EventCommitDone) {
+		committed = append(committed, attrs["cookie"])
+	}
+	assert.Equal(t, []any{1, 2, 3}, committed)
+
+	retries := logger.events(EventRetry)
+	require.Len(t, retries, 1)
+	assert.Equal(t, 1, retries[0]["attempt"])
+}
+
+func TestPipe_Logger_DuplicateAndNack(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &nackProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, 1), makeItems(0, 1)},
+			cookies: []int{7, 7},
+			readErr: io.EOF,
+		},
+	}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+	logger := &recordingLogger{}
+
+	err := Pipe(p, c, WithLogger(logger), WithDuplicateDetection(4, DuplicateWarn))
+	require.Error(t, err)
+
+	dups := logger.events(EventDuplicateFound)
+	require.Len(t, dups, 1)
+	assert.Equal(t, 7, dups[0]["cookie"])
+
+	nacked := logger.events(EventBatchNacked)
+	require.Len(t, nacked, 1)
+	assert.Equal(t, []int{7, 7}, nacked[0]["cookies"])
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"time"
+
+	"github.com/zlatoivan/go-advanced/batcher"
+)
+
+// WithMaxDelay ограничивает время, которое элементы проводят в буфере: накопленный буфер, прождавший d,
+// отправляется в Process, даже если MaxItems не набран, а Next источника ещё не вернулся.
+// В отличие от ByAge, таймер срабатывает и пока Pipe ждёт в Next: для этого Next вызывается из отдельной
+// горутины — по-прежнему не больше одного вызова за раз. Отсчёт идёт по Clock Pipe. d <= 0 — без таймера.
+func WithMaxDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.maxDelay = d
+	}
+}
+
+// nextResult — результат одного вызова Next.
+type nextResult[T any] struct {
+	items  []T
+	cookie int
+	meta   Meta
+	err    error
+}
+
+// delayedNext вызывает Next источника в отдельной горутине, чтобы Pipe мог отправить буфер по таймеру, пока Next ждёт данных.
+type delayedNext[T any] struct {
+	next    func() nextResult[T] // вызов Next источника
+	res     chan nextResult[T]
+	pending bool // Next запущен, результат ещё не получен
+}
+
+func newDelayedNext[T any](next func() nextResult[T]) *delayedNext[T] {
+	return &delayedNext[T]{next: next, res: make(chan nextResult[T], 1)}
+}
+
+// await запускает Next, если он ещё не запущен, и ждёт его результата. Пока ждёт, вызывает flush,
+// как только непустой буфер acc прождал maxDelay. Ошибка flush прерывает ожидание; Next остаётся запущенным.
+func (d *delayedNext[T]) await(clock Clock, maxDelay time.Duration, acc *batcher.Batcher[T], flush func() error) (nextResult[T], error) {
+	if !d.pending {
+		d.pending = true
+		go func() {
+			d.res <- d.next()
+		}()
+	}
+	for {
+		var timer Timer
+		var due <-chan time.Time
+		if acc.Len() > 0 {
+			timer = clock.NewTimer(maxDelay - acc.Age())
+			due = timer.C()
+		}
+		select {
+		case r := <-d.res:
+			d.pending = false
+			if timer != nil {
+				timer.Stop()
+			}
+			return r, nil
+		case <-due:
+			err := flush()
+			if err != nil {
+				return nextResult[T]{}, err
+			}
+		}
+	}
+}
+
+// wait дожидается запущенного Next и отбрасывает его результат: Pipe не завершается, пока источник в Next.
+// Cookie отброшенного батча не коммитится, и источник доставит его повторно.
+func (d *delayedNext[T]) wait() {
+	if d.pending {
+		<-d.res
+		d.pending = false
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/pipetest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestPipe_MaxDelay_FlushesWhileNextBlocks(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	clock := newFakeClock()
+	release := make(chan struct{})
+	tl := pipetest.NewTimeline().
+		Emit(pipetest.Batch{Items: []any{1, 2}, Cookie: 1}).
+		BlockUntil(release).
+		Emit(pipetest.Batch{Items: []any{3}, Cookie: 2})
+	c := pipetest.NewConsumer()
+
+	done := make(chan error, 1)
+	go func() { done <- Pipe(tl, c, WithClock(clock), WithMaxDelay(time.Second)) }()
+
+	clock.BlockUntil(1) // Буфер {1, 2} ждёт, Next висит на BlockUntil
+	clock.Advance(999 * time.Millisecond)
+	assert.Empty(t, c.Items(), "буфер ещё не прождал MaxDelay")
+	clock.Advance(time.Millisecond)
+	require.Eventually(t, func() bool { return len(tl.Commits()) == 1 }, time.Second, time.Millisecond,
+		"буфер отправлен по таймеру, не дожидаясь Next")
+	assert.Equal(t, [][]any{{1, 2}}, c.Calls())
+
+	close(release)
+	require.Equal(t, io.EOF, <-done)
+	assert.Equal(t, [][]any{{1, 2}, {3}}, c.Calls())
+	assert.Equal(t, []int{1, 2}, tl.Commits())
+}
+
+func TestPipe_MaxDelay_FastProducerKeepsBatching(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	tl := pipetest.NewTimeline().
+		Emit(pipetest.Batch{Items: []any{1}, Cookie: 1}, pipetest.Batch{Items: []any{2}, Cookie: 2}, pipetest.Batch{Items: []any{3}, Cookie: 3}).
+		EndWith(io.EOF)
+	c := pipetest.NewConsumer()
+
+	// Часы стоят: таймер не срабатывает, и порции объединяются как обычно
+	err := Pipe(tl, c, WithClock(newFakeClock()), WithMaxDelay(time.Second))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{{1, 2, 3}}, c.Calls())
+	assert.Equal(t, []int{1, 2, 3}, tl.Commits())
+}
+
+func TestPipe_MaxDelay_ProcessErrorWaitsForNext(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	clock := newFakeClock()
+	release := make(chan struct{})
+	tl := pipetest.NewTimeline().
+		Emit(pipetest.Batch{Items: []any{1}, Cookie: 1}).
+		BlockUntil(release).
+		Emit(pipetest.Batch{Items: []any{2}, Cookie: 2})
+	procErr := errors.New("process failed")
+	c := pipetest.NewConsumer().FailCall(0, procErr)
+
+	done := make(chan error, 1)
+	go func() { done <- Pipe(tl, c, WithClock(clock), WithMaxDelay(time.Second)) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("Pipe завершился, пока источник в Next: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	require.ErrorIs(t, <-done, procErr)
+	assert.Empty(t, tl.Commits(), "батч из прерванного Next не коммитится")
+}
@@ -0,0 +1,62 @@
+package main
+
+// Meta — метаданные одного вызова Next: trace context, tenant id и т.п.
+type Meta map[string]string
+
+// MetaProducerOf — расширение ProducerOf, которое возвращает метаданные вместе с батчем.
+// Если источник реализует его, Pipe вызывает NextWithMeta вместо Next.
+type MetaProducerOf[T any] interface {
+	ProducerOf[T]
+	NextWithMeta() (items []T, cookie int, meta Meta, err error)
+}
+
+// MetaConsumerOf — расширение ConsumerOf, получающее метаданные исходных батчей.
+// Если потребитель реализует его, Pipe вызывает ProcessWithMeta вместо Process.
+type MetaConsumerOf[T any] interface {
+	ConsumerOf[T]
+	ProcessWithMeta(items []T, spans []MetaSpan) error
+}
+
+// MetaProducer — MetaProducerOf для нетипизированных элементов.
+type MetaProducer = MetaProducerOf[any]
+
+// MetaConsumer — MetaConsumerOf для нетипизированных элементов.
+type MetaConsumer = MetaConsumerOf[any]
+
+// MetaSpan связывает метаданные одного вызова Next с диапазоном элементов [Start, End) в переданном срезе items.
+// Накопленный батч может объединять результаты нескольких Next, поэтому спанов может быть несколько.
+type MetaSpan struct {
+	Start int
+	End   int
+	Meta  Meta
+}
+
+// nextWithMeta читает очередной батч, используя MetaProducer, если источник его поддерживает.
+func nextWithMeta[T any](p ProducerOf[T]) ([]T, int, Meta, error) {
+	if mp, ok := p.(MetaProducerOf[T]); ok {
+		return mp.NextWithMeta()
+	}
+	items, cookie, err := p.Next()
+	return items, cookie, nil, err
+}
+
+// process передаёт элементы в потребитель вместе с метаданными, если он их поддерживает.
+func process[T any](c ConsumerOf[T], items []T, spans []MetaSpan) error {
+	if mc, ok := c.(MetaConsumerOf[T]); ok {
+		return mc.ProcessWithMeta(items, spans)
+	}
+	return c.Process(items)
+}
+
+// sliceSpans возвращает спаны, пересекающие [start, end), со смещёнными к началу под-среза границами.
+func sliceSpans(spans []MetaSpan, start, end int) []MetaSpan {
+	var res []MetaSpan
+	for _, s := range spans {
+		lo, hi := max(s.Start, start), min(s.End, end)
+		if lo >= hi {
+			continue
+		}
+		res = append(res, MetaSpan{Start: lo - start, End: hi - start, Meta: s.Meta})
+	}
+	return res
+}
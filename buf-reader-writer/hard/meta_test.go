@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+type metaProducer struct {
+	mockProducer
+	metas []Meta
+}
+
+func (m *metaProducer) NextWithMeta() ([]any, int, Meta, error) {
+	idx := m.callIndex
+	items, cookie, err := m.Next()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return items, cookie, m.metas[idx], nil
+}
+
+type metaConsumer struct {
+	mockConsumer
+	spans [][]MetaSpan
+}
+
+func (m *metaConsumer) ProcessWithMeta(items []any, spans []MetaSpan) error {
+	m.spans = append(m.spans, spans)
+	return m.Process(items)
+}
+
+func TestPipe_Meta_PropagatedWithSpans(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	tenantA := Meta{"tenant": "a"}
+	tenantB := Meta{"tenant": "b"}
+	p := &metaProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, 2), makeItems(2, 3), makeItems(5, MaxItems)},
+			cookies: []int{1, 2, 3},
+			readErr: io.EOF,
+		},
+		metas: []Meta{tenantA, tenantB, tenantA},
+	}
+	c := &metaConsumer{}
+
+	err := Pipe(p, c)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, c.spans, 2)
+	assert.Equal(t, []MetaSpan{{Start: 0, End: 2, Meta: tenantA}, {Start: 2, End: 5, Meta: tenantB}}, c.spans[0])
+	assert.Equal(t, []MetaSpan{{Start: 0, End: MaxItems, Meta: tenantA}}, c.spans[1])
+}
+
+func TestPipe_Meta_SplitAcrossSubBatches(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	tenantA := Meta{"tenant": "a"}
+	tenantB := Meta{"tenant": "b"}
+	p := &metaProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, 3), makeItems(3, 3)},
+			cookies: []int{1, 2},
+			readErr: io.EOF,
+		},
+		metas: []Meta{tenantA, tenantB},
+	}
+	c := &metaConsumer{}
+
+	err := Pipe(p, c, WithMaxProcessItems(4))
+	require.Equal(t, io.EOF, err)
+	require.Len(t, c.spans, 2)
+	assert.Equal(t, []MetaSpan{{Start: 0, End: 3, Meta: tenantA}, {Start: 3, End: 4, Meta: tenantB}}, c.spans[0])
+	assert.Equal(t, []MetaSpan{{Start: 0, End: 2, Meta: tenantB}}, c.spans[1])
+}
+
+func TestPipe_Meta_PlainConsumerStillWorks(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &metaProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 2)}, cookies: []int{1}, readErr: io.EOF},
+		metas:        []Meta{{"tenant": "a"}},
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{makeItems(0, 2)}, c.processed)
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// Metrics получает события жизненного цикла Pipe для счётчиков и гистограмм (например, Prometheus).
+// Вызывается синхронно из горутин Pipe — читающей и воркеров, — поэтому реализация должна быть
+// потокобезопасной и быстрой. Чтобы реализовать только часть методов, встройте NopMetrics.
+type Metrics interface {
+	// OnNext — Next вернул порцию из n элементов.
+	OnNext(n int)
+	// OnFlush — батч из batchSize элементов передан воркеру; latency — сколько его первый элемент ждал в буфере
+	// (по Clock Pipe, включая ожидание свободного воркера).
+	OnFlush(batchSize int, latency time.Duration)
+	// OnCommit — вызван Commit cookie (после повторов WithRetry); err — его результат.
+	OnCommit(cookie int, err error)
+	// OnProcessError — Process вернул ошибку; вызывается на каждую попытку, в том числе перед перезапуском супервизора.
+	OnProcessError(err error)
+}
+
+// NopMetrics — Metrics, который ничего не делает; удобен для встраивания.
+type NopMetrics struct{}
+
+// Проверка, что NopMetrics удовлетворяет интерфейсу Metrics
+var _ Metrics = NopMetrics{}
+
+func (NopMetrics) OnNext(int)                 {}
+func (NopMetrics) OnFlush(int, time.Duration) {}
+func (NopMetrics) OnCommit(int, error)        {}
+func (NopMetrics) OnProcessError(error)       {}
+
+// WithMetrics подключает хуки метрик Pipe.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
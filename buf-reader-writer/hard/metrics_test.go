@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// recordingMetrics запоминает события Metrics.
+type recordingMetrics struct {
+	mu            sync.Mutex
+	nexts         []int
+	flushes       []int
+	latencies     []time.Duration
+	commits       []int
+	commitErrs    []error
+	processErrors []error
+}
+
+func (m *recordingMetrics) OnNext(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nexts = append(m.nexts, n)
+}
+
+func (m *recordingMetrics) OnFlush(batchSize int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flushes = append(m.flushes, batchSize)
+	m.latencies = append(m.latencies, latency)
+}
+
+func (m *recordingMetrics) OnCommit(cookie int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commits = append(m.commits, cookie)
+	m.commitErrs = append(m.commitErrs, err)
+}
+
+func (m *recordingMetrics) OnProcessError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processErrors = append(m.processErrors, err)
+}
+
+func TestPipe_Metrics(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	mp := &mockProducer{
+		batches: [][]any{makeItems(0, 2), makeItems(2, 3), makeItems(5, 1)},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	clock := newFakeClock()
+	p := &tickingProducer{mockProducer: mp, clock: clock, step: time.Second}
+	c := &flakyConsumer{failErr: errors.New("temporary"), failures: 1}
+	m := &recordingMetrics{}
+
+	err := Pipe(p, c, WithClock(clock), WithMetrics(m), WithBatchLimits(ByCount(5)),
+		WithSupervisor(SupervisorPolicy{MaxRestarts: 1}))
+	require.ErrorIs(t, err, io.EOF)
+
+	assert.Equal(t, []int{2, 3, 1}, m.nexts)
+	assert.Equal(t, []int{5, 1}, m.flushes)
+	// Первый батч начат после первого Next и отправлен на третьем; хвост отправлен на io.EOF через секунду
+	assert.Equal(t, []time.Duration{2 * time.Second, time.Second}, m.latencies)
+	assert.Equal(t, []int{1, 2, 3}, m.commits)
+	assert.Equal(t, []error{nil, nil, nil}, m.commitErrs)
+	assert.Equal(t, []error{c.failErr}, m.processErrors, "ошибка Process передаётся без обёртки")
+}
+
+func TestPipe_Metrics_CommitError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches:            [][]any{makeItems(0, 1)},
+		cookies:            []int{7},
+		readErr:            io.EOF,
+		commitErrForCookie: 7,
+		commitErr:          errors.New("broker down"),
+	}
+	m := &recordingMetrics{}
+
+	err := Pipe(p, &mockConsumer{}, WithMetrics(m))
+	require.ErrorIs(t, err, p.commitErr)
+	assert.Equal(t, []int{7}, m.commits)
+	require.Len(t, m.commitErrs, 1)
+	assert.ErrorIs(t, m.commitErrs[0], p.commitErr)
+}
+
+// nextCounter реализует только OnNext, остальное — от NopMetrics.
+type nextCounter struct {
+	NopMetrics
+	nexts int
+}
+
+func (m *nextCounter) OnNext(int) { m.nexts++ }
+
+func TestNopMetrics_Embedding(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	m := &nextCounter{}
+	p := &mockProducer{batches: [][]any{makeItems(0, 1), makeItems(1, 1)}, cookies: []int{1, 2}, readErr: io.EOF}
+
+	err := Pipe(p, &mockConsumer{}, WithMetrics(m))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 2, m.nexts)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Операции, о которых сообщают метрические обёртки.
+const (
+	OpNext    = "next"
+	OpCommit  = "commit"
+	OpNack    = "nack"
+	OpProcess = "process"
+)
+
+// Observer получает результат каждого вызова обёрнутого Producer/Consumer: длительность, число элементов и ошибку.
+// Вызывается из разных горутин Pipe, поэтому реализация должна быть потокобезопасной.
+type Observer interface {
+	Observe(op string, duration time.Duration, items int, err error)
+}
+
+// metricsProducer — Producer, замеряющий Next/NextWithMeta/Commit/Nack и пробрасывающий Pause/Resume.
+// Наружу отдаётся через withProducerExtensions, поэтому его расширения видны, только если их поддерживает p.
+type metricsProducer struct {
+	p     Producer
+	obs   Observer
+	clock Clock
+}
+
+// WrapProducerWithMetrics оборачивает p: каждый Next, Commit и Nack замеряется и передаётся в obs.
+// Возвращаемый Producer реализует те же Nacker, Pausable и MetaProducer, что и p.
+// Из opts используется WithClock: по нему отсчитывается длительность вызовов.
+func WrapProducerWithMetrics(p Producer, obs Observer, opts ...Option) Producer {
+	return withProducerExtensions(&metricsProducer{p: p, obs: obs, clock: newOptions(opts).clock}, p)
+}
+
+func (mp *metricsProducer) Next() (items []any, cookie int, err error) {
+	start := mp.clock.Now()
+	items, cookie, err = mp.p.Next()
+	mp.obs.Observe(OpNext, mp.clock.Now().Sub(start), len(items), err)
+	return items, cookie, err
+}
+
+func (mp *metricsProducer) NextWithMeta() (items []any, cookie int, meta Meta, err error) {
+	start := mp.clock.Now()
+	items, cookie, meta, err = nextWithMeta(mp.p)
+	mp.obs.Observe(OpNext, mp.clock.Now().Sub(start), len(items), err)
+	return items, cookie, meta, err
+}
+
+func (mp *metricsProducer) Commit(cookie int) error {
+	start := mp.clock.Now()
+	err := mp.p.Commit(cookie)
+	mp.obs.Observe(OpCommit, mp.clock.Now().Sub(start), 0, err)
+	return err
+}
+
+func (mp *metricsProducer) Nack(cookie int) error {
+	start := mp.clock.Now()
+	err := mp.p.(Nacker).Nack(cookie)
+	mp.obs.Observe(OpNack, mp.clock.Now().Sub(start), 0, err)
+	return err
+}
+
+func (mp *metricsProducer) Pause()  { mp.p.(Pausable).Pause() }
+func (mp *metricsProducer) Resume() { mp.p.(Pausable).Resume() }
+
+// metricsConsumer — Consumer, замеряющий Process и ProcessWithMeta.
+type metricsConsumer struct {
+	c     Consumer
+	obs   Observer
+	clock Clock
+}
+
+// WrapConsumerWithMetrics оборачивает c: каждый Process замеряется и передаётся в obs.
+// Возвращаемый Consumer реализует MetaConsumer, если его реализует c. Из opts используется WithClock.
+func WrapConsumerWithMetrics(c Consumer, obs Observer, opts ...Option) Consumer {
+	mc := &metricsConsumer{c: c, obs: obs, clock: newOptions(opts).clock}
+	if _, ok := c.(MetaConsumer); ok {
+		return mc
+	}
+	return struct{ Consumer }{mc} // Прячем ProcessWithMeta
+}
+
+func (mc *metricsConsumer) Process(items []any) error {
+	start := mc.clock.Now()
+	err := mc.c.Process(items)
+	mc.obs.Observe(OpProcess, mc.clock.Now().Sub(start), len(items), err)
+	return err
+}
+
+func (mc *metricsConsumer) ProcessWithMeta(items []any, spans []MetaSpan) error {
+	start := mc.clock.Now()
+	err := mc.c.(MetaConsumer).ProcessWithMeta(items, spans)
+	mc.obs.Observe(OpProcess, mc.clock.Now().Sub(start), len(items), err)
+	return err
+}
+
+// extendedProducer — обёртка источника со всеми его опциональными расширениями.
+type extendedProducer interface {
+	Producer
+	Nacker
+	Pausable
+	metaNexter
+}
+
+// metaNexter — метод MetaProducer без методов Producer, чтобы встраиваться рядом с ним.
+type metaNexter interface {
+	NextWithMeta() (items []any, cookie int, meta Meta, err error)
+}
+
+// withProducerExtensions возвращает w с теми же расширениями Nacker, Pausable и MetaProducer, что у inner:
+// иначе Pipe счёл бы обёрнутый источник поддерживающим Nack, паузы и метаданные, или, наоборот, не увидел бы их.
+func withProducerExtensions(w extendedProducer, inner Producer) Producer {
+	_, nack := inner.(Nacker)
+	_, pause := inner.(Pausable)
+	_, meta := inner.(MetaProducer)
+	switch {
+	case nack && pause && meta:
+		return w
+	case nack && pause:
+		return struct {
+			Producer
+			Nacker
+			Pausable
+		}{w, w, w}
+	case nack && meta:
+		return struct {
+			Producer
+			Nacker
+			metaNexter
+		}{w, w, w}
+	case pause && meta:
+		return struct {
+			Producer
+			Pausable
+			metaNexter
+		}{w, w, w}
+	case nack:
+		return struct {
+			Producer
+			Nacker
+		}{w, w}
+	case pause:
+		return struct {
+			Producer
+			Pausable
+		}{w, w}
+	case meta:
+		return struct {
+			Producer
+			metaNexter
+		}{w, w}
+	}
+	return struct{ Producer }{w}
+}
+
+// OpStats — накопленная статистика одной операции.
+type OpStats struct {
+	Calls    int
+	Errors   int
+	Items    int
+	Duration time.Duration // суммарное время вызовов
+}
+
+// CounterObserver — простой Observer, накапливающий статистику по операциям в памяти.
+type CounterObserver struct {
+	mu    sync.Mutex
+	stats map[string]OpStats
+}
+
+// Проверка, что CounterObserver удовлетворяет интерфейсу Observer
+var _ Observer = (*CounterObserver)(nil)
+
+func (co *CounterObserver) Observe(op string, duration time.Duration, items int, err error) {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	if co.stats == nil {
+		co.stats = make(map[string]OpStats)
+	}
+	s := co.stats[op]
+	s.Calls++
+	s.Items += items
+	s.Duration += duration
+	if err != nil {
+		s.Errors++
+	}
+	co.stats[op] = s
+}
+
+// Snapshot возвращает копию накопленной статистики.
+func (co *CounterObserver) Snapshot() map[string]OpStats {
+	co.mu.Lock()
+	defer co.mu.Unlock()
+	res := make(map[string]OpStats, len(co.stats))
+	for op, s := range co.stats {
+		res[op] = s
+	}
+	return res
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWrappers_CountCallsItemsAndErrors(t *testing.T) {
+	obs := &CounterObserver{}
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 3), makeItems(3, MaxItems)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(WrapProducerWithMetrics(p, obs), WrapConsumerWithMetrics(&mockConsumer{}, obs))
+	require.Equal(t, io.EOF, err)
+
+	stats := obs.Snapshot()
+	assert.Equal(t, 3, stats[OpNext].Calls)
+	assert.Equal(t, 1, stats[OpNext].Errors, "io.EOF считается ошибкой вызова Next")
+	assert.Equal(t, 3+MaxItems, stats[OpNext].Items)
+	assert.Equal(t, 2, stats[OpProcess].Calls)
+	assert.Equal(t, 3+MaxItems, stats[OpProcess].Items)
+	assert.Equal(t, 2, stats[OpCommit].Calls)
+	assert.Zero(t, stats[OpCommit].Errors)
+}
+
+func TestMetricsWrappers_ProcessErrorAndNack(t *testing.T) {
+	obs := &CounterObserver{}
+	p := &nackProducer{mockProducer: mockProducer{batches: [][]any{{1}}, cookies: []int{1}, readErr: io.EOF}}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+
+	err := Pipe(WrapProducerWithMetrics(p, obs), WrapConsumerWithMetrics(c, obs))
+	require.ErrorIs(t, err, c.procErr)
+
+	stats := obs.Snapshot()
+	assert.Equal(t, 1, stats[OpProcess].Errors)
+	assert.Equal(t, 1, stats[OpNack].Calls, "Nack должен пробрасываться через обёртку")
+	assert.Equal(t, []int{1}, p.nacked)
+}
+
+func TestMetricsWrappers_ExposeOnlyInnerExtensions(t *testing.T) {
+	obs := &CounterObserver{}
+
+	plain := WrapProducerWithMetrics(&mockProducer{}, obs)
+	_, isNacker := plain.(Nacker)
+	_, isPausable := plain.(Pausable)
+	_, isMeta := plain.(MetaProducer)
+	assert.False(t, isNacker || isPausable || isMeta, "у обычного источника обёртка не должна добавлять расширений")
+	_, isMeta = WrapConsumerWithMetrics(&mockConsumer{}, obs).(MetaConsumer)
+	assert.False(t, isMeta)
+
+	pp := &pausableProducer{}
+	paused := WrapProducerWithMetrics(pp, obs)
+	require.Implements(t, (*Pausable)(nil), paused)
+	_, isNacker = paused.(Nacker)
+	assert.False(t, isNacker)
+	paused.(Pausable).Pause()
+	paused.(Pausable).Resume()
+	assert.Equal(t, 1, pp.paused)
+	assert.Equal(t, 1, pp.resumed)
+}
+
+func TestMetricsWrappers_ForwardMeta(t *testing.T) {
+	obs := &CounterObserver{}
+	tenant := Meta{"tenant": "a"}
+	p := &metaProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 2)}, cookies: []int{1}, readErr: io.EOF},
+		metas:        []Meta{tenant},
+	}
+	c := &metaConsumer{}
+
+	err := Pipe(WrapProducerWithMetrics(p, obs), WrapConsumerWithMetrics(c, obs))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]MetaSpan{{{Start: 0, End: 2, Meta: tenant}}}, c.spans, "метаданные должны проходить через обёртки")
+	assert.Equal(t, 2, obs.Snapshot()[OpNext].Calls, "NextWithMeta замеряется как Next")
+}
+
+// advancingConsumer сдвигает часы на step в каждом Process.
+type advancingConsumer struct {
+	mockConsumer
+	clock fakeClock
+	step  time.Duration
+}
+
+func (c *advancingConsumer) Process(items []any) error {
+	c.clock.Advance(c.step)
+	return c.mockConsumer.Process(items)
+}
+
+func TestMetricsWrappers_UseClockOption(t *testing.T) {
+	obs := &CounterObserver{}
+	clock := newFakeClock()
+	c := WrapConsumerWithMetrics(&advancingConsumer{clock: clock, step: 3 * time.Second}, obs, WithClock(clock))
+
+	require.NoError(t, c.Process(makeItems(0, 1)))
+	assert.Equal(t, 3*time.Second, obs.Snapshot()[OpProcess].Duration)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Nacker — опциональное расширение Producer для источников с повторной доставкой (очереди).
+// Если Process для батча завершился ошибкой, Pipe вызывает Nack для всех его cookies по порядку,
+// чтобы источник сразу вернул сообщения в очередь, а не держал их до истечения таймаута видимости.
+type Nacker interface {
+	// Nack is used to mark data batch as failed and request redelivery
+	Nack(cookie int) error
+}
+
+// nackAll вызывает Nack для всех cookies, если источник p поддерживает Nacker.
+// Ошибки Nack не прерывают цикл: каждый cookie должен получить шанс на повторную доставку.
+func nackAll(p any, cookies []int) error {
+	n, ok := p.(Nacker)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, ck := range cookies {
+		err := n.Nack(ck)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error nacking cookie %d: %w", ck, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+type nackProducer struct {
+	mockProducer
+
+	nackErr error
+	nacked  []int
+}
+
+func (m *nackProducer) Nack(cookie int) error {
+	m.nacked = append(m.nacked, cookie)
+	return m.nackErr
+}
+
+func TestPipe_ProcessError_NacksBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	var err error
+	firstBatchSize := MaxItems / 2
+	secondBatchSize := MaxItems - firstBatchSize
+
+	p := &nackProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{
+				makeItems(0, firstBatchSize),
+				makeItems(firstBatchSize, secondBatchSize),
+				makeItems(MaxItems, 1), // overflow triggers Process
+			},
+			cookies: []int{1, 2, 3},
+			readErr: io.EOF,
+		},
+	}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+
+	err = Pipe(p, c)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, c.procErr), "ожидалась ошибка обработки, получено: %v", err)
+	assert.Equal(t, []int{1, 2}, p.nacked, "ожидался Nack всех cookies упавшего батча")
+	assert.Len(t, p.commitAttempts, 0, "не должно быть вызовов Commit при ошибке Process")
+}
+
+func TestPipe_ProcessError_NackErrorJoined(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	var err error
+	p := &nackProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, 1)},
+			cookies: []int{1},
+			readErr: io.EOF,
+		},
+		nackErr: errors.New("nack failed"),
+	}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+
+	err = Pipe(p, c)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, c.procErr), "ожидалась ошибка обработки, получено: %v", err)
+	assert.True(t, errors.Is(err, p.nackErr), "ожидалась ошибка Nack, получено: %v", err)
+}
+
+func TestPipe_ProcessError_WithoutNacker(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 1)},
+		cookies: []int{1},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+
+	err := Pipe(p, c)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, c.procErr), "ожидалась ошибка обработки, получено: %v", err)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PartitionOffset — позиция записи в партиции Kafka-подобного лога.
+type PartitionOffset struct {
+	Partition int32
+	Offset    int64
+}
+
+// FetchPoller — минимальный интерфейс Kafka-подобного клиента.
+type FetchPoller interface {
+	// Poll возвращает очередную пачку записей одной партиции и позицию последней записи в ней.
+	// Пустая пачка допустима; по завершении потока возвращается io.EOF.
+	Poll() (records []any, last PartitionOffset, err error)
+	// CommitOffset фиксирует позицию, с которой нужно продолжить чтение после рестарта (следующий offset).
+	CommitOffset(next PartitionOffset) error
+}
+
+// OffsetProducer адаптирует FetchPoller к Producer: каждая пачка получает собственный cookie,
+// а Commit(cookie) превращается в CommitOffset(last+1) для соответствующей партиции.
+// Offset работает как водяной знак: фиксируется только продвижение вперёд, коммит более старого offset пропускается.
+type OffsetProducer struct {
+	poller FetchPoller
+
+	mu         sync.Mutex              // защищает поля ниже: Next и Commit вызываются из разных горутин
+	nextCookie int                     // следующий выдаваемый cookie
+	pending    map[int]PartitionOffset // выданные, но ещё не закоммиченные cookies
+	committed  map[int32]int64         // последний закоммиченный (следующий к чтению) offset по партициям
+	empty      map[int]struct{}        // cookies пустых пачек: коммитить нечего
+}
+
+// Проверка, что OffsetProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*OffsetProducer)(nil)
+
+// NewOffsetProducer создаёт Producer поверх Kafka-подобного клиента.
+func NewOffsetProducer(poller FetchPoller) *OffsetProducer {
+	return &OffsetProducer{
+		poller:    poller,
+		pending:   make(map[int]PartitionOffset),
+		committed: make(map[int32]int64),
+		empty:     make(map[int]struct{}),
+	}
+}
+
+// Next опрашивает клиента и сопоставляет пачке новый cookie.
+func (op *OffsetProducer) Next() (items []any, cookie int, err error) {
+	records, last, err := op.poller.Poll()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	cookie = op.nextCookie
+	op.nextCookie++
+	if len(records) == 0 {
+		op.empty[cookie] = struct{}{}
+	} else {
+		op.pending[cookie] = last
+	}
+
+	return records, cookie, nil
+}
+
+// Commit фиксирует offset, следующий за последней записью пачки, если он продвигает водяной знак партиции.
+func (op *OffsetProducer) Commit(cookie int) error {
+	op.mu.Lock()
+	if _, ok := op.empty[cookie]; ok {
+		delete(op.empty, cookie)
+		op.mu.Unlock()
+		return nil
+	}
+	last, ok := op.pending[cookie]
+	if !ok {
+		op.mu.Unlock()
+		return fmt.Errorf("unknown cookie %d", cookie)
+	}
+	next := PartitionOffset{Partition: last.Partition, Offset: last.Offset + 1}
+	if cur, ok := op.committed[next.Partition]; ok && cur >= next.Offset { // Водяной знак уже не ниже
+		delete(op.pending, cookie)
+		op.mu.Unlock()
+		return nil
+	}
+	op.mu.Unlock()
+
+	err := op.poller.CommitOffset(next)
+	if err != nil {
+		return fmt.Errorf("commit offset %d of partition %d: %w", next.Offset, next.Partition, err)
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	delete(op.pending, cookie)
+	if cur, ok := op.committed[next.Partition]; !ok || next.Offset > cur {
+		op.committed[next.Partition] = next.Offset
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pollResult struct {
+	records []any
+	last    PartitionOffset
+}
+
+type mockPoller struct {
+	polls     []pollResult
+	idx       int
+	commitErr error
+	committed []PartitionOffset
+}
+
+func (m *mockPoller) Poll() ([]any, PartitionOffset, error) {
+	if m.idx >= len(m.polls) {
+		return nil, PartitionOffset{}, io.EOF
+	}
+	r := m.polls[m.idx]
+	m.idx++
+	return r.records, r.last, nil
+}
+
+func (m *mockPoller) CommitOffset(next PartitionOffset) error {
+	if m.commitErr != nil {
+		return m.commitErr
+	}
+	m.committed = append(m.committed, next)
+	return nil
+}
+
+func TestOffsetProducer_CommitsNextOffsetPerPartition(t *testing.T) {
+	poller := &mockPoller{polls: []pollResult{
+		{records: makeItems(0, 2), last: PartitionOffset{Partition: 0, Offset: 11}},
+		{records: makeItems(2, 1), last: PartitionOffset{Partition: 1, Offset: 5}},
+		{records: nil},
+		{records: makeItems(3, 3), last: PartitionOffset{Partition: 0, Offset: 14}},
+	}}
+	c := &mockConsumer{}
+
+	err := Pipe(NewOffsetProducer(poller), c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, []PartitionOffset{{0, 12}, {1, 6}, {0, 15}}, poller.committed)
+	assert.Equal(t, [][]any{makeItems(0, 6)}, c.processed)
+}
+
+func TestOffsetProducer_WatermarkSkipsStaleOffset(t *testing.T) {
+	poller := &mockPoller{polls: []pollResult{
+		{records: makeItems(0, 1), last: PartitionOffset{Partition: 0, Offset: 20}},
+		{records: makeItems(1, 1), last: PartitionOffset{Partition: 0, Offset: 7}}, // передоставка после ребаланса
+	}}
+	op := NewOffsetProducer(poller)
+
+	_, ck1, err := op.Next()
+	require.NoError(t, err)
+	_, ck2, err := op.Next()
+	require.NoError(t, err)
+
+	require.NoError(t, op.Commit(ck1))
+	require.NoError(t, op.Commit(ck2))
+	assert.Equal(t, []PartitionOffset{{0, 21}}, poller.committed, "offset не должен откатываться назад")
+}
+
+func TestOffsetProducer_Errors(t *testing.T) {
+	poller := &mockPoller{
+		polls:     []pollResult{{records: makeItems(0, 1), last: PartitionOffset{Offset: 1}}},
+		commitErr: errors.New("broker unavailable"),
+	}
+	op := NewOffsetProducer(poller)
+
+	require.Error(t, op.Commit(42), "неизвестный cookie должен давать ошибку")
+
+	_, ck, err := op.Next()
+	require.NoError(t, err)
+	require.ErrorIs(t, op.Commit(ck), poller.commitErr)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Option — функциональная опция для настройки Pipe.
+type Option func(*options)
+
+// options — итоговая конфигурация Pipe. Значения по умолчанию задаёт newOptions.
+type options struct {
+	clock  Clock         // источник времени для time-based механизмов
+	logger Logger        // хук структурного логирования; nil — события не пишутся
+	dedup  *cookieWindow // окно обнаружения дублей cookie; nil — проверка выключена
+	tail   TailPolicy    // обработка накопленного буфера на io.EOF
+
+	supervisor      *SupervisorPolicy // политика перезапуска воркера; nil — выход при первой ошибке
+	retry           *RetryPolicy      // повторы отдельных Next и Commit; nil — без повторов
+	deadLetter      any               // DeadLetterOf[T] для необработанных батчей; nil — выход при ошибке Process
+	metrics         Metrics           // хуки метрик; nil — метрики не собираются
+	maxProcessItems int               // лимит элементов на один вызов Process; <= 0 — без дробления
+	dryRun          bool              // не вызывать Commit и Nack
+	workers         int               // число параллельно обрабатываемых батчей; <= 1 — один воркер
+	batch           batchLimits       // дополнительные условия отправки накопленного буфера
+	policy          BatchPolicy       // когда отправлять буфер вместо MaxItems; nil — MaxItems
+	maxDelay        time.Duration     // сколько буфер может ждать, в том числе пока Pipe в Next; <= 0 — без ограничения
+	ctx             context.Context   // родительский контекст Pipe
+	shutdown        ShutdownPolicy    // завершение по отмене ctx
+	summary         *Summary          // куда записать итоги работы; nil — не нужно
+}
+
+// newOptions применяет опции поверх конфигурации по умолчанию.
+func newOptions(opts []Option) options {
+	o := options{
+		clock: systemClock{},
+		ctx:   context.Background(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMaxProcessItems ограничивает число элементов в одном вызове Process, не меняя размер накопления MaxItems:
+// накопленный батч передаётся потребителю последовательными под-срезами не длиннее n.
+// Cookies батча коммитятся только после обработки всех под-срезов.
+func WithMaxProcessItems(n int) Option {
+	return func(o *options) {
+		o.maxProcessItems = n
+	}
+}
+
+// WithContext задаёт родительский контекст: после его отмены Pipe завершается с ctx.Err(),
+// не дожидаясь io.EOF источника. Отмена проверяется между вызовами Next; судьбу буфера и воркера задаёт WithShutdown.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		o.ctx = ctx
+	}
+}
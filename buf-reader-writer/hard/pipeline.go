@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Stage — шаг преобразования батча перед Consumer: получает элементы и возвращает новые (можно меньше или больше).
+// Ошибка шага обрабатывается Pipe как ошибка Process.
+type Stage func(items []any) ([]any, error)
+
+// stageConsumer прогоняет батч через шаги по порядку и отдаёт результат next.
+// Метаданные через шаги не пробрасываются: шаг может изменить число элементов, и спаны потеряют смысл.
+type stageConsumer struct {
+	stages []Stage
+	next   Consumer
+}
+
+func (sc *stageConsumer) Process(items []any) error {
+	for _, stage := range sc.stages {
+		var err error
+		items, err = stage(items)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 { // Шаги отфильтровали всё: потребителю передавать нечего
+			return nil
+		}
+	}
+	return sc.next.Process(items)
+}
+
+// Pipeline — декларативная сборка Pipe: источник, условия батча, шаги, обёртки и потребитель
+// вместо вложенных вызовов конструкторов и списка опций:
+//
+//	err := From(producer).
+//		Batch(ByCount(1000), ByBytes(1<<20), ByAge(time.Second)).
+//		Via(parse, enrich).
+//		To(consumer).
+//		Run(ctx)
+//
+// Методы возвращают тот же *Pipeline; собранный пайплайн запускается Run.
+type Pipeline struct {
+	p             Producer
+	c             Consumer
+	stages        []Stage
+	wrapProducers []func(Producer) Producer
+	wrapConsumers []func(Consumer) Consumer
+	opts          []Option
+}
+
+// From начинает сборку пайплайна с источника p.
+func From(p Producer) *Pipeline {
+	return &Pipeline{p: p}
+}
+
+// Batch задаёт условия отправки накопленного буфера (см. WithBatchLimits).
+func (pl *Pipeline) Batch(limits ...BatchLimit) *Pipeline {
+	pl.opts = append(pl.opts, WithBatchLimits(limits...))
+	return pl
+}
+
+// Via добавляет шаги преобразования; они выполняются по порядку перед Consumer.
+func (pl *Pipeline) Via(stages ...Stage) *Pipeline {
+	pl.stages = append(pl.stages, stages...)
+	return pl
+}
+
+// WrapProducer добавляет обёртку источника (например, метрики или запись); обёртки применяются в порядке добавления.
+func (pl *Pipeline) WrapProducer(wrap func(Producer) Producer) *Pipeline {
+	pl.wrapProducers = append(pl.wrapProducers, wrap)
+	return pl
+}
+
+// WrapConsumer добавляет обёртку потребителя; обёртки применяются в порядке добавления, до шагов Via:
+// шаги выполняются снаружи всех обёрток.
+func (pl *Pipeline) WrapConsumer(wrap func(Consumer) Consumer) *Pipeline {
+	pl.wrapConsumers = append(pl.wrapConsumers, wrap)
+	return pl
+}
+
+// With добавляет произвольные опции Pipe (WithWorkers, WithSupervisor, WithLogger и т.д.).
+func (pl *Pipeline) With(opts ...Option) *Pipeline {
+	pl.opts = append(pl.opts, opts...)
+	return pl
+}
+
+// To задаёт потребителя.
+func (pl *Pipeline) To(c Consumer) *Pipeline {
+	pl.c = c
+	return pl
+}
+
+// Run собирает и запускает Pipe, пока источник не вернёт io.EOF, не случится ошибка или не отменят ctx.
+// В отличие от Pipe, штатное завершение по io.EOF возвращает nil.
+func (pl *Pipeline) Run(ctx context.Context) error {
+	if pl.p == nil {
+		return errors.New("pipeline: producer is not set")
+	}
+	if pl.c == nil {
+		return errors.New("pipeline: consumer is not set")
+	}
+
+	p := pl.p
+	for _, wrap := range pl.wrapProducers {
+		p = wrap(p)
+	}
+	c := pl.c
+	for _, wrap := range pl.wrapConsumers {
+		c = wrap(c)
+	}
+	if len(pl.stages) > 0 {
+		c = &stageConsumer{stages: pl.stages, next: c}
+	}
+
+	opts := append([]Option{WithContext(ctx)}, pl.opts...)
+	err := Pipe(p, c, opts...)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline_AssemblesStagesWrappersAndOptions(t *testing.T) {
+	p := &mockProducer{
+		batches: [][]any{{"a", "bb"}, {"ccc"}, {"dddd", "e"}},
+		cookies: []int{1, 2, 3},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+	obs := &CounterObserver{}
+	var summary Summary
+
+	upper := func(items []any) ([]any, error) {
+		out := make([]any, len(items))
+		for i, it := range items {
+			out[i] = strings.ToUpper(it.(string))
+		}
+		return out, nil
+	}
+	dropShort := func(items []any) ([]any, error) {
+		var out []any
+		for _, it := range items {
+			if len(it.(string)) > 1 {
+				out = append(out, it)
+			}
+		}
+		return out, nil
+	}
+
+	err := From(p).
+		Batch(ByCount(3)).
+		Via(dropShort, upper).
+		WrapProducer(func(p Producer) Producer { return WrapProducerWithMetrics(p, obs) }).
+		With(WithSummary(&summary)).
+		To(c).
+		Run(context.Background())
+	require.NoError(t, err, "штатное завершение источника — не ошибка")
+
+	assert.Equal(t, [][]any{{"BB", "CCC"}, {"DDDD"}}, c.processed)
+	assert.Equal(t, []int{1, 2, 3}, p.committed)
+	assert.Equal(t, 2, summary.Batches)
+	assert.Equal(t, 3, obs.Snapshot()[OpCommit].Calls)
+}
+
+func TestPipeline_StageErrorNacksBatch(t *testing.T) {
+	p := &nackProducer{mockProducer: mockProducer{batches: [][]any{{1}}, cookies: []int{7}, readErr: io.EOF}}
+	stageErr := errors.New("bad record")
+
+	err := From(p).
+		Via(func([]any) ([]any, error) { return nil, stageErr }).
+		To(&mockConsumer{}).
+		Run(context.Background())
+	require.ErrorIs(t, err, stageErr)
+	assert.Equal(t, []int{7}, p.nacked)
+	assert.Empty(t, p.committed)
+}
+
+// endlessProducer отдаёт батчи без конца, вызывая onNext перед каждым.
+type endlessProducer struct {
+	next   int
+	onNext func(n int)
+}
+
+func (e *endlessProducer) Next() ([]any, int, error) {
+	e.next++
+	e.onNext(e.next)
+	return []any{e.next}, e.next, nil
+}
+
+func (e *endlessProducer) Commit(int) error { return nil }
+
+func TestPipeline_RunStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &endlessProducer{onNext: func(n int) {
+		if n == 3 {
+			cancel()
+		}
+	}}
+
+	done := make(chan error, 1)
+	go func() { done <- From(p).To(&mockConsumer{}).Run(ctx) }()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run не завершился после отмены контекста")
+	}
+}
+
+func TestPipeline_RequiresProducerAndConsumer(t *testing.T) {
+	assert.Error(t, From(nil).To(&mockConsumer{}).Run(context.Background()))
+	assert.Error(t, From(&mockProducer{}).Run(context.Background()))
+}
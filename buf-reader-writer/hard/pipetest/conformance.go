@@ -0,0 +1,215 @@
+package pipetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// PipeProducer — контракт источника Pipe (структурная копия интерфейса Producer).
+type PipeProducer interface {
+	Next() (items []any, cookie int, err error)
+	Commit(cookie int) error
+}
+
+// PipeConsumer — контракт потребителя Pipe (структурная копия интерфейса Consumer).
+type PipeConsumer interface {
+	Process(items []any) error
+}
+
+// defaultMaxBatches — сколько пачек читать в поисках io.EOF, если ProducerConformance.MaxBatches не задан.
+const defaultMaxBatches = 10_000
+
+// ProducerConformance описывает проверяемый источник.
+type ProducerConformance struct {
+	// New создаёт новый экземпляр источника для каждой проверки. Источник должен завершаться io.EOF
+	// не позже чем через MaxBatches пачек. Освобождение ресурсов — через t.Cleanup.
+	New        func(t *testing.T) PipeProducer
+	MaxBatches int // предел пачек до io.EOF; 0 — defaultMaxBatches
+}
+
+// RunProducerConformance проверяет детали контракта, на которые опирается at-least-once модель Pipe:
+// конец потока сигнализируется именно io.EOF (Pipe сравнивает ошибку без errors.Is) и повторяется при
+// следующих вызовах Next, Commit каждого cookie по порядку успешен и идемпотентен (после перезапуска
+// воркера или повторной доставки cookie может быть закоммичен снова), а выданные срезы элементов
+// не переиспользуются источником (Pipe накапливает их до отправки в Consumer).
+func RunProducerConformance(t *testing.T, pc ProducerConformance) {
+	t.Helper()
+	maxBatches := pc.MaxBatches
+	if maxBatches <= 0 {
+		maxBatches = defaultMaxBatches
+	}
+
+	t.Run("EOF", func(t *testing.T) {
+		p := pc.New(t)
+		if _, err := drain(p, maxBatches); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			items, _, err := p.Next()
+			if err != io.EOF { // Pipe сравнивает ошибку с io.EOF напрямую, без errors.Is
+				t.Fatalf("Next after EOF #%d: err = %v, want io.EOF", i, err)
+			}
+			if len(items) != 0 {
+				t.Fatalf("Next after EOF #%d returned %d items", i, len(items))
+			}
+		}
+	})
+
+	t.Run("CommitInOrder", func(t *testing.T) {
+		p := pc.New(t)
+		batches, err := drain(p, maxBatches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range batches {
+			if err = p.Commit(b.Cookie); err != nil {
+				t.Fatalf("Commit(%d): %v", b.Cookie, err)
+			}
+		}
+	})
+
+	t.Run("CommitIdempotent", func(t *testing.T) {
+		p := pc.New(t)
+		batches, err := drain(p, maxBatches)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range batches {
+			for attempt := 1; attempt <= 2; attempt++ {
+				if err = p.Commit(b.Cookie); err != nil {
+					t.Fatalf("Commit(%d) attempt %d: %v", b.Cookie, attempt, err)
+				}
+			}
+		}
+	})
+
+	t.Run("ItemsNotReused", func(t *testing.T) {
+		p := pc.New(t)
+		var returned, snapshots [][]any
+		for i := 0; ; i++ {
+			if i == maxBatches {
+				t.Fatalf("no io.EOF after %d batches", maxBatches)
+			}
+			items, _, err := p.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next #%d: %v", i, err)
+			}
+			returned = append(returned, items)
+			snapshots = append(snapshots, deepCopyItems(items))
+		}
+		for i := range returned {
+			if !reflect.DeepEqual(returned[i], snapshots[i]) {
+				t.Fatalf("items of batch %d were modified by later Next calls: the producer must not reuse returned slices", i)
+			}
+		}
+	})
+}
+
+// ConsumerConformance описывает проверяемого потребителя.
+type ConsumerConformance struct {
+	// New создаёт новый экземпляр потребителя для каждой проверки.
+	New func(t *testing.T) PipeConsumer
+	// Item возвращает i-й элемент входных данных; nil — целые числа i.
+	Item func(i int) any
+	// Received возвращает всё, что c принял, в порядке поступления. Nil — проверка
+	// неудержания входного среза пропускается.
+	Received func(t *testing.T, c PipeConsumer) []any
+}
+
+// RunConsumerConformance проверяет, что Process не изменяет переданный срез (после ошибки Pipe может
+// передать его остаток повторно), не удерживает его после возврата (Pipe переиспользует буфер)
+// и допускает повторную доставку того же батча.
+func RunConsumerConformance(t *testing.T, cc ConsumerConformance) {
+	t.Helper()
+	item := cc.Item
+	if item == nil {
+		item = func(i int) any { return i }
+	}
+	makeBatch := func(from, n int) []any {
+		items := make([]any, n)
+		for i := range items {
+			items[i] = item(from + i)
+		}
+		return items
+	}
+
+	t.Run("InputNotModified", func(t *testing.T) {
+		c := cc.New(t)
+		items := makeBatch(0, 16)
+		want := deepCopyItems(items)
+		if err := c.Process(items); err != nil {
+			t.Fatalf("Process: %v", err)
+		}
+		if !reflect.DeepEqual(items, want) {
+			t.Fatal("Process modified the input slice")
+		}
+	})
+
+	t.Run("InputNotRetained", func(t *testing.T) {
+		if cc.Received == nil {
+			t.Skip("Received не задан")
+		}
+		c := cc.New(t)
+		var want []any
+		for b := 0; b < 3; b++ {
+			items := makeBatch(b*8, 8)
+			want = append(want, deepCopyItems(items)...)
+			if err := c.Process(items); err != nil {
+				t.Fatalf("Process batch %d: %v", b, err)
+			}
+			for i := range items { // Pipe переиспользует буфер: затираем его сразу после возврата
+				items[i] = item(-1 - i)
+			}
+		}
+		if got := cc.Received(t, c); !reflect.DeepEqual(got, want) {
+			t.Fatalf("received %v, want %v: the consumer must copy items it keeps after Process returns", got, want)
+		}
+	})
+
+	t.Run("Redelivery", func(t *testing.T) {
+		c := cc.New(t)
+		items := makeBatch(0, 4)
+		for attempt := 1; attempt <= 2; attempt++ {
+			if err := c.Process(items); err != nil {
+				t.Fatalf("Process attempt %d: %v", attempt, err)
+			}
+		}
+	})
+}
+
+// drain читает источник до io.EOF.
+func drain(p PipeProducer, maxBatches int) ([]Batch, error) {
+	var batches []Batch
+	for i := 0; i < maxBatches; i++ {
+		items, cookie, err := p.Next()
+		if err == io.EOF {
+			return batches, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("Next #%d returned wrapped EOF %q: Pipe only recognises io.EOF itself", i, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Next #%d: %w", i, err)
+		}
+		batches = append(batches, Batch{Items: items, Cookie: cookie})
+	}
+	return nil, fmt.Errorf("no io.EOF after %d batches", maxBatches)
+}
+
+// deepCopyItems копирует срез элементов, включая содержимое элементов-срезов байт.
+func deepCopyItems(items []any) []any {
+	out := make([]any, len(items))
+	for i, it := range items {
+		if b, ok := it.([]byte); ok {
+			it = append([]byte(nil), b...)
+		}
+		out[i] = it
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package pipetest
+
+import (
+	"testing"
+)
+
+func TestRunProducerConformance_Producer(t *testing.T) {
+	RunProducerConformance(t, ProducerConformance{
+		New: func(*testing.T) PipeProducer {
+			return NewProducer(Batch{Items: []any{1, 2}, Cookie: 1}, Batch{Items: []any{3}, Cookie: 2})
+		},
+	})
+}
+
+func TestRunProducerConformance_Timeline(t *testing.T) {
+	RunProducerConformance(t, ProducerConformance{
+		New: func(*testing.T) PipeProducer {
+			return NewTimeline().Emit(Batch{Items: []any{[]byte("a")}, Cookie: 1}, Batch{Items: []any{[]byte("b")}, Cookie: 2})
+		},
+	})
+}
+
+func TestRunConsumerConformance_Consumer(t *testing.T) {
+	RunConsumerConformance(t, ConsumerConformance{
+		New:      func(*testing.T) PipeConsumer { return NewConsumer() },
+		Received: func(_ *testing.T, c PipeConsumer) []any { return c.(*Consumer).Items() },
+	})
+}
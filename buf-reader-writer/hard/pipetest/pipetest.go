@@ -0,0 +1,173 @@
+// Package pipetest содержит тестовые двойники Producer и Consumer для модульных тестов пайплайнов на основе Pipe.
+//
+// Типы удовлетворяют интерфейсам Producer, Consumer и Nacker структурно, поэтому пакет не зависит от пакета с Pipe.
+package pipetest
+
+import (
+	"io"
+	"sync"
+)
+
+// Batch — одна заранее заданная пачка, которую Producer вернёт из Next.
+type Batch struct {
+	Items  []any
+	Cookie int
+}
+
+// Producer — сценарный Producer: отдаёт пачки по порядку, затем возвращает конечную ошибку (по умолчанию io.EOF).
+// Ошибки можно внедрить на конкретные cookie для Next, Commit и Nack. Все вызовы записываются.
+type Producer struct {
+	mu         sync.Mutex
+	batches    []Batch
+	pos        int
+	endErr     error
+	nextErrs   map[int]error
+	commitErrs map[int]error
+	nackErrs   map[int]error
+	nextCalls  int
+	commits    []int
+	nacks      []int
+}
+
+// NewProducer создаёт Producer, который по очереди вернёт batches.
+func NewProducer(batches ...Batch) *Producer {
+	return &Producer{
+		batches:    batches,
+		endErr:     io.EOF,
+		nextErrs:   make(map[int]error),
+		commitErrs: make(map[int]error),
+		nackErrs:   make(map[int]error),
+	}
+}
+
+// EndWith задаёт ошибку, которую Next вернёт после исчерпания пачек.
+func (p *Producer) EndWith(err error) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endErr = err
+	return p
+}
+
+// FailNext заставляет Next вернуть err вместо пачки с указанным cookie. Пачка при этом считается выданной.
+func (p *Producer) FailNext(cookie int, err error) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextErrs[cookie] = err
+	return p
+}
+
+// FailCommit заставляет Commit(cookie) вернуть err.
+func (p *Producer) FailCommit(cookie int, err error) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commitErrs[cookie] = err
+	return p
+}
+
+// FailNack заставляет Nack(cookie) вернуть err.
+func (p *Producer) FailNack(cookie int, err error) *Producer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nackErrs[cookie] = err
+	return p
+}
+
+func (p *Producer) Next() (items []any, cookie int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nextCalls++
+	if p.pos >= len(p.batches) {
+		return nil, 0, p.endErr
+	}
+	b := p.batches[p.pos]
+	p.pos++
+	if err = p.nextErrs[b.Cookie]; err != nil {
+		return nil, 0, err
+	}
+	return append([]any(nil), b.Items...), b.Cookie, nil
+}
+
+func (p *Producer) Commit(cookie int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commits = append(p.commits, cookie)
+	return p.commitErrs[cookie]
+}
+
+func (p *Producer) Nack(cookie int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nacks = append(p.nacks, cookie)
+	return p.nackErrs[cookie]
+}
+
+// NextCalls возвращает число вызовов Next.
+func (p *Producer) NextCalls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nextCalls
+}
+
+// Commits возвращает cookie всех вызовов Commit в порядке вызова, включая неуспешные.
+func (p *Producer) Commits() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.commits...)
+}
+
+// Nacks возвращает cookie всех вызовов Nack в порядке вызова.
+func (p *Producer) Nacks() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.nacks...)
+}
+
+// Consumer — Consumer, записывающий все вызовы Process. Ошибки внедряются по номеру вызова (с нуля).
+type Consumer struct {
+	mu        sync.Mutex
+	processed [][]any
+	errs      map[int]error
+}
+
+// NewConsumer создаёт Consumer, успешно принимающий любые пачки.
+func NewConsumer() *Consumer {
+	return &Consumer{errs: make(map[int]error)}
+}
+
+// FailCall заставляет вызов Process с номером call (с нуля) вернуть err. Пачка при этом всё равно записывается.
+func (c *Consumer) FailCall(call int, err error) *Consumer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errs[call] = err
+	return c
+}
+
+func (c *Consumer) Process(items []any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call := len(c.processed)
+	c.processed = append(c.processed, append([]any(nil), items...))
+	return c.errs[call]
+}
+
+// Calls возвращает копии всех пачек, переданных в Process.
+func (c *Consumer) Calls() [][]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([][]any, len(c.processed))
+	for i, items := range c.processed {
+		res[i] = append([]any(nil), items...)
+	}
+	return res
+}
+
+// Items возвращает все элементы, переданные в Process, в порядке поступления.
+func (c *Consumer) Items() []any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var res []any
+	for _, items := range c.processed {
+		res = append(res, items...)
+	}
+	return res
+}
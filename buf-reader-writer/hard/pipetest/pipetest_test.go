@@ -0,0 +1,55 @@
+package pipetest
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProducer_ScriptedBatchesAndErrors(t *testing.T) {
+	nextErr := errors.New("next failed")
+	commitErr := errors.New("commit failed")
+	p := NewProducer(Batch{Items: []any{1, 2}, Cookie: 10}, Batch{Items: []any{3}, Cookie: 20}).
+		FailNext(20, nextErr).
+		FailCommit(10, commitErr)
+
+	items, cookie, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{1, 2}, items)
+	assert.Equal(t, 10, cookie)
+
+	_, _, err = p.Next()
+	assert.ErrorIs(t, err, nextErr)
+
+	_, _, err = p.Next()
+	assert.Equal(t, io.EOF, err, "после пачек возвращается io.EOF")
+
+	assert.ErrorIs(t, p.Commit(10), commitErr)
+	assert.NoError(t, p.Nack(20))
+	assert.Equal(t, 3, p.NextCalls())
+	assert.Equal(t, []int{10}, p.Commits())
+	assert.Equal(t, []int{20}, p.Nacks())
+}
+
+func TestProducer_EndWith(t *testing.T) {
+	endErr := errors.New("broken source")
+	p := NewProducer().EndWith(endErr)
+
+	_, _, err := p.Next()
+	assert.ErrorIs(t, err, endErr)
+}
+
+func TestConsumer_FailCallRecordsBatch(t *testing.T) {
+	procErr := errors.New("process failed")
+	c := NewConsumer().FailCall(1, procErr)
+
+	require.NoError(t, c.Process([]any{1}))
+	assert.ErrorIs(t, c.Process([]any{2, 3}), procErr)
+	require.NoError(t, c.Process([]any{4}))
+
+	assert.Equal(t, [][]any{{1}, {2, 3}, {4}}, c.Calls())
+	assert.Equal(t, []any{1, 2, 3, 4}, c.Items())
+}
@@ -0,0 +1,84 @@
+package pipetest
+
+import (
+	"sync"
+	"time"
+)
+
+// SlowConfig — настройки SlowConsumer.
+type SlowConfig struct {
+	Delay      time.Duration       // задержка каждого вызова Process
+	StallEvery int                 // каждый StallEvery-й вызов (считая с 1) дополнительно зависает на Stall; 0 — без зависаний
+	Stall      time.Duration       // длительность редкого долгого зависания
+	Sleep      func(time.Duration) // функция ожидания; nil — time.Sleep
+}
+
+// SlowConsumer имитирует медленного потребителя для тестов backpressure: каждый Process выполняется с задержкой,
+// изредка — с долгим зависанием, после чего батч передаётся в next. Счётчики позволяют проверить,
+// сколько элементов Pipe держит в памяти сверх уже обработанных.
+type SlowConsumer struct {
+	next PipeConsumer
+	cfg  SlowConfig
+
+	mu        sync.Mutex
+	calls     int
+	processed int
+	busy      bool
+}
+
+// NewSlowConsumer создаёт медленного потребителя поверх next (nil — новый Consumer, записывающий батчи).
+func NewSlowConsumer(next PipeConsumer, cfg SlowConfig) *SlowConsumer {
+	if next == nil {
+		next = NewConsumer()
+	}
+	if cfg.Sleep == nil {
+		cfg.Sleep = time.Sleep
+	}
+	return &SlowConsumer{next: next, cfg: cfg}
+}
+
+func (sc *SlowConsumer) Process(items []any) error {
+	sc.mu.Lock()
+	sc.calls++
+	call := sc.calls
+	sc.busy = true
+	sc.mu.Unlock()
+
+	delay := sc.cfg.Delay
+	if sc.cfg.StallEvery > 0 && call%sc.cfg.StallEvery == 0 {
+		delay += sc.cfg.Stall
+	}
+	if delay > 0 {
+		sc.cfg.Sleep(delay)
+	}
+	err := sc.next.Process(items)
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.busy = false
+	if err == nil {
+		sc.processed += len(items)
+	}
+	return err
+}
+
+// Calls возвращает число начатых вызовов Process.
+func (sc *SlowConsumer) Calls() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.calls
+}
+
+// Processed возвращает число успешно обработанных элементов.
+func (sc *SlowConsumer) Processed() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.processed
+}
+
+// Busy сообщает, выполняется ли сейчас Process.
+func (sc *SlowConsumer) Busy() bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.busy
+}
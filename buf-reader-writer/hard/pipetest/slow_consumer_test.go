@@ -0,0 +1,49 @@
+package pipetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowConsumer_DelaysAndStalls(t *testing.T) {
+	var slept []time.Duration
+	sc := NewSlowConsumer(nil, SlowConfig{
+		Delay:      time.Millisecond,
+		StallEvery: 3,
+		Stall:      time.Second,
+		Sleep:      func(d time.Duration) { slept = append(slept, d) },
+	})
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, sc.Process([]any{i, i}))
+	}
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond, time.Second + time.Millisecond, time.Millisecond}, slept)
+	assert.Equal(t, 4, sc.Calls())
+	assert.Equal(t, 8, sc.Processed())
+	assert.False(t, sc.Busy())
+}
+
+func TestSlowConsumer_FailedBatchNotCounted(t *testing.T) {
+	procErr := errors.New("process failed")
+	sc := NewSlowConsumer(NewConsumer().FailCall(0, procErr), SlowConfig{})
+
+	assert.ErrorIs(t, sc.Process([]any{1}), procErr)
+	require.NoError(t, sc.Process([]any{2}))
+	assert.Equal(t, 1, sc.Processed())
+}
+
+func TestSlowConsumer_BusyDuringProcess(t *testing.T) {
+	release := make(chan struct{})
+	sc := NewSlowConsumer(nil, SlowConfig{Delay: time.Nanosecond, Sleep: func(time.Duration) { <-release }})
+	done := make(chan error, 1)
+	go func() { done <- sc.Process([]any{1}) }()
+
+	require.Eventually(t, sc.Busy, time.Second, time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+	assert.False(t, sc.Busy())
+}
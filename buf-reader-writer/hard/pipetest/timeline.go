@@ -0,0 +1,164 @@
+package pipetest
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// stepKind — вид шага сценария Timeline.
+type stepKind int
+
+const (
+	stepEmit  stepKind = iota // вернуть пачку
+	stepFail                  // вернуть ошибку
+	stepBlock                 // заблокировать Next на время
+	stepWait                  // заблокировать Next до закрытия канала
+)
+
+// step — один шаг сценария Timeline.
+type step struct {
+	kind  stepKind
+	batch Batch
+	err   error
+	delay time.Duration
+	wait  <-chan struct{}
+}
+
+// Timeline — Producer со сценарием во времени: «вернуть эти пачки, затем зависнуть на 2 секунды, затем ошибка,
+// затем снова пачки». Каждый вызов Next проходит шаги по порядку: блокирующие шаги задерживают вызов и переходят
+// к следующему шагу, Emit и Fail завершают вызов. После последнего шага Next возвращает конечную ошибку
+// (по умолчанию io.EOF). Блокировка происходит без удержания мьютекса, поэтому Commit и Nack
+// из других горутин проходят, пока Next висит.
+type Timeline struct {
+	mu        sync.Mutex
+	steps     []step
+	pos       int
+	endErr    error
+	sleep     func(time.Duration)
+	nextCalls int
+	commits   []int
+	nacks     []int
+}
+
+// NewTimeline создаёт пустой сценарий.
+func NewTimeline() *Timeline {
+	return &Timeline{endErr: io.EOF, sleep: time.Sleep}
+}
+
+// Emit добавляет шаги, возвращающие batches — по одной пачке на вызов Next.
+func (tl *Timeline) Emit(batches ...Batch) *Timeline {
+	for _, b := range batches {
+		tl.add(step{kind: stepEmit, batch: b})
+	}
+	return tl
+}
+
+// Fail добавляет шаг, на котором Next вернёт err. Следующий вызов Next продолжит сценарий.
+func (tl *Timeline) Fail(err error) *Timeline {
+	return tl.add(step{kind: stepFail, err: err})
+}
+
+// Block добавляет паузу d перед результатом следующего шага.
+func (tl *Timeline) Block(d time.Duration) *Timeline {
+	return tl.add(step{kind: stepBlock, delay: d})
+}
+
+// BlockUntil добавляет блокировку до закрытия release. Nil-канал блокирует навсегда (имитация зависшего источника).
+func (tl *Timeline) BlockUntil(release <-chan struct{}) *Timeline {
+	return tl.add(step{kind: stepWait, wait: release})
+}
+
+// EndWith задаёт ошибку, которую Next вернёт после окончания сценария.
+func (tl *Timeline) EndWith(err error) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.endErr = err
+	return tl
+}
+
+// WithSleep подменяет функцию ожидания для шагов Block, например на продвижение фейковых часов.
+func (tl *Timeline) WithSleep(sleep func(time.Duration)) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.sleep = sleep
+	return tl
+}
+
+func (tl *Timeline) add(s step) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.steps = append(tl.steps, s)
+	return tl
+}
+
+func (tl *Timeline) Next() (items []any, cookie int, err error) {
+	tl.mu.Lock()
+	tl.nextCalls++
+	for tl.pos < len(tl.steps) {
+		s := tl.steps[tl.pos]
+		tl.pos++
+		switch s.kind {
+		case stepEmit:
+			tl.mu.Unlock()
+			return append([]any(nil), s.batch.Items...), s.batch.Cookie, nil
+		case stepFail:
+			tl.mu.Unlock()
+			return nil, 0, s.err
+		case stepBlock:
+			sleep := tl.sleep
+			tl.mu.Unlock()
+			sleep(s.delay)
+			tl.mu.Lock()
+		case stepWait:
+			tl.mu.Unlock()
+			<-s.wait
+			tl.mu.Lock()
+		}
+	}
+	err = tl.endErr
+	tl.mu.Unlock()
+	return nil, 0, err
+}
+
+func (tl *Timeline) Commit(cookie int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.commits = append(tl.commits, cookie)
+	return nil
+}
+
+func (tl *Timeline) Nack(cookie int) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.nacks = append(tl.nacks, cookie)
+	return nil
+}
+
+// Remaining возвращает число ещё не пройденных шагов сценария.
+func (tl *Timeline) Remaining() int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return len(tl.steps) - tl.pos
+}
+
+// NextCalls возвращает число вызовов Next.
+func (tl *Timeline) NextCalls() int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.nextCalls
+}
+
+// Commits возвращает cookie всех вызовов Commit в порядке вызова.
+func (tl *Timeline) Commits() []int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]int(nil), tl.commits...)
+}
+
+// Nacks возвращает cookie всех вызовов Nack в порядке вызова.
+func (tl *Timeline) Nacks() []int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return append([]int(nil), tl.nacks...)
+}
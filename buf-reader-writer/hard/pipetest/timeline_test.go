@@ -0,0 +1,72 @@
+package pipetest
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeline_EmitBlockFailRecover(t *testing.T) {
+	srcErr := errors.New("source unavailable")
+	var slept []time.Duration
+	tl := NewTimeline().
+		WithSleep(func(d time.Duration) { slept = append(slept, d) }).
+		Emit(Batch{Items: []any{1}, Cookie: 1}).
+		Block(2 * time.Second).
+		Fail(srcErr).
+		Emit(Batch{Items: []any{2}, Cookie: 2})
+
+	items, cookie, err := tl.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{1}, items)
+	assert.Equal(t, 1, cookie)
+	assert.Empty(t, slept)
+
+	_, _, err = tl.Next()
+	assert.ErrorIs(t, err, srcErr)
+	assert.Equal(t, []time.Duration{2 * time.Second}, slept, "пауза предшествует ошибке в том же вызове")
+
+	items, cookie, err = tl.Next()
+	require.NoError(t, err, "после ошибки источник восстанавливается")
+	assert.Equal(t, []any{2}, items)
+	assert.Equal(t, 2, cookie)
+
+	_, _, err = tl.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, tl.Remaining())
+	assert.Equal(t, 4, tl.NextCalls())
+}
+
+func TestTimeline_BlockUntilDoesNotHoldCommit(t *testing.T) {
+	release := make(chan struct{})
+	tl := NewTimeline().BlockUntil(release).Emit(Batch{Items: []any{"x"}, Cookie: 5}).EndWith(io.ErrUnexpectedEOF)
+
+	done := make(chan error, 1)
+	go func() {
+		_, cookie, err := tl.Next()
+		if err == nil && cookie != 5 {
+			err = errors.New("unexpected cookie")
+		}
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool { return tl.Remaining() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, tl.Commit(4), "Commit не блокируется зависшим Next")
+	require.NoError(t, tl.Nack(3))
+	select {
+	case <-done:
+		t.Fatal("Next вернулся до release")
+	default:
+	}
+
+	close(release)
+	require.NoError(t, <-done)
+	_, _, err := tl.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, []int{4}, tl.Commits())
+	assert.Equal(t, []int{3}, tl.Nacks())
+}
@@ -0,0 +1,225 @@
+package pipetest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var updateGolden = flag.Bool("pipetest.update", false, "перезаписать golden-файлы транскриптов текущим результатом")
+
+// maxListedItems — сколько элементов батча выводить поимённо, если они не складываются в диапазон.
+const maxListedItems = 8
+
+// Transcript записывает полную последовательность вызовов Pipe в нормализованный текст для сравнения с golden-файлом.
+//
+// Next вызывается главной горутиной Pipe, а Process/Commit/Nack — воркером, поэтому их взаимный порядок
+// недетерминирован. Транскрипт состоит из двух секций, каждая из которых упорядочена детерминированно:
+// вызовы Next и вызовы воркера. Батчи подряд идущих целых чисел сворачиваются в диапазон [a..b].
+type Transcript struct {
+	mu     sync.Mutex
+	source []string
+	worker []string
+}
+
+// NewTranscript создаёт пустой транскрипт.
+func NewTranscript() *Transcript {
+	return &Transcript{}
+}
+
+// Producer оборачивает p, записывая Next, Commit и Nack. Поддержка Nack сохраняется, только если её умеет p.
+func (tr *Transcript) Producer(p PipeProducer) PipeProducer {
+	rp := &recordedProducer{p: p, tr: tr}
+	if n, ok := p.(interface{ Nack(cookie int) error }); ok {
+		return &recordedNackProducer{recordedProducer: rp, n: n}
+	}
+	return rp
+}
+
+// Consumer оборачивает c, записывая Process.
+func (tr *Transcript) Consumer(c PipeConsumer) PipeConsumer {
+	return &recordedConsumer{c: c, tr: tr}
+}
+
+// String возвращает нормализованный транскрипт.
+func (tr *Transcript) String() string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	var b strings.Builder
+	b.WriteString("# source\n")
+	for _, line := range tr.source {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteString("# worker\n")
+	for _, line := range tr.worker {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (tr *Transcript) addSource(format string, args ...any) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.source = append(tr.source, fmt.Sprintf(format, args...))
+}
+
+func (tr *Transcript) addWorker(format string, args ...any) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.worker = append(tr.worker, fmt.Sprintf(format, args...))
+}
+
+// AssertGolden сравнивает транскрипт с файлом path. При расхождении выводит построчный diff.
+// С флагом -pipetest.update файл перезаписывается текущим транскриптом.
+func (tr *Transcript) AssertGolden(t testing.TB, path string) {
+	t.Helper()
+	got := tr.String()
+	if *updateGolden {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(got), 0o644)
+		}
+		if err != nil {
+			t.Fatalf("update golden %s: %v", path, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden %s: %v (запустите тест с -pipetest.update, чтобы создать файл)", path, err)
+	}
+	if d := Diff(string(want), got); d != "" {
+		t.Errorf("транскрипт расходится с %s (- ожидалось, + получено):\n%s", path, d)
+	}
+}
+
+// Diff возвращает построчный diff want и got или пустую строку, если они совпадают.
+// Неизменённые строки выводятся с отступом, удалённые — с "-", добавленные — с "+".
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] — длина наибольшей общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
+
+// describeItems сворачивает батч в короткое описание: число элементов и диапазон/список значений.
+func describeItems(items []any) string {
+	if len(items) == 0 {
+		return "0 items"
+	}
+	if first, ok := items[0].(int); ok && len(items) > 1 {
+		consecutive := true
+		for i, it := range items {
+			if v, ok := it.(int); !ok || v != first+i {
+				consecutive = false
+				break
+			}
+		}
+		if consecutive {
+			return fmt.Sprintf("%d items [%d..%d]", len(items), first, first+len(items)-1)
+		}
+	}
+	parts := make([]string, 0, min(len(items), maxListedItems))
+	for _, it := range items[:min(len(items), maxListedItems)] {
+		parts = append(parts, fmt.Sprintf("%v", it))
+	}
+	list := strings.Join(parts, " ")
+	if len(items) > maxListedItems {
+		list += " ..."
+	}
+	return fmt.Sprintf("%d items [%s]", len(items), list)
+}
+
+// resultSuffix описывает результат вызова.
+func resultSuffix(err error) string {
+	if err == nil {
+		return ""
+	}
+	return " -> error: " + err.Error()
+}
+
+// recordedProducer — обёртка Producer, записывающая вызовы в транскрипт.
+type recordedProducer struct {
+	p  PipeProducer
+	tr *Transcript
+}
+
+func (rp *recordedProducer) Next() (items []any, cookie int, err error) {
+	items, cookie, err = rp.p.Next()
+	if err != nil {
+		rp.tr.addSource("next%s", resultSuffix(err))
+	} else {
+		rp.tr.addSource("next cookie=%d %s", cookie, describeItems(items))
+	}
+	return items, cookie, err
+}
+
+func (rp *recordedProducer) Commit(cookie int) error {
+	err := rp.p.Commit(cookie)
+	rp.tr.addWorker("commit %d%s", cookie, resultSuffix(err))
+	return err
+}
+
+// recordedNackProducer — recordedProducer для источников с Nack.
+type recordedNackProducer struct {
+	*recordedProducer
+	n interface{ Nack(cookie int) error }
+}
+
+func (rp *recordedNackProducer) Nack(cookie int) error {
+	err := rp.n.Nack(cookie)
+	rp.tr.addWorker("nack %d%s", cookie, resultSuffix(err))
+	return err
+}
+
+// recordedConsumer — обёртка Consumer, записывающая вызовы в транскрипт.
+type recordedConsumer struct {
+	c  PipeConsumer
+	tr *Transcript
+}
+
+func (rc *recordedConsumer) Process(items []any) error {
+	err := rc.c.Process(items)
+	rc.tr.addWorker("process %s%s", describeItems(items), resultSuffix(err))
+	return err
+}
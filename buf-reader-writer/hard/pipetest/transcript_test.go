@@ -0,0 +1,66 @@
+package pipetest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscript_RecordsCalls(t *testing.T) {
+	tr := NewTranscript()
+	p := tr.Producer(NewProducer(Batch{Items: []any{1, 2, 3}, Cookie: 7}, Batch{Items: []any{"a", 5}, Cookie: 8}).
+		FailCommit(8, errors.New("broker down")))
+	c := tr.Consumer(NewConsumer())
+
+	for {
+		items, cookie, err := p.Next()
+		if err != nil {
+			break
+		}
+		require.NoError(t, c.Process(items))
+		_ = p.Commit(cookie)
+	}
+	require.NoError(t, p.(interface{ Nack(int) error }).Nack(8))
+
+	want := `# source
+next cookie=7 3 items [1..3]
+next cookie=8 2 items [a 5]
+next -> error: EOF
+# worker
+process 3 items [1..3]
+commit 7
+process 2 items [a 5]
+commit 8 -> error: broker down
+nack 8
+`
+	assert.Equal(t, want, tr.String())
+}
+
+func TestTranscript_ProducerWithoutNack(t *testing.T) {
+	tr := NewTranscript()
+	_, ok := tr.Producer(struct{ PipeProducer }{NewProducer()}).(interface{ Nack(int) error })
+	assert.False(t, ok, "обёртка не должна добавлять Nack источнику без него")
+}
+
+func TestDescribeItems(t *testing.T) {
+	assert.Equal(t, "0 items", describeItems(nil))
+	assert.Equal(t, "1 items [4]", describeItems([]any{4}))
+	assert.Equal(t, "10 items [0 1 2 3 4 5 6 7 ...]", describeItems([]any{0, 1, 2, 3, 4, 5, 6, 7, 9, 10}))
+}
+
+func TestDiff(t *testing.T) {
+	assert.Empty(t, Diff("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "  a\n- b\n+ x\n  c\n+ d\n", Diff("a\nb\nc\n", "a\nx\nc\nd\n"))
+}
+
+func TestTranscript_AssertGolden(t *testing.T) {
+	tr := NewTranscript()
+	_, _, _ = tr.Producer(NewProducer()).Next()
+	path := filepath.Join(t.TempDir(), "golden.txt")
+	require.NoError(t, os.WriteFile(path, []byte(tr.String()), 0o644))
+	tr.AssertGolden(t, path)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/pipetest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// Проверка, что двойники из pipetest удовлетворяют интерфейсам Pipe
+var (
+	_ Producer = (*pipetest.Producer)(nil)
+	_ Nacker   = (*pipetest.Producer)(nil)
+	_ Consumer = (*pipetest.Consumer)(nil)
+	_ Producer = (*pipetest.Timeline)(nil)
+	_ Nacker   = (*pipetest.Timeline)(nil)
+)
+
+func TestPipetest_PipeCommitsAllBatches(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := pipetest.NewProducer(
+		pipetest.Batch{Items: makeItems(0, MaxItems), Cookie: 1},
+		pipetest.Batch{Items: makeItems(MaxItems, 2), Cookie: 2},
+	)
+	c := pipetest.NewConsumer()
+
+	err := Pipe(p, c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, makeItems(0, MaxItems+2), c.Items())
+	assert.Equal(t, []int{1, 2}, p.Commits())
+}
+
+func TestPipetest_ProcessFailureNacksBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	procErr := errors.New("process failed")
+	p := pipetest.NewProducer(pipetest.Batch{Items: []any{1}, Cookie: 7})
+	c := pipetest.NewConsumer().FailCall(0, procErr)
+
+	err := Pipe(p, c)
+	require.ErrorIs(t, err, procErr)
+	assert.Empty(t, p.Commits())
+	assert.Equal(t, []int{7}, p.Nacks())
+}
+
+func TestPipetest_TimelineNextErrorLeavesBufferUncommitted(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	srcErr := errors.New("source failed")
+	tl := pipetest.NewTimeline().
+		Emit(pipetest.Batch{Items: []any{1}, Cookie: 1}, pipetest.Batch{Items: []any{2}, Cookie: 2}).
+		Fail(srcErr).
+		Emit(pipetest.Batch{Items: []any{3}, Cookie: 3})
+	c := pipetest.NewConsumer()
+
+	err := Pipe(tl, c)
+	require.ErrorIs(t, err, srcErr)
+	assert.Empty(t, c.Items(), "накопленный буфер не отправляется в Process после ошибки Next")
+	assert.Empty(t, tl.Commits(), "незакоммиченные пачки источник доставит повторно")
+	assert.Equal(t, 1, tl.Remaining(), "после ошибки Next больше не вызывается")
+}
+
+func TestPipetest_TimelineStallHoldsAccumulatedBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	release := make(chan struct{})
+	tl := pipetest.NewTimeline().
+		Emit(pipetest.Batch{Items: []any{1}, Cookie: 1}).
+		BlockUntil(release).
+		Emit(pipetest.Batch{Items: []any{2}, Cookie: 2})
+	c := pipetest.NewConsumer()
+
+	done := make(chan error, 1)
+	go func() { done <- Pipe(tl, c) }()
+
+	require.Eventually(t, func() bool { return tl.Remaining() == 1 }, time.Second, time.Millisecond, "Next висит на BlockUntil")
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, c.Items(), "неполный батч ждёт следующего Next, пока источник завис")
+	assert.Empty(t, tl.Commits())
+
+	close(release)
+	require.Equal(t, io.EOF, <-done)
+	assert.Equal(t, [][]any{{1, 2}}, c.Calls(), "после восстановления пачки объединяются в один Process")
+	assert.Equal(t, []int{1, 2}, tl.Commits())
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// recordEvent — одна строка записи (JSON Lines): результат Next или вызов Commit/Nack.
+type recordEvent struct {
+	Op     string `json:"op"` // "next", "commit" или "nack"
+	Items  []any  `json:"items,omitempty"`
+	Meta   Meta   `json:"meta,omitempty"` // метаданные Next, если источник — MetaProducer
+	Cookie int    `json:"cookie"`
+	Err    string `json:"err,omitempty"` // текст ошибки; "EOF" для io.EOF
+}
+
+const (
+	recordOpNext   = "next"
+	recordOpCommit = "commit"
+	recordOpNack   = "nack"
+)
+
+// RecordingProducer проксирует вызовы к источнику и пишет их последовательность в w (JSON Lines),
+// чтобы потом воспроизвести инцидент через ReplayProducer. Элементы должны сериализоваться в JSON.
+// Nack, Pause/Resume и метаданные MetaProducer пробрасываются в источник, если он их поддерживает.
+type RecordingProducer struct {
+	p   Producer
+	mu  sync.Mutex // Next и Commit вызываются из разных горутин
+	enc *json.Encoder
+}
+
+// Проверка, что RecordingProducer удовлетворяет интерфейсам Producer, Nacker, Pausable и MetaProducer
+var (
+	_ Producer     = (*RecordingProducer)(nil)
+	_ Nacker       = (*RecordingProducer)(nil)
+	_ Pausable     = (*RecordingProducer)(nil)
+	_ MetaProducer = (*RecordingProducer)(nil)
+)
+
+// NewRecordingProducer создаёт записывающую обёртку над p.
+func NewRecordingProducer(p Producer, w io.Writer) *RecordingProducer {
+	return &RecordingProducer{p: p, enc: json.NewEncoder(w)}
+}
+
+func (rp *RecordingProducer) Next() (items []any, cookie int, err error) {
+	items, cookie, err = rp.p.Next()
+	recErr := rp.record(recordEvent{Op: recordOpNext, Items: items, Cookie: cookie, Err: errString(err)})
+	if recErr != nil && err == nil {
+		return nil, 0, recErr
+	}
+	return items, cookie, err
+}
+
+// NextWithMeta читает источник с метаданными, если он их поддерживает, и записывает их вместе с пачкой.
+func (rp *RecordingProducer) NextWithMeta() (items []any, cookie int, meta Meta, err error) {
+	items, cookie, meta, err = nextWithMeta(rp.p)
+	recErr := rp.record(recordEvent{Op: recordOpNext, Items: items, Meta: meta, Cookie: cookie, Err: errString(err)})
+	if recErr != nil && err == nil {
+		return nil, 0, nil, recErr
+	}
+	return items, cookie, meta, err
+}
+
+// Nack пробрасывается в исходный источник, если он поддерживает Nacker, и записывается.
+func (rp *RecordingProducer) Nack(cookie int) error {
+	n, ok := rp.p.(Nacker)
+	if !ok {
+		return nil
+	}
+	err := n.Nack(cookie)
+	recErr := rp.record(recordEvent{Op: recordOpNack, Cookie: cookie, Err: errString(err)})
+	if err != nil {
+		return err
+	}
+	return recErr
+}
+
+// Pause пробрасывается в исходный источник, если он поддерживает Pausable.
+func (rp *RecordingProducer) Pause() {
+	if pp, ok := rp.p.(Pausable); ok {
+		pp.Pause()
+	}
+}
+
+// Resume пробрасывается в исходный источник, если он поддерживает Pausable.
+func (rp *RecordingProducer) Resume() {
+	if pp, ok := rp.p.(Pausable); ok {
+		pp.Resume()
+	}
+}
+
+func (rp *RecordingProducer) Commit(cookie int) error {
+	err := rp.p.Commit(cookie)
+	recErr := rp.record(recordEvent{Op: recordOpCommit, Cookie: cookie, Err: errString(err)})
+	if err != nil {
+		return err
+	}
+	return recErr
+}
+
+// record пишет событие в поток записи.
+func (rp *RecordingProducer) record(ev recordEvent) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	err := rp.enc.Encode(ev)
+	if err != nil {
+		return fmt.Errorf("record %s: %w", ev.Op, err)
+	}
+	return nil
+}
+
+// ReplayProducer детерминированно воспроизводит результаты Next из записи RecordingProducer.
+// Элементы восстанавливаются из JSON (числа — float64, объекты — map[string]any).
+// Записанные ошибки возвращаются как errors.New с исходным текстом, "EOF" — как io.EOF.
+type ReplayProducer struct {
+	nexts           []recordEvent
+	pos             int
+	recordedCommits []int
+
+	mu      sync.Mutex
+	commits []int
+}
+
+// Проверка, что ReplayProducer удовлетворяет интерфейсам Producer и MetaProducer
+var (
+	_ Producer     = (*ReplayProducer)(nil)
+	_ MetaProducer = (*ReplayProducer)(nil)
+)
+
+// NewReplayProducer читает запись целиком из r.
+func NewReplayProducer(r io.Reader) (*ReplayProducer, error) {
+	rp := &ReplayProducer{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		var ev recordEvent
+		err := json.Unmarshal(sc.Bytes(), &ev)
+		if err != nil {
+			return nil, fmt.Errorf("parse record line %d: %w", line, err)
+		}
+		switch ev.Op {
+		case recordOpNext:
+			rp.nexts = append(rp.nexts, ev)
+		case recordOpCommit:
+			if ev.Err == "" {
+				rp.recordedCommits = append(rp.recordedCommits, ev.Cookie)
+			}
+		case recordOpNack: // Воспроизведение не зависит от Nack: они записываются для разбора инцидента
+		default:
+			return nil, fmt.Errorf("parse record line %d: unknown op %q", line, ev.Op)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read record: %w", err)
+	}
+	return rp, nil
+}
+
+// Next возвращает следующий записанный результат. После конца записи — io.EOF.
+func (rp *ReplayProducer) Next() (items []any, cookie int, err error) {
+	items, cookie, _, err = rp.NextWithMeta()
+	return items, cookie, err
+}
+
+// NextWithMeta — Next вместе с записанными метаданными.
+func (rp *ReplayProducer) NextWithMeta() (items []any, cookie int, meta Meta, err error) {
+	if rp.pos >= len(rp.nexts) {
+		return nil, 0, nil, io.EOF
+	}
+	ev := rp.nexts[rp.pos]
+	rp.pos++
+	if ev.Err != "" {
+		return nil, 0, nil, parseErrString(ev.Err)
+	}
+	return ev.Items, ev.Cookie, ev.Meta, nil
+}
+
+// Commit запоминает cookie для сравнения с записанной последовательностью.
+func (rp *ReplayProducer) Commit(cookie int) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.commits = append(rp.commits, cookie)
+	return nil
+}
+
+// Commits возвращает cookies, закоммиченные при воспроизведении.
+func (rp *ReplayProducer) Commits() []int {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return append([]int(nil), rp.commits...)
+}
+
+// RecordedCommits возвращает cookies, успешно закоммиченные в исходном запуске.
+func (rp *ReplayProducer) RecordedCommits() []int {
+	return append([]int(nil), rp.recordedCommits...)
+}
+
+// errString превращает ошибку в текст записи.
+func errString(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, io.EOF):
+		return "EOF"
+	default:
+		return err.Error()
+	}
+}
+
+// parseErrString восстанавливает ошибку из текста записи.
+func parseErrString(s string) error {
+	if s == "EOF" {
+		return io.EOF
+	}
+	return errors.New(s)
+}
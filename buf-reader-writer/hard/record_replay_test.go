@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay_RoundTrip(t *testing.T) {
+	var rec bytes.Buffer
+	p := &mockProducer{
+		batches: [][]any{{"a", "b"}, {"c"}},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+	err := Pipe(NewRecordingProducer(p, &rec), &mockConsumer{})
+	require.Equal(t, io.EOF, err)
+
+	rp, err := NewReplayProducer(bytes.NewReader(rec.Bytes()))
+	require.NoError(t, err)
+	c := &mockConsumer{}
+
+	err = Pipe(rp, c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{{"a", "b", "c"}}, c.processed)
+	assert.Equal(t, rp.RecordedCommits(), rp.Commits(), "воспроизведение должно коммитить то же, что и исходный запуск")
+}
+
+func TestRecordReplay_ErrorsReproduced(t *testing.T) {
+	var rec bytes.Buffer
+	p := &mockProducer{
+		batches:            [][]any{{"a"}},
+		cookies:            []int{1},
+		readErr:            errors.New("connection reset"),
+		commitErrForCookie: 1,
+		commitErr:          errors.New("commit failed"),
+	}
+	rp := NewRecordingProducer(p, &rec)
+	_, ck, err := rp.Next()
+	require.NoError(t, err)
+	require.Error(t, rp.Commit(ck))
+	_, _, err = rp.Next()
+	require.Error(t, err)
+
+	replay, err := NewReplayProducer(&rec)
+	require.NoError(t, err)
+	assert.Empty(t, replay.RecordedCommits(), "неуспешный коммит не считается записанным")
+
+	items, cookie, err := replay.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a"}, items)
+	assert.Equal(t, 1, cookie)
+
+	_, _, err = replay.Next()
+	require.EqualError(t, err, "connection reset")
+}
+
+func TestRecordingProducer_ForwardsNackPauseAndMeta(t *testing.T) {
+	var rec bytes.Buffer
+	tenant := Meta{"tenant": "a"}
+	p := &metaProducer{
+		mockProducer: mockProducer{batches: [][]any{{"a"}}, cookies: []int{1}, readErr: io.EOF},
+		metas:        []Meta{tenant},
+	}
+	c := &metaConsumer{}
+	err := Pipe(NewRecordingProducer(p, &rec), c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]MetaSpan{{{Start: 0, End: 1, Meta: tenant}}}, c.spans, "метаданные должны проходить через запись")
+
+	replay, err := NewReplayProducer(&rec)
+	require.NoError(t, err)
+	c = &metaConsumer{}
+	require.Equal(t, io.EOF, Pipe(replay, c))
+	assert.Equal(t, [][]MetaSpan{{{Start: 0, End: 1, Meta: tenant}}}, c.spans, "метаданные воспроизводятся из записи")
+
+	rec.Reset()
+	np := &nackProducer{mockProducer: mockProducer{batches: [][]any{{"a"}}, cookies: []int{7}, readErr: io.EOF}}
+	err = Pipe(NewRecordingProducer(np, &rec), &mockConsumer{procErr: errors.New("process failed")})
+	require.Error(t, err)
+	assert.Equal(t, []int{7}, np.nacked, "Nack должен пробрасываться в источник")
+	assert.Contains(t, rec.String(), `"op":"nack"`)
+	_, err = NewReplayProducer(&rec)
+	require.NoError(t, err, "запись с Nack должна читаться")
+
+	pp := &pausableProducer{}
+	recorder := NewRecordingProducer(pp, io.Discard)
+	recorder.Pause()
+	recorder.Resume()
+	assert.Equal(t, 1, pp.paused)
+	assert.Equal(t, 1, pp.resumed)
+}
+
+func TestReplayProducer_BadRecord(t *testing.T) {
+	_, err := NewReplayProducer(bytes.NewBufferString("{\"op\":\"bogus\"}\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/retry"
+)
+
+// RetryPolicy описывает повторы отдельных вызовов Next и Commit источника. В отличие от WithSupervisor,
+// попытки считаются для каждого вызова заново: после успеха следующий сбой снова получает MaxAttempts.
+// io.EOF от Next повтором не считается никогда.
+type RetryPolicy struct {
+	MaxAttempts int                  // максимум попыток одного вызова, включая первую; <= 1 — без повторов
+	Backoff     time.Duration        // пауза перед первым повтором, далее удваивается
+	MaxBackoff  time.Duration        // верхняя граница паузы; 0 — без ограничения
+	Jitter      float64              // доля паузы [0, 1], на которую она случайно уменьшается
+	Retryable   func(err error) bool // классификатор ошибок; nil — любая ошибка считается повторяемой
+}
+
+// WithRetry повторяет упавшие вызовы Next и Commit по политике policy, прежде чем считать ошибку фатальной.
+// Паузы отсчитываются по Clock Pipe и прерываются отменой Pipe. Исчерпанный Commit после этого ещё
+// может перезапустить супервизор (см. WithSupervisor).
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+// retrier повторяет вызовы источника в рамках одного Pipe; nil — каждый вызов выполняется один раз.
+type retrier struct {
+	retry  retry.Policy // паузы, классификатор и ожидание по Clock Pipe
+	logger Logger
+}
+
+func newRetrier(o options) *retrier {
+	if o.retry == nil || o.retry.MaxAttempts <= 1 {
+		return nil
+	}
+	classify := o.retry.Retryable
+	return &retrier{
+		retry: retry.Policy{
+			MaxAttempts: o.retry.MaxAttempts,
+			Backoff:     o.retry.Backoff,
+			MaxBackoff:  o.retry.MaxBackoff,
+			Jitter:      o.retry.Jitter,
+			Retryable: func(err error) bool {
+				return !errors.Is(err, io.EOF) && (classify == nil || classify(err))
+			},
+			Sleep: func(ctx context.Context, d time.Duration) error {
+				if !sleep(o.clock, d, ctx.Done()) {
+					return ctx.Err()
+				}
+				return nil
+			},
+		},
+		logger: o.logger,
+	}
+}
+
+// do выполняет вызов op источника, повторяя повторяемые ошибки. Каждый повтор пишется в лог как EventCallRetry.
+// Ошибка исчерпанных или прерванных повторов дополняется именем вызова.
+func (r *retrier) do(ctx context.Context, call string, op func() error) error {
+	if r == nil {
+		return op()
+	}
+	policy := r.retry
+	policy.OnRetry = func(attempt int, delay time.Duration, err error) {
+		logEvent(r.logger, EventCallRetry, map[string]any{"call": call, "attempt": attempt, "delay": delay, "error": err})
+	}
+	var last error // последняя ошибка op: неповторяемая возвращается как есть
+	err := policy.Do(ctx, func() error {
+		last = op()
+		return last
+	})
+	if err == nil || err == last {
+		return err
+	}
+	return fmt.Errorf("%s %w", call, err)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// flakyNextProducer возвращает nextErr на вызовах Next с номерами из fail (с единицы).
+type flakyNextProducer struct {
+	mockProducer
+	nextErr error
+	fail    map[int]bool
+	calls   int
+}
+
+func (m *flakyNextProducer) Next() ([]any, int, error) {
+	m.calls++
+	if m.fail[m.calls] {
+		return nil, 0, m.nextErr
+	}
+	return m.mockProducer.Next()
+}
+
+func TestPipe_Retry_Next(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyNextProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 1), makeItems(1, 1)}, cookies: []int{1, 2}, readErr: io.EOF},
+		nextErr:      errors.New("broker unavailable"),
+		fail:         map[int]bool{1: true, 2: true, 4: true, 5: true},
+	}
+	c := &mockConsumer{}
+	clock := newAutoClock()
+
+	err := Pipe(p, c, WithClock(clock), WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Second}))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []int{1, 2}, p.committed)
+	// Попытки считаются для каждого вызова заново; io.EOF не повторяется
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second}, clock.Sleeps())
+	assert.Equal(t, 7, p.calls)
+}
+
+func TestPipe_Retry_NextGivesUp(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyNextProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF},
+		nextErr:      errors.New("broker unavailable"),
+		fail:         map[int]bool{1: true, 2: true, 3: true},
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithClock(newAutoClock()), WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Second}))
+	require.ErrorIs(t, err, p.nextErr)
+	assert.ErrorContains(t, err, "next failed after 3 attempts")
+	assert.Equal(t, 3, p.calls)
+	assert.Empty(t, c.processed)
+}
+
+func TestPipe_Retry_NonRetryable(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyNextProducer{
+		mockProducer: mockProducer{readErr: io.EOF},
+		nextErr:      errors.New("fatal"),
+		fail:         map[int]bool{1: true},
+	}
+
+	err := Pipe(p, &mockConsumer{}, WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return !errors.Is(err, p.nextErr) },
+	}))
+	require.ErrorIs(t, err, p.nextErr)
+	assert.Equal(t, 1, p.calls, "неповторяемая ошибка не должна повторять Next")
+}
+
+func TestPipe_Retry_Commit(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyCommitProducer{
+		mockProducer: mockProducer{
+			batches:   [][]any{makeItems(0, 1), makeItems(1, 1)},
+			cookies:   []int{1, 2},
+			readErr:   io.EOF,
+			commitErr: errors.New("temporary"),
+		},
+		failures: 2,
+	}
+	c := &mockConsumer{}
+	clock := newAutoClock()
+	logger := &recordingLogger{}
+
+	err := Pipe(p, c, WithClock(clock), WithLogger(logger), WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Second}))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Len(t, c.processed, 1, "повтор Commit не обрабатывает батч заново")
+	assert.Equal(t, []int{1, 2}, p.committed)
+	assert.Equal(t, []int{1, 1, 1, 2}, p.commitAttempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.Sleeps())
+
+	retries := logger.events(EventCallRetry)
+	require.Len(t, retries, 2)
+	assert.Equal(t, "commit", retries[0]["call"])
+	assert.Equal(t, 2, retries[1]["attempt"])
+	assert.Empty(t, logger.events(EventRetry), "повторы вызовов не расходуют перезапуски супервизора")
+}
+
+func TestPipe_Retry_CommitThenSupervisor(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyCommitProducer{
+		mockProducer: mockProducer{
+			batches:   [][]any{makeItems(0, 1)},
+			cookies:   []int{1},
+			readErr:   io.EOF,
+			commitErr: errors.New("temporary"),
+		},
+		failures: 3,
+	}
+	clock := newAutoClock()
+
+	err := Pipe(p, &mockConsumer{}, WithClock(clock),
+		WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: time.Second}),
+		WithSupervisor(SupervisorPolicy{MaxRestarts: 1, Backoff: time.Minute}),
+	)
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []int{1}, p.committed)
+	// Две попытки, перезапуск супервизора и ещё одна серия попыток
+	assert.Equal(t, []time.Duration{time.Second, time.Minute, time.Second}, clock.Sleeps())
+}
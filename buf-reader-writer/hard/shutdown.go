@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/zlatoivan/go-advanced/batcher"
+)
+
+// ShutdownPolicy определяет, как Pipe завершается после отмены контекста WithContext.
+type ShutdownPolicy int
+
+const (
+	// ShutdownAbort — выйти сразу (по умолчанию): накопленный буфер и батч в очереди воркера отбрасываются,
+	// Process уже идущего батча не прерывается, но его Commit может не выполниться.
+	ShutdownAbort ShutdownPolicy = iota
+	// ShutdownFlush — отправить накопленный буфер в Process и дождаться, пока воркер обработает
+	// и закоммитит все переданные ему батчи.
+	ShutdownFlush
+	// ShutdownDrain — отбросить накопленный буфер (источник с Nacker получает Nack его cookies)
+	// и дождаться, пока воркер обработает и закоммитит уже переданные ему батчи.
+	ShutdownDrain
+)
+
+// WithShutdown задаёт завершение Pipe по отмене контекста WithContext. С ShutdownFlush и ShutdownDrain
+// отмена не прерывает воркер: Pipe дожидается его и возвращает ctx.Err() (вместе с ошибкой воркера, если она была).
+// Отмена, как и прежде, замечается между вызовами Next.
+func WithShutdown(policy ShutdownPolicy) Option {
+	return func(o *options) {
+		o.shutdown = policy
+	}
+}
+
+// shutdown завершает Pipe по отмене контекста согласно o.shutdown: отправляет буфер acc через flush
+// или отбрасывает его, затем дожидается воркера через drain. Возвращает ctxErr вместе с ошибками завершения.
+func shutdown[T any](o options, p any, acc *batcher.Batcher[T], flush func(batcher.Batch[T]) error, drain func() error, ctxErr error) error {
+	errs := []error{ctxErr}
+	if acc.Pending() > 0 {
+		b := acc.Flush()
+		switch {
+		case o.shutdown == ShutdownFlush:
+			err := flush(b)
+			if err != nil {
+				return errors.Join(ctxErr, err)
+			}
+		case !o.dryRun:
+			errs = append(errs, nackAll(p, b.Cookies))
+		}
+	}
+	errs = append(errs, drain())
+	return errors.Join(errs...)
+}
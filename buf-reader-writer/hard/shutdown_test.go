@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// cancelingProducer отменяет контекст Pipe, отдав after батчей.
+type cancelingProducer struct {
+	nackProducer
+	cancel context.CancelFunc
+	after  int
+	calls  int
+}
+
+func (m *cancelingProducer) Next() ([]any, int, error) {
+	m.calls++
+	items, cookie, err := m.nackProducer.Next()
+	if m.calls == m.after {
+		m.cancel()
+	}
+	return items, cookie, err
+}
+
+func newCancelingProducer(cancel context.CancelFunc, after int, batches ...[]any) *cancelingProducer {
+	p := &cancelingProducer{cancel: cancel, after: after}
+	p.batches = batches
+	for i := range batches {
+		p.cookies = append(p.cookies, i+1)
+	}
+	p.readErr = io.EOF
+	return p
+}
+
+func TestPipe_Shutdown_Flush(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newCancelingProducer(cancel, 2, makeItems(0, 1), makeItems(1, 1), makeItems(2, 1))
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithContext(ctx), WithShutdown(ShutdownFlush))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{2}, batchSizes(c), "накопленный буфер обработан")
+	assert.Equal(t, []int{1, 2}, p.committed)
+	assert.Equal(t, 2, p.calls, "после отмены Next не вызывается")
+}
+
+func TestPipe_Shutdown_Drain(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newCancelingProducer(cancel, 3, makeItems(0, 1), makeItems(1, 1), makeItems(2, 1))
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithContext(ctx), WithShutdown(ShutdownDrain), WithBatchLimits(ByCount(1)))
+	require.ErrorIs(t, err, context.Canceled)
+	// Батчи 1 и 2 уже переданы воркеру и дообработаны; батч 3 остался в буфере
+	assert.Equal(t, []int{1, 1}, batchSizes(c))
+	assert.Equal(t, []int{1, 2}, p.committed)
+	assert.Equal(t, []int{3}, p.nacked, "отброшенный буфер возвращён источнику")
+}
+
+func TestPipe_Shutdown_WorkerError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newCancelingProducer(cancel, 1, makeItems(0, 1))
+	c := &mockConsumer{procErr: errors.New("boom")}
+
+	err := Pipe(p, c, WithContext(ctx), WithShutdown(ShutdownFlush))
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, c.procErr)
+	assert.Empty(t, p.committed)
+}
+
+func TestPipe_Shutdown_AbortByDefault(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newCancelingProducer(cancel, 1, makeItems(0, 1), makeItems(1, 1))
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithContext(ctx))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, c.processed, "буфер отброшен")
+	assert.Empty(t, p.nacked)
+}
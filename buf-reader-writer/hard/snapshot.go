@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync/atomic"
+)
+
+// ErrCorruptSnapshot возвращается SnapshotProducer, если файл снапшота обрывается посреди кадра.
+var ErrCorruptSnapshot = errors.New("corrupt snapshot frame")
+
+// BatchCodec сериализует батч целиком — одну запись файла снапшота.
+type BatchCodec interface {
+	Marshal(items []any) ([]byte, error)
+	Unmarshal(data []byte) ([]any, error)
+}
+
+// JSONBatchCodec кодирует батч как JSON-массив. При чтении числа восстанавливаются как float64.
+type JSONBatchCodec struct{}
+
+func (JSONBatchCodec) Marshal(items []any) ([]byte, error) {
+	return json.Marshal(items)
+}
+
+func (JSONBatchCodec) Unmarshal(data []byte) ([]any, error) {
+	var items []any
+	err := json.Unmarshal(data, &items)
+	return items, err
+}
+
+// GobBatchCodec кодирует батч через encoding/gob с сохранением типов элементов.
+// Конкретные типы элементов должны быть зарегистрированы через gob.Register.
+type GobBatchCodec struct{}
+
+func (GobBatchCodec) Marshal(items []any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(items)
+	return buf.Bytes(), err
+}
+
+func (GobBatchCodec) Unmarshal(data []byte) ([]any, error) {
+	var items []any
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items)
+	return items, err
+}
+
+// SyncPolicy определяет, когда SnapshotConsumer вызывает fsync.
+type SyncPolicy int
+
+const (
+	SyncEveryBatch SyncPolicy = iota // fsync после каждого батча: коммит источника только после попадания на диск (по умолчанию)
+	SyncOnClose                      // fsync только в Close: быстрее, но при падении ОС возможна потеря подтверждённых батчей
+)
+
+// SnapshotConfig — настройки SnapshotConsumer.
+type SnapshotConfig struct {
+	Codec BatchCodec // кодек батча; nil — JSONBatchCodec
+	Sync  SyncPolicy
+}
+
+// snapshotFrameHeader — размер префикса длины кадра (uint32, big-endian).
+const snapshotFrameHeader = 4
+
+// SnapshotConsumer дописывает каждый батч в файл снапшота отдельным кадром: [длина uint32][данные кодека].
+// Файл можно позже воспроизвести как Producer через NewSnapshotProducer.
+type SnapshotConsumer struct {
+	f     *os.File
+	codec BatchCodec
+	sync  SyncPolicy
+	frame []byte // переиспользуемый буфер кадра
+}
+
+// Проверка, что SnapshotConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*SnapshotConsumer)(nil)
+
+// NewSnapshotConsumer открывает (или создаёт) файл снапшота path на дозапись. После завершения Pipe нужно вызвать Close.
+func NewSnapshotConsumer(path string, cfg SnapshotConfig) (*SnapshotConsumer, error) {
+	if cfg.Codec == nil {
+		cfg.Codec = JSONBatchCodec{}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	return &SnapshotConsumer{f: f, codec: cfg.Codec, sync: cfg.Sync}, nil
+}
+
+// Process кодирует батч и дописывает его одним вызовом Write, чтобы кадр не перемежался с другими записями.
+func (sc *SnapshotConsumer) Process(items []any) error {
+	data, err := sc.codec.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("encode batch: %w", err)
+	}
+	if len(data) > math.MaxUint32 {
+		return fmt.Errorf("%w: batch is %d bytes", ErrItemTooLarge, len(data))
+	}
+
+	frame := binary.BigEndian.AppendUint32(sc.frame[:0], uint32(len(data)))
+	frame = append(frame, data...)
+	sc.frame = frame[:0]
+
+	_, err = sc.f.Write(frame)
+	if err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if sc.sync == SyncEveryBatch {
+		err = sc.f.Sync()
+		if err != nil {
+			return fmt.Errorf("sync snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close синхронизирует и закрывает файл снапшота.
+func (sc *SnapshotConsumer) Close() error {
+	return errors.Join(sc.f.Sync(), sc.f.Close())
+}
+
+// SnapshotProducer воспроизводит файл, записанный SnapshotConsumer: один кадр — один батч, cookie — номер кадра.
+type SnapshotProducer struct {
+	f         *os.File
+	r         *bufio.Reader
+	codec     BatchCodec
+	next      int          // номер следующего кадра
+	committed atomic.Int64 // число подтверждённых кадров
+}
+
+// Проверка, что SnapshotProducer удовлетворяет интерфейсу Producer
+var _ Producer = (*SnapshotProducer)(nil)
+
+// NewSnapshotProducer открывает файл снапшота path для чтения кадров кодеком codec (nil — JSONBatchCodec).
+func NewSnapshotProducer(path string, codec BatchCodec) (*SnapshotProducer, error) {
+	if codec == nil {
+		codec = JSONBatchCodec{}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	return &SnapshotProducer{f: f, r: bufio.NewReader(f), codec: codec}, nil
+}
+
+// Next читает очередной кадр. По окончании файла возвращает io.EOF, при оборванном кадре — ErrCorruptSnapshot.
+func (sp *SnapshotProducer) Next() (items []any, cookie int, err error) {
+	var header [snapshotFrameHeader]byte
+	_, err = io.ReadFull(sp.r, header[:])
+	switch {
+	case errors.Is(err, io.EOF): // Файл закончился ровно на границе кадра
+		return nil, 0, io.EOF
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return nil, 0, fmt.Errorf("%w: frame %d: truncated header", ErrCorruptSnapshot, sp.next)
+	case err != nil:
+		return nil, 0, fmt.Errorf("read frame %d: %w", sp.next, err)
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(header[:]))
+	_, err = io.ReadFull(sp.r, data)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, 0, fmt.Errorf("%w: frame %d: truncated body", ErrCorruptSnapshot, sp.next)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("read frame %d: %w", sp.next, err)
+	}
+
+	items, err = sp.codec.Unmarshal(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("decode frame %d: %w", sp.next, err)
+	}
+
+	cookie = sp.next
+	sp.next++
+	return items, cookie, nil
+}
+
+// Commit подтверждает кадр. Pipe коммитит cookies строго по порядку, поэтому номер должен совпадать с ожидаемым.
+// Повторный Commit уже подтверждённого номера (повторная доставка) ничего не делает.
+func (sp *SnapshotProducer) Commit(cookie int) error {
+	expected := sp.committed.Load()
+	if int64(cookie) < expected {
+		return nil
+	}
+	if int64(cookie) != expected {
+		return fmt.Errorf("commit out of order: got frame %d, expected %d", cookie, expected)
+	}
+	sp.committed.Add(1)
+	return nil
+}
+
+// Committed возвращает число подтверждённых кадров.
+func (sp *SnapshotProducer) Committed() int {
+	return int(sp.committed.Load())
+}
+
+// Close закрывает файл снапшота.
+func (sp *SnapshotProducer) Close() error {
+	return sp.f.Close()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_RoundTripThroughPipe(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		codec BatchCodec
+		want  []any
+	}{
+		{name: "json", codec: JSONBatchCodec{}, want: []any{"a", 1.0, "b"}},
+		{name: "gob", codec: GobBatchCodec{}, want: []any{"a", 1, "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.bin")
+
+			sc, err := NewSnapshotConsumer(path, SnapshotConfig{Codec: tc.codec})
+			require.NoError(t, err)
+			require.NoError(t, sc.Process([]any{"a", 1}))
+			require.NoError(t, sc.Process([]any{"b"}))
+			require.NoError(t, sc.Close())
+
+			sp, err := NewSnapshotProducer(path, tc.codec)
+			require.NoError(t, err)
+			defer sp.Close()
+
+			c := &mockConsumer{}
+			err = Pipe(sp, c)
+			require.Equal(t, io.EOF, err)
+			assert.Equal(t, tc.want, c.processed[0], "кадры должны склеиться в один батч Pipe")
+			assert.Equal(t, 2, sp.Committed())
+		})
+	}
+}
+
+func TestSnapshot_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.bin")
+	for _, batch := range [][]any{{"first"}, {"second"}} {
+		sc, err := NewSnapshotConsumer(path, SnapshotConfig{Sync: SyncOnClose})
+		require.NoError(t, err)
+		require.NoError(t, sc.Process(batch))
+		require.NoError(t, sc.Close())
+	}
+
+	sp, err := NewSnapshotProducer(path, nil)
+	require.NoError(t, err)
+	defer sp.Close()
+
+	items, cookie, err := sp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"first"}, items)
+	assert.Equal(t, 0, cookie)
+
+	items, cookie, err = sp.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"second"}, items)
+	assert.Equal(t, 1, cookie)
+
+	_, _, err = sp.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSnapshot_TruncatedFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.bin")
+	sc, err := NewSnapshotConsumer(path, SnapshotConfig{})
+	require.NoError(t, err)
+	require.NoError(t, sc.Process([]any{"ok"}))
+	require.NoError(t, sc.Process([]any{"lost"}))
+	require.NoError(t, sc.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-2)) // Имитация падения посреди записи кадра
+
+	sp, err := NewSnapshotProducer(path, nil)
+	require.NoError(t, err)
+	defer sp.Close()
+
+	_, _, err = sp.Next()
+	require.NoError(t, err)
+	_, _, err = sp.Next()
+	assert.ErrorIs(t, err, ErrCorruptSnapshot)
+}
+
+func TestSnapshotProducer_CommitOutOfOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.bin")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+
+	sp, err := NewSnapshotProducer(path, nil)
+	require.NoError(t, err)
+	defer sp.Close()
+
+	assert.Error(t, sp.Commit(1))
+	assert.NoError(t, sp.Commit(0))
+	assert.NoError(t, sp.Commit(0), "повторный коммит уже подтверждённого кадра")
+	assert.Equal(t, 1, sp.Committed())
+}
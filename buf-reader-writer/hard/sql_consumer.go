@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// InsertBuilder строит запрос вставки для батча: текст SQL и аргументы (например, многострочный INSERT ... VALUES).
+type InsertBuilder func(items []any) (query string, args []any, err error)
+
+// SQLConsumer записывает каждый батч в базу одной транзакцией.
+// Process возвращает успех (и Pipe коммитит cookies) только после tx.Commit — классический at-least-once приёмник:
+// при сбое батч откатывается целиком и будет доставлен повторно.
+type SQLConsumer struct {
+	ctx   context.Context
+	db    *sql.DB
+	build InsertBuilder
+}
+
+// Проверка, что SQLConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*SQLConsumer)(nil)
+
+// NewSQLConsumer создаёт транзакционный приёмник поверх db. ctx ограничивает каждую транзакцию.
+func NewSQLConsumer(ctx context.Context, db *sql.DB, build InsertBuilder) *SQLConsumer {
+	return &SQLConsumer{ctx: ctx, db: db, build: build}
+}
+
+// Process вставляет батч в рамках одной транзакции.
+func (sc *SQLConsumer) Process(items []any) (err error) {
+	query, args, err := sc.build(items)
+	if err != nil {
+		return fmt.Errorf("build insert: %w", err)
+	}
+
+	tx, err := sc.db.BeginTx(sc.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rbErr := tx.Rollback()
+			if rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				err = errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(sc.ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("exec insert: %w", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQLDriver — минимальный драйвер database/sql, записывающий выполненные операции.
+type fakeSQLDriver struct {
+	mu      sync.Mutex
+	log     []string
+	execErr error
+}
+
+func (d *fakeSQLDriver) record(op string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, op)
+}
+
+func (d *fakeSQLDriver) Open(string) (driver.Conn, error) { return &fakeSQLConn{d: d}, nil }
+
+type fakeSQLConn struct{ d *fakeSQLDriver }
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{d: c.d, query: query}, nil
+}
+func (c *fakeSQLConn) Close() error { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	c.d.record("begin")
+	return &fakeSQLTx{d: c.d}, nil
+}
+
+type fakeSQLTx struct{ d *fakeSQLDriver }
+
+func (tx *fakeSQLTx) Commit() error   { tx.d.record("commit"); return nil }
+func (tx *fakeSQLTx) Rollback() error { tx.d.record("rollback"); return nil }
+
+type fakeSQLStmt struct {
+	d     *fakeSQLDriver
+	query string
+}
+
+func (s *fakeSQLStmt) Close() error  { return nil }
+func (s *fakeSQLStmt) NumInput() int { return -1 }
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.d.execErr != nil {
+		return nil, s.d.execErr
+	}
+	s.d.record(fmt.Sprintf("exec %s %v", s.query, args))
+	return driver.RowsAffected(len(args)), nil
+}
+func (s *fakeSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+var fakeSQLDriverSeq int
+
+func openFakeDB(t *testing.T, d *fakeSQLDriver) *sql.DB {
+	t.Helper()
+	fakeSQLDriverSeq++
+	name := fmt.Sprintf("fake-sql-%d", fakeSQLDriverSeq)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func buildInsert(items []any) (string, []any, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("(?),", len(items)), ",")
+	return "INSERT INTO t VALUES " + placeholders, items, nil
+}
+
+func TestSQLConsumer_BatchPerTransaction(t *testing.T) {
+	d := &fakeSQLDriver{}
+	c := NewSQLConsumer(context.Background(), openFakeDB(t, d), buildInsert)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 2), makeItems(2, MaxItems)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(p, c)
+	require.Equal(t, io.EOF, err)
+	require.Len(t, d.log, 6)
+	assert.Equal(t, "begin", d.log[0])
+	assert.Equal(t, "exec INSERT INTO t VALUES (?),(?) [0 1]", d.log[1])
+	assert.Equal(t, "commit", d.log[2])
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestSQLConsumer_RollbackOnExecError(t *testing.T) {
+	d := &fakeSQLDriver{execErr: errors.New("constraint violation")}
+	c := NewSQLConsumer(context.Background(), openFakeDB(t, d), buildInsert)
+
+	err := c.Process(makeItems(0, 1))
+	require.ErrorIs(t, err, d.execErr)
+	assert.Equal(t, []string{"begin", "rollback"}, d.log)
+}
+
+func TestSQLConsumer_BuildError(t *testing.T) {
+	d := &fakeSQLDriver{}
+	buildErr := errors.New("bad item")
+	c := NewSQLConsumer(context.Background(), openFakeDB(t, d), func([]any) (string, []any, error) {
+		return "", nil, buildErr
+	})
+
+	require.ErrorIs(t, c.Process(makeItems(0, 1)), buildErr)
+	assert.Empty(t, d.log, "транзакция не должна открываться")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrStreamNotConfirmed возвращается StreamConsumer, если сервер не подтвердил приём батча.
+var ErrStreamNotConfirmed = errors.New("stream batch not confirmed by server")
+
+// ClientStream — клиентская сторона client-streaming вызова. Структурно совпадает с клиентом,
+// который генерирует protoc-gen-go-grpc (Send(*Req) error; CloseAndRecv() (*Resp, error)),
+// поэтому сгенерированный стрим передаётся напрямую, без зависимости пакета от grpc.
+type ClientStream[Req, Resp any] interface {
+	Send(req Req) error
+	CloseAndRecv() (Resp, error)
+}
+
+// StreamConfig — настройки StreamConsumer.
+type StreamConfig[Req, Resp any] struct {
+	// Open устанавливает новый стрим, например: func(ctx) { return client.Upload(ctx) }. Обязательно.
+	Open func(ctx context.Context) (ClientStream[Req, Resp], error)
+	// Marshal преобразует элемент батча в сообщение запроса. Обязательно.
+	Marshal func(item any) (Req, error)
+	// Confirm проверяет ответ сервера на батч из sent сообщений; nil — любой успешный ответ считается подтверждением.
+	Confirm func(resp Resp, sent int) error
+}
+
+// StreamConsumer отправляет каждый батч отдельным client-streaming вызовом и возвращает успех
+// только после подтверждения сервера, поэтому Pipe коммитит cookies лишь для принятых сервером данных.
+// Стрим устанавливается заново для каждого батча: обрыв соединения затрагивает только текущий батч,
+// а следующий (или повтор через WithSupervisor) идёт по новому стриму.
+type StreamConsumer[Req, Resp any] struct {
+	ctx context.Context
+	cfg StreamConfig[Req, Resp]
+}
+
+// NewStreamConsumer создаёт Consumer поверх client-streaming вызова. ctx ограничивает время жизни всех стримов.
+func NewStreamConsumer[Req, Resp any](ctx context.Context, cfg StreamConfig[Req, Resp]) (*StreamConsumer[Req, Resp], error) {
+	if cfg.Open == nil || cfg.Marshal == nil {
+		return nil, errors.New("stream consumer: Open and Marshal are required")
+	}
+	return &StreamConsumer[Req, Resp]{ctx: ctx, cfg: cfg}, nil
+}
+
+// Process кодирует все элементы до открытия стрима, чтобы ошибка кодирования не оставляла полуотправленный вызов,
+// затем отправляет их и ждёт ответа сервера.
+func (sc *StreamConsumer[Req, Resp]) Process(items []any) error {
+	reqs := make([]Req, len(items))
+	for i, item := range items {
+		req, err := sc.cfg.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("marshal item %d: %w", i, err)
+		}
+		reqs[i] = req
+	}
+
+	ctx, cancel := context.WithCancel(sc.ctx)
+	defer cancel() // Отмена контекста закрывает стрим, брошенный посреди отправки
+
+	stream, err := sc.cfg.Open(ctx)
+	if err != nil {
+		return fmt.Errorf("open stream: %w", err)
+	}
+	for i, req := range reqs {
+		err = stream.Send(req)
+		if err != nil {
+			return fmt.Errorf("send item %d: %w", i, err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("close stream: %w", err)
+	}
+	if sc.cfg.Confirm != nil {
+		err = sc.cfg.Confirm(resp, len(reqs))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrStreamNotConfirmed, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStreamServer имитирует сервер client-streaming вызова: подтверждает число принятых сообщений.
+type mockStreamServer struct {
+	opened   int
+	received [][]string
+	sendErr  error // ошибка Send в первом стриме
+}
+
+type mockStream struct {
+	srv *mockStreamServer
+	ctx context.Context
+	buf []string
+	err error
+}
+
+func (s *mockStream) Send(req string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.buf = append(s.buf, req)
+	return nil
+}
+
+func (s *mockStream) CloseAndRecv() (int, error) {
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.srv.received = append(s.srv.received, s.buf)
+	return len(s.buf), nil
+}
+
+func (m *mockStreamServer) open(ctx context.Context) (ClientStream[string, int], error) {
+	m.opened++
+	s := &mockStream{srv: m, ctx: ctx}
+	if m.opened == 1 {
+		s.err = m.sendErr
+	}
+	return s, nil
+}
+
+func newTestStreamConsumer(t *testing.T, srv *mockStreamServer) *StreamConsumer[string, int] {
+	sc, err := NewStreamConsumer(context.Background(), StreamConfig[string, int]{
+		Open:    srv.open,
+		Marshal: func(item any) (string, error) { return fmt.Sprint(item), nil },
+		Confirm: func(acked, sent int) error {
+			if acked != sent {
+				return fmt.Errorf("acked %d of %d", acked, sent)
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	return sc
+}
+
+func TestStreamConsumer_OneStreamPerBatch(t *testing.T) {
+	srv := &mockStreamServer{}
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, MaxItems), {"x"}},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(p, newTestStreamConsumer(t, srv))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, 2, srv.opened)
+	assert.Len(t, srv.received[0], MaxItems)
+	assert.Equal(t, []string{"x"}, srv.received[1])
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestStreamConsumer_ReestablishesAfterBrokenStream(t *testing.T) {
+	srv := &mockStreamServer{sendErr: errors.New("connection reset")}
+	sc := newTestStreamConsumer(t, srv)
+
+	err := sc.Process([]any{1})
+	require.ErrorIs(t, err, srv.sendErr)
+	assert.Empty(t, srv.received, "оборванный батч не должен считаться принятым")
+
+	require.NoError(t, sc.Process([]any{1}))
+	assert.Equal(t, 2, srv.opened, "следующий батч идёт по новому стриму")
+	assert.Equal(t, [][]string{{"1"}}, srv.received)
+}
+
+func TestStreamConsumer_NotConfirmed(t *testing.T) {
+	sc, err := NewStreamConsumer(context.Background(), StreamConfig[string, int]{
+		Open:    (&mockStreamServer{}).open,
+		Marshal: func(item any) (string, error) { return fmt.Sprint(item), nil },
+		Confirm: func(int, int) error { return errors.New("rejected") },
+	})
+	require.NoError(t, err)
+
+	err = sc.Process([]any{1})
+	assert.ErrorIs(t, err, ErrStreamNotConfirmed)
+}
+
+func TestNewStreamConsumer_RequiresOpenAndMarshal(t *testing.T) {
+	_, err := NewStreamConsumer(context.Background(), StreamConfig[string, int]{})
+	assert.Error(t, err)
+}
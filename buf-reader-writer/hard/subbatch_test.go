@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestPipe_MaxProcessItems_SplitsBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 4), makeItems(4, 3)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithMaxProcessItems(3))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{makeItems(0, 3), makeItems(3, 3), makeItems(6, 1)}, c.processed)
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestPipe_MaxProcessItems_ResumesFromFailedSubBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 4)},
+		cookies: []int{1},
+		readErr: io.EOF,
+	}
+	// Первый под-срез проходит, второй падает один раз
+	c := &failOnCallConsumer{failErr: errors.New("temporary"), failCall: 2}
+
+	err := Pipe(p, c, WithMaxProcessItems(2), WithSupervisor(SupervisorPolicy{MaxRestarts: 1, Backoff: time.Microsecond}))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]any{makeItems(0, 2), makeItems(2, 2)}, c.processed, "обработанный под-срез не должен повторяться")
+	assert.Equal(t, []int{1}, p.committed)
+}
+
+// failOnCallConsumer падает ровно на вызове Process с номером failCall.
+type failOnCallConsumer struct {
+	mockConsumer
+	failErr  error
+	failCall int
+	calls    int
+}
+
+func (m *failOnCallConsumer) Process(items []any) error {
+	m.calls++
+	if m.calls == m.failCall {
+		return m.failErr
+	}
+	return m.mockConsumer.Process(items)
+}
@@ -0,0 +1,49 @@
+package main
+
+import "sync/atomic"
+
+// Summary — итоги одного вызова Pipe. Заполняется при выходе из Pipe, если передан через WithSummary.
+type Summary struct {
+	DryRun         bool // Pipe работал в режиме WithDryRun: Commit не вызывался ни разу
+	Batches        int  // число полностью обработанных батчей
+	Items          int  // число элементов в обработанных батчах
+	Commits        int  // число успешных вызовов Commit
+	SkippedCommits int  // число cookies, Commit которых был пропущен (dry run или TailFlushWithoutCommit)
+	DeadLettered   int  // число элементов, переданных в DeadLetter
+}
+
+// WithSummary просит Pipe заполнить summary итогами работы перед возвратом.
+func WithSummary(summary *Summary) Option {
+	return func(o *options) {
+		o.summary = summary
+	}
+}
+
+// WithDryRun включает режим проверки: Next и Process работают как обычно, но Commit и Nack не вызываются,
+// поэтому прогресс источника не меняется. Удобно для обкатки нового Consumer на боевых данных.
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// pipeStats — счётчики Summary. Обновляются воркером, читаются при выходе из Pipe.
+type pipeStats struct {
+	batches        atomic.Int64
+	items          atomic.Int64
+	commits        atomic.Int64
+	skippedCommits atomic.Int64
+	deadLettered   atomic.Int64
+}
+
+// fill переносит текущие значения счётчиков в dst.
+func (s *pipeStats) fill(dst *Summary, dryRun bool) {
+	*dst = Summary{
+		DryRun:         dryRun,
+		Batches:        int(s.batches.Load()),
+		Items:          int(s.items.Load()),
+		Commits:        int(s.commits.Load()),
+		SkippedCommits: int(s.skippedCommits.Load()),
+		DeadLettered:   int(s.deadLettered.Load()),
+	}
+}
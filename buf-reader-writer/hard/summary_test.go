@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestPipe_DryRun_NeverCommits(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &nackProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, MaxItems), makeItems(MaxItems, 2)},
+			cookies: []int{1, 2},
+			readErr: io.EOF,
+		},
+	}
+	c := &mockConsumer{}
+	var summary Summary
+
+	err := Pipe(p, c, WithDryRun(), WithSummary(&summary))
+	require.Equal(t, io.EOF, err)
+	assert.Len(t, c.processed, 2, "Process должен вызываться как обычно")
+	assert.Len(t, p.commitAttempts, 0, "в dry run не должно быть вызовов Commit")
+	assert.Equal(t, Summary{DryRun: true, Batches: 2, Items: MaxItems + 2, SkippedCommits: 2}, summary)
+}
+
+func TestPipe_DryRun_ProcessErrorDoesNotNack(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &nackProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF},
+	}
+	c := &mockConsumer{procErr: errors.New("process failed")}
+
+	err := Pipe(p, c, WithDryRun())
+	require.ErrorIs(t, err, c.procErr)
+	assert.Len(t, p.nacked, 0, "в dry run источник не должен получать Nack")
+}
+
+func TestPipe_Summary_NormalRun(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 3), makeItems(3, 2)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+	var summary Summary
+
+	err := Pipe(p, &mockConsumer{}, WithSummary(&summary))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, Summary{Batches: 1, Items: 5, Commits: 2}, summary)
+}
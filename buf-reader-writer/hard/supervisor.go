@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/retry"
+)
+
+// SupervisorPolicy описывает перезапуск воркера после ошибки Process/Commit.
+// Воркер продолжает с незакоммиченной части батча: если Process уже прошёл, повторяются только Commit.
+type SupervisorPolicy struct {
+	MaxRestarts int                  // максимальное число перезапусков за время работы Pipe
+	Backoff     time.Duration        // пауза перед первым перезапуском, далее удваивается
+	MaxBackoff  time.Duration        // верхняя граница паузы; 0 — без ограничения
+	Jitter      float64              // доля паузы [0, 1], на которую она случайно уменьшается
+	Retryable   func(err error) bool // классификатор ошибок; nil — любая ошибка считается повторяемой
+}
+
+// WithSupervisor включает перезапуск воркера по политике policy вместо немедленного выхода из Pipe.
+func WithSupervisor(policy SupervisorPolicy) Option {
+	return func(o *options) {
+		o.supervisor = &policy
+	}
+}
+
+// supervisor считает перезапуски воркера в рамках одного вызова Pipe.
+// Лимит общий для всех воркеров WithWorkers, поэтому счётчик защищён мьютексом.
+type supervisor struct {
+	policy *SupervisorPolicy
+	retry  retry.Policy // паузы, классификатор и ожидание по Clock Pipe
+	logger Logger
+
+	mu       sync.Mutex
+	restarts int
+}
+
+func newSupervisor(o options) *supervisor {
+	if o.supervisor == nil {
+		return nil
+	}
+	return &supervisor{
+		policy: o.supervisor,
+		retry: retry.Policy{
+			Backoff:    o.supervisor.Backoff,
+			MaxBackoff: o.supervisor.MaxBackoff,
+			Jitter:     o.supervisor.Jitter,
+			Retryable:  o.supervisor.Retryable,
+			Sleep: func(ctx context.Context, d time.Duration) error {
+				if !sleep(o.clock, d, ctx.Done()) {
+					return ctx.Err()
+				}
+				return nil
+			},
+		},
+		logger: o.logger,
+	}
+}
+
+// restart решает, перезапускать ли воркер после err, и выдерживает паузу backoff.
+// Возвращает false, если ошибка неповторяемая, лимит исчерпан или ctx отменён во время паузы.
+func (s *supervisor) restart(ctx context.Context, err error) bool {
+	if s == nil {
+		return false
+	}
+	if !s.retry.IsRetryable(err) {
+		return false
+	}
+
+	s.mu.Lock()
+	if s.restarts >= s.policy.MaxRestarts {
+		s.mu.Unlock()
+		return false
+	}
+	s.restarts++
+	delay := s.retry.Delay(s.restarts)
+	attempt := s.restarts
+	s.mu.Unlock()
+	logEvent(s.logger, EventRetry, map[string]any{"attempt": attempt, "delay": delay, "error": err})
+
+	return s.retry.Wait(ctx, delay) == nil
+}
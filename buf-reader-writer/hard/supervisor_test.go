@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// flakyConsumer возвращает failErr на первых failures вызовах Process.
+type flakyConsumer struct {
+	mockConsumer
+	failErr  error
+	failures int
+	calls    int
+}
+
+func (m *flakyConsumer) Process(items []any) error {
+	m.calls++
+	if m.calls <= m.failures {
+		return m.failErr
+	}
+	return m.mockConsumer.Process(items)
+}
+
+// flakyCommitProducer возвращает commitErr на первых failures вызовах Commit.
+type flakyCommitProducer struct {
+	mockProducer
+	failures int
+	calls    int
+}
+
+func (m *flakyCommitProducer) Commit(cookie int) error {
+	m.calls++
+	if m.calls <= m.failures {
+		m.commitAttempts = append(m.commitAttempts, cookie)
+		return m.commitErr
+	}
+	return m.mockProducer.Commit(cookie)
+}
+
+func TestPipe_Supervisor_RestartsAfterProcessError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches: [][]any{makeItems(0, 2), makeItems(2, 2)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+	c := &flakyConsumer{failErr: errors.New("temporary"), failures: 2}
+
+	clock := newAutoClock()
+
+	err := Pipe(p, c, WithClock(clock), WithSupervisor(SupervisorPolicy{MaxRestarts: 3, Backoff: time.Second}))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, 3, c.calls, "ожидались два перезапуска и успешный Process")
+	assert.Equal(t, []int{1, 2}, p.committed)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, clock.Sleeps(), "пауза удваивается с каждым перезапуском")
+}
+
+func TestPipe_Supervisor_ResumesFromUncommittedCookie(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &flakyCommitProducer{
+		mockProducer: mockProducer{
+			batches:   [][]any{makeItems(0, 2), makeItems(2, 2)},
+			cookies:   []int{1, 2},
+			readErr:   io.EOF,
+			commitErr: errors.New("temporary"),
+		},
+		failures: 2, // первая попытка Commit(1) и первый перезапуск падают
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithClock(newAutoClock()), WithSupervisor(SupervisorPolicy{MaxRestarts: 5, Backoff: time.Second}))
+	require.ErrorIs(t, err, io.EOF)
+	assert.Len(t, c.processed, 1, "после ошибки Commit батч не должен обрабатываться повторно")
+	assert.Equal(t, []int{1, 2}, p.committed)
+	assert.Equal(t, []int{1, 1, 1, 2}, p.commitAttempts)
+}
+
+func TestPipe_Supervisor_GivesUpAfterMaxRestarts(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &nackProducer{
+		mockProducer: mockProducer{
+			batches: [][]any{makeItems(0, 1)},
+			cookies: []int{1},
+			readErr: io.EOF,
+		},
+	}
+	c := &flakyConsumer{failErr: errors.New("temporary"), failures: 10}
+
+	clock := newAutoClock()
+
+	err := Pipe(p, c, WithClock(clock), WithSupervisor(SupervisorPolicy{MaxRestarts: 2, Backoff: time.Second, MaxBackoff: 1500 * time.Millisecond}))
+	require.ErrorIs(t, err, c.failErr)
+	assert.Equal(t, 3, c.calls, "первая попытка и два перезапуска")
+	assert.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond}, clock.Sleeps(), "пауза ограничена MaxBackoff")
+	assert.Equal(t, []int{1}, p.nacked, "Nack только после окончательной ошибки")
+}
+
+func TestPipe_Supervisor_WaitsForBackoff(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF}
+	c := &flakyConsumer{failErr: errors.New("temporary"), failures: 1}
+	clock := newFakeClock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Pipe(p, c, WithClock(clock), WithSupervisor(SupervisorPolicy{MaxRestarts: 1, Backoff: time.Hour}))
+	}()
+
+	clock.BlockUntil(1) // Воркер упал и ждёт паузу перед перезапуском
+	assert.Equal(t, 1, c.calls)
+	clock.Advance(time.Hour)
+	require.ErrorIs(t, <-done, io.EOF)
+	assert.Equal(t, 2, c.calls, "после паузы воркер перезапускается и обрабатывает батч")
+	assert.Equal(t, []int{1}, p.committed)
+}
+
+func TestPipe_Supervisor_NonRetryableError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{batches: [][]any{makeItems(0, 1)}, cookies: []int{1}, readErr: io.EOF}
+	c := &flakyConsumer{failErr: errors.New("fatal"), failures: 10}
+
+	err := Pipe(p, c, WithSupervisor(SupervisorPolicy{
+		MaxRestarts: 5,
+		Retryable:   func(err error) bool { return !errors.Is(err, c.failErr) },
+	}))
+	require.ErrorIs(t, err, c.failErr)
+	assert.Equal(t, 1, c.calls, "неповторяемая ошибка не должна перезапускать воркер")
+}
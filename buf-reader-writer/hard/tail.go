@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTailFlush оборачивает ошибку, возникшую при финальной обработке хвоста после io.EOF источника.
+// Чистое завершение Pipe возвращает ровно io.EOF; если же хвост не удалось обработать или закоммитить,
+// возвращается ошибка с ErrTailFlush (и исходной причиной), но без io.EOF в цепочке.
+var ErrTailFlush = errors.New("tail flush failed")
+
+// TailPolicy определяет, что делать с накопленным буфером, когда источник вернул io.EOF.
+type TailPolicy int
+
+const (
+	// TailFlushAndCommit — передать хвост в Process и закоммитить его cookies (по умолчанию).
+	TailFlushAndCommit TailPolicy = iota
+	// TailDiscard — отбросить хвост: Process не вызывается, cookies остаются незакоммиченными.
+	TailDiscard
+	// TailFlushWithoutCommit — передать хвост в Process, но не коммитить его cookies (например, для dry run).
+	TailFlushWithoutCommit
+)
+
+// WithTailPolicy задаёт обработку хвоста на io.EOF.
+func WithTailPolicy(policy TailPolicy) Option {
+	return func(o *options) {
+		o.tail = policy
+	}
+}
+
+// tailError помечает ошибку финальной стадии, чтобы её нельзя было спутать с чистым io.EOF.
+func tailError(err error) error {
+	return fmt.Errorf("%w: %w", ErrTailFlush, err)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func newTailProducer() *mockProducer {
+	return &mockProducer{
+		batches: [][]any{makeItems(0, MaxItems), makeItems(MaxItems, 2)},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+}
+
+func TestPipe_Tail_FlushAndCommit(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newTailProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c)
+	require.Equal(t, io.EOF, err, "чистое завершение должно возвращать ровно io.EOF")
+	assert.Len(t, c.processed, 2)
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestPipe_Tail_Discard(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newTailProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithTailPolicy(TailDiscard))
+	require.Equal(t, io.EOF, err)
+	assert.Len(t, c.processed, 1, "хвост не должен попадать в Process")
+	assert.Equal(t, []int{1}, p.committed, "cookies хвоста не должны коммититься")
+}
+
+func TestPipe_Tail_FlushWithoutCommit(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newTailProducer()
+	c := &mockConsumer{}
+
+	err := Pipe(p, c, WithTailPolicy(TailFlushWithoutCommit))
+	require.Equal(t, io.EOF, err)
+	assert.Len(t, c.processed, 2, "хвост должен попасть в Process")
+	assert.Equal(t, []int{1}, p.committed, "cookies хвоста не должны коммититься")
+}
+
+func TestPipe_Tail_FailureIsNotEOF(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &mockProducer{
+		batches:            [][]any{makeItems(0, 2)},
+		cookies:            []int{1},
+		readErr:            io.EOF,
+		commitErrForCookie: 1,
+		commitErr:          errors.New("commit failed"),
+	}
+	c := &mockConsumer{}
+
+	err := Pipe(p, c)
+	require.ErrorIs(t, err, ErrTailFlush)
+	assert.ErrorIs(t, err, p.commitErr)
+	assert.NotErrorIs(t, err, io.EOF, "ошибка хвоста не должна выглядеть как чистый EOF")
+}
+
+func TestPipe_Tail_EarlierBatchErrorNotBlamedOnTail(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	for _, opts := range [][]Option{
+		{WithTailPolicy(TailDiscard)},
+		{WithTailPolicy(TailFlushAndCommit)},
+		{WithTailPolicy(TailFlushAndCommit), WithWorkers(2)},
+	} {
+		p := newTailProducer()
+		p.commitErrForCookie = 1
+		p.commitErr = errors.New("commit failed")
+
+		err := Pipe(p, discardConsumer{}, opts...)
+		require.ErrorIs(t, err, p.commitErr)
+		assert.NotErrorIs(t, err, ErrTailFlush, "ошибка обычного батча не относится к хвосту")
+	}
+}
+
+func TestPipe_Tail_FailureWithWorkers(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := newTailProducer()
+	p.commitErrForCookie = 2
+	p.commitErr = errors.New("commit failed")
+
+	err := Pipe(p, discardConsumer{}, WithWorkers(2))
+	require.ErrorIs(t, err, ErrTailFlush)
+	assert.ErrorIs(t, err, p.commitErr)
+}
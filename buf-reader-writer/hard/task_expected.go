@@ -2,44 +2,73 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+
+	"github.com/zlatoivan/go-advanced/batcher"
 )
 
 // MaxItems — максимальный размер объединённого батча для одного вызова Process.
 const MaxItems = 9999
 
-// Producer — источник данных. Возвращает элементы и cookie для последующего Commit.
-type Producer interface {
+// ProducerOf — источник элементов типа T. Возвращает элементы и cookie для последующего Commit.
+type ProducerOf[T any] interface {
 	// Next returns:
 	// - batch of items to be processed
 	// - cookie to be commited when processing is done
 	// - error
-	Next() (items []any, cookie int, err error)
+	Next() (items []T, cookie int, err error)
 	// Commit is used to mark data batch as processed
 	Commit(cookie int) error
 }
 
-// Consumer — потребитель данных. Обрабатывает переданные элементы.
-type Consumer interface {
-	Process(items []any) error
+// ConsumerOf — потребитель элементов типа T. Обрабатывает переданные элементы.
+type ConsumerOf[T any] interface {
+	Process(items []T) error
 }
 
+// Producer — источник нетипизированных элементов; с ним работают Pipe, Pipeline и обёртки пакета.
+type Producer = ProducerOf[any]
+
+// Consumer — потребитель нетипизированных элементов.
+type Consumer = ConsumerOf[any]
+
 // batch — единица передачи в воркер: объединённые items из нескольких Next
 // и упорядоченный набор cookies, которые требуется коммитить строго по порядку.
-type batch struct {
-	items   []any
-	cookies []int
+type batch[T any] struct {
+	items      []T
+	cookies    []int
+	spans      []MetaSpan // метаданные исходных батчей (только для MetaProducer)
+	skipCommit bool       // обработать батч без Commit (хвост при TailFlushWithoutCommit)
+	tail       bool       // хвост после io.EOF источника: ошибки его обработки помечаются ErrTailFlush
 }
 
 // startWorker поднимает горутину-воркер, которая:
-// 1) вызывает Process для батча,
+// 1) вызывает Process для батча (при ошибке — Nack всех его cookies, если источник умеет),
 // 2) последовательно делает Commit для всех cookies,
 // 3) отправляет ошибки в errCh и корректно завершается по ctx.Done() или закрытию batchCh.
-func startWorker(ctx context.Context, p Producer, c Consumer) (chan batch, chan error, chan struct{}) {
-	batchCh := make(chan batch, 1)
+// Если задан супервизор, после повторяемой ошибки воркер перезапускается с незакоммиченной части батча.
+// С WithWorkers батчи обрабатываются параллельно (см. startPoolWorker).
+func startWorker[T any](ctx context.Context, p ProducerOf[T], c ConsumerOf[T], o options, stats *pipeStats) (chan batch[T], chan error, chan struct{}) {
+	batchCh := make(chan batch[T], 1)
 	errCh := make(chan error, 1)
 	doneCh := make(chan struct{})
+	w := &worker[T]{
+		p:               p,
+		c:               c,
+		sup:             newSupervisor(o),
+		retry:           newRetrier(o),
+		deadLetter:      deadLetterOf[T](o),
+		metrics:         o.metrics,
+		logger:          o.logger,
+		maxProcessItems: o.maxProcessItems,
+		dryRun:          o.dryRun,
+		stats:           stats,
+	}
+	if o.workers > 1 {
+		return startPoolWorker(ctx, w, o.workers)
+	}
 
 	// Worker: последовательно Process, затем Commit всех cookies
 	go func() {
@@ -52,28 +81,17 @@ func startWorker(ctx context.Context, p Producer, c Consumer) (chan batch, chan
 				if !ok {
 					return
 				}
-				if len(b.items) == 0 {
+				if len(b.items) == 0 && len(b.cookies) == 0 {
 					continue
 				}
-				var err error
-				err = c.Process(b.items)
+				err := w.handleBatch(ctx, b)
 				if err != nil {
 					select {
-					case errCh <- fmt.Errorf("push error: %w", err):
+					case errCh <- err:
 					default:
 					}
 					return
 				}
-				for _, ck := range b.cookies {
-					err = p.Commit(ck)
-					if err != nil {
-						select {
-						case errCh <- fmt.Errorf("error commiting cookie %d: %w", ck, err):
-						default:
-						}
-						return
-					}
-				}
 			}
 		}
 	}()
@@ -81,34 +99,231 @@ func startWorker(ctx context.Context, p Producer, c Consumer) (chan batch, chan
 	return batchCh, errCh, doneCh
 }
 
-// Pipe читает элементы из Producer, аккумулирует их до MaxItems и отправляет в воркер.
-// Воркер выполняет Process и Commit по порядку. На io.EOF выполняется «флеш» хвоста
+// worker — зависимости и настройки горутины-воркера.
+type worker[T any] struct {
+	p               ProducerOf[T]
+	c               ConsumerOf[T]
+	sup             *supervisor     // nil — без перезапусков
+	retry           *retrier        // повторы Commit; nil — без повторов
+	deadLetter      DeadLetterOf[T] // приёмник необработанных батчей; nil — ошибка Process завершает Pipe
+	metrics         Metrics         // nil — метрики не собираются
+	logger          Logger
+	maxProcessItems int  // лимит элементов на один вызов Process; <= 0 — весь батч за раз
+	dryRun          bool // не вызывать Commit и Nack
+	stats           *pipeStats
+}
+
+// handleBatch выполняет Process и Commit одного батча, запоминая прогресс между перезапусками:
+// уже обработанные элементы повторно в Process не попадают, уже закоммиченные cookies не коммитятся снова.
+func (w *worker[T]) handleBatch(ctx context.Context, b batch[T]) error {
+	err := w.processBatch(ctx, b)
+	if err == nil {
+		err = w.commitBatch(ctx, b)
+	}
+	return batchError(b, err)
+}
+
+// batchError помечает ошибку обработки хвоста ErrTailFlush; ошибки прочих батчей возвращаются как есть.
+func batchError[T any](b batch[T], err error) error {
+	if err == nil || !b.tail {
+		return err
+	}
+	return tailError(err)
+}
+
+// processBatch передаёт элементы батча в Process (под-срезами по maxProcessItems), перезапускаясь по политике супервизора.
+// Если батч так и не обработан, просит источник доставить его cookies повторно.
+func (w *worker[T]) processBatch(ctx context.Context, b batch[T]) error {
+	processed := 0 // сколько элементов батча уже обработано
+	for processed < len(b.items) {
+		end := len(b.items)
+		if w.maxProcessItems > 0 {
+			end = min(end, processed+w.maxProcessItems)
+		}
+		err := process(w.c, b.items[processed:end], sliceSpans(b.spans, processed, end))
+		if err == nil {
+			processed = end
+			continue
+		}
+		if w.metrics != nil {
+			w.metrics.OnProcessError(err)
+		}
+		err = fmt.Errorf("push error: %w", err)
+		if w.sup.restart(ctx, err) {
+			continue
+		}
+		if w.deadLetter != nil {
+			dlErr := w.deadLetter.Handle(b.items[processed:], b.cookies, err)
+			if dlErr == nil { // Батч принят DeadLetter: коммитим его и продолжаем
+				w.stats.deadLettered.Add(int64(len(b.items) - processed))
+				logEvent(w.logger, EventDeadLettered, map[string]any{"items": len(b.items) - processed, "cookies": b.cookies, "error": err})
+				return nil
+			}
+			err = errors.Join(err, fmt.Errorf("dead letter: %w", dlErr))
+		}
+
+		if !w.dryRun { // Батч окончательно не обработан: просим источник доставить его повторно
+			nackErr := nackAll(w.p, b.cookies)
+			if nackErr != nil {
+				err = errors.Join(err, nackErr)
+			}
+			if _, ok := w.p.(Nacker); ok {
+				logEvent(w.logger, EventBatchNacked, map[string]any{"cookies": b.cookies, "error": err})
+			}
+		}
+		return err
+	}
+	w.stats.batches.Add(1)
+	w.stats.items.Add(int64(len(b.items)))
+	return nil
+}
+
+// commitBatch последовательно коммитит cookies обработанного батча, перезапускаясь по политике супервизора
+// с первого незакоммиченного.
+func (w *worker[T]) commitBatch(ctx context.Context, b batch[T]) error {
+	committed := 0
+	if b.skipCommit || w.dryRun {
+		committed = len(b.cookies)
+		w.stats.skippedCommits.Add(int64(len(b.cookies)))
+	}
+	for committed < len(b.cookies) {
+		ck := b.cookies[committed]
+		err := w.retry.do(ctx, "commit", func() error { return w.p.Commit(ck) })
+		if w.metrics != nil {
+			w.metrics.OnCommit(ck, err)
+		}
+		if err == nil {
+			committed++
+			w.stats.commits.Add(1)
+			if w.logger != nil { // Горячий путь: атрибуты собираются, только если их есть кому отдать
+				logEvent(w.logger, EventCommitDone, map[string]any{"cookie": ck})
+			}
+			continue
+		}
+		err = fmt.Errorf("error commiting cookie %d: %w", ck, err)
+		if !w.sup.restart(ctx, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pipe читает элементы из Producer, аккумулирует их до MaxItems (и условий WithBatchLimits) и отправляет в воркер.
+// Воркер выполняет Process и Commit по порядку. На io.EOF выполняется «флеш» хвоста (см. TailPolicy)
 // и ожидание завершения воркера; при ошибках Next/Process/Commit — немедленный выход.
-func Pipe(p Producer, c Consumer) error {
-	var buf []any
-	var cookies []int
+// Поведение настраивается опциями opts (см. Option).
+func Pipe(p Producer, c Consumer, opts ...Option) error {
+	return PipeOf(p, c, opts...)
+}
+
+// PipeOf — Pipe для элементов типа T: элементы передаются от источника к потребителю без упаковки в any.
+// Расширения источника и потребителя (Nacker, Pausable, MetaProducerOf, MetaConsumerOf) работают так же.
+func PipeOf[T any](p ProducerOf[T], c ConsumerOf[T], opts ...Option) error {
+	o := newOptions(opts)
+	if o.deadLetter != nil && deadLetterOf[T](o) == nil {
+		return fmt.Errorf("dead letter %T does not accept items of the pipe type", o.deadLetter)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	acc, err := newAccumulator[T](o)
+	if err != nil {
+		return err
+	}
+	var metas []Meta // метаданные порций текущего буфера, параллельно acc (только для MetaProducer)
+
+	workerCtx := o.ctx
+	if o.shutdown != ShutdownAbort { // Отмена o.ctx не должна прерывать воркер, который Pipe дожидается
+		workerCtx = context.WithoutCancel(o.ctx)
+	}
+	ctx, cancel := context.WithCancel(workerCtx)
 	defer cancel()
 
-	batchCh, errCh, doneCh := startWorker(ctx, p, c)
+	stats := &pipeStats{}
+	if o.summary != nil {
+		defer stats.fill(o.summary, o.dryRun)
+	}
 
-	// flush отправляет текущий накопленный буфер в воркер и очищает локальные срезы.
-	flush := func() error {
-		if len(buf) == 0 {
-			return nil
+	batchCh, errCh, doneCh := startWorker(ctx, p, c, o, stats)
+
+	// next вызывает Next источника, повторяя сбои по WithRetry
+	retrier := newRetrier(o)
+	next := func() (r nextResult[T]) {
+		r.err = retrier.do(ctx, "next", func() (err error) {
+			r.items, r.cookie, r.meta, err = nextWithMeta(p)
+			return err
+		})
+		return r
+	}
+
+	var delayed *delayedNext[T] // nil — Next вызывается синхронно
+	if o.maxDelay > 0 {
+		delayed = newDelayedNext(next)
+		defer func() {
+			cancel() // Прерывает паузу повтора в ожидаемом Next
+			delayed.wait()
+		}()
+	}
+
+	// flush отправляет накопленный батч acc в воркер вместе с метаданными его порций; tail — хвост после io.EOF.
+	// Если воркер уже завершился, возвращается его ошибка, если отменён ctx — context.Canceled.
+	flush := func(acc batcher.Batch[T], metas []Meta, tail bool) error {
+		b := batch[T]{
+			items:      acc.Items,
+			cookies:    acc.Cookies,
+			spans:      metaSpans(acc, metas),
+			skipCommit: tail && o.tail == TailFlushWithoutCommit,
+			tail:       tail,
 		}
 		select {
-		case <-ctx.Done():
-			return context.Canceled
-		case batchCh <- batch{items: buf, cookies: cookies}:
+		case batchCh <- b:
+		default:
+			// Очередь воркера заполнена: сигнализируем источнику о backpressure и ждём освобождения
+			resume := pauseProducer(p)
+			select {
+			case <-ctx.Done():
+				resume()
+				return context.Canceled
+			case <-doneCh:
+				// Воркер завершился с ошибкой и больше не примет батч
+				resume()
+				select {
+				case e := <-errCh:
+					return e
+				default:
+					return context.Canceled
+				}
+			case batchCh <- b:
+				resume()
+			}
+		}
+		if o.logger != nil {
+			logEvent(o.logger, EventBatchFlushed, map[string]any{"items": len(b.items), "cookies": b.cookies})
+		}
+		if o.metrics != nil {
+			o.metrics.OnFlush(len(b.items), o.clock.Now().Sub(acc.Started))
 		}
-		// Сбросим локальный буфер
-		buf = nil
-		cookies = nil
 		return nil
 	}
 
+	// drain закрывает очередь воркера и дожидается его; возвращает ошибку воркера, если она была.
+	drain := func() error {
+		close(batchCh)
+		select {
+		case e := <-errCh:
+			cancel()
+			<-doneCh
+			return e
+		case <-doneCh:
+			// На случай гонки проверим, не пришла ли ошибка
+			select {
+			case e := <-errCh:
+				cancel()
+				return e
+			default:
+			}
+			return nil
+		}
+	}
+
 	for {
 		// Ранняя реакция на ошибку воркера, если она уже есть.
 		select {
@@ -117,54 +332,90 @@ func Pipe(p Producer, c Consumer) error {
 			return e
 		default:
 		}
+		if err := o.ctx.Err(); err != nil {
+			if o.shutdown == ShutdownAbort {
+				return err
+			}
+			return shutdown(o, p, acc, func(b batcher.Batch[T]) error { return flush(b, metas, false) }, drain, err)
+		}
 
-		items, cookie, err := p.Next()
+		var r nextResult[T]
+		if delayed == nil {
+			r = next()
+		} else {
+			// Пока Next ждёт данных, буфер уходит в воркер по таймеру WithMaxDelay
+			r, err = delayed.await(o.clock, o.maxDelay, acc, func() error {
+				err := flush(acc.Flush(), metas, false)
+				metas = nil
+				return err
+			})
+			if err != nil {
+				cancel()
+				return err
+			}
+		}
+		items, cookie, meta, err := r.items, r.cookie, r.meta, r.err
 		if err != nil {
 			if err == io.EOF {
-				// Источник завершился: флешим хвост, закрываем канал и ждём воркер.
-				flushErr := flush()
+				// Источник завершился: обрабатываем хвост по TailPolicy, закрываем канал и ждём воркер.
+				var flushErr error
+				if acc.Pending() > 0 && o.tail != TailDiscard {
+					flushErr = flush(acc.Flush(), metas, true)
+				}
 				if flushErr != nil {
 					cancel()
-					return flushErr
+					if flushErr == context.Canceled {
+						return tailError(flushErr)
+					}
+					return flushErr // Воркер завершился ошибкой одного из предыдущих батчей и хвост не принял
 				}
-				close(batchCh)
-				// Дождаться результата воркера: если он завершился ошибкой — вернуть её, иначе EOF
-				select {
-				case e := <-errCh:
-					cancel()
-					<-doneCh
+				// Дождаться результата воркера: если он завершился ошибкой — вернуть её (ошибки самого хвоста
+				// воркер уже пометил ErrTailFlush), иначе EOF
+				if e := drain(); e != nil {
 					return e
-				case <-doneCh:
-					// На случай гонки проверим, не пришла ли ошибка
-					select {
-					case e := <-errCh:
-						cancel()
-						return e
-					default:
-					}
-					return io.EOF
 				}
+				return io.EOF
 			}
 			cancel()
 			return fmt.Errorf("read error: %w", err)
 		}
 
-		// Накопление: если не переполняем буфер — просто добавляем элементы и cookie.
-		if len(buf)+len(items) <= MaxItems {
-			buf = append(buf, items...)
-			cookies = append(cookies, cookie)
-			continue
+		if o.metrics != nil {
+			o.metrics.OnNext(len(items))
 		}
 
-		// Переполнение: отправляем накопленное на обработку в воркер.
-		err = flush()
-		if err != nil {
-			cancel()
-			return err
+		// Защита от повторно доставленных батчей, если она включена
+		if o.dedup != nil {
+			skip, dupErr := o.dedup.check(cookie, o.logger)
+			if dupErr != nil {
+				cancel()
+				return dupErr
+			}
+			if skip { // Элементы дубля отбрасываются, а cookie коммитится вместе с буфером, чтобы источник не ждал его
+				items = nil
+			}
+		}
+
+		// Накопление. Если порция не поместилась, накопленное уходит в воркер, а порция начинает новый буфер.
+		full, ok := acc.Add(items, cookie)
+		if ok {
+			err = flush(full, metas, false)
+			if err != nil {
+				cancel()
+				return err
+			}
+			metas = nil
 		}
+		metas = append(metas, meta)
 
-		// Начинаем новый буфер с текущего батча (эти items ещё не обрабатывались).
-		buf = items
-		cookies = []int{cookie}
+		// Буфер ждёт дольше ByAge: отправляем, не дожидаясь переполнения.
+		if acc.Due() {
+			err = flush(acc.Flush(), metas, false)
+			if err != nil {
+				cancel()
+				return err
+			}
+			metas = nil
+		}
 	}
 }
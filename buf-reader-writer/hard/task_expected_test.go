@@ -4,10 +4,13 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/testutil"
 )
 
 type mockProducer struct {
@@ -70,6 +73,7 @@ func concat(a, b []any) []any {
 }
 
 func TestPipe_Success_BatchingAndCommitOrder(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
 	var err error
 	firstBatchSize := MaxItems / 2
 	secondBatchSize := MaxItems - firstBatchSize
@@ -105,6 +109,7 @@ func TestPipe_Success_BatchingAndCommitOrder(t *testing.T) {
 }
 
 func TestPipe_ReadError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
 	var err error
 	p := &mockProducer{readErr: io.ErrUnexpectedEOF}
 	c := &mockConsumer{}
@@ -117,6 +122,7 @@ func TestPipe_ReadError(t *testing.T) {
 }
 
 func TestPipe_ProcessError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
 	var err error
 	firstBatchSize := MaxItems / 2
 	secondBatchSize := MaxItems - firstBatchSize
@@ -138,6 +144,7 @@ func TestPipe_ProcessError(t *testing.T) {
 }
 
 func TestPipe_CommitError(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
 	var err error
 	firstBatchSize := MaxItems / 2
 	secondBatchSize := MaxItems - firstBatchSize
@@ -163,3 +170,85 @@ func TestPipe_CommitError(t *testing.T) {
 	// No successful commits
 	assert.Len(t, p.committed, 0, "не должно быть успешных коммитов")
 }
+
+// typedProducer — источник элементов типа T с метаданными и Nack.
+type typedProducer[T any] struct {
+	batches [][]T
+	next    int
+	commits []int
+	nacks   []int
+}
+
+func (p *typedProducer[T]) Next() ([]T, int, error) {
+	items, cookie, _, err := p.NextWithMeta()
+	return items, cookie, err
+}
+
+func (p *typedProducer[T]) NextWithMeta() ([]T, int, Meta, error) {
+	if p.next == len(p.batches) {
+		return nil, 0, nil, io.EOF
+	}
+	p.next++
+	return p.batches[p.next-1], p.next, Meta{"batch": strconv.Itoa(p.next)}, nil
+}
+
+func (p *typedProducer[T]) Commit(cookie int) error {
+	p.commits = append(p.commits, cookie)
+	return nil
+}
+
+func (p *typedProducer[T]) Nack(cookie int) error {
+	p.nacks = append(p.nacks, cookie)
+	return nil
+}
+
+// typedConsumer — потребитель элементов типа T, запоминающий вызовы и спаны метаданных.
+type typedConsumer[T any] struct {
+	calls [][]T
+	spans [][]MetaSpan
+	err   error
+}
+
+func (c *typedConsumer[T]) Process(items []T) error {
+	return c.ProcessWithMeta(items, nil)
+}
+
+func (c *typedConsumer[T]) ProcessWithMeta(items []T, spans []MetaSpan) error {
+	c.calls = append(c.calls, append([]T(nil), items...))
+	c.spans = append(c.spans, spans)
+	return c.err
+}
+
+// Проверка, что типизированные двойники удовлетворяют расширениям Pipe
+var (
+	_ MetaProducerOf[string] = (*typedProducer[string])(nil)
+	_ Nacker                 = (*typedProducer[string])(nil)
+	_ MetaConsumerOf[string] = (*typedConsumer[string])(nil)
+)
+
+func TestPipeOf_TypedItemsWithMetaAndBatchLimits(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	p := &typedProducer[string]{batches: [][]string{{"ab", "cd"}, {"ef"}, {"gh", "ij"}}}
+	c := &typedConsumer[string]{}
+
+	err := PipeOf(p, c, WithBatchLimits(ByBytes(6)))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]string{{"ab", "cd", "ef"}, {"gh", "ij"}}, c.calls, "ByBytes считает размер строк без приведения к any")
+	assert.Equal(t, [][]MetaSpan{
+		{{Start: 0, End: 2, Meta: Meta{"batch": "1"}}, {Start: 2, End: 3, Meta: Meta{"batch": "2"}}},
+		{{Start: 0, End: 2, Meta: Meta{"batch": "3"}}},
+	}, c.spans)
+	assert.Equal(t, []int{1, 2, 3}, p.commits)
+}
+
+func TestPipeOf_ProcessErrorNacksTypedBatch(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	procErr := errors.New("process failed")
+	p := &typedProducer[int]{batches: [][]int{{1, 2}, {3}}}
+	c := &typedConsumer[int]{err: procErr}
+
+	err := PipeOf(p, c, WithWorkers(2))
+	require.ErrorIs(t, err, procErr)
+	assert.Empty(t, p.commits)
+	assert.Equal(t, []int{1, 2}, p.nacks)
+}
@@ -0,0 +1,182 @@
+// Package testclock — детерминированные часы для тестов time-based механизмов Pipe (флеш по таймеру, таймауты, backoff).
+//
+// Clock не зависит от пакета Pipe: его методы Now и NewTimer структурно совпадают с интерфейсом Clock,
+// а *Timer — с интерфейсом Timer, поэтому в тестах достаточно тонкого адаптера.
+//
+// Часы бывают двух видов:
+//   - New — время стоит, пока тест не вызовет Advance или Step; BlockUntil позволяет дождаться,
+//     пока тестируемая горутина заведёт таймер, без time.Sleep;
+//   - NewAuto — каждый новый таймер срабатывает сразу, сдвигая время на свою длительность.
+//     Подходит для синхронного кода, который просто «спит» (rate limit, backoff, опрос по расписанию).
+package testclock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock — фейковые часы. Безопасны для конкурентного использования.
+type Clock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond // сигнализирует об изменении набора активных таймеров
+	now    time.Time
+	auto   bool
+	timers []*Timer        // активные таймеры
+	sleeps []time.Duration // длительности всех заведённых таймеров в порядке вызовов
+}
+
+// New создаёт часы, показывающие start, которые двигаются только вызовами Advance и Step.
+func New(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// NewAuto создаёт часы, в которых каждый заведённый таймер срабатывает сразу, а время сдвигается на его длительность.
+func NewAuto(start time.Time) *Clock {
+	c := New(start)
+	c.auto = true
+	return c
+}
+
+// Now возвращает текущее фейковое время.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer заводит таймер на d. Таймер с d <= 0 срабатывает сразу.
+func (c *Clock) NewTimer(d time.Duration) *Timer {
+	t := &Timer{c: c, ch: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule(t, d)
+	return t
+}
+
+// Advance сдвигает время на d и по порядку срабатывает все таймеры, чей срок наступил.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// Step сдвигает время до ближайшего таймера и срабатывает его. Возвращает false, если активных таймеров нет.
+func (c *Clock) Step() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.timers) == 0 {
+		return false
+	}
+	c.advanceTo(c.timers[0].deadline)
+	return true
+}
+
+// Pending возвращает число активных (заведённых и ещё не сработавших) таймеров.
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil ждёт, пока число активных таймеров станет не меньше n.
+// Типичный шаг теста: BlockUntil(1) — тестируемая горутина уснула; Advance(d) — разбудить её.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// Sleeps возвращает длительности всех заведённых таймеров (NewTimer и Reset) в порядке вызовов.
+func (c *Clock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+// schedule ставит таймер t на срок now+d. Вызывается под c.mu.
+func (c *Clock) schedule(t *Timer, d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	t.deadline = c.now.Add(d)
+	t.active = true
+	c.timers = append(c.timers, t)
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].deadline.Before(c.timers[j].deadline) })
+	c.cond.Broadcast()
+
+	switch {
+	case c.auto:
+		c.advanceTo(maxTime(c.now, t.deadline))
+	case d <= 0:
+		c.advanceTo(c.now)
+	}
+}
+
+// advanceTo переводит время в until, срабатывая наступившие таймеры в порядке сроков. Вызывается под c.mu.
+func (c *Clock) advanceTo(until time.Time) {
+	for len(c.timers) > 0 && !c.timers[0].deadline.After(until) {
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = maxTime(c.now, t.deadline)
+		t.active = false
+		select {
+		case t.ch <- c.now:
+		default: // Предыдущее срабатывание не прочитано — как у time.Timer, второе значение не кладём
+		}
+	}
+	c.now = maxTime(c.now, until)
+	c.cond.Broadcast()
+}
+
+// remove снимает таймер t с учёта. Возвращает true, если он был активен. Вызывается под c.mu.
+func (c *Clock) remove(t *Timer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+	}
+	c.cond.Broadcast()
+	return true
+}
+
+// Timer — таймер фейковых часов с семантикой *time.Timer.
+type Timer struct {
+	c        *Clock
+	ch       chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+// C возвращает канал, в который приходит время срабатывания.
+func (t *Timer) C() <-chan time.Time { return t.ch }
+
+// Stop отменяет таймер. Возвращает false, если он уже сработал или был остановлен.
+func (t *Timer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	return t.c.remove(t)
+}
+
+// Reset перезаводит таймер на d от текущего фейкового времени. Возвращает true, если таймер был активен.
+func (t *Timer) Reset(d time.Duration) bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	wasActive := t.c.remove(t)
+	t.c.schedule(t, d)
+	return wasActive
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,101 @@
+package testclock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var epoch = time.Unix(0, 0)
+
+// fired сообщает, сработал ли таймер, не блокируясь.
+func fired(t *Timer) (time.Time, bool) {
+	select {
+	case at := <-t.C():
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestClock_AdvanceFiresDueTimersInOrder(t *testing.T) {
+	c := New(epoch)
+	late := c.NewTimer(2 * time.Second)
+	early := c.NewTimer(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	_, ok := fired(early)
+	assert.False(t, ok, "срок ещё не наступил")
+
+	c.Advance(time.Second)
+	at, ok := fired(early)
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), at, "в канал приходит время срока, а не конец Advance")
+	_, ok = fired(late)
+	assert.False(t, ok)
+	assert.Equal(t, epoch.Add(1500*time.Millisecond), c.Now())
+	assert.Equal(t, 1, c.Pending())
+
+	require.True(t, c.Step())
+	_, ok = fired(late)
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(2*time.Second), c.Now())
+	assert.False(t, c.Step(), "активных таймеров не осталось")
+}
+
+func TestClock_StopAndReset(t *testing.T) {
+	c := New(epoch)
+	tm := c.NewTimer(time.Second)
+	assert.True(t, tm.Stop())
+	assert.False(t, tm.Stop(), "повторный Stop")
+	c.Advance(time.Hour)
+	_, ok := fired(tm)
+	assert.False(t, ok, "остановленный таймер не срабатывает")
+
+	assert.False(t, tm.Reset(time.Minute), "таймер был неактивен")
+	assert.True(t, tm.Reset(2*time.Minute))
+	c.Advance(time.Minute)
+	_, ok = fired(tm)
+	assert.False(t, ok, "Reset переносит срок")
+	c.Advance(time.Minute)
+	_, ok = fired(tm)
+	assert.True(t, ok)
+	assert.Equal(t, []time.Duration{time.Second, time.Minute, 2 * time.Minute}, c.Sleeps())
+}
+
+func TestClock_NonPositiveDurationFiresImmediately(t *testing.T) {
+	c := New(epoch)
+	_, ok := fired(c.NewTimer(0))
+	assert.True(t, ok)
+	assert.Equal(t, epoch, c.Now())
+}
+
+func TestClock_Auto(t *testing.T) {
+	c := NewAuto(epoch)
+	for _, d := range []time.Duration{time.Second, time.Minute} {
+		_, ok := fired(c.NewTimer(d))
+		assert.True(t, ok)
+	}
+	assert.Equal(t, epoch.Add(time.Minute+time.Second), c.Now())
+	assert.Zero(t, c.Pending())
+}
+
+func TestClock_BlockUntilWaitsForSleeper(t *testing.T) {
+	c := New(epoch)
+	woke := make(chan time.Time)
+	go func() {
+		tm := c.NewTimer(time.Hour)
+		woke <- <-tm.C()
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	select {
+	case at := <-woke:
+		assert.Equal(t, epoch.Add(time.Hour), at)
+	case <-time.After(time.Second):
+		t.Fatal("горутина не проснулась после Advance")
+	}
+}
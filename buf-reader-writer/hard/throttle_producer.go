@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ThrottleConfig — настройки NewThrottledProducer.
+type ThrottleConfig struct {
+	MinInterval time.Duration // минимальный интервал между началами вызовов Next (ограничение частоты)
+	Latency     time.Duration // фиксированная задержка перед каждым Next
+	Jitter      time.Duration // случайная добавка к задержке в диапазоне [0, Jitter)
+	Rand        *rand.Rand    // источник случайности для jitter; nil — с фиксированным seed 1 для воспроизводимости
+	Clock       Clock         // источник времени; nil — системное время
+}
+
+// throttledProducer ограничивает частоту вызовов Next и добавляет к ним задержку с jitter.
+// Подходит и для защиты upstream-систем, и для нагрузочного тестирования Consumer на медленном источнике.
+// Commit, Nack и Pause/Resume не задерживаются. Наружу отдаётся через withProducerExtensions.
+type throttledProducer struct {
+	p        Producer
+	cfg      ThrottleConfig
+	lastCall time.Time // время начала предыдущего Next; нулевое — вызовов ещё не было
+}
+
+// NewThrottledProducer создаёт обёртку над p. Возвращаемый Producer реализует те же Nacker, Pausable
+// и MetaProducer, что и p; NextWithMeta ограничивается так же, как Next.
+func NewThrottledProducer(p Producer, cfg ThrottleConfig) Producer {
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	if cfg.Rand == nil {
+		cfg.Rand = rand.New(rand.NewSource(1))
+	}
+	return withProducerExtensions(&throttledProducer{p: p, cfg: cfg}, p)
+}
+
+func (tp *throttledProducer) Next() (items []any, cookie int, err error) {
+	tp.wait()
+	return tp.p.Next()
+}
+
+func (tp *throttledProducer) NextWithMeta() (items []any, cookie int, meta Meta, err error) {
+	tp.wait()
+	return nextWithMeta(tp.p)
+}
+
+// wait выдерживает MinInterval с начала предыдущего вызова и добавляет задержку с jitter.
+func (tp *throttledProducer) wait() {
+	if !tp.lastCall.IsZero() {
+		wait := tp.cfg.MinInterval - tp.cfg.Clock.Now().Sub(tp.lastCall)
+		sleep(tp.cfg.Clock, wait, nil)
+	}
+	tp.lastCall = tp.cfg.Clock.Now()
+
+	delay := tp.cfg.Latency
+	if tp.cfg.Jitter > 0 {
+		delay += time.Duration(tp.cfg.Rand.Int63n(int64(tp.cfg.Jitter)))
+	}
+	sleep(tp.cfg.Clock, delay, nil)
+}
+
+func (tp *throttledProducer) Commit(cookie int) error {
+	return tp.p.Commit(cookie)
+}
+
+func (tp *throttledProducer) Nack(cookie int) error {
+	return tp.p.(Nacker).Nack(cookie)
+}
+
+func (tp *throttledProducer) Pause()  { tp.p.(Pausable).Pause() }
+func (tp *throttledProducer) Resume() { tp.p.(Pausable).Resume() }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottledProducer_RateLimitAndLatency(t *testing.T) {
+	clock := newAutoClock()
+	p := &mockProducer{batches: [][]any{{1}, {2}, {3}}, cookies: []int{1, 2, 3}, readErr: io.EOF}
+	tp := NewThrottledProducer(p, ThrottleConfig{MinInterval: 100 * time.Millisecond, Latency: 30 * time.Millisecond, Clock: clock})
+
+	err := Pipe(tp, &mockConsumer{})
+	require.Equal(t, io.EOF, err)
+	// Первый вызов — только латентность; далее добор до MinInterval (100-30) и латентность
+	assert.Equal(t, []time.Duration{
+		30 * time.Millisecond,
+		70 * time.Millisecond, 30 * time.Millisecond,
+		70 * time.Millisecond, 30 * time.Millisecond,
+		70 * time.Millisecond, 30 * time.Millisecond,
+	}, clock.Sleeps())
+	assert.Equal(t, []int{1, 2, 3}, p.committed)
+}
+
+func TestThrottledProducer_JitterIsSeedable(t *testing.T) {
+	run := func() []time.Duration {
+		clock := newAutoClock()
+		p := &mockProducer{batches: [][]any{{1}, {2}}, cookies: []int{1, 2}, readErr: io.EOF}
+		tp := NewThrottledProducer(p, ThrottleConfig{Jitter: time.Second, Rand: rand.New(rand.NewSource(42)), Clock: clock})
+		for i := 0; i < 2; i++ {
+			_, _, err := tp.Next()
+			require.NoError(t, err)
+		}
+		return clock.Sleeps()
+	}
+
+	first := run()
+	require.Len(t, first, 2)
+	for _, d := range first {
+		assert.Less(t, d, time.Second)
+	}
+	assert.Equal(t, first, run(), "одинаковый seed — одинаковые задержки")
+}
+
+func TestThrottledProducer_ExposesOnlyInnerExtensions(t *testing.T) {
+	plain := NewThrottledProducer(&mockProducer{}, ThrottleConfig{Clock: newAutoClock()})
+	_, isNacker := plain.(Nacker)
+	_, isPausable := plain.(Pausable)
+	_, isMeta := plain.(MetaProducer)
+	assert.False(t, isNacker || isPausable || isMeta, "у обычного источника обёртка не должна добавлять расширений")
+}
+
+func TestThrottledProducer_ForwardsNack(t *testing.T) {
+	p := &nackProducer{mockProducer: mockProducer{batches: [][]any{{1}}, cookies: []int{1}, readErr: io.EOF}}
+	tp := NewThrottledProducer(p, ThrottleConfig{Latency: time.Millisecond, Clock: newAutoClock()})
+	require.Implements(t, (*Nacker)(nil), tp)
+
+	err := Pipe(tp, &mockConsumer{procErr: errors.New("boom")})
+	require.Error(t, err)
+	assert.Equal(t, []int{1}, p.nacked)
+}
+
+func TestThrottledProducer_ForwardsPause(t *testing.T) {
+	pp := &pausableProducer{}
+	tp := NewThrottledProducer(pp, ThrottleConfig{Clock: newAutoClock()})
+	require.Implements(t, (*Pausable)(nil), tp)
+	_, isNacker := tp.(Nacker)
+	assert.False(t, isNacker)
+
+	tp.(Pausable).Pause()
+	tp.(Pausable).Resume()
+	assert.Equal(t, 1, pp.paused)
+	assert.Equal(t, 1, pp.resumed)
+}
+
+func TestThrottledProducer_ForwardsMeta(t *testing.T) {
+	clock := newAutoClock()
+	tenant := Meta{"tenant": "a"}
+	p := &metaProducer{
+		mockProducer: mockProducer{batches: [][]any{makeItems(0, 2)}, cookies: []int{1}, readErr: io.EOF},
+		metas:        []Meta{tenant},
+	}
+	c := &metaConsumer{}
+
+	err := Pipe(NewThrottledProducer(p, ThrottleConfig{Latency: time.Millisecond, Clock: clock}), c)
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, [][]MetaSpan{{{Start: 0, End: 2, Meta: tenant}}}, c.spans, "метаданные должны проходить через обёртку")
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond}, clock.Sleeps(), "NextWithMeta ограничивается как Next")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/buf-reader-writer/hard/pipetest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// Транскрипты фиксируют порядок батчинга и коммитов Pipe. Ошибки в сценариях возникают после того,
+// как главный цикл Pipe дочитал источник: иначе число вызовов Next зависело бы от планировщика.
+// После намеренного изменения поведения golden-файлы обновляются командой:
+// go test -run Transcripts -pipetest.update
+func TestPipe_Transcripts(t *testing.T) {
+	cases := []struct {
+		name string
+		p    func() pipetest.PipeProducer
+		c    func() pipetest.PipeConsumer
+		opts []Option
+	}{
+		{
+			name: "batching",
+			p: func() pipetest.PipeProducer {
+				return pipetest.NewProducer(
+					pipetest.Batch{Items: makeItems(0, MaxItems-1), Cookie: 1},
+					pipetest.Batch{Items: makeItems(MaxItems-1, 2), Cookie: 2},
+					pipetest.Batch{Items: makeItems(MaxItems+1, 3), Cookie: 3},
+					pipetest.Batch{Items: makeItems(MaxItems+4, 1), Cookie: 4},
+				)
+			},
+			c: func() pipetest.PipeConsumer { return pipetest.NewConsumer() },
+		},
+		{
+			name: "process_error_nack",
+			p: func() pipetest.PipeProducer {
+				return pipetest.NewProducer(
+					pipetest.Batch{Items: makeItems(0, 2), Cookie: 1},
+					pipetest.Batch{Items: makeItems(2, 2), Cookie: 2},
+				)
+			},
+			c: func() pipetest.PipeConsumer {
+				return pipetest.NewConsumer().FailCall(0, errors.New("sink unavailable"))
+			},
+		},
+		{
+			name: "commit_error",
+			p: func() pipetest.PipeProducer {
+				return pipetest.NewProducer(
+					pipetest.Batch{Items: makeItems(0, MaxItems), Cookie: 1},
+					pipetest.Batch{Items: makeItems(MaxItems, 1), Cookie: 2},
+					pipetest.Batch{Items: makeItems(MaxItems+1, 1), Cookie: 3},
+				).FailCommit(2, errors.New("offset store down"))
+			},
+			c: func() pipetest.PipeConsumer { return pipetest.NewConsumer() },
+		},
+		{
+			name: "supervisor_restart",
+			p: func() pipetest.PipeProducer {
+				return pipetest.NewProducer(
+					pipetest.Batch{Items: makeItems(0, 3), Cookie: 1},
+				)
+			},
+			c: func() pipetest.PipeConsumer {
+				return pipetest.NewConsumer().FailCall(0, errors.New("temporary"))
+			},
+			opts: []Option{WithSupervisor(SupervisorPolicy{MaxRestarts: 2, Backoff: time.Microsecond})},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			testutil.VerifyNoLeaks(t)
+			tr := pipetest.NewTranscript()
+			err := Pipe(tr.Producer(tc.p()), tr.Consumer(tc.c()), tc.opts...)
+			tr.AssertGolden(t, filepath.Join("testdata", "transcripts", tc.name+".golden"))
+			_ = err // итоговая ошибка Pipe видна в транскрипте как результат соответствующего вызова
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// QueueMessage — сообщение SQS-подобной очереди.
+type QueueMessage struct {
+	Receipt string // handle для продления видимости и удаления
+	Body    any
+}
+
+// VisibilityQueue — минимальный интерфейс очереди с таймаутом видимости (SQS и аналоги).
+type VisibilityQueue interface {
+	// Receive забирает пачку сообщений; они становятся невидимыми для других получателей на таймаут видимости.
+	Receive() ([]QueueMessage, error)
+	// ChangeVisibility задаёт новый таймаут видимости; 0 — вернуть сообщения в очередь немедленно.
+	ChangeVisibility(receipts []string, timeout time.Duration) error
+	// Delete удаляет обработанные сообщения.
+	Delete(receipts []string) error
+}
+
+// VisibilityConfig — настройки VisibilityProducer.
+type VisibilityConfig struct {
+	Timeout           time.Duration // на сколько продлевать видимость при каждом heartbeat
+	HeartbeatInterval time.Duration // период heartbeat, > 0; должен быть заметно меньше Timeout
+	Clock             Clock         // источник времени; nil — системное время
+}
+
+// VisibilityProducer адаптирует очередь с таймаутом видимости к Producer.
+// Cookie соответствует пачке receipt handle'ов. Пока пачка в работе, фоновый heartbeat продлевает её видимость,
+// Commit удаляет сообщения, Nack сразу возвращает их в очередь.
+type VisibilityProducer struct {
+	queue VisibilityQueue
+	cfg   VisibilityConfig
+
+	mu         sync.Mutex               // защищает поля ниже
+	nextCookie int                      // следующий выдаваемый cookie
+	inFlight   map[int]*visibilityBatch // пачки в работе
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// Проверка, что VisibilityProducer удовлетворяет интерфейсам Producer и Nacker
+var (
+	_ Producer = (*VisibilityProducer)(nil)
+	_ Nacker   = (*VisibilityProducer)(nil)
+)
+
+// visibilityBatch — пачка в работе. mu связывает продление видимости пачки с её удалением или возвратом
+// в очередь: heartbeat не продлевает снятую с учёта пачку, а Commit и Nack дожидаются начатого продления.
+type visibilityBatch struct {
+	receipts []string
+
+	mu   sync.Mutex
+	done bool // пачка закоммичена или возвращена в очередь
+
+	err error // ошибка продления видимости, пока пачка в работе; защищена VisibilityProducer.mu
+}
+
+// NewVisibilityProducer создаёт адаптер и запускает горутину heartbeat. Остановка — через Close.
+func NewVisibilityProducer(queue VisibilityQueue, cfg VisibilityConfig) (*VisibilityProducer, error) {
+	if cfg.HeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("heartbeat interval must be positive, got %v", cfg.HeartbeatInterval)
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	vp := &VisibilityProducer{
+		queue:    queue,
+		cfg:      cfg,
+		inFlight: make(map[int]*visibilityBatch),
+		stopCh:   make(chan struct{}),
+	}
+	vp.wg.Add(1)
+	go vp.heartbeatLoop()
+	return vp, nil
+}
+
+// Next получает пачку сообщений и регистрирует её receipt handle'ы как находящиеся в работе.
+// Если продлить видимость пачки в работе не удалось, Next возвращает эту ошибку, пока пачку не закоммитят или не вернут.
+func (vp *VisibilityProducer) Next() (items []any, cookie int, err error) {
+	hbErr := vp.heartbeatErr()
+	if hbErr != nil {
+		return nil, 0, fmt.Errorf("heartbeat: %w", hbErr)
+	}
+
+	msgs, err := vp.queue.Receive()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items = make([]any, len(msgs))
+	receipts := make([]string, len(msgs))
+	for i, m := range msgs {
+		items[i] = m.Body
+		receipts[i] = m.Receipt
+	}
+
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	cookie = vp.nextCookie
+	vp.nextCookie++
+	vp.inFlight[cookie] = &visibilityBatch{receipts: receipts}
+
+	return items, cookie, nil
+}
+
+// Commit удаляет сообщения пачки из очереди.
+func (vp *VisibilityProducer) Commit(cookie int) error {
+	receipts, err := vp.take(cookie)
+	if err != nil || len(receipts) == 0 {
+		return err
+	}
+	err = vp.queue.Delete(receipts)
+	if err != nil {
+		return fmt.Errorf("delete messages: %w", err)
+	}
+	return nil
+}
+
+// Nack возвращает сообщения пачки в очередь, обнуляя таймаут видимости.
+func (vp *VisibilityProducer) Nack(cookie int) error {
+	receipts, err := vp.take(cookie)
+	if err != nil || len(receipts) == 0 {
+		return err
+	}
+	err = vp.queue.ChangeVisibility(receipts, 0)
+	if err != nil {
+		return fmt.Errorf("release messages: %w", err)
+	}
+	return nil
+}
+
+// Close останавливает heartbeat. Сообщения, оставшиеся в работе, вернутся в очередь по истечении таймаута.
+func (vp *VisibilityProducer) Close() error {
+	vp.once.Do(func() { close(vp.stopCh) })
+	vp.wg.Wait()
+	return nil
+}
+
+// take снимает пачку с учёта heartbeat и возвращает её receipt handle'ы.
+// Дожидается начатого продления видимости пачки, чтобы оно не отменило удаление или возврат в очередь.
+func (vp *VisibilityProducer) take(cookie int) ([]string, error) {
+	vp.mu.Lock()
+	b, ok := vp.inFlight[cookie]
+	delete(vp.inFlight, cookie)
+	vp.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown cookie %d", cookie)
+	}
+
+	b.mu.Lock()
+	b.done = true
+	b.mu.Unlock()
+	return b.receipts, nil
+}
+
+// heartbeatErr возвращает ошибки продления видимости пачек, которые всё ещё в работе.
+func (vp *VisibilityProducer) heartbeatErr() error {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	var errs []error
+	for _, b := range vp.inFlight {
+		if b.err != nil {
+			errs = append(errs, b.err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// heartbeatLoop периодически продлевает видимость всех пачек в работе.
+func (vp *VisibilityProducer) heartbeatLoop() {
+	defer vp.wg.Done()
+	for sleep(vp.cfg.Clock, vp.cfg.HeartbeatInterval, vp.stopCh) {
+		select {
+		case <-vp.stopCh:
+			return
+		default:
+		}
+
+		vp.mu.Lock()
+		batches := make(map[int]*visibilityBatch, len(vp.inFlight))
+		for cookie, b := range vp.inFlight {
+			batches[cookie] = b
+		}
+		vp.mu.Unlock()
+
+		for cookie, b := range batches {
+			vp.extend(cookie, b)
+		}
+	}
+}
+
+// extend продлевает видимость пачки, если она ещё в работе. Ошибка запоминается, только если пачку
+// не успели закоммитить или вернуть в очередь, пока шло продление.
+func (vp *VisibilityProducer) extend(cookie int, b *visibilityBatch) {
+	b.mu.Lock()
+	if b.done {
+		b.mu.Unlock()
+		return
+	}
+	err := vp.queue.ChangeVisibility(b.receipts, vp.cfg.Timeout)
+	b.mu.Unlock()
+	if err == nil {
+		return
+	}
+
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	if vp.inFlight[cookie] == b && b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type visibilityCall struct {
+	receipts []string
+	timeout  time.Duration
+}
+
+type mockVisibilityQueue struct {
+	mu         sync.Mutex
+	batches    [][]QueueMessage
+	changes    []visibilityCall
+	deleted    []string
+	changeErr  error
+	receiveIdx int
+}
+
+func (m *mockVisibilityQueue) Receive() ([]QueueMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.receiveIdx >= len(m.batches) {
+		return nil, io.EOF
+	}
+	b := m.batches[m.receiveIdx]
+	m.receiveIdx++
+	return b, nil
+}
+
+func (m *mockVisibilityQueue) ChangeVisibility(receipts []string, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes = append(m.changes, visibilityCall{receipts: receipts, timeout: timeout})
+	return m.changeErr
+}
+
+func (m *mockVisibilityQueue) Delete(receipts []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, receipts...)
+	return nil
+}
+
+// sleepyConsumer имитирует долгую обработку батча: каждый Process длится heartbeats интервалов heartbeat по фейковым часам.
+type sleepyConsumer struct {
+	mockConsumer
+	clock      fakeClock
+	interval   time.Duration
+	heartbeats int
+}
+
+func (m *sleepyConsumer) Process(items []any) error {
+	for i := 0; i < m.heartbeats; i++ {
+		m.clock.BlockUntil(1) // heartbeat уснул до следующего тика
+		m.clock.Advance(m.interval)
+	}
+	m.clock.BlockUntil(1) // и отработал последний тик
+	return m.mockConsumer.Process(items)
+}
+
+func TestVisibilityProducer_HeartbeatAndDelete(t *testing.T) {
+	q := &mockVisibilityQueue{batches: [][]QueueMessage{
+		{{Receipt: "r1", Body: 1}, {Receipt: "r2", Body: 2}},
+		{{Receipt: "r3", Body: 3}},
+	}}
+	clock := newFakeClock()
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	err = Pipe(vp, &sleepyConsumer{clock: clock, interval: time.Second, heartbeats: 2})
+	require.Equal(t, io.EOF, err)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Equal(t, []string{"r1", "r2", "r3"}, q.deleted)
+	var extended []string
+	for _, call := range q.changes {
+		assert.Equal(t, time.Minute, call.timeout)
+		extended = append(extended, call.receipts...)
+	}
+	sort.Strings(extended) // Порядок пачек в heartbeat не определён
+	assert.Equal(t, []string{"r1", "r1", "r2", "r2", "r3", "r3"}, extended,
+		"во время долгой обработки видимость каждой пачки продлевается на каждом тике")
+}
+
+func TestVisibilityProducer_NoHeartbeatWithoutInFlight(t *testing.T) {
+	q := &mockVisibilityQueue{}
+	clock := newFakeClock()
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+	clock.BlockUntil(1)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Empty(t, q.changes)
+}
+
+func TestVisibilityProducer_NackReleasesMessages(t *testing.T) {
+	q := &mockVisibilityQueue{batches: [][]QueueMessage{{{Receipt: "r1", Body: 1}}}}
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Hour})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	err = Pipe(vp, &mockConsumer{procErr: errors.New("process failed")})
+	require.Error(t, err)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Equal(t, []visibilityCall{{receipts: []string{"r1"}, timeout: 0}}, q.changes)
+	assert.Empty(t, q.deleted)
+}
+
+func TestVisibilityProducer_HeartbeatErrorStopsNext(t *testing.T) {
+	q := &mockVisibilityQueue{
+		batches:   [][]QueueMessage{{{Receipt: "r1", Body: 1}}, {{Receipt: "r2", Body: 2}}},
+		changeErr: errors.New("queue unavailable"),
+	}
+	clock := newFakeClock()
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	_, _, err = vp.Next()
+	require.NoError(t, err)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1) // Heartbeat отработал тик и снова уснул
+
+	_, _, err = vp.Next()
+	assert.ErrorIs(t, err, q.changeErr, "ошибка heartbeat должна вернуться из Next")
+}
+
+func TestVisibilityProducer_HeartbeatErrorClearedByCommit(t *testing.T) {
+	q := &mockVisibilityQueue{
+		batches:   [][]QueueMessage{{{Receipt: "r1", Body: 1}}, {{Receipt: "r2", Body: 2}}},
+		changeErr: errors.New("queue unavailable"),
+	}
+	clock := newFakeClock()
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	_, cookie, err := vp.Next()
+	require.NoError(t, err)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+
+	require.NoError(t, vp.Commit(cookie))
+	_, _, err = vp.Next()
+	assert.NoError(t, err, "ошибка heartbeat закоммиченной пачки не должна мешать следующим")
+}
+
+// blockingVisibilityQueue задерживает продление видимости, пока не закрыт release.
+type blockingVisibilityQueue struct {
+	mockVisibilityQueue
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingVisibilityQueue) ChangeVisibility(receipts []string, timeout time.Duration) error {
+	if timeout > 0 {
+		m.started <- struct{}{}
+		<-m.release
+	}
+	return m.mockVisibilityQueue.ChangeVisibility(receipts, timeout)
+}
+
+func TestVisibilityProducer_NackWaitsForHeartbeat(t *testing.T) {
+	q := &blockingVisibilityQueue{
+		mockVisibilityQueue: mockVisibilityQueue{batches: [][]QueueMessage{{{Receipt: "r1", Body: 1}}}},
+		started:             make(chan struct{}),
+		release:             make(chan struct{}),
+	}
+	clock := newFakeClock()
+	vp, err := NewVisibilityProducer(q, VisibilityConfig{Timeout: time.Minute, HeartbeatInterval: time.Second, Clock: clock})
+	require.NoError(t, err)
+	defer vp.Close()
+
+	_, cookie, err := vp.Next()
+	require.NoError(t, err)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-q.started // Heartbeat продлевает видимость пачки
+
+	nacked := make(chan error)
+	go func() { nacked <- vp.Nack(cookie) }()
+	select {
+	case err = <-nacked:
+		t.Fatalf("Nack вернулся до завершения продления: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(q.release)
+	require.NoError(t, <-nacked)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	assert.Equal(t, []visibilityCall{
+		{receipts: []string{"r1"}, timeout: time.Minute},
+		{receipts: []string{"r1"}, timeout: 0},
+	}, q.changes, "возврат в очередь должен идти после продления, а не до")
+}
+
+func TestVisibilityProducer_RejectsNonPositiveInterval(t *testing.T) {
+	_, err := NewVisibilityProducer(&mockVisibilityQueue{}, VisibilityConfig{Timeout: time.Minute})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+
+	"github.com/zlatoivan/go-advanced/workerpool"
+)
+
+// WithWorkers обрабатывает до n батчей параллельно: Process вызывается из нескольких горутин,
+// а Commit — строго в порядке поступления батчей, после того как обработаны все предыдущие.
+// Consumer должен допускать конкурентные вызовы Process. n <= 1 — один воркер (по умолчанию).
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// startPoolWorker — вариант startWorker для o.workers > 1 поверх workerpool:
+// пул выполняет processBatch параллельно и выдаёт батчи по порядку, а коммитит их одна горутина.
+// При первой ошибке пул отменяется, и ошибка отправляется в errCh только после завершения всех Process.
+func startPoolWorker[T any](ctx context.Context, w *worker[T], workers int) (chan batch[T], chan error, chan struct{}) {
+	batchCh := make(chan batch[T], 1)
+	errCh := make(chan error, 1)
+	doneCh := make(chan struct{})
+
+	poolCtx, poolCancel := context.WithCancel(ctx)
+	pool, err := workerpool.New(poolCtx, workerpool.Config{Workers: workers},
+		func(ctx context.Context, b batch[T]) (batch[T], error) {
+			if err := ctx.Err(); err != nil { // Пул уже остановлен ошибкой другого батча
+				return b, err
+			}
+			return b, w.processBatch(ctx, b)
+		})
+	if err != nil { // workers > 1 проверено вызывающим
+		poolCancel()
+		errCh <- err
+		close(doneCh)
+		return batchCh, errCh, doneCh
+	}
+
+	// Раздача: батчи из batchCh отправляются в пул, пока их не перестанут присылать или пул не отменён
+	go func() {
+		defer pool.Close()
+		for {
+			select {
+			case <-poolCtx.Done():
+				return
+			case b, ok := <-batchCh:
+				if !ok {
+					return
+				}
+				if len(b.items) == 0 && len(b.cookies) == 0 {
+					continue
+				}
+				if pool.Submit(poolCtx, b) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Коммит: результаты приходят в порядке отправки
+	go func() {
+		defer close(doneCh)
+		defer poolCancel()
+		for res := range pool.Results() {
+			err := res.Err
+			if err == nil {
+				err = w.commitBatch(poolCtx, res.Value)
+			}
+			err = batchError(res.Value, err)
+			if err != nil {
+				// Pipe возвращает ошибку сразу после errCh, поэтому сначала дожидаемся оставшихся Process:
+				// их результаты не коммитятся
+				poolCancel()
+				for range pool.Results() {
+				}
+				select {
+				case errCh <- err:
+				default:
+				}
+				return
+			}
+		}
+	}()
+
+	return batchCh, errCh, doneCh
+}
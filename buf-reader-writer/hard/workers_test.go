@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrentConsumer допускает параллельные Process: обрабатывает батчи со случайной задержкой,
+// запоминает пик одновременных вызовов и может падать на батче, начинающемся с failAt (nil — на любом).
+type concurrentConsumer struct {
+	failAt  any
+	failErr error
+
+	calls, running, peak, active atomic.Int32
+	mu                           sync.Mutex
+	processed                    int
+}
+
+func (c *concurrentConsumer) Process(items []any) error {
+	c.calls.Add(1)
+	cur := c.running.Add(1)
+	defer c.running.Add(-1)
+	c.active.Add(1)
+	defer c.active.Add(-1)
+	for {
+		old := c.peak.Load()
+		if cur <= old || c.peak.CompareAndSwap(old, cur) {
+			break
+		}
+	}
+	time.Sleep(time.Duration(rand.Intn(3000)) * time.Microsecond) // Батчи завершаются не по порядку
+	if c.failErr != nil && (c.failAt == nil || items[0] == c.failAt) {
+		return c.failErr
+	}
+	c.mu.Lock()
+	c.processed += len(items)
+	c.mu.Unlock()
+	return nil
+}
+
+// bigBatchesProducer отдаёт n батчей по MaxItems/2+1 элементов: Pipe не может объединить два таких батча,
+// поэтому каждый уходит в воркер отдельно.
+func bigBatchesProducer(n int) *mockProducer {
+	p := &mockProducer{readErr: io.EOF}
+	size := MaxItems/2 + 1
+	for i := range n {
+		p.batches = append(p.batches, makeItems(i*size, size))
+		p.cookies = append(p.cookies, 100+i)
+	}
+	return p
+}
+
+func TestPipe_WorkersProcessConcurrentlyCommitInOrder(t *testing.T) {
+	const n = 24
+	p := bigBatchesProducer(n)
+	c := &concurrentConsumer{}
+
+	err := Pipe(p, c, WithWorkers(4))
+	require.ErrorIs(t, err, io.EOF)
+
+	assert.Equal(t, p.cookies, p.committed, "cookies должны коммититься в порядке поступления")
+	assert.Equal(t, n*(MaxItems/2+1), c.processed)
+	assert.GreaterOrEqual(t, c.peak.Load(), int32(2), "Process должен вызываться параллельно")
+	assert.LessOrEqual(t, c.peak.Load(), int32(4))
+}
+
+func TestPipe_WorkersErrorStopsAllProcess(t *testing.T) {
+	const n = 24
+	p := bigBatchesProducer(n)
+	failErr := errors.New("process failed")
+	size := MaxItems/2 + 1
+	c := &concurrentConsumer{failAt: 5 * size, failErr: failErr}
+
+	err := Pipe(p, c, WithWorkers(4))
+	require.ErrorIs(t, err, failErr)
+	assert.Zero(t, c.active.Load(), "после возврата Pipe не должно оставаться выполняющихся Process")
+	assert.Equal(t, p.cookies[:5], p.committed, "коммитятся только батчи до упавшего")
+}
+
+// TestPipe_WorkersShareSupervisorLimit: MaxRestarts — общий лимит на все воркеры, а не на каждый.
+func TestPipe_WorkersShareSupervisorLimit(t *testing.T) {
+	const restarts, workers = 10, 4
+	p := bigBatchesProducer(40)
+	failErr := errors.New("always failing")
+	c := &concurrentConsumer{failErr: failErr}
+
+	err := Pipe(p, c, WithWorkers(workers), WithSupervisor(SupervisorPolicy{MaxRestarts: restarts}))
+	require.ErrorIs(t, err, failErr)
+	assert.Empty(t, p.committed)
+	// Общий лимит: перезапуски плюс последние попытки батчей, успевших начаться до остановки пула.
+	// С отдельным лимитом на воркер вызовов было бы до workers*(restarts+1)
+	assert.Greater(t, c.calls.Load(), int32(restarts))
+	assert.LessOrEqual(t, c.calls.Load(), int32(restarts+2*workers))
+}
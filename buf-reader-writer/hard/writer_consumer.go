@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Flusher — приёмник с внутренним буфером (bufio.Writer, gzip.Writer, сегментированный писатель).
+type Flusher interface {
+	Flush() error
+}
+
+// Syncer — приёмник, умеющий гарантировать запись на диск (*os.File).
+type Syncer interface {
+	Sync() error
+}
+
+// WriterConsumer пишет байтовые элементы батча ([]byte или string) в один или несколько io.Writer —
+// например, в MultiWriter или ротируемый сегментированный писатель. Так Pipe связывается с подсистемой
+// reader/writer: «вычитать очередь — записать файлы сегментов».
+// После каждого батча у приёмников вызываются Flush и Sync (если поддерживаются), поэтому Pipe коммитит
+// cookies только после того, как данные покинули буферы.
+type WriterConsumer struct {
+	sinks []io.Writer
+}
+
+// Проверка, что WriterConsumer удовлетворяет интерфейсу Consumer
+var _ Consumer = (*WriterConsumer)(nil)
+
+// NewWriterConsumer создаёт Consumer, пишущий каждый элемент во все sinks по порядку (fan-out).
+func NewWriterConsumer(sinks ...io.Writer) *WriterConsumer {
+	return &WriterConsumer{sinks: sinks}
+}
+
+// Process проверяет типы всех элементов до записи, затем пишет их во все приёмники и сбрасывает буферы.
+// Ошибка записи в любой приёмник проваливает батч целиком: для at-least-once повтор допустим.
+func (wc *WriterConsumer) Process(items []any) error {
+	payloads := make([][]byte, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case []byte:
+			payloads[i] = v
+		case string:
+			payloads[i] = []byte(v)
+		default:
+			return fmt.Errorf("%w: item %d is %T", ErrUnexpectedItemType, i, item)
+		}
+	}
+
+	for s, sink := range wc.sinks {
+		for i, data := range payloads {
+			_, err := sink.Write(data)
+			if err != nil {
+				return fmt.Errorf("sink %d: write item %d: %w", s, i, err)
+			}
+		}
+	}
+
+	var errs []error
+	for s, sink := range wc.sinks {
+		if f, ok := sink.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, fmt.Errorf("sink %d: flush: %w", s, err))
+				continue
+			}
+		}
+		if sy, ok := sink.(Syncer); ok {
+			if err := sy.Sync(); err != nil {
+				errs = append(errs, fmt.Errorf("sink %d: sync: %w", s, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
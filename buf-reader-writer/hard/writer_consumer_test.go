@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+// syncBuffer — bytes.Buffer с подсчётом вызовов Sync.
+type syncBuffer struct {
+	bytes.Buffer
+	syncs   int
+	syncErr error
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	return b.syncErr
+}
+
+// failingWriter всегда возвращает ошибку записи.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestWriterConsumer_FanOutAndFlush(t *testing.T) {
+	var buffered bytes.Buffer
+	bw := bufio.NewWriter(&buffered)
+	synced := &syncBuffer{}
+	p := &mockProducer{
+		batches: [][]any{{"a", []byte("b")}, {"c"}},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	err := Pipe(p, NewWriterConsumer(bw, synced))
+	require.Equal(t, io.EOF, err)
+	assert.Equal(t, "abc", buffered.String(), "буфер должен сбрасываться после батча")
+	assert.Equal(t, "abc", synced.String())
+	assert.Equal(t, 1, synced.syncs)
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestWriterConsumer_ThrottledSinks(t *testing.T) {
+	bucket, err := throttle.NewBucket(20_000, 1000)
+	require.NoError(t, err)
+	ctx := context.Background()
+	a, b := &syncBuffer{}, &syncBuffer{}
+	p := &mockProducer{
+		batches: [][]any{{strings.Repeat("x", 1500)}, {strings.Repeat("y", 1500)}},
+		cookies: []int{1, 2},
+		readErr: io.EOF,
+	}
+
+	start := time.Now()
+	err = Pipe(p, NewWriterConsumer(throttle.NewWriter(ctx, a, bucket), throttle.NewWriter(ctx, b, bucket)))
+	require.Equal(t, io.EOF, err)
+	// 6000 байт через общую корзину: 1000 из полной корзины, остальные 5000 — по 20000 байт/с
+	assert.GreaterOrEqual(t, time.Since(start), 240*time.Millisecond)
+	assert.Equal(t, 3000, a.Len())
+	assert.Equal(t, a.String(), b.String())
+	assert.Equal(t, 1, a.syncs, "обёртка не должна скрывать Sync приёмника")
+	assert.Equal(t, []int{1, 2}, p.committed)
+}
+
+func TestWriterConsumer_RejectsNonByteItems(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewWriterConsumer(&buf).Process([]any{"ok", 42})
+	require.ErrorIs(t, err, ErrUnexpectedItemType)
+	assert.Zero(t, buf.Len(), "при ошибке типов ничего не пишется")
+}
+
+func TestWriterConsumer_SinkErrors(t *testing.T) {
+	writeErr := errors.New("disk full")
+	err := NewWriterConsumer(failingWriter{err: writeErr}).Process([]any{"a"})
+	assert.ErrorIs(t, err, writeErr)
+
+	syncErr := errors.New("sync failed")
+	err = NewWriterConsumer(&syncBuffer{syncErr: syncErr}).Process([]any{"a"})
+	assert.ErrorIs(t, err, syncErr)
+}
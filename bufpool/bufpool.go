@@ -0,0 +1,155 @@
+// Package bufpool — общий пул байтовых буферов с классами размеров (степени двойки) поверх sync.Pool.
+// Им пользуются префетчер MultiReader, стадия кодирования Pipe и хелперы копирования, чтобы подсистемы
+// не выделяли буферы горячего пути каждая по-своему. В отладочном режиме пул считает невозвращённые буферы.
+package bufpool
+
+import (
+	"io"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+const (
+	minClass = 6  // 64 байта — меньшие запросы округляются до него
+	maxClass = 24 // 16 МиБ — большие буферы выделяются напрямую и в пул не возвращаются
+
+	copyBufferSize = 32 * 1024 // размер буфера Copy, как у io.Copy
+)
+
+// Pool — пул буферов с классами размеров. Безопасен для конкурентного использования.
+// Буфер класса k имеет ёмкость не меньше 2^k, поэтому Get(n) берёт буфер из класса ⌈log2 n⌉,
+// а Put кладёт буфер в класс ⌊log2 cap⌋ — в пул можно вернуть и буфер, выросший через append.
+type Pool struct {
+	classes [maxClass - minClass + 1]sync.Pool // *[]byte; указатель, чтобы Put не выделял память на интерфейс
+	boxes   sync.Pool                          // пустые *[]byte для повторного использования
+
+	debug       bool
+	mu          sync.Mutex       // защищает outstanding
+	outstanding map[*byte]string // выданные и не возвращённые буферы: первый байт -> стек Get
+}
+
+// Option настраивает Pool.
+type Option func(*Pool)
+
+// Debug включает учёт утечек: каждый Get запоминает стек вызова до возврата буфера через Put.
+// Замедляет Get и выделяет память, поэтому предназначен для тестов.
+func Debug() Option {
+	return func(p *Pool) {
+		p.debug = true
+		p.outstanding = make(map[*byte]string)
+	}
+}
+
+// New создаёт пул.
+func New(opts ...Option) *Pool {
+	p := &Pool{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Default — пул, общий для всех подсистем.
+var Default = New()
+
+// Get возвращает буфер длины n из Default.
+func Get(n int) []byte { return Default.Get(n) }
+
+// Put возвращает буфер в Default.
+func Put(b []byte) { Default.Put(b) }
+
+// Get возвращает буфер длины n; ёмкость округлена вверх до класса. Содержимое не обнуляется.
+func (p *Pool) Get(n int) []byte {
+	class := classFor(n)
+	if class > maxClass {
+		return make([]byte, n)
+	}
+
+	var b []byte
+	if box, ok := p.classes[class-minClass].Get().(*[]byte); ok {
+		b = *box
+		*box = nil
+		p.boxes.Put(box)
+	} else {
+		b = make([]byte, 1<<class)
+	}
+	b = b[:n]
+
+	if p.debug {
+		p.track(b)
+	}
+	return b
+}
+
+// Put возвращает буфер в пул. После Put буфер нельзя использовать.
+// Буферы меньше минимального класса и больше максимального отбрасываются.
+func (p *Pool) Put(b []byte) {
+	if cap(b) < 1<<minClass {
+		return
+	}
+	b = b[:cap(b)]
+	if p.debug {
+		p.untrack(b)
+	}
+	class := bits.Len(uint(cap(b))) - 1 // ⌊log2 cap⌋
+	if class > maxClass {
+		return
+	}
+
+	box, ok := p.boxes.Get().(*[]byte)
+	if !ok {
+		box = new([]byte)
+	}
+	*box = b
+	p.classes[class-minClass].Put(box)
+}
+
+// Outstanding возвращает число выданных и не возвращённых буферов. Работает только в режиме Debug.
+func (p *Pool) Outstanding() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.outstanding)
+}
+
+// Leaks возвращает стеки вызовов Get для невозвращённых буферов. Работает только в режиме Debug.
+func (p *Pool) Leaks() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	leaks := make([]string, 0, len(p.outstanding))
+	for _, stack := range p.outstanding {
+		leaks = append(leaks, stack)
+	}
+	return leaks
+}
+
+// Copy копирует src в dst как io.Copy, но с буфером из Default.
+func Copy(dst io.Writer, src io.Reader) (int64, error) {
+	buf := Get(copyBufferSize)
+	defer Put(buf)
+	return io.CopyBuffer(dst, src, buf)
+}
+
+// classFor возвращает класс ⌈log2 n⌉, но не меньше minClass.
+func classFor(n int) int {
+	if n <= 1<<minClass {
+		return minClass
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// track запоминает стек Get для выданного буфера.
+func (p *Pool) track(b []byte) {
+	stack := make([]byte, 4096)
+	stack = stack[:runtime.Stack(stack, false)]
+	p.mu.Lock()
+	p.outstanding[&b[:1][0]] = string(stack)
+	p.mu.Unlock()
+}
+
+// untrack снимает буфер с учёта. Буфер, не выданный этим пулом (например, выросший через append), принимается молча.
+func (p *Pool) untrack(b []byte) {
+	p.mu.Lock()
+	delete(p.outstanding, &b[0])
+	p.mu.Unlock()
+}
@@ -0,0 +1,77 @@
+package bufpool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestGet_SizeClasses(t *testing.T) {
+	p := New()
+	for _, tc := range []struct{ n, cap int }{
+		{0, 64},
+		{1, 64},
+		{64, 64},
+		{65, 128},
+		{1000, 1024},
+		{1 << 20, 1 << 20},
+		{1<<24 + 1, 1<<24 + 1}, // Больше максимального класса: выделяется напрямую
+	} {
+		b := p.Get(tc.n)
+		if len(b) != tc.n || cap(b) != tc.cap {
+			t.Errorf("Get(%d): len=%d cap=%d, ожидались len=%d cap=%d", tc.n, len(b), cap(b), tc.n, tc.cap)
+		}
+		p.Put(b)
+	}
+}
+
+func TestPut_GrownBufferServesSmallerClass(t *testing.T) {
+	p := New()
+	b := append(p.Get(0)[:0], make([]byte, 100)...) // После append ёмкость может быть не степенью двойки
+	p.Put(b)
+
+	got := p.Get(64)
+	if cap(got) < 64 {
+		t.Fatalf("Get(64) вернул буфер ёмкостью %d", cap(got))
+	}
+}
+
+func TestPool_ReusesBuffers(t *testing.T) {
+	p := New()
+	p.Put(p.Get(4096)) // Прогрев: класс и коробка уже в пуле
+	testutil.AllocBudget(t, 0, func() {
+		p.Put(p.Get(4096))
+	})
+}
+
+func TestDebug_ReportsLeaks(t *testing.T) {
+	p := New(Debug())
+	a := p.Get(100)
+	b := p.Get(100)
+	if p.Outstanding() != 2 {
+		t.Fatalf("Outstanding = %d, ожидалось 2", p.Outstanding())
+	}
+
+	p.Put(a)
+	leaks := p.Leaks()
+	if len(leaks) != 1 || !strings.Contains(leaks[0], "TestDebug_ReportsLeaks") {
+		t.Fatalf("Leaks должен указывать на место Get невозвращённого буфера: %q", leaks)
+	}
+
+	p.Put(b[:10]) // Возврат по подсрезу тоже снимает буфер с учёта
+	p.Put(make([]byte, 256))
+	if p.Outstanding() != 0 {
+		t.Fatalf("после возврата всех буферов Outstanding = %d", p.Outstanding())
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src := strings.Repeat("0123456789", 10_000)
+	var dst bytes.Buffer
+	n, err := Copy(&dst, strings.NewReader(src))
+	if err != nil || n != int64(len(src)) || dst.String() != src {
+		t.Fatalf("Copy: n=%d, err=%v, совпадение=%v", n, err, dst.String() == src)
+	}
+}
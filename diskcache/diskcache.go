@@ -0,0 +1,329 @@
+// Package diskcache — ограниченный по размеру дисковый кэш блоков с вытеснением LRU.
+// Служит вторым уровнем кэша префетчера MultiReader: блоки удалённых объектов, ключом которых служит
+// пара (идентификатор источника, смещение), переживают перезапуск процесса и не запрашиваются у источника повторно.
+//
+// Отдельного файла индекса нет: каждая запись — самоописывающий файл с ключом и контрольной суммой,
+// записываемый через временный файл и rename. Индекс восстанавливается сканированием каталога при Open,
+// поэтому падение в любой момент оставляет кэш согласованным: недописанные записи удаляются, битые — отбрасываются.
+package diskcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	entryExt = ".blk"
+	tmpExt   = ".tmp"
+	magic    = "DCB1"
+)
+
+// Key — ключ блока: идентификатор источника и смещение блока в нём.
+type Key struct {
+	Source string
+	Offset int64
+}
+
+// Config — настройки Cache.
+type Config struct {
+	Dir      string // каталог кэша; создаётся, если его нет
+	MaxBytes int64  // предельный суммарный размер записей на диске
+}
+
+// Stats — счётчики кэша.
+type Stats struct {
+	Entries   int
+	Bytes     int64 // суммарный размер файлов записей
+	Hits      int64
+	Misses    int64
+	Evictions int64
+}
+
+// Cache — дисковый кэш блоков. Безопасен для конкурентного использования; операции с диском сериализуются.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	lru     *list.List            // *entry; в начале — недавно использованные
+	entries map[Key]*list.Element // ключ -> элемент lru
+	stats   Stats
+}
+
+type entry struct {
+	key  Key
+	size int64 // размер файла записи
+}
+
+// Open открывает кэш в каталоге cfg.Dir, восстанавливая индекс по файлам записей.
+// Порядок LRU восстанавливается по времени модификации файлов: Get обновляет его при каждом попадании.
+func Open(cfg Config) (*Cache, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("diskcache: empty directory")
+	}
+	if cfg.MaxBytes <= 0 {
+		return nil, fmt.Errorf("diskcache: max bytes must be positive, got %d", cfg.MaxBytes)
+	}
+	err := os.MkdirAll(cfg.Dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("diskcache: create dir: %w", err)
+	}
+
+	c := &Cache{dir: cfg.Dir, maxBytes: cfg.MaxBytes, lru: list.New(), entries: make(map[Key]*list.Element)}
+	err = c.load()
+	if err != nil {
+		return nil, err
+	}
+	c.evict()
+	return c, nil
+}
+
+// Get копирует блок key в p и возвращает его длину. Отсутствующий, битый или не помещающийся в p блок — промах.
+func (c *Cache) Get(key Key, p []byte) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return 0, false
+	}
+	path := c.path(key)
+	n, err := readEntry(path, key, p)
+	if err != nil {
+		if !errors.Is(err, errShortBuffer) { // Запись испорчена или удалена извне: убираем её из индекса
+			c.remove(elem)
+		}
+		c.stats.Misses++
+		return 0, false
+	}
+
+	c.lru.MoveToFront(elem)
+	now := time.Now()
+	_ = os.Chtimes(path, now, now) // Порядок LRU для следующего Open; ошибка не мешает отдать данные
+	c.stats.Hits++
+	return n, true
+}
+
+// Put сохраняет блок key (копию data), вытесняя давно не использованные записи сверх MaxBytes.
+// Запись, которая больше MaxBytes, не сохраняется.
+func (c *Cache) Put(key Key, data []byte) error {
+	if len(key.Source) > math.MaxUint16 {
+		return fmt.Errorf("diskcache: source id is %d bytes, limit %d", len(key.Source), math.MaxUint16)
+	}
+	size := int64(headerSize(key) + len(data))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.writeEntry(key, data)
+	if err != nil {
+		return err
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.stats.Bytes -= elem.Value.(*entry).size
+		c.lru.Remove(elem)
+		c.stats.Entries--
+	}
+	c.add(&entry{key: key, size: size})
+	c.evict()
+	return nil
+}
+
+// Stats возвращает текущие счётчики.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// load сканирует каталог: удаляет недописанные файлы и битые записи, остальные добавляет в LRU по времени модификации.
+func (c *Cache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("diskcache: read dir: %w", err)
+	}
+
+	type found struct {
+		entry
+		mtime time.Time
+	}
+	var all []found
+	for _, de := range dirEntries {
+		name := de.Name()
+		path := filepath.Join(c.dir, name)
+		switch {
+		case strings.HasSuffix(name, tmpExt): // Запись не была доведена до rename
+			_ = os.Remove(path)
+			continue
+		case !strings.HasSuffix(name, entryExt):
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		key, err := readKey(path)
+		if err != nil || c.path(key) != path {
+			_ = os.Remove(path)
+			continue
+		}
+		all = append(all, found{entry: entry{key: key, size: info.Size()}, mtime: info.ModTime()})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].mtime.Before(all[j].mtime) })
+	for i := range all {
+		c.add(&all[i].entry) // Самые свежие добавляются последними и оказываются в начале
+	}
+	return nil
+}
+
+// add добавляет запись в начало LRU. Вызывается под c.mu.
+func (c *Cache) add(e *entry) {
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.stats.Entries++
+	c.stats.Bytes += e.size
+}
+
+// remove удаляет запись из индекса и с диска. Вызывается под c.mu.
+func (c *Cache) remove(elem *list.Element) {
+	e := elem.Value.(*entry)
+	c.lru.Remove(elem)
+	delete(c.entries, e.key)
+	c.stats.Entries--
+	c.stats.Bytes -= e.size
+	_ = os.Remove(c.path(e.key))
+}
+
+// evict вытесняет записи с конца LRU, пока размер превышает предел. Вызывается под c.mu.
+func (c *Cache) evict() {
+	for c.stats.Bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// path возвращает путь файла записи: имя — хеш ключа, чтобы идентификатор источника не зависел от ограничений ФС.
+func (c *Cache) path(key Key) string {
+	sum := sha256.Sum256([]byte(key.Source + "\x00" + strconv.FormatInt(key.Offset, 10)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:16])+entryExt)
+}
+
+// writeEntry атомарно записывает файл записи: временный файл, fsync, rename. Вызывается под c.mu.
+func (c *Cache) writeEntry(key Key, data []byte) error {
+	f, err := os.CreateTemp(c.dir, "*"+tmpExt)
+	if err != nil {
+		return fmt.Errorf("diskcache: create entry: %w", err)
+	}
+	tmp := f.Name()
+
+	buf := appendHeader(make([]byte, 0, headerSize(key)+len(data)), key, data)
+	buf = append(buf, data...)
+	_, err = f.Write(buf)
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("diskcache: write entry: %w", err)
+	}
+	return nil
+}
+
+// Формат записи: magic, длина идентификатора источника (uint16), идентификатор, смещение (int64),
+// длина данных (uint32), crc32 данных (uint32), данные. Целые — big endian.
+
+var (
+	errCorrupt     = errors.New("diskcache: corrupt entry")
+	errShortBuffer = errors.New("diskcache: buffer too short")
+)
+
+func headerSize(key Key) int {
+	return len(magic) + 2 + len(key.Source) + 8 + 4 + 4
+}
+
+func appendHeader(b []byte, key Key, data []byte) []byte {
+	b = append(b, magic...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(key.Source)))
+	b = append(b, key.Source...)
+	b = binary.BigEndian.AppendUint64(b, uint64(key.Offset))
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(data))
+}
+
+// readHeader читает заголовок записи и возвращает ключ, длину и контрольную сумму данных.
+func readHeader(r io.Reader) (key Key, size int, sum uint32, err error) {
+	var fixed [len(magic) + 2]byte
+	if _, err = io.ReadFull(r, fixed[:]); err != nil || string(fixed[:len(magic)]) != magic {
+		return Key{}, 0, 0, errCorrupt
+	}
+	source := make([]byte, binary.BigEndian.Uint16(fixed[len(magic):]))
+	var rest [8 + 4 + 4]byte
+	if _, err = io.ReadFull(r, source); err != nil {
+		return Key{}, 0, 0, errCorrupt
+	}
+	if _, err = io.ReadFull(r, rest[:]); err != nil {
+		return Key{}, 0, 0, errCorrupt
+	}
+	key = Key{Source: string(source), Offset: int64(binary.BigEndian.Uint64(rest[:8]))}
+	return key, int(binary.BigEndian.Uint32(rest[8:12])), binary.BigEndian.Uint32(rest[12:]), nil
+}
+
+// readKey читает ключ из заголовка файла записи.
+func readKey(path string) (Key, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Key{}, err
+	}
+	defer f.Close()
+	key, _, _, err := readHeader(f)
+	return key, err
+}
+
+// readEntry читает данные записи key в p, проверяя ключ, длину и контрольную сумму.
+func readEntry(path string, key Key, p []byte) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	gotKey, size, sum, err := readHeader(f)
+	switch {
+	case err != nil:
+		return 0, err
+	case gotKey != key:
+		return 0, errCorrupt
+	case size > len(p):
+		return 0, errShortBuffer
+	}
+	_, err = io.ReadFull(f, p[:size])
+	if err != nil || crc32.ChecksumIEEE(p[:size]) != sum {
+		return 0, errCorrupt
+	}
+	return size, nil
+}
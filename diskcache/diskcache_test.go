@@ -0,0 +1,181 @@
+package diskcache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestCache(t *testing.T, dir string, maxBytes int64) *Cache {
+	t.Helper()
+	c, err := Open(Config{Dir: dir, MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return c
+}
+
+func mustPut(t *testing.T, c *Cache, key Key, data string) {
+	t.Helper()
+	if err := c.Put(key, []byte(data)); err != nil {
+		t.Fatalf("Put %v: %v", key, err)
+	}
+}
+
+func get(c *Cache, key Key) (string, bool) {
+	p := make([]byte, 64)
+	n, ok := c.Get(key, p)
+	return string(p[:n]), ok
+}
+
+// entrySize — размер файла записи с ключом key и данными длины n.
+func entrySize(key Key, n int) int64 {
+	return int64(headerSize(key) + n)
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c := openTestCache(t, t.TempDir(), 1<<20)
+	key := Key{Source: "s3://bucket/object", Offset: 4096}
+
+	if _, ok := get(c, key); ok {
+		t.Fatalf("попадание в пустом кэше")
+	}
+	mustPut(t, c, key, "block data")
+	if got, ok := get(c, key); !ok || got != "block data" {
+		t.Fatalf("Get: %q, %v", got, ok)
+	}
+	if _, ok := get(c, Key{Source: key.Source, Offset: 0}); ok {
+		t.Fatalf("другое смещение того же источника не должно попадать")
+	}
+	if n, ok := c.Get(key, make([]byte, 3)); ok {
+		t.Fatalf("Get в короткий буфер должен быть промахом, получено %d байт", n)
+	}
+	if got, ok := get(c, key); !ok || got != "block data" {
+		t.Fatalf("короткий буфер не должен удалять запись: %q, %v", got, ok)
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.Hits != 2 || stats.Misses != 3 || stats.Bytes != entrySize(key, 10) {
+		t.Fatalf("Stats = %+v", stats)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	keys := []Key{{"a", 0}, {"b", 0}, {"c", 0}, {"d", 0}}
+	c := openTestCache(t, t.TempDir(), 3*entrySize(keys[0], 8))
+
+	for _, key := range keys[:3] {
+		mustPut(t, c, key, strings.Repeat(key.Source, 8))
+	}
+	get(c, keys[0]) // a становится самым свежим, вытеснен будет b
+	mustPut(t, c, keys[3], strings.Repeat("d", 8))
+
+	for _, tc := range []struct {
+		key  Key
+		want bool
+	}{{keys[0], true}, {keys[1], false}, {keys[2], true}, {keys[3], true}} {
+		if _, ok := get(c, tc.key); ok != tc.want {
+			t.Errorf("%s: попадание = %v, ожидалось %v", tc.key.Source, ok, tc.want)
+		}
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 3 {
+		t.Fatalf("Stats = %+v", stats)
+	}
+	if _, err := os.Stat(c.path(keys[1])); !os.IsNotExist(err) {
+		t.Fatalf("файл вытесненной записи должен быть удалён: %v", err)
+	}
+
+	if err := c.Put(Key{Source: "huge"}, make([]byte, 1<<20)); err != nil {
+		t.Fatalf("Put записи больше предела: %v", err)
+	}
+	if c.Stats().Entries != 3 {
+		t.Fatalf("запись больше MaxBytes не должна вытеснять остальные")
+	}
+}
+
+func TestCache_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	keys := []Key{{"obj", 0}, {"obj", 8}, {"obj", 16}}
+	c := openTestCache(t, dir, 1<<20)
+	for i, key := range keys {
+		mustPut(t, c, key, strings.Repeat(string(rune('x'+i)), 8))
+	}
+	// Порядок LRU хранится во времени модификации: делаем keys[0] самым старым, keys[1] — самым свежим
+	base := time.Now().Add(-time.Hour)
+	for i, age := range []time.Duration{0, 2 * time.Minute, time.Minute} {
+		if err := os.Chtimes(c.path(keys[i]), base.Add(age), base.Add(age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Перезапуск с меньшим пределом: при загрузке вытесняется самая старая запись
+	c = openTestCache(t, dir, 2*entrySize(keys[0], 8))
+	if _, ok := get(c, keys[0]); ok {
+		t.Fatalf("самая старая запись должна быть вытеснена при Open")
+	}
+	for _, key := range keys[1:] {
+		if _, ok := get(c, key); !ok {
+			t.Fatalf("запись %v потеряна после перезапуска", key)
+		}
+	}
+}
+
+func TestCache_RecoversFromCrash(t *testing.T) {
+	dir := t.TempDir()
+	good, corrupt := Key{"obj", 0}, Key{"obj", 8}
+	c := openTestCache(t, dir, 1<<20)
+	mustPut(t, c, good, "intact")
+	mustPut(t, c, corrupt, "damaged")
+
+	// Падение посреди Put оставляет временный файл; запись на диске испорчена; посторонний файл с расширением записи
+	writeFile(t, filepath.Join(dir, "123.tmp"), "partial")
+	data, err := os.ReadFile(c.path(corrupt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	writeFile(t, c.path(corrupt), string(data))
+	writeFile(t, filepath.Join(dir, "garbage.blk"), "not an entry")
+	writeFile(t, filepath.Join(dir, "README"), "чужой файл")
+
+	c = openTestCache(t, dir, 1<<20)
+	if got, ok := get(c, good); !ok || got != "intact" {
+		t.Fatalf("целая запись: %q, %v", got, ok)
+	}
+	if _, ok := get(c, corrupt); ok {
+		t.Fatalf("испорченная запись не должна отдаваться")
+	}
+
+	var names []string
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{filepath.Base(c.path(good)), "README"}
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Fatalf("файлы после восстановления: %v, ожидались %v", names, want)
+	}
+}
+
+func TestOpen_Validates(t *testing.T) {
+	if _, err := Open(Config{MaxBytes: 1}); err == nil {
+		t.Errorf("пустой каталог должен отклоняться")
+	}
+	if _, err := Open(Config{Dir: t.TempDir()}); err == nil {
+		t.Errorf("нулевой предел должен отклоняться")
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestAllocs_ReadAcrossSegments(t *testing.T) {
+	segments := make([]SizedReadSeekCloser, 64)
+	for i := range segments {
+		segments[i] = testutil.NewStringsReader(strings.Repeat("x", 1000))
+	}
+	m := NewMultiReader(segments...)
+	defer m.Close()
+
+	p := make([]byte, 300) // Каждое третье-четвёртое чтение пересекает границу сегментов
+	testutil.AllocBudget(t, 0, func() {
+		if _, err := io.ReadFull(m, p); err != nil {
+			if _, err = m.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestAllocs_Seek(t *testing.T) {
+	m := NewMultiReader(testutil.NewStringsReader("abc"), testutil.NewStringsReader("def"))
+	defer m.Close()
+
+	p := make([]byte, 2)
+	testutil.AllocBudget(t, 0, func() {
+		if _, err := m.Seek(-4, io.SeekEnd); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(m, p); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
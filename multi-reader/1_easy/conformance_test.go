@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/readertest"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestConformance_MockStringsReader(t *testing.T) {
+	readertest.RunReaderConformance(t, func(_ *testing.T, content []byte) readertest.SizedReadSeekCloser {
+		return testutil.NewStringsReader(string(content))
+	})
+}
+
+// MultiReader сам является сегментом и может вкладываться в другой MultiReader, поэтому тоже проходит проверки.
+func TestConformance_MultiReader(t *testing.T) {
+	readertest.RunReaderConformance(t, func(_ *testing.T, content []byte) readertest.SizedReadSeekCloser {
+		return NewMultiReader(splitSegments(string(content))...)
+	})
+}
+
+// splitSegments режет s на три сегмента неравной длины (включая, возможно, пустые).
+func splitSegments(s string) []SizedReadSeekCloser {
+	a, b := len(s)/3, len(s)*3/4
+	return []SizedReadSeekCloser{
+		testutil.NewStringsReader(s[:a]),
+		testutil.NewStringsReader(s[a:b]),
+		testutil.NewStringsReader(""),
+		testutil.NewStringsReader(s[b:]),
+	}
+}
@@ -1,20 +1,7 @@
 package main
 
-func main() {
-	tests := append(testCases, privateTestCases...)
-
-	for _, tc := range tests {
-		name := tc.name
-		run := tc.run
+import "github.com/zlatoivan/go-advanced/testutil"
 
-		CustomTestBody(
-			name,
-			func() struct{} {
-				return struct{}{}
-			},
-			func(_ struct{}) bool {
-				return run()
-			},
-		)
-	}
+func main() {
+	testutil.RunMain(testCases, privateTestCases)
 }
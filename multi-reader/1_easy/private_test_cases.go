@@ -4,185 +4,183 @@ import (
 	"errors"
 	"io"
 	"strings"
+
+	"github.com/zlatoivan/go-advanced/testutil"
 )
 
-var privateTestCases = []TestCase{
+var privateTestCases = []testutil.TestCase{
 	{
-		name: "Seek от конца",
-		run: func() bool {
-			a := newMockStringsReader("abc")
-			b := newMockStringsReader("def")
+		Name: "Seek от конца",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
+			b := testutil.NewStringsReader("def")
 			m := NewMultiReader(a, b)
 
 			pos, err := m.Seek(-2, io.SeekEnd)
-			if err != nil || pos != 4 {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, int64(4))); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 2)
 			n, err := m.Read(buf)
-			if err != nil || n != 2 {
-				return false
-			}
-			return string(buf) == "ef"
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 2),
+				testutil.ExpectBytes("данные", buf, []byte("ef")),
+			)
 		},
 	},
 	{
-		name: "Seek от текущей позиции",
-		run: func() bool {
-			a := newMockStringsReader("abcd")
+		Name: "Seek от текущей позиции",
+		Run: func() error {
+			a := testutil.NewStringsReader("abcd")
 			m := NewMultiReader(a)
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if err != nil || n != 1 || string(buf) != "a" {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("первый Read", err),
+				testutil.ExpectEqual("первый Read n", n, 1),
+				testutil.ExpectBytes("первый байт", buf, []byte("a")),
+			); err != nil {
+				return err
 			}
 
 			pos, err := m.Seek(2, io.SeekCurrent)
-			if err != nil || pos != 3 {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, int64(3))); err != nil {
+				return err
 			}
 
 			n, err = m.Read(buf)
-			if err != nil || n != 1 {
-				return false
-			}
-			return string(buf) == "d"
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectEqual("второй Read n", n, 1),
+				testutil.ExpectBytes("байт после Seek", buf, []byte("d")),
+			)
 		},
 	},
 	{
-		name: "Ошибочные варианты Seek",
-		run: func() bool {
-			a := newMockStringsReader("abc")
+		Name: "Ошибочные варианты Seek",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
 			m := NewMultiReader(a)
 
-			if _, err := m.Seek(0, 99); err == nil {
-				return false
-			}
-			if _, err := m.Seek(-1, io.SeekStart); err == nil {
-				return false
-			}
-			if _, err := m.Seek(5, io.SeekStart); err == nil {
-				return false
-			}
-			return true
+			_, errWhence := m.Seek(0, 99)
+			_, errNegative := m.Seek(-1, io.SeekStart)
+			_, errBeyond := m.Seek(5, io.SeekStart)
+			return testutil.Check(
+				testutil.ExpectError("Seek с неизвестным whence", errWhence),
+				testutil.ExpectError("Seek на отрицательную позицию", errNegative),
+				testutil.ExpectError("Seek за конец потока", errBeyond),
+			)
 		},
 	},
 	{
-		name: "Close агрегирует ошибки",
-		run: func() bool {
+		Name: "Close агрегирует ошибки",
+		Run: func() error {
 			errA := errors.New("A")
 			errB := errors.New("B")
-			a := newMockStringsReader("x")
-			b := newMockStringsReader("y")
-			c := newMockStringsReader("z")
-			a.closeErr = errA
-			b.closeErr = errB
+			a := testutil.NewStringsReader("x")
+			b := testutil.NewStringsReader("y")
+			c := testutil.NewStringsReader("z")
+			a.FailClose(errA)
+			b.FailClose(errB)
 
 			m := NewMultiReader(a, b, c)
 
 			err := m.Close()
-			if err == nil {
-				return false
-			}
-			if !errors.Is(err, errA) || !errors.Is(err, errB) {
-				return false
-			}
-			return a.closed && b.closed && c.closed
+			return testutil.Check(
+				testutil.ExpectErrorIs("Close", err, errA),
+				testutil.ExpectErrorIs("Close", err, errB),
+				testutil.ExpectTrue("все ридеры закрыты", a.Closed() && b.Closed() && c.Closed()),
+			)
 		},
 	},
 	{
-		name: "Read/Seek после Close",
-		run: func() bool {
-			a := newMockStringsReader("abc")
+		Name: "Read/Seek после Close",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
 			m := NewMultiReader(a)
 
-			err := m.Close()
-			if err != nil {
-				return false
+			if err := testutil.ExpectNoError("Close", m.Close()); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
-			n, err := m.Read(buf)
-			if n != 0 || !errors.Is(err, io.ErrClosedPipe) {
-				return false
-			}
-
-			if _, err = m.Seek(0, io.SeekStart); !errors.Is(err, io.ErrClosedPipe) {
-				return false
-			}
-
-			err = m.Close()
-			return err == nil
+			n, errRead := m.Read(buf)
+			_, errSeek := m.Seek(0, io.SeekStart)
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 0),
+				testutil.ExpectErrorIs("Read после Close", errRead, io.ErrClosedPipe),
+				testutil.ExpectErrorIs("Seek после Close", errSeek, io.ErrClosedPipe),
+				testutil.ExpectNoError("повторный Close", m.Close()),
+			)
 		},
 	},
 	{
-		name: "Size кэшируется и не пересчитывается",
-		run: func() bool {
-			var calls int
-			tr1 := newMockStringsReader(strings.Repeat("a", 2))
-			tr2 := newMockStringsReader(strings.Repeat("b", 3))
-			tr1.sizeCalls = &calls
-			tr2.sizeCalls = &calls
+		Name: "Size кэшируется и не пересчитывается",
+		Run: func() error {
+			trace := &testutil.CallTrace{}
+			tr1 := testutil.NewStringsReader(strings.Repeat("a", 2)).TraceTo(trace, "tr1")
+			tr2 := testutil.NewStringsReader(strings.Repeat("b", 3)).TraceTo(trace, "tr2")
 
 			m := NewMultiReader(tr1, tr2)
-			if calls != 2 {
-				return false
+			if err := testutil.ExpectEqual("вызовы Size при создании", trace.Count(0, "", testutil.OpSize), 2); err != nil {
+				return err
 			}
+			mark := trace.Mark()
 			_ = m.Size()
 			_ = m.Size()
-			return calls == 2
+			return trace.ExpectNoCalls("m.Size()", mark, "", testutil.OpSize)
 		},
 	},
 	{
-		name: "Ленивый Seek выполняется при первом чтении",
-		run: func() bool {
-			var seekCalls1, seekCalls2 int
-			tr1 := newMockStringsReader("abc")
-			tr2 := newMockStringsReader("def")
-			tr1.seekCalls = &seekCalls1
-			tr2.seekCalls = &seekCalls2
+		Name: "Ленивый Seek выполняется при первом чтении",
+		Run: func() error {
+			trace := &testutil.CallTrace{}
+			tr1 := testutil.NewStringsReader("abc").TraceTo(trace, "tr1")
+			tr2 := testutil.NewStringsReader("def").TraceTo(trace, "tr2")
 
 			m := NewMultiReader(tr1, tr2)
 
 			pos, err := m.Seek(4, io.SeekStart)
-			if err != nil || pos != 4 {
-				return false
-			}
-			if seekCalls1 != 0 || seekCalls2 != 0 {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("Seek", err),
+				testutil.ExpectEqual("позиция", pos, int64(4)),
+				trace.ExpectNoCalls("Seek до Read", 0, "", testutil.OpSeek),
+			); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if err != nil || n != 1 || string(buf) != "e" {
-				return false
-			}
-			if seekCalls1 != 0 {
-				return false
-			}
-			return seekCalls2 > 0
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 1),
+				testutil.ExpectBytes("данные", buf, []byte("e")),
+				trace.ExpectNoCalls("Read после Seek", 0, "tr1", testutil.OpSeek),
+				trace.ExpectCalls("Read после Seek", 0, "tr2", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Seek на EOF допустим и Read возвращает EOF",
-		run: func() bool {
-			a := newMockStringsReader("data")
+		Name: "Seek на EOF допустим и Read возвращает EOF",
+		Run: func() error {
+			a := testutil.NewStringsReader("data")
 			m := NewMultiReader(a)
 
 			size := m.Size()
 			pos, err := m.Seek(0, io.SeekEnd)
-			if err != nil || pos != size {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, size)); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if n != 0 {
-				return false
-			}
-			return errors.Is(err, io.EOF)
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 0),
+				testutil.ExpectErrorIs("Read", err, io.EOF),
+			)
 		},
 	},
 }
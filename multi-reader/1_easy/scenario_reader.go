@@ -0,0 +1,18 @@
+package main
+
+import "github.com/zlatoivan/go-advanced/testutil"
+
+// newScenarioReader собирает MultiReader варианта задания для Scenario.
+// Префетча в этом варианте нет, поэтому настройки буферов игнорируются.
+func newScenarioReader(_ int64, _ int, segs []*testutil.StringsReader) testutil.ScenarioReader {
+	readers := make([]SizedReadSeekCloser, len(segs))
+	for i, s := range segs {
+		readers[i] = s
+	}
+	return NewMultiReader(readers...)
+}
+
+// NewScenario начинает сценарий проверки MultiReader этого варианта задания.
+func NewScenario(name string) *testutil.Scenario {
+	return testutil.NewScenario(name, newScenarioReader)
+}
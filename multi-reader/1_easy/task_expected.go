@@ -89,6 +89,9 @@ func (m *MultiReader) Read(p []byte) (n int, err error) {
 		case readErr == nil && k == 0: // Текущий ридер не продвинулся и не вернул ошибку. Выходим, чтобы не зациклиться
 			return n, nil
 		case readErr == nil: // Прочитали k > 0 байт без ошибки. Пытаемся дочитать дальше
+			if m.absPos == m.prefixSizes[i+1] { // Ридер дочитан без EOF: следующий мог быть сдвинут прошлыми чтениями
+				m.needSeek = true
+			}
 			continue
 		case errors.Is(readErr, io.EOF): // Текущий ридер закончился. Не возвращаем EOF сразу, а переходим к след. ридеру.
 			m.absPos = m.prefixSizes[i+1] // Перейти к началу следующего ридера
@@ -155,5 +158,5 @@ func (m *MultiReader) Close() error {
 
 // Size возвращает суммарный размер всех ридеров.
 func (m *MultiReader) Size() int64 {
-	return m.Size()
+	return m.prefixSizes[len(m.readers)]
 }
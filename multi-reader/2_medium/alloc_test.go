@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// allocBlockSize — размер блока префетча в тестах бюджета аллокаций.
+const allocBlockSize = 4 << 10
+
+func TestAllocs_ReadSteadyState(t *testing.T) {
+	segment := strings.Repeat("x", 1<<20)
+	m := NewMultiReader(allocBlockSize, 4, testutil.NewStringsReader(segment), testutil.NewStringsReader(segment))
+	defer m.Close()
+
+	p := make([]byte, allocBlockSize/3) // Чтения не выровнены по блокам: окно то вычитывается, то нет
+	read := func() {
+		if _, err := io.ReadFull(m, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read() // Прогрев: запуск префетчера и первое наполнение пула блоков
+	testutil.AllocBudget(t, 0, read)
+}
+
+// TestAllocs_IndependentOfStreamLength проверяет, что аллокации на полное чтение потока не растут с числом блоков:
+// блоки префетча переиспользуются, а не выделяются заново.
+func TestAllocs_IndependentOfStreamLength(t *testing.T) {
+	if testutil.RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	readStream := func(segment string) float64 {
+		return testing.AllocsPerRun(20, func() {
+			m := NewMultiReader(allocBlockSize, 4, testutil.NewStringsReader(segment))
+			if _, err := io.Copy(io.Discard, m); err != nil {
+				t.Fatal(err)
+			}
+			_ = m.Close()
+		})
+	}
+	short := readStream(strings.Repeat("x", 16*allocBlockSize))
+	long := readStream(strings.Repeat("x", 1024*allocBlockSize))
+	if long-short > 1 {
+		t.Errorf("аллокации растут с длиной потока: %v на 16 блоков, %v на 1024 блока", short, long)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// reuseSource — источник с различающимися байтами, чтобы перезапись переиспользуемого блока была заметна.
+type reuseSource struct {
+	*bytes.Reader
+	size int64
+}
+
+func newReuseSource(data []byte) *reuseSource {
+	return &reuseSource{Reader: bytes.NewReader(data), size: int64(len(data))}
+}
+
+func (r *reuseSource) Size() int64  { return r.size }
+func (r *reuseSource) Close() error { return nil }
+
+// TestBlockReuse_KeepsDataAcrossReads проверяет, что блок возвращается в пул только после вычитывания окна:
+// чтения не выровнены по блокам, и данные окна не должны перезаписываться префетчером.
+func TestBlockReuse_KeepsDataAcrossReads(t *testing.T) {
+	data := make([]byte, 64<<10)
+	for i := range data {
+		data[i] = byte(i * 7 % 251)
+	}
+	m := NewMultiReader(4<<10, 2, newReuseSource(data[:40000]), newReuseSource(data[40000:]))
+	defer m.Close()
+
+	var got []byte
+	p := make([]byte, 1000)
+	for {
+		n, err := m.Read(p)
+		got = append(got, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("прочитанные данные не совпадают с источниками: %d байт из %d", len(got), len(data))
+	}
+}
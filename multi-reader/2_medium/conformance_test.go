@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/readertest"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestConformance_MockStringsReader(t *testing.T) {
+	readertest.RunReadCloserConformance(t, func(_ *testing.T, content []byte) readertest.SizedReadCloser {
+		return testutil.NewStringsReader(string(content))
+	})
+}
+
+// MultiReader сам является сегментом и может вкладываться в другой MultiReader, поэтому тоже проходит проверки.
+func TestConformance_MultiReader(t *testing.T) {
+	readertest.RunReadCloserConformance(t, func(_ *testing.T, content []byte) readertest.SizedReadCloser {
+		s := string(content)
+		a, b := len(s)/3, len(s)*3/4
+		return NewMultiReader(3, 2,
+			testutil.NewStringsReader(s[:a]),
+			testutil.NewStringsReader(s[a:b]),
+			testutil.NewStringsReader(""),
+			testutil.NewStringsReader(s[b:]),
+		)
+	})
+}
@@ -1,160 +1,161 @@
 package main
 
 import (
-	"errors"
 	"io"
 	"strings"
+
+	"github.com/zlatoivan/go-advanced/testutil"
 )
 
 const bufferSize = 1024 * 1024
 
-var privateTestCases = []TestCase{
+var privateTestCases = []testutil.TestCase{
 	//{
 	//	name: "Close агрегирует ошибки",
-	//	run: func() bool {
+	//	run: func() error {
 	//		errA := errors.New("A")
 	//		errB := errors.New("B")
-	//		a := newMockStringsReader("x")
-	//		b := newMockStringsReader("y")
-	//		c := newMockStringsReader("z")
-	//		a.closeErr = errA
-	//		b.closeErr = errB
+	//		a := testutil.NewStringsReader("x")
+	//		b := testutil.NewStringsReader("y")
+	//		c := testutil.NewStringsReader("z")
+	//		a.FailClose(errA)
+	//		b.FailClose(errB)
 	//
 	//		m := NewMultiReader(bufferSize, 4, a, b, c)
 	//
 	//		err := m.Close()
-	//		if err == nil {
-	//			return false
-	//		}
-	//		if !errors.Is(err, errA) || !errors.Is(err, errB) {
-	//			return false
-	//		}
-	//		return a.closed && b.closed && c.closed
+	//		return testutil.Check(
+	//			testutil.ExpectErrorIs("Close", err, errA),
+	//			testutil.ExpectErrorIs("Close", err, errB),
+	//			testutil.ExpectTrue("все ридеры закрыты", a.Closed() && b.Closed() && c.Closed()),
+	//		)
 	//	},
 	//},
 	{
-		name: "Read после Close",
-		run: func() bool {
-			a := newMockStringsReader("abc")
+		Name: "Read после Close",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
 			m := NewMultiReader(bufferSize, 4, a)
 
-			err := m.Close()
-			if err != nil {
-				return false
+			if err := testutil.ExpectNoError("Close", m.Close()); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if n != 0 || !errors.Is(err, io.ErrClosedPipe) {
-				return false
-			}
-
-			err = m.Close()
-			return err == nil
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 0),
+				testutil.ExpectErrorIs("Read после Close", err, io.ErrClosedPipe),
+				testutil.ExpectNoError("повторный Close", m.Close()),
+			)
 		},
 	},
 	// Проверка корректности Size без требований к кэшированию
 	{
-		name: "Size возвращает корректную сумму",
-		run: func() bool {
-			tr1 := newMockStringsReader(strings.Repeat("a", 2))
-			tr2 := newMockStringsReader(strings.Repeat("b", 3))
+		Name: "Size возвращает корректную сумму",
+		Run: func() error {
+			tr1 := testutil.NewStringsReader(strings.Repeat("a", 2))
+			tr2 := testutil.NewStringsReader(strings.Repeat("b", 3))
 			m := NewMultiReader(bufferSize, 4, tr1, tr2)
-			return m.Size() == 5
+			return testutil.ExpectEqual("Size", m.Size(), int64(5))
 		},
 	},
 	{
-		name: "Read с нулевой длиной возвращает (0, nil)",
-		run: func() bool {
-			a := newMockStringsReader("xy")
+		Name: "Read с нулевой длиной возвращает (0, nil)",
+		Run: func() error {
+			a := testutil.NewStringsReader("xy")
 			m := NewMultiReader(bufferSize, 4, a)
 			n, err := m.Read(nil)
-			return n == 0 && err == nil
+			return testutil.Check(testutil.ExpectEqual("Read n", n, 0), testutil.ExpectNoError("Read", err))
 		},
 	},
 	// Удалены все сценарии Seek; ниже — проверки последовательного чтения через границы
 	{
-		name: "Чтение через границу A->B без Seek",
-		run: func() bool {
+		Name: "Чтение через границу A->B без Seek",
+		Run: func() error {
 			s1 := strings.Repeat("A", 1024)
 			s2 := strings.Repeat("B", 768)
-			a := newMockStringsReader(s1)
-			b := newMockStringsReader(s2)
+			a := testutil.NewStringsReader(s1)
+			b := testutil.NewStringsReader(s2)
 			m := NewMultiReader(bufferSize, 4, a, b)
 
 			// Пропускаем len(s1)-10
 			discard := make([]byte, len(s1)-10)
 			n, err := m.Read(discard)
-			if err != nil || n != len(discard) {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("пропуск", err), testutil.ExpectEqual("пропуск n", n, len(discard))); err != nil {
+				return err
 			}
 			// Читаем 20 байт, должны пересечь границу
 			buf := make([]byte, 20)
 			n, err = m.Read(buf)
-			if err != nil || n != 20 {
-				return false
-			}
-			return string(buf) == strings.Repeat("A", 10)+strings.Repeat("B", 10)
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 20),
+				testutil.ExpectBytes("данные", string(buf), strings.Repeat("A", 10)+strings.Repeat("B", 10)),
+			)
 		},
 	},
 	{
-		name: "Чтение через границу B->C без Seek",
-		run: func() bool {
+		Name: "Чтение через границу B->C без Seek",
+		Run: func() error {
 			s1 := strings.Repeat("A", 1024)
 			s2 := strings.Repeat("B", 768)
 			s3 := strings.Repeat("C", 512)
-			a := newMockStringsReader(s1)
-			b := newMockStringsReader(s2)
-			c := newMockStringsReader(s3)
+			a := testutil.NewStringsReader(s1)
+			b := testutil.NewStringsReader(s2)
+			c := testutil.NewStringsReader(s3)
 			m := NewMultiReader(bufferSize, 4, a, b, c)
 
 			// Пропускаем len(s1)+len(s2)-5
 			discard := make([]byte, len(s1)+len(s2)-5)
 			n, err := m.Read(discard)
-			if err != nil || n != len(discard) {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("пропуск", err), testutil.ExpectEqual("пропуск n", n, len(discard))); err != nil {
+				return err
 			}
 			// Читаем 15 байт, пересекаем B->C
 			buf := make([]byte, 15)
 			n, err = m.Read(buf)
-			if err != nil || n != 15 {
-				return false
-			}
-			return string(buf) == strings.Repeat("B", 5)+strings.Repeat("C", 10)
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 15),
+				testutil.ExpectBytes("данные", string(buf), strings.Repeat("B", 5)+strings.Repeat("C", 10)),
+			)
 		},
 	},
 	{
-		name: "Маленькие ридеры, большие буферы",
-		run: func() bool {
-			a := newMockStringsReader("aaaaa")
-			b := newMockStringsReader("bbb")
-			c := newMockStringsReader("cccccccc")
+		Name: "Маленькие ридеры, большие буферы",
+		Run: func() error {
+			a := testutil.NewStringsReader("aaaaa")
+			b := testutil.NewStringsReader("bbb")
+			c := testutil.NewStringsReader("cccccccc")
 			m := NewMultiReader(bufferSize, 2, a, b, c)
 			buf := make([]byte, int(m.Size()))
 			n, err := m.Read(buf)
-			if err != nil || n != len(buf) {
-				return false
-			}
-			return string(buf) == "aaaaabbbcccccccc"
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, len(buf)),
+				testutil.ExpectBytes("данные", buf, []byte("aaaaabbbcccccccc")),
+			)
 		},
 	},
 	{
-		name: "EOF при достижении конца общего потока",
-		run: func() bool {
-			r := newMockStringsReader("z")
+		Name: "EOF при достижении конца общего потока",
+		Run: func() error {
+			r := testutil.NewStringsReader("z")
 			m := NewMultiReader(bufferSize, 1, r)
 			b := make([]byte, 10)
 			n, err := m.Read(b)
-			if n != 1 || string(b[:n]) != "z" || !errors.Is(err, io.EOF) {
-				return false
-			}
-			return true
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 1),
+				testutil.ExpectBytes("данные", b[:n], []byte("z")),
+				testutil.ExpectErrorIs("Read", err, io.EOF),
+			)
 		},
 	},
 	{
-		name: "Close во время фонового чтения не падает",
-		run: func() bool {
-			r := newMockStringsReader(strings.Repeat("a", 1<<16))
+		Name: "Close во время фонового чтения не падает",
+		Run: func() error {
+			r := testutil.NewStringsReader(strings.Repeat("a", 1<<16))
 			m := NewMultiReader(bufferSize, 2, r)
 			done := make(chan struct{})
 			go func() {
@@ -164,70 +165,71 @@ var privateTestCases = []TestCase{
 			}()
 			_ = m.Close()
 			<-done
-			return true
+			return nil
 		},
 	},
 	{
-		name: "Большие данные: полное чтение и чтение через границы",
-		run: func() bool {
+		Name: "Большие данные: полное чтение и чтение через границы",
+		Run: func() error {
 			// Сгенерируем несколько «больших» источников по ~1–2KB суммарно
 			s1 := strings.Repeat("A", 1024)
 			s2 := strings.Repeat("B", 768)
 			s3 := strings.Repeat("C", 512)
-			a := newMockStringsReader(s1)
-			b := newMockStringsReader(s2)
-			c := newMockStringsReader(s3)
+			a := testutil.NewStringsReader(s1)
+			b := testutil.NewStringsReader(s2)
+			c := testutil.NewStringsReader(s3)
 			m := NewMultiReader(bufferSize, 4, a, b, c)
 
 			// Полное чтение и сравнение
 			expected := s1 + s2 + s3
 			buf := make([]byte, len(expected))
 			n, err := m.Read(buf)
-			if err != nil || n != len(expected) || string(buf) != expected {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("полное чтение", err),
+				testutil.ExpectEqual("полное чтение n", n, len(expected)),
+				testutil.ExpectBytes("полное чтение", string(buf), expected),
+			); err != nil {
+				return err
 			}
 
 			// Чтение через границу A->B: пересоздаём ридеры, пропускаем len(s1)-10, читаем 20
 			{
-				a2 := newMockStringsReader(s1)
-				b2 := newMockStringsReader(s2)
+				a2 := testutil.NewStringsReader(s1)
+				b2 := testutil.NewStringsReader(s2)
 				m2 := NewMultiReader(bufferSize, 4, a2, b2)
 				discard := make([]byte, len(s1)-10)
 				n, err = m2.Read(discard)
-				if err != nil || n != len(discard) {
-					return false
+				if err := testutil.Check(testutil.ExpectNoError("пропуск до A->B", err), testutil.ExpectEqual("пропуск до A->B n", n, len(discard))); err != nil {
+					return err
 				}
 				buf2 := make([]byte, 20)
 				n, err = m2.Read(buf2)
-				if err != nil || n != 20 {
-					return false
-				}
-				if string(buf2) != strings.Repeat("A", 10)+strings.Repeat("B", 10) {
-					return false
+				if err := testutil.Check(
+					testutil.ExpectNoError("чтение через A->B", err),
+					testutil.ExpectEqual("чтение через A->B n", n, 20),
+					testutil.ExpectBytes("чтение через A->B", string(buf2), strings.Repeat("A", 10)+strings.Repeat("B", 10)),
+				); err != nil {
+					return err
 				}
 			}
 
 			// Чтение через границу B->C: пересоздаём ридеры, пропускаем len(s1)+len(s2)-5, читаем 15
-			{
-				a3 := newMockStringsReader(s1)
-				b3 := newMockStringsReader(s2)
-				c3 := newMockStringsReader(s3)
-				m3 := NewMultiReader(bufferSize, 4, a3, b3, c3)
-				discard := make([]byte, len(s1)+len(s2)-5)
-				n, err = m3.Read(discard)
-				if err != nil || n != len(discard) {
-					return false
-				}
-				buf3 := make([]byte, 15)
-				n, err = m3.Read(buf3)
-				if err != nil || n != 15 {
-					return false
-				}
-				if string(buf3) != strings.Repeat("B", 5)+strings.Repeat("C", 10) {
-					return false
-				}
+			a3 := testutil.NewStringsReader(s1)
+			b3 := testutil.NewStringsReader(s2)
+			c3 := testutil.NewStringsReader(s3)
+			m3 := NewMultiReader(bufferSize, 4, a3, b3, c3)
+			discard := make([]byte, len(s1)+len(s2)-5)
+			n, err = m3.Read(discard)
+			if err := testutil.Check(testutil.ExpectNoError("пропуск до B->C", err), testutil.ExpectEqual("пропуск до B->C n", n, len(discard))); err != nil {
+				return err
 			}
-			return true
+			buf3 := make([]byte, 15)
+			n, err = m3.Read(buf3)
+			return testutil.Check(
+				testutil.ExpectNoError("чтение через B->C", err),
+				testutil.ExpectEqual("чтение через B->C n", n, 15),
+				testutil.ExpectBytes("чтение через B->C", string(buf3), strings.Repeat("B", 5)+strings.Repeat("C", 10)),
+			)
 		},
 	},
 }
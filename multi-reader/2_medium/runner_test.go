@@ -0,0 +1,12 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestCases(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	testutil.RunTestCasesT(t, append(testCases, privateTestCases...), testutil.RunConfig{Timeout: testutil.DefaultCaseTimeout, Parallel: 4})
+}
@@ -0,0 +1,17 @@
+package main
+
+import "github.com/zlatoivan/go-advanced/testutil"
+
+// newScenarioReader собирает MultiReader варианта задания для Scenario.
+func newScenarioReader(bufferSize int64, buffersNum int, segs []*testutil.StringsReader) testutil.ScenarioReader {
+	readers := make([]SizedReadCloser, len(segs))
+	for i, s := range segs {
+		readers[i] = s
+	}
+	return NewMultiReader(bufferSize, buffersNum, readers...)
+}
+
+// NewScenario начинает сценарий проверки MultiReader этого варианта задания.
+func NewScenario(name string) *testutil.Scenario {
+	return testutil.NewScenario(name, newScenarioReader)
+}
@@ -21,10 +21,13 @@ type MultiReader struct {
 	buffersNum int                // количество буферов
 	mu         sync.Mutex         // мьютекс для блокировок, блокирует все нижние поля:
 	windowBuf  []byte             // текущее окно данных
+	windowBlk  []byte             // блок префетча, на который указывает окно; возвращается в blockPool после вычитывания
+	blockPool  chan []byte        // вычитанные блоки для повторного использования префетчером
 	pfBufCh    chan []byte        // буферизированный канал блоков, наполняется префетчером
 	pfErrCh    chan error         // канал для ошибки/EOF от префетчера (ёмкость 1)
 	pfCancel   context.CancelFunc // отмена контекста префетчера
 	pfWg       sync.WaitGroup     // ожидание завершения горутины префетчера
+	pfErr      error              // итоговая ошибка префетчера; после завершения возвращается каждым Read
 	closed     bool               // флаг закрытия мультиридера
 }
 
@@ -43,6 +46,7 @@ func NewMultiReader(buffersSize int64, buffersNum int, readers ...SizedReadClose
 		totalSize:  total,
 		buffersNum: buffersNum,
 		bufferSize: buffersSize,
+		blockPool:  make(chan []byte, buffersNum+2), // Вмещает все блоки в обороте: buffersNum в канале, окно и блок префетчера
 	}
 }
 
@@ -71,6 +75,9 @@ func (m *MultiReader) Read(p []byte) (n int, err error) {
 			copy(dst[:toCopy], m.windowBuf[:toCopy])
 			m.windowBuf = m.windowBuf[toCopy:]
 			n += toCopy
+			if len(m.windowBuf) == 0 {
+				m.releaseWindow()
+			}
 			if n == len(p) {
 				m.mu.Unlock()
 				return n, nil
@@ -80,15 +87,20 @@ func (m *MultiReader) Read(p []byte) (n int, err error) {
 
 		buf, okPf := <-m.pfBufCh // Окно пусто - ждём новый блок от префетчера
 		if !okPf {               // Канал данных закрыт - считываем итоговую ошибку/EOF
-			select {
-			case err = <-m.pfErrCh:
-			default:
-				err = io.EOF
+			m.mu.Lock()
+			if m.pfErr == nil { // Закрытый pfErrCh отдаёт nil, поэтому запоминаем ошибку при первом чтении
+				m.pfErr = <-m.pfErrCh
+				if m.pfErr == nil {
+					m.pfErr = io.EOF
+				}
 			}
+			err = m.pfErr
+			m.mu.Unlock()
 			return n, err
 		}
 		m.mu.Lock()
-		m.windowBuf = append(m.windowBuf, buf...)
+		m.windowBuf = buf // Окно к этому моменту всегда вычитано: блок становится окном без копирования
+		m.windowBlk = buf
 		m.mu.Unlock()
 	}
 }
@@ -134,7 +146,7 @@ func (m *MultiReader) prefetchLoop(ctx context.Context) {
 
 	for _, reader := range m.readers {
 		for {
-			buf := make([]byte, m.bufferSize)
+			buf := m.getBlock()
 			n, err := reader.Read(buf)
 			if n > 0 {
 				select {
@@ -157,6 +169,29 @@ func (m *MultiReader) prefetchLoop(ctx context.Context) {
 	m.sendErr(io.EOF)
 }
 
+// getBlock возвращает блок из пула или выделяет новый размером bufferSize.
+func (m *MultiReader) getBlock() []byte {
+	select {
+	case buf := <-m.blockPool:
+		return buf
+	default:
+		return make([]byte, m.bufferSize)
+	}
+}
+
+// releaseWindow сбрасывает окно и возвращает его блок в пул. Вызывается под m.mu.
+func (m *MultiReader) releaseWindow() {
+	m.windowBuf = nil
+	if m.windowBlk == nil {
+		return
+	}
+	select {
+	case m.blockPool <- m.windowBlk[:cap(m.windowBlk)]:
+	default: // Пул полон — лишний блок заберёт GC
+	}
+	m.windowBlk = nil
+}
+
 // sendErr отправляет ошибку в канал, если есть место
 func (m *MultiReader) sendErr(err error) {
 	select {
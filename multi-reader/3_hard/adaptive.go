@@ -0,0 +1,27 @@
+package main
+
+// WithAdaptiveBlockSize включает адаптивный размер блока префетча, как readahead в ОС: префетчер начинает
+// с блока minSize и удваивает его с каждым отданным блоком до maxSize, пока чтение идёт подряд. Seek за
+// пределы окна перезапускает префетчер, и блок снова начинается с minSize: случайный доступ не держит
+// в памяти больших блоков, а длинный последовательный проход быстро выходит на крупные чтения.
+// Заменяет WithBlockSize. С дисковым кэшем блоки всегда maxSize: ключи кэша выровнены по размеру блока.
+func WithAdaptiveBlockSize(minSize, maxSize int64) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.adaptive = true
+		o.minBlockSize = minSize
+		o.blockSize = maxSize
+	}
+}
+
+// nextBlockSize возвращает размер блока префетча, следующего за блоком size. Вызывается префетчером.
+func (m *MultiReader) nextBlockSize(size int64) int64 {
+	return min(size*2, m.bufferSize)
+}
+
+// firstBlockSize возвращает размер первого блока префетчера.
+func (m *MultiReader) firstBlockSize() int64 {
+	if m.minBlock > 0 {
+		return m.minBlock
+	}
+	return m.bufferSize
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// sizeRecordingSegment запоминает длину буфера каждого Read источника.
+type sizeRecordingSegment struct {
+	*testutil.StringsReader
+	mu    sync.Mutex
+	sizes []int
+}
+
+func (s *sizeRecordingSegment) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	s.sizes = append(s.sizes, len(p))
+	s.mu.Unlock()
+	return s.StringsReader.Read(p)
+}
+
+// takeSizes возвращает накопленные длины и начинает запись заново.
+func (s *sizeRecordingSegment) takeSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := s.sizes
+	s.sizes = nil
+	return sizes
+}
+
+func TestAdaptiveBlockSize_GrowsAndResetsAfterSeek(t *testing.T) {
+	content := strings.Repeat("0123456789", 10)
+	seg := &sizeRecordingSegment{StringsReader: testutil.NewStringsReader(content)}
+	m, err := NewMultiReaderWithOptions([]SizedReadSeekCloser{seg}, WithAdaptiveBlockSize(4, 32), WithBuffersNum(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != content {
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+	if sizes, want := seg.takeSizes(), []int{4, 8, 16, 32, 32, 8}; !slices.Equal(sizes, want) {
+		t.Fatalf("блоки последовательного прохода %v, ожидались %v", sizes, want)
+	}
+
+	if _, err = m.Seek(50, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err = io.ReadFull(m, buf); err != nil || string(buf) != "0123" {
+		t.Fatalf("Read после Seek: %q, %v", buf, err)
+	}
+	if sizes := seg.takeSizes(); len(sizes) == 0 || sizes[0] != 4 {
+		t.Fatalf("блоки после Seek %v, ожидалось начало с 4", sizes)
+	}
+}
+
+func TestAdaptiveBlockSize_InvalidSizes(t *testing.T) {
+	for _, tc := range [][2]int64{{0, 8}, {16, 8}, {-1, 8}} {
+		_, err := NewMultiReaderWithOptions([]SizedReadSeekCloser{testutil.NewStringsReader("x")}, WithAdaptiveBlockSize(tc[0], tc[1]))
+		if err == nil {
+			t.Errorf("WithAdaptiveBlockSize(%d, %d): ожидалась ошибка", tc[0], tc[1])
+		}
+	}
+}
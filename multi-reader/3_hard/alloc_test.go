@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/bufpool"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// allocBlockSize — размер блока префетча в тестах бюджета аллокаций.
+const allocBlockSize = 4 << 10
+
+// newAllocMultiReader создаёт MultiReader над практически бесконечным источником, чтобы замеры не упирались в EOF.
+func newAllocMultiReader(t *testing.T) *MultiReader {
+	m := NewMultiReader(allocBlockSize, 4, newMockGeneratedReader(1, 1<<20), newMockGeneratedReader(2, 1<<50))
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+func TestAllocs_ReadSteadyState(t *testing.T) {
+	m := newAllocMultiReader(t)
+	p := make([]byte, allocBlockSize/3) // Чтения не выровнены по блокам: окно то вычитывается, то нет
+	read := func() {
+		if _, err := io.ReadFull(m, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read() // Прогрев: запуск префетчера и первое наполнение пула блоков
+	testutil.AllocBudget(t, 0, read)
+}
+
+func TestAllocs_SeekForwardWithinWindow(t *testing.T) {
+	m := newAllocMultiReader(t)
+	p := make([]byte, 16)
+	if _, err := io.ReadFull(m, p); err != nil {
+		t.Fatal(err)
+	}
+	testutil.AllocBudget(t, 0, func() {
+		if _, err := m.Seek(int64(len(p)), io.SeekCurrent); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(m, p); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestAllocs_IndependentOfStreamLength проверяет, что аллокации на полное чтение потока не растут с числом блоков:
+// блоки префетча переиспользуются, а не выделяются заново.
+func TestAllocs_IndependentOfStreamLength(t *testing.T) {
+	if testutil.RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	readStream := func(blocks int64) float64 {
+		return testing.AllocsPerRun(20, func() {
+			m := NewMultiReader(allocBlockSize, 4, newMockGeneratedReader(1, blocks*allocBlockSize))
+			if _, err := io.Copy(io.Discard, m); err != nil {
+				t.Fatal(err)
+			}
+			_ = m.Close()
+		})
+	}
+	short, long := readStream(16), readStream(1024)
+	if long-short > 1 {
+		t.Errorf("аллокации растут с длиной потока: %v на 16 блоков, %v на 1024 блока", short, long)
+	}
+}
+
+// TestBlocks_ReturnedToPool проверяет учёт блоков в отладочном пуле: после Close все блоки префетча возвращены,
+// включая окно, блоки в канале префетчера и блоки, устаревшие из-за Seek.
+func TestBlocks_ReturnedToPool(t *testing.T) {
+	for name, run := range map[string]func(m *MultiReader) error{
+		"полное чтение": func(m *MultiReader) error {
+			_, err := io.Copy(io.Discard, m)
+			return err
+		},
+		"Seek за окно и Close посреди потока": func(m *MultiReader) error {
+			p := make([]byte, 100)
+			for _, pos := range []int64{0, 5000, 1000, 20000} {
+				if _, err := m.Seek(pos, io.SeekStart); err != nil {
+					return err
+				}
+				if _, err := io.ReadFull(m, p); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pool := bufpool.New(bufpool.Debug())
+			m := NewMultiReader(1<<10, 4, newMockGeneratedReader(1, 30_000), newMockGeneratedReader(2, 10_000))
+			m.pool = pool
+			if err := run(m); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if leaks := pool.Leaks(); len(leaks) != 0 {
+				t.Fatalf("после Close не возвращено блоков: %d; первый взят в:\n%s", len(leaks), strings.Join(leaks[:1], ""))
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+// Arbiter делит общую пропускную способность (например, один WAN-канал) между префетчем нескольких MultiReader.
+// Блоки выдаются по одному: очередной получает ожидающий запрос с истёкшим сроком (раньше всех — самый
+// просроченный), иначе — с наивысшим приоритетом, при равенстве — пришедший раньше. Так фоновый префетч
+// не отнимает канал у интерактивных потоков, а срок не даёт фоновым голодать бесконечно.
+type Arbiter struct {
+	bucket *throttle.Bucket
+
+	mu      sync.Mutex
+	waiting []*arbiterWaiter // запросы, ждущие очереди; единицы штук, поэтому выбор — линейным проходом
+	busy    bool             // выбранный запрос сейчас набирает токены в bucket
+	seq     uint64           // порядок поступления запросов
+}
+
+// StreamConfig — параметры потока, зарегистрированного в Arbiter.
+type StreamConfig struct {
+	Priority int           // больше — раньше
+	Deadline time.Duration // запрос, прождавший дольше, обгоняет любые приоритеты; 0 — без срока
+}
+
+// ArbiterStream — поток арбитра; создаётся Register и используется префетчем одного MultiReader.
+type ArbiterStream struct {
+	a   *Arbiter
+	cfg StreamConfig
+}
+
+// arbiterWaiter — запрос потока на очередной блок.
+type arbiterWaiter struct {
+	priority int
+	deadline time.Time // нулевое — без срока
+	seq      uint64
+	turn     chan struct{} // закрывается, когда запросу выпала очередь
+}
+
+// NewArbiter создаёт арбитр с общим лимитом bytesPerSec; burst ограничивает размер одной порции.
+func NewArbiter(bytesPerSec, burst int64) (*Arbiter, error) {
+	bucket, err := throttle.NewBucket(bytesPerSec, burst)
+	if err != nil {
+		return nil, err
+	}
+	return &Arbiter{bucket: bucket}, nil
+}
+
+// Register подключает префетч m к арбитру с параметрами cfg. Работающий префетчер перезапускается
+// уже под арбитром. Возвращает ErrClosedPipe, если m закрыт.
+func (a *Arbiter) Register(m *MultiReader, cfg StreamConfig) (*ArbiterStream, error) {
+	if cfg.Deadline < 0 {
+		return nil, fmt.Errorf("negative stream deadline: %v", cfg.Deadline)
+	}
+	s := &ArbiterStream{a: a, cfg: cfg}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, io.ErrClosedPipe
+	}
+	m.resetPrefetch() // Префетчер читает m.arbiter без блокировки: подменяем, только когда он остановлен
+	m.arbiter = s
+	return s, nil
+}
+
+// Acquire ждёт очереди потока и n байт пропускной способности. Порции больше Burst делятся.
+// При отмене ctx запрос снимается с очереди.
+func (s *ArbiterStream) Acquire(ctx context.Context, n int64) error {
+	for n > 0 {
+		k := min(n, s.a.bucket.Burst())
+		if err := s.a.acquire(ctx, s.cfg, k); err != nil {
+			return err
+		}
+		n -= k
+	}
+	return nil
+}
+
+// acquireBlock ждёт очереди на чтение блока из n байт источника; без арбитра (s == nil) не ждёт.
+func (s *ArbiterStream) acquireBlock(ctx context.Context, n int) error {
+	if s == nil {
+		return nil
+	}
+	return s.Acquire(ctx, int64(n))
+}
+
+// acquire проводит одну порцию через очередь и корзину.
+func (a *Arbiter) acquire(ctx context.Context, cfg StreamConfig, n int64) error {
+	w := &arbiterWaiter{priority: cfg.Priority, turn: make(chan struct{})}
+	if cfg.Deadline > 0 {
+		w.deadline = time.Now().Add(cfg.Deadline)
+	}
+	a.mu.Lock()
+	w.seq = a.seq
+	a.seq++
+	a.waiting = append(a.waiting, w)
+	a.dispatch()
+	a.mu.Unlock()
+
+	select {
+	case <-w.turn:
+	case <-ctx.Done():
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		select {
+		case <-w.turn: // Очередь выпала одновременно с отменой: отдаём её следующему
+			a.busy = false
+			a.dispatch()
+		default:
+			a.remove(w)
+		}
+		return ctx.Err()
+	}
+
+	err := a.bucket.WaitN(ctx, n)
+	a.mu.Lock()
+	a.busy = false
+	a.dispatch()
+	a.mu.Unlock()
+	return err
+}
+
+// dispatch отдаёт очередь лучшему ожидающему запросу, если корзину сейчас никто не занимает. Вызывается под a.mu.
+func (a *Arbiter) dispatch() {
+	if a.busy || len(a.waiting) == 0 {
+		return
+	}
+	now := time.Now()
+	best := 0
+	for i, w := range a.waiting[1:] {
+		if w.before(a.waiting[best], now) {
+			best = i + 1
+		}
+	}
+	w := a.waiting[best]
+	a.remove(w)
+	a.busy = true
+	close(w.turn)
+}
+
+// remove снимает w с очереди. Вызывается под a.mu.
+func (a *Arbiter) remove(w *arbiterWaiter) {
+	for i, x := range a.waiting {
+		if x == w {
+			a.waiting = append(a.waiting[:i], a.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// before сообщает, должен ли w получить очередь раньше o в момент now.
+func (w *arbiterWaiter) before(o *arbiterWaiter, now time.Time) bool {
+	wLate := !w.deadline.IsZero() && !now.Before(w.deadline)
+	oLate := !o.deadline.IsZero() && !now.Before(o.deadline)
+	switch {
+	case wLate && oLate && !w.deadline.Equal(o.deadline):
+		return w.deadline.Before(o.deadline)
+	case wLate != oLate:
+		return wLate
+	case !wLate && w.priority != o.priority:
+		return w.priority > o.priority
+	}
+	return w.seq < o.seq
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// busyArbiter создаёт арбитр на 10 КБ/с с порцией 1000 байт и занимает его: корзина опустошена,
+// а очередной запрос ждёт токенов ~100мс. Возвращает канал, закрывающийся по завершении занимающего запроса.
+func busyArbiter(t *testing.T) (*Arbiter, chan struct{}) {
+	t.Helper()
+	a, err := NewArbiter(10_000, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder := &ArbiterStream{a: a}
+	if err := holder.Acquire(context.Background(), 1000); err != nil { // Опустошаем корзину
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = holder.Acquire(context.Background(), 1000)
+	}()
+	waitFor(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.busy
+	})
+	return a, done
+}
+
+// waitFor ждёт выполнения cond не дольше 5 секунд.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("условие не выполнилось")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquireOrder запускает Acquire потоков в указанном порядке (каждый — после постановки предыдущего в очередь)
+// и возвращает имена в порядке получения пропускной способности.
+func acquireOrder(t *testing.T, a *Arbiter, streams map[string]StreamConfig, order []string, pause time.Duration) []string {
+	t.Helper()
+	var (
+		mu  sync.Mutex
+		got []string
+		wg  sync.WaitGroup
+	)
+	for i, name := range order {
+		s := &ArbiterStream{a: a, cfg: streams[name]}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Acquire(context.Background(), 1000); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			mu.Lock()
+			got = append(got, name)
+			mu.Unlock()
+		}()
+		waitFor(t, func() bool {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			return len(a.waiting) == i+1
+		})
+	}
+	time.Sleep(pause)
+	wg.Wait()
+	return got
+}
+
+func TestArbiter_PriorityOrder(t *testing.T) {
+	a, _ := busyArbiter(t)
+	streams := map[string]StreamConfig{"bulk": {Priority: 0}, "interactive": {Priority: 10}, "bulk2": {Priority: 0}}
+	got := acquireOrder(t, a, streams, []string{"bulk", "interactive", "bulk2"}, 0)
+	if want := []string{"interactive", "bulk", "bulk2"}; !slices.Equal(got, want) {
+		t.Fatalf("порядок %v, ожидался %v", got, want)
+	}
+}
+
+func TestArbiter_DeadlineBeatsPriority(t *testing.T) {
+	a, done := busyArbiter(t)
+	streams := map[string]StreamConfig{"bulk": {Deadline: time.Millisecond}, "interactive": {Priority: 10}}
+	got := acquireOrder(t, a, streams, []string{"bulk", "interactive"}, 0)
+	<-done
+	// К моменту освобождения арбитра (~100мс) срок bulk истёк: он обгоняет более приоритетный поток
+	if want := []string{"bulk", "interactive"}; !slices.Equal(got, want) {
+		t.Fatalf("порядок %v, ожидался %v", got, want)
+	}
+}
+
+func TestArbiter_CancelLeavesQueue(t *testing.T) {
+	a, done := busyArbiter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- (&ArbiterStream{a: a}).Acquire(ctx, 1000) }()
+	waitFor(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return len(a.waiting) == 1
+	})
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire после отмены: %v", err)
+	}
+	<-done
+	if err := (&ArbiterStream{a: a}).Acquire(context.Background(), 1000); err != nil {
+		t.Fatalf("арбитр не освободился после отменённого запроса: %v", err)
+	}
+}
+
+func TestArbiter_SharesUplinkBetweenMultiReaders(t *testing.T) {
+	a, err := NewArbiter(1<<30, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := []string{string(bytes.Repeat([]byte("interactive"), 500)), string(bytes.Repeat([]byte("bulk"), 3000))}
+	var wg sync.WaitGroup
+	for i, content := range contents {
+		m := NewMultiReader(64, 2, testutil.NewStringsReader(content[:100]), testutil.NewStringsReader(content[100:]))
+		defer m.Close()
+		if _, err := a.Register(m, StreamConfig{Priority: 1 - i, Deadline: 50 * time.Millisecond}); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := io.ReadAll(m)
+			if err != nil || string(got) != content {
+				t.Errorf("поток %d: %d байт, %v", i, len(got), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	m := NewMultiReader(64, 2, testutil.NewStringsReader("x"))
+	_ = m.Close()
+	if _, err := a.Register(m, StreamConfig{}); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Register закрытого MultiReader: %v", err)
+	}
+	if _, err := a.Register(NewMultiReader(64, 2), StreamConfig{Deadline: -1}); err == nil {
+		t.Fatalf("Register с отрицательным сроком: ожидалась ошибка")
+	}
+}
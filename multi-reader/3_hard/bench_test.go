@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// benchTotalSize — объём данных, читаемый за одну итерацию бенчмарка.
+const benchTotalSize = 1 << 20
+
+// BenchmarkPrefetch перебирает bufferSize × buffersNum × число сегментов × задержку источника.
+// Пропускная способность — в MB/s (b.SetBytes), аллокации — allocs/op.
+// Запуск: go test -run XXX -bench Prefetch -benchtime 20x
+func BenchmarkPrefetch(b *testing.B) {
+	for _, bufferSize := range []int64{4 << 10, 64 << 10, 256 << 10} {
+		for _, buffersNum := range []int{1, 4, 16} {
+			for _, segments := range []int{1, 16} {
+				for _, latency := range []time.Duration{0, 100 * time.Microsecond} {
+					name := fmt.Sprintf("buf=%dKiB/num=%d/segs=%d/latency=%v", bufferSize>>10, buffersNum, segments, latency)
+					b.Run(name, func(b *testing.B) {
+						benchmarkPrefetch(b, bufferSize, buffersNum, segments, latency)
+					})
+				}
+			}
+		}
+	}
+}
+
+func benchmarkPrefetch(b *testing.B, bufferSize int64, buffersNum, segments int, latency time.Duration) {
+	segment := strings.Repeat("x", benchTotalSize/segments)
+	b.SetBytes(int64(len(segment) * segments))
+	b.ReportAllocs()
+
+	for range b.N {
+		b.StopTimer()
+		readers := make([]SizedReadSeekCloser, segments)
+		for i := range readers {
+			readers[i] = newMockLatencyReader(segment, latency, 0, 0)
+		}
+		m := NewMultiReader(bufferSize, buffersNum, readers...)
+		b.StartTimer()
+
+		if _, err := io.Copy(io.Discard, m); err != nil {
+			b.Fatal(err)
+		}
+		_ = m.Close()
+	}
+}
+
+// BenchmarkRead_SteadyState — чтение из уже запущенного префетча. Падает, если Read начнёт выделять память.
+// Запуск: go test -run XXX -bench Read_SteadyState
+func BenchmarkRead_SteadyState(b *testing.B) {
+	m := NewMultiReader(64<<10, 4, newMockGeneratedReader(1, 1<<50))
+	defer m.Close()
+	p := make([]byte, 32<<10)
+	if _, err := io.ReadFull(m, p); err != nil { // Прогрев префетча и пула блоков
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(p)))
+	b.ResetTimer()
+	defer testutil.BenchAllocBudget(b, 0)()
+
+	for range b.N {
+		if _, err := io.ReadFull(m, p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
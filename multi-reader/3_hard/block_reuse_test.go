@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// TestBlockReuse_KeepsDataAcrossReads проверяет, что блок возвращается в пул только после вычитывания окна:
+// чтения не выровнены по блокам, и данные окна не должны перезаписываться префетчером.
+func TestBlockReuse_KeepsDataAcrossReads(t *testing.T) {
+	const size = 40000
+	m := NewMultiReader(4<<10, 2, newMockGeneratedReader(1, size), newMockGeneratedReader(2, size))
+	defer m.Close()
+
+	var want []byte
+	for seed := uint64(1); seed <= 2; seed++ {
+		data, err := io.ReadAll(newMockGeneratedReader(seed, size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, data...)
+	}
+	got, err := io.ReadAll(io.LimitReader(m, 2*size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("прочитанные данные не совпадают с источниками: %d байт из %d", len(got), len(want))
+	}
+}
+
+func TestSeek_WindowEndKeepsPrefetch(t *testing.T) {
+	m := NewMultiReader(4, 2, newMockGeneratedReader(1, 16))
+	defer m.Close()
+	var resets atomic.Int32
+	m.hooks = prefetchHooks{hookResetWait: func() { resets.Add(1) }}
+
+	want, err := io.ReadAll(newMockGeneratedReader(1, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 2)
+	if _, err = io.ReadFull(m, p); err != nil {
+		t.Fatal(err)
+	}
+	// Окно — остаток первого блока [2, 4): позиция 4 находится сразу за ним, префетчер уже читает с неё
+	if _, err = m.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	p = make([]byte, 4)
+	if _, err = io.ReadFull(m, p); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, want[4:8]) {
+		t.Fatalf("после Seek прочитано %v, ожидалось %v", p, want[4:8])
+	}
+	if n := resets.Load(); n != 0 {
+		t.Fatalf("Seek в конец окна сбросил префетч %d раз", n)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+// Boundaries возвращает абсолютные границы источников: источник i занимает [b[i], b[i+1]) потока,
+// последний элемент равен Size. Срез — копия, его можно менять.
+// С WithLazySizes узнаёт размеры всех источников.
+func (m *MultiReader) Boundaries() []int64 {
+	return m.sizes.bounds()
+}
+
+// ReaderAt сопоставляет абсолютной позиции pos источник и смещение внутри него — например, чтобы сообщить,
+// в каком куске потока не сошлась контрольная сумма. Пустые источники пропускаются.
+// Для pos вне [0, Size) возвращает (-1, 0).
+func (m *MultiReader) ReaderAt(pos int64) (index int, localOffset int64) {
+	if pos < 0 || m.sizes.atEnd(pos) {
+		return -1, 0
+	}
+	idx := m.readerIndex(pos)
+	return idx, pos - m.sizes.start(idx)
+}
+
+// readerIndex возвращает индекс источника, содержащего абсолютную позицию pos, или len(m.readers), если pos >= Size.
+func (m *MultiReader) readerIndex(pos int64) int {
+	return m.sizes.index(pos)
+}
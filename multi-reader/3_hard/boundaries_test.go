@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestBoundaries(t *testing.T) {
+	m := NewMultiReader(4, 2, testutil.NewStringsReader("0123"), testutil.NewStringsReader(""), testutil.NewStringsReader("abcdef"))
+	defer m.Close()
+
+	got := m.Boundaries()
+	if want := []int64{0, 4, 4, 10}; !slices.Equal(got, want) {
+		t.Fatalf("Boundaries() = %v, ожидалось %v", got, want)
+	}
+	got[1] = 100 // Копия: внутренние границы не меняются
+	if idx, off := m.ReaderAt(5); idx != 2 || off != 1 {
+		t.Fatalf("после изменения копии ReaderAt(5) = (%d, %d), ожидалось (2, 1)", idx, off)
+	}
+}
+
+func TestReaderAt(t *testing.T) {
+	m := NewMultiReader(4, 2, testutil.NewStringsReader("0123"), testutil.NewStringsReader(""), testutil.NewStringsReader("abcdef"))
+	defer m.Close()
+
+	tests := []struct {
+		pos     int64
+		wantIdx int
+		wantOff int64
+	}{
+		{0, 0, 0},
+		{3, 0, 3},
+		{4, 2, 0}, // Пустой источник 1 пропускается
+		{9, 2, 5},
+		{10, -1, 0},
+		{-1, -1, 0},
+	}
+	for _, tt := range tests {
+		idx, off := m.ReaderAt(tt.pos)
+		if idx != tt.wantIdx || off != tt.wantOff {
+			t.Errorf("ReaderAt(%d) = (%d, %d), ожидалось (%d, %d)", tt.pos, idx, off, tt.wantIdx, tt.wantOff)
+		}
+	}
+}
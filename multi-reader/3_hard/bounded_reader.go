@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrBoundExceeded — источник отдал больше данных, чем заявленный размер.
+var ErrBoundExceeded = errors.New("source exceeds declared size")
+
+// BoundedReader — строгий аналог io.LimitReader: отдаёт ровно n байт источника и сообщает об ошибке,
+// если источник длиннее (ErrBoundExceeded) или короче (io.ErrUnexpectedEOF) заявленного.
+// Защищает префиксные суммы MultiReader от сегментов, чей Size не соответствует содержимому:
+// MultiReader читает сегмент ровно до Size, поэтому лишние байты проверяются сразу при достижении границы.
+type BoundedReader struct {
+	r        io.ReadSeekCloser
+	n        int64 // заявленный размер
+	pos      int64 // позиция в пределах [0, n]
+	exceeded bool  // источник уже отдал байт за границей: ошибка возвращается при каждом чтении на границе
+}
+
+// Проверка, что BoundedReader удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*BoundedReader)(nil)
+
+// NewBoundedReader ограничивает r заявленным размером n. Источник должен стоять в начале.
+func NewBoundedReader(r io.ReadSeekCloser, n int64) *BoundedReader {
+	return &BoundedReader{r: r, n: n}
+}
+
+// Read читает не дальше границы n. Чтение, доходящее до границы, проверяет, что у источника больше нет данных.
+func (br *BoundedReader) Read(p []byte) (int, error) {
+	if br.pos >= br.n {
+		return 0, br.checkEnd()
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if rest := br.n - br.pos; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+	n, err := br.r.Read(p)
+	br.pos += int64(n)
+	switch {
+	case errors.Is(err, io.EOF) && br.pos < br.n:
+		return n, fmt.Errorf("source ended at %d of declared %d bytes: %w", br.pos, br.n, io.ErrUnexpectedEOF)
+	case err != nil && !errors.Is(err, io.EOF):
+		return n, err
+	case br.pos == br.n:
+		return n, br.checkEnd()
+	}
+	return n, nil
+}
+
+// Seek перемещает позицию в пределах [0, n].
+func (br *BoundedReader) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		target += br.pos
+	case io.SeekEnd:
+		target += br.n
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if target < 0 || target > br.n {
+		return 0, fmt.Errorf("seek position (%d) should be >= 0 and <= size (%d)", target, br.n)
+	}
+
+	_, err := br.r.Seek(target, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	br.pos = target
+	return target, nil
+}
+
+// Size возвращает заявленный размер n.
+func (br *BoundedReader) Size() int64 {
+	return br.n
+}
+
+// Close закрывает источник.
+func (br *BoundedReader) Close() error {
+	return br.r.Close()
+}
+
+// checkEnd пробует прочитать байт за границей: io.EOF, если его нет, иначе ErrBoundExceeded.
+func (br *BoundedReader) checkEnd() error {
+	if br.exceeded {
+		return ErrBoundExceeded
+	}
+	var probe [1]byte
+	n, err := br.r.Read(probe[:])
+	if n > 0 {
+		br.exceeded = true
+		return ErrBoundExceeded
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return io.EOF
+}
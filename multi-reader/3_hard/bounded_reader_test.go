@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestBoundedReader_ExactSize(t *testing.T) {
+	br := NewBoundedReader(testutil.NewStringsReader("abcdef"), 6)
+	got, err := io.ReadAll(br)
+	if err != nil || string(got) != "abcdef" || br.Size() != 6 {
+		t.Fatalf("ReadAll: %q, %v, Size=%d", got, err, br.Size())
+	}
+}
+
+func TestBoundedReader_SourceLongerThanBound(t *testing.T) {
+	br := NewBoundedReader(testutil.NewStringsReader("abcdefgh"), 6)
+
+	buf := make([]byte, 10)
+	n, err := br.Read(buf)
+	if n != 6 || !errors.Is(err, ErrBoundExceeded) {
+		t.Fatalf("Read: n=%d, err=%v; ожидались 6 байт и ErrBoundExceeded", n, err)
+	}
+	if _, err = br.Read(buf); !errors.Is(err, ErrBoundExceeded) {
+		t.Fatalf("повторный Read на границе: %v, ожидалась ErrBoundExceeded", err)
+	}
+}
+
+func TestBoundedReader_SourceShorterThanBound(t *testing.T) {
+	br := NewBoundedReader(testutil.NewStringsReader("abc"), 6)
+	got, err := io.ReadAll(br)
+	if string(got) != "abc" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("ReadAll: %q, %v; ожидалась io.ErrUnexpectedEOF", got, err)
+	}
+}
+
+func TestBoundedReader_Seek(t *testing.T) {
+	br := NewBoundedReader(testutil.NewStringsReader("0123456789"), 6)
+	if _, err := br.Seek(7, io.SeekStart); err == nil {
+		t.Fatalf("Seek за границу должен возвращать ошибку")
+	}
+	pos, err := br.Seek(-2, io.SeekEnd)
+	if err != nil || pos != 4 {
+		t.Fatalf("Seek от конца: pos=%d, err=%v", pos, err)
+	}
+	buf := make([]byte, 4)
+	n, err := br.Read(buf)
+	if string(buf[:n]) != "45" || !errors.Is(err, ErrBoundExceeded) {
+		t.Fatalf("Read у границы: %q, %v", buf[:n], err)
+	}
+}
+
+// lyingSegment заявляет размер меньше фактического содержимого.
+type lyingSegment struct {
+	*testutil.StringsReader
+	size int64
+}
+
+func (l lyingSegment) Size() int64 { return l.size }
+
+func TestBoundedReader_DefendsMultiReader(t *testing.T) {
+	lying := lyingSegment{StringsReader: testutil.NewStringsReader(strings.Repeat("b", 30)), size: 20}
+
+	// Без защиты MultiReader молча обрезает сегмент по заявленному размеру
+	m := NewMultiReader(8, 2, testutil.NewStringsReader("aaaa"), lying)
+	got, err := io.ReadAll(m)
+	if err != nil || len(got) != 24 {
+		t.Fatalf("без BoundedReader: %d байт, %v", len(got), err)
+	}
+	_ = m.Close()
+
+	lying = lyingSegment{StringsReader: testutil.NewStringsReader(strings.Repeat("b", 30)), size: 20}
+	m = NewMultiReader(8, 2, testutil.NewStringsReader("aaaa"), NewBoundedReader(lying, lying.Size()))
+	defer m.Close()
+	if _, err = io.ReadAll(m); !errors.Is(err, ErrBoundExceeded) {
+		t.Fatalf("с BoundedReader: %v, ожидалась ErrBoundExceeded", err)
+	}
+}
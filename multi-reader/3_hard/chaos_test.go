@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+var (
+	chaosRuns = flag.Int("chaos.runs", 30, "число прогонов хаос-теста MultiReader")
+	chaosSeed = flag.Int64("chaos.seed", 0, "seed первого прогона хаос-теста; 0 — текущее время")
+)
+
+// chaosTimeout — за сколько должен завершиться один прогон; дольше — считаем, что MultiReader завис.
+const chaosTimeout = 10 * time.Second
+
+// chaosMonkey со второй горутины вмешивается в чтение MultiReader: делает Seek за пределы окна и
+// в случайный момент закрывает ридер. Вмешательства происходят как по таймеру, так и точно посреди Read —
+// через hookBlockReceived, когда Read уже получил блок от префетчера, но ещё не добавил его в окно.
+type chaosMonkey struct {
+	m       *MultiReader
+	rnd     *rand.Rand // используется только горутиной run
+	hookRnd *rand.Rand // используется только из hook (горутина Read)
+	size    int64
+	trigger chan chan struct{} // запрос вмешательства посреди Read; закрытие ответа — вмешательство выполнено
+	done    chan struct{}      // закрывается после Close
+	errs    chan error
+}
+
+func newChaosMonkey(m *MultiReader, seed int64) *chaosMonkey {
+	cm := &chaosMonkey{
+		m:       m,
+		rnd:     rand.New(rand.NewSource(seed)),
+		hookRnd: rand.New(rand.NewSource(^seed)),
+		size:    m.Size(),
+		trigger: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		errs:    make(chan error, 16),
+	}
+	m.hooks = prefetchHooks{hookBlockReceived: cm.hook}
+	return cm
+}
+
+// hook с вероятностью 1/4 останавливает Read посреди обработки блока до завершения вмешательства.
+func (cm *chaosMonkey) hook() {
+	if cm.hookRnd.Intn(4) != 0 {
+		return
+	}
+	ack := make(chan struct{})
+	select {
+	case cm.trigger <- ack:
+		<-ack
+	case <-cm.done:
+	}
+}
+
+// run выполняет вмешательства, пока не закроет ридер.
+func (cm *chaosMonkey) run() {
+	defer close(cm.done)
+	defer cm.recoverPanic("chaos")
+	for {
+		var ack chan struct{}
+		select {
+		case ack = <-cm.trigger:
+		case <-time.After(time.Duration(cm.rnd.Intn(300)) * time.Microsecond):
+		}
+		closed := cm.act()
+		if ack != nil {
+			close(ack)
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// act выполняет одно вмешательство и сообщает, был ли ридер закрыт.
+func (cm *chaosMonkey) act() bool {
+	if cm.rnd.Intn(40) == 0 {
+		err := cm.m.Close()
+		if err != nil {
+			cm.report(fmt.Errorf("Close: %w", err))
+		}
+		return true
+	}
+	pos := cm.rnd.Int63n(cm.size + 1)
+	got, err := cm.m.Seek(pos, io.SeekStart)
+	if err != nil {
+		cm.report(fmt.Errorf("Seek(%d): %w", pos, err))
+	} else if got != pos {
+		cm.report(fmt.Errorf("Seek(%d) = %d", pos, got))
+	}
+	return false
+}
+
+// read читает ридер, пока его не закроют, проверяя, что каждый вызов возвращает определённый результат.
+func (cm *chaosMonkey) read() {
+	defer cm.recoverPanic("read")
+	buf := make([]byte, 700)
+	for {
+		size := cm.hookRnd.Intn(len(buf)) + 1
+		n, err := cm.m.Read(buf[:size])
+		switch {
+		case n < 0 || n > size:
+			cm.report(fmt.Errorf("Read returned n=%d for %d-byte buffer", n, size))
+			return
+		case errors.Is(err, io.ErrClosedPipe):
+			cm.checkClosed()
+			return
+		case errors.Is(err, io.EOF):
+			_, err = cm.m.Seek(0, io.SeekStart)
+			if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				cm.report(fmt.Errorf("Seek after EOF: %w", err))
+				return
+			}
+		case err != nil:
+			cm.report(fmt.Errorf("Read: unexpected error %w", err))
+			return
+		}
+		for _, b := range buf[:n] {
+			if b >= 251 {
+				cm.report(errors.New("Read returned byte outside of content"))
+				return
+			}
+		}
+	}
+}
+
+// checkClosed проверяет, что после Close все операции сразу возвращают io.ErrClosedPipe.
+func (cm *chaosMonkey) checkClosed() {
+	if n, err := cm.m.Read(make([]byte, 8)); n != 0 || !errors.Is(err, io.ErrClosedPipe) {
+		cm.report(fmt.Errorf("Read after Close = (%d, %v), want (0, ErrClosedPipe)", n, err))
+	}
+	if _, err := cm.m.Seek(0, io.SeekStart); !errors.Is(err, io.ErrClosedPipe) {
+		cm.report(fmt.Errorf("Seek after Close: %v, want ErrClosedPipe", err))
+	}
+	if err := cm.m.Close(); err != nil {
+		cm.report(fmt.Errorf("repeated Close: %w", err))
+	}
+}
+
+// report запоминает ошибку; сверх ёмкости канала ошибки отбрасываются, чтобы не блокировать горутины.
+func (cm *chaosMonkey) report(err error) {
+	select {
+	case cm.errs <- err:
+	default:
+	}
+}
+
+func (cm *chaosMonkey) recoverPanic(who string) {
+	if r := recover(); r != nil {
+		cm.report(fmt.Errorf("panic in %s goroutine: %v\n%s", who, r, debug.Stack()))
+	}
+}
+
+// TestChaos_ConcurrentCloseAndSeekDuringRead проверяет, что MultiReader не зависает, не паникует
+// и возвращает определённые ошибки при Close и Seek из другой горутины посреди Read.
+// Воспроизведение: go test -race -run Chaos -chaos.seed=<seed> -chaos.runs=1
+func TestChaos_ConcurrentCloseAndSeekDuringRead(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	seed := *chaosSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	runs := *chaosRuns
+	if testing.Short() {
+		runs = min(runs, 5)
+	}
+
+	content := stressContent(20_000)
+	for run := 0; run < runs; run++ {
+		runSeed := seed + int64(run)
+		segRnd := rand.New(rand.NewSource(runSeed))
+		var segments []SizedReadSeekCloser
+		for rest := content; len(rest) > 0; {
+			n := min(len(rest), segRnd.Intn(3000)+1)
+			segments = append(segments, newMockLatencyReader(rest[:n], 20*time.Microsecond, 0, 50*time.Microsecond))
+			rest = rest[n:]
+		}
+		m := NewMultiReader(int64(segRnd.Intn(512)+1), segRnd.Intn(4)+1, segments...)
+		cm := newChaosMonkey(m, runSeed)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); cm.run() }()
+		go func() { defer wg.Done(); cm.read() }()
+
+		finished := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(finished)
+		}()
+		select {
+		case <-finished:
+		case <-time.After(chaosTimeout):
+			stack := make([]byte, 1<<20)
+			stack = stack[:runtime.Stack(stack, true)]
+			t.Fatalf("хаос-тест завис (seed %d):\n%s", runSeed, stack)
+		}
+		close(cm.errs)
+		for err := range cm.errs {
+			t.Errorf("seed %d: %v", runSeed, err)
+		}
+		if t.Failed() {
+			return
+		}
+	}
+}
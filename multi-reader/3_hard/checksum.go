@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// WithChecksum проверяет дайджест всего потока: MultiReader хеширует данные h по мере того, как они покидают окно,
+// и, когда прочитан последний байт, сверяет дайджест с expected. При несовпадении этот и все последующие
+// Read в конце потока вместо io.EOF возвращают *ChecksumError (errors.Is(err, ErrChecksumMismatch) == true).
+//
+// Seek поддерживается: каждый байт хешируется один раз, когда чтение впервые до него доходит, а перечитанные
+// после Seek назад данные не хешируются повторно. Пропущенный через Seek вперёд участок откладывает проверку,
+// пока его не прочитают. С проверкой WriteTo не использует быстрый путь EnableZeroCopy.
+func WithChecksum(h hash.Hash, expected []byte) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.streamHash = h
+		o.streamSum = expected
+	}
+}
+
+// WithSegmentChecksums проверяет дайджест каждого источника: expected[i] сверяется, когда дочитан последний байт
+// источника i; nil в expected пропускает проверку источника. Хеш каждого источника создаёт newHash
+// (например, crc32.NewIEEE или sha256.New). Ошибка несовпадения называет источник: "reader i: checksum mismatch: ...",
+// и возвращается из Read, дочитавшего источник, а затем вместо io.EOF в конце потока. Seek — как в WithChecksum.
+func WithSegmentChecksums(newHash func() hash.Hash, expected ...[]byte) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.segmentHash = newHash
+		o.segmentSums = expected
+	}
+}
+
+// checksums хеширует данные MultiReader по мере чтения и сверяет дайджесты. Все методы вызываются под m.mu;
+// nil — проверка выключена.
+type checksums struct {
+	sizes *layout // границы источников MultiReader
+
+	stream    hash.Hash // дайджест всего потока; nil — не проверяется
+	streamSum []byte
+	segment   hash.Hash // дайджест текущего источника; nil — источники не проверяются
+	segSums   [][]byte
+
+	pos int64 // сколько байт с начала потока уже захешировано
+	idx int   // текущий источник
+	err error // первое несовпадение; возвращается вместо io.EOF
+}
+
+func newChecksums(o multiReaderOptions, sizes *layout) (*checksums, error) {
+	if o.streamHash == nil && o.segmentHash == nil {
+		return nil, nil
+	}
+	s := &checksums{sizes: sizes, stream: o.streamHash, streamSum: o.streamSum}
+	if o.segmentHash != nil {
+		if len(o.segmentSums) != len(sizes.readers) {
+			return nil, fmt.Errorf("got %d segment checksums for %d readers", len(o.segmentSums), len(sizes.readers))
+		}
+		s.segment = o.segmentHash()
+		s.segSums = o.segmentSums
+	}
+	return s, nil
+}
+
+// update хеширует b, прочитанные с абсолютной позиции pos, и сверяет дайджесты источников и потока,
+// которые b дочитывают. Уже захешированная часть b пропускается; если между захешированным и pos есть
+// пропуск, b не хешируется вовсе. Возвращает новое несовпадение, если оно обнаружено.
+func (s *checksums) update(pos int64, b []byte) error {
+	if s == nil || pos > s.pos || pos+int64(len(b)) < s.pos {
+		return nil
+	}
+	b = b[s.pos-pos:]
+
+	var errs []error
+	readers := len(s.sizes.readers)
+	for s.idx < readers && (len(b) > 0 || s.pos == s.sizes.end(s.idx)) {
+		end := s.sizes.end(s.idx)
+		k := min(int64(len(b)), end-s.pos)
+		if s.stream != nil {
+			s.stream.Write(b[:k])
+		}
+		if s.segment != nil {
+			s.segment.Write(b[:k])
+		}
+		s.pos += k
+		b = b[k:]
+		if s.pos == end { // Источник дочитан
+			if s.segment != nil {
+				err := verifySum(s.segment, s.segSums[s.idx])
+				if err != nil {
+					errs = append(errs, fmt.Errorf("reader %d: %w", s.idx, err))
+				}
+				s.segment.Reset()
+			}
+			s.idx++
+		}
+	}
+	if s.idx == readers && s.stream != nil { // Поток дочитан: сверяем один раз
+		err := verifySum(s.stream, s.streamSum)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		s.stream = nil
+	}
+
+	err := errors.Join(errs...)
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	return err
+}
+
+// eof возвращает ошибку конца потока: первое несовпадение дайджеста или io.EOF.
+func (s *checksums) eof() error {
+	if s == nil {
+		return io.EOF
+	}
+	s.update(s.pos, nil) // Пустые источники в конце потока сверяются, когда до них дошли
+	if s.err != nil {
+		return s.err
+	}
+	return io.EOF
+}
+
+// verifySum сверяет дайджест h с expected; expected == nil — без проверки.
+func verifySum(h hash.Hash, expected []byte) error {
+	if expected == nil {
+		return nil
+	}
+	actual := h.Sum(nil)
+	if !bytes.Equal(actual, expected) {
+		return &ChecksumError{Expected: expected, Actual: actual}
+	}
+	return nil
+}
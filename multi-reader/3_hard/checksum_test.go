@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+var checksumParts = []string{"0123", "4567", "", "89ab"}
+
+// checksumReaders возвращает источники checksumParts.
+func checksumReaders() []SizedReadSeekCloser {
+	var readers []SizedReadSeekCloser
+	for _, part := range checksumParts {
+		readers = append(readers, testutil.NewStringsReader(part))
+	}
+	return readers
+}
+
+func crc32Of(s string) []byte {
+	h := crc32.NewIEEE()
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+func newCRC32() hash.Hash { return crc32.NewIEEE() }
+
+// newChecksumReader создаёт MultiReader над checksumParts с блоком 3 байта и опциями opts.
+func newChecksumReader(t *testing.T, opts ...MultiReaderOption) *MultiReader {
+	t.Helper()
+	m, err := NewMultiReaderWithOptions(checksumReaders(), append([]MultiReaderOption{WithBlockSize(3), WithBuffersNum(2)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestChecksum_Stream(t *testing.T) {
+	content := strings.Join(checksumParts, "")
+
+	m := newChecksumReader(t, WithChecksum(sha256.New(), sha256Of(content)))
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != content {
+		t.Fatalf("ReadAll с верным дайджестом: %q, %v", got, err)
+	}
+
+	m = newChecksumReader(t, WithChecksum(sha256.New(), sha256Of("something else")))
+	got, err = io.ReadAll(m)
+	if !errors.Is(err, ErrChecksumMismatch) || string(got) != content {
+		t.Fatalf("ReadAll с неверным дайджестом: %q, %v; ожидались все данные и ErrChecksumMismatch", got, err)
+	}
+	if _, err = m.Read(make([]byte, 1)); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Read после конца потока: %v, ожидалась та же ошибка вместо io.EOF", err)
+	}
+}
+
+func TestChecksum_SegmentMismatchNamesReader(t *testing.T) {
+	sums := [][]byte{crc32Of("0123"), crc32Of("bad!"), crc32Of(""), nil}
+	m := newChecksumReader(t, WithSegmentChecksums(newCRC32, sums...))
+
+	var read int
+	buf := make([]byte, 3)
+	var err error
+	for err == nil {
+		var n int
+		n, err = m.Read(buf)
+		read += n
+	}
+	var ce *ChecksumError
+	if !errors.As(err, &ce) || !strings.Contains(err.Error(), "reader 1:") {
+		t.Fatalf("ошибка %v, ожидалась *ChecksumError источника 1", err)
+	}
+	if read != 8 {
+		t.Fatalf("ошибка после %d байт, ожидалась сразу по окончании источника 1 (8 байт)", read)
+	}
+}
+
+func TestChecksum_SurvivesSeek(t *testing.T) {
+	content := strings.Join(checksumParts, "")
+	m := newChecksumReader(t, WithChecksum(sha256.New(), sha256Of(content)))
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(m, buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Seek(2, io.SeekStart); err != nil { // Перечитанное не хешируется повторно
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != content[2:] {
+		t.Fatalf("чтение после Seek назад: %q, %v", got, err)
+	}
+}
+
+func TestChecksum_SkippedRangeDefersVerification(t *testing.T) {
+	m := newChecksumReader(t, WithChecksum(sha256.New(), sha256Of("something else")))
+
+	if _, err := m.Seek(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(m); err != nil {
+		t.Fatalf("ReadAll с пропуском: %v, ожидался io.EOF без проверки", err)
+	}
+	if _, err := m.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(m); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("ReadAll после чтения пропуска: %v, ожидалась ErrChecksumMismatch", err)
+	}
+}
+
+func TestChecksum_DirectReadAndWriteTo(t *testing.T) {
+	content := strings.Join(checksumParts, "")
+	wrong := WithChecksum(sha256.New(), sha256Of("something else"))
+
+	m := newChecksumReader(t, wrong, WithoutReadAhead())
+	if _, err := io.ReadAll(m); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("ReadAll без префетча: %v, ожидалась ErrChecksumMismatch", err)
+	}
+
+	m = newChecksumReader(t, WithChecksum(sha256.New(), sha256Of("something else")))
+	m.EnableZeroCopy()
+	var sb strings.Builder
+	n, err := m.WriteTo(&sb)
+	if !errors.Is(err, ErrChecksumMismatch) || sb.String() != content || n != int64(len(content)) {
+		t.Fatalf("WriteTo: %d, %q, %v; ожидались все данные и ErrChecksumMismatch", n, sb.String(), err)
+	}
+}
+
+func TestChecksum_InvalidOptions(t *testing.T) {
+	_, err := NewMultiReaderWithOptions(checksumReaders(), WithSegmentChecksums(newCRC32, crc32Of("0123")))
+	if err == nil {
+		t.Fatal("ожидалась ошибка: дайджестов меньше, чем источников")
+	}
+}
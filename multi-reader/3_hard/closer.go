@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CloseAll закрывает closers по порядку, даже если какие-то из них вернули ошибку, и возвращает
+// errors.Join всех ошибок с номером источника: "close <name> <i>: ...". nil-элементы пропускаются.
+func CloseAll[C io.Closer](closers []C, name string) error {
+	var errs []error
+	for i, c := range closers {
+		if any(c) == nil {
+			continue
+		}
+		err := c.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("close %s %d: %w", name, i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CloseAllParallel закрывает closers одновременно — для источников с медленным Close (сетевых соединений).
+// Ждёт, пока закроются все или завершится ctx; в последнем случае к ошибкам добавляется ctx.Err()
+// с числом незакрытых, а их Close продолжают выполняться в фоне. Ошибки упорядочены по номеру источника.
+func CloseAllParallel[C io.Closer](ctx context.Context, closers []C, name string) error {
+	type result struct {
+		i   int
+		err error
+	}
+	results := make(chan result, len(closers)) // Буфер: опоздавшие Close не блокируются после выхода
+	pending := 0
+	for i, c := range closers {
+		if any(c) == nil {
+			continue
+		}
+		pending++
+		go func() {
+			results <- result{i: i, err: c.Close()}
+		}()
+	}
+
+	errs := make([]error, len(closers), len(closers)+1)
+	for ; pending > 0; pending-- {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				errs[r.i] = fmt.Errorf("close %s %d: %w", name, r.i, r.err)
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("close %s: %d still pending: %w", name, pending, ctx.Err()))
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCloser считает вызовы Close, возвращает err и может ждать release перед возвратом.
+type testCloser struct {
+	err     error
+	release chan struct{}
+	closed  atomic.Int32
+}
+
+func (c *testCloser) Close() error {
+	if c.release != nil {
+		<-c.release
+	}
+	c.closed.Add(1)
+	return c.err
+}
+
+func TestCloseAll_ClosesEverythingAndJoinsErrors(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	closers := []io.Closer{&testCloser{err: errA}, &testCloser{}, nil, &testCloser{err: errB}}
+
+	err := CloseAll(closers, "reader")
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("CloseAll: %v, ожидались обе ошибки", err)
+	}
+	if !strings.Contains(err.Error(), "close reader 0") || !strings.Contains(err.Error(), "close reader 3") {
+		t.Fatalf("ошибки без номеров источников: %v", err)
+	}
+	for i, c := range closers {
+		if c != nil && c.(*testCloser).closed.Load() != 1 {
+			t.Fatalf("источник %d не закрыт", i)
+		}
+	}
+	if err := CloseAll([]io.Closer{&testCloser{}}, "reader"); err != nil {
+		t.Fatalf("CloseAll без ошибок: %v", err)
+	}
+}
+
+func TestCloseAllParallel_ClosesConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	errB := errors.New("b failed")
+	closers := []*testCloser{{release: release}, {release: release, err: errB}, {release: release}}
+
+	done := make(chan error, 1)
+	go func() { done <- CloseAllParallel(context.Background(), closers, "segment") }()
+	// Все Close ждут одного сигнала: последовательное закрытие здесь бы зависло
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errB) || !strings.Contains(err.Error(), "close segment 1") {
+			t.Fatalf("CloseAllParallel: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("CloseAllParallel не завершился")
+	}
+	for i, c := range closers {
+		if c.closed.Load() != 1 {
+			t.Fatalf("источник %d не закрыт", i)
+		}
+	}
+}
+
+func TestCloseAllParallel_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	errFast := errors.New("fast failed")
+	closers := []*testCloser{{err: errFast}, {release: release}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := CloseAllParallel(ctx, closers, "segment")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errFast) {
+		t.Fatalf("CloseAllParallel: %v, ожидались ошибка быстрого источника и дедлайн", err)
+	}
+	if !strings.Contains(err.Error(), "1 still pending") {
+		t.Fatalf("в ошибке нет числа незакрытых: %v", err)
+	}
+}
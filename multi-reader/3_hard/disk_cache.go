@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zlatoivan/go-advanced/diskcache"
+)
+
+// EnableDiskCache подключает дисковый кэш как второй уровень префетча: блоки сегментов ищутся в cache
+// по ключу (sourceIDs[i], смещение блока) и только при промахе читаются из источника и сохраняются в кэш.
+// Идентификаторы должны однозначно определять содержимое сегмента (например, URL с версией объекта),
+// иначе после изменения источника кэш отдаст старые данные.
+//
+// С кэшем префетчер читает блоки, выровненные по bufferSize внутри сегмента, чтобы ключи не зависели от позиций Seek.
+func (m *MultiReader) EnableDiskCache(cache *diskcache.Cache, sourceIDs ...string) error {
+	if len(sourceIDs) != len(m.readers) {
+		return fmt.Errorf("got %d source ids for %d readers", len(sourceIDs), len(m.readers))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return io.ErrClosedPipe
+	}
+
+	m.resetPrefetch() // Префетчер читает поля кэша без блокировки: подменяем их, только когда он остановлен
+	m.cache = cache
+	m.cacheIDs = append([]string(nil), sourceIDs...)
+	return nil
+}
+
+// readCached читает выровненный блок сегмента idx, содержащий локальную позицию local, из кэша или из источника,
+// и возвращает его часть начиная с local. Источник уже спозиционирован на local.
+// Ошибки кэша не прерывают чтение: кэш — лишь ускорение. Только промах ждёт очереди арбитра, ctx прерывает ожидание.
+func (m *MultiReader) readCached(ctx context.Context, idx int, local int64) (buf []byte, n int, err error) {
+	start := local - local%m.bufferSize
+	size := min(m.bufferSize, m.readers[idx].Size()-start)
+	key := diskcache.Key{Source: m.cacheIDs[idx], Offset: start}
+	buf = m.pool.Get(int(size))
+
+	n, ok := m.cache.Get(key, buf)
+	if !ok || n != int(size) {
+		err = m.arbiter.acquireBlock(ctx, len(buf))
+		if err != nil {
+			return buf, 0, err
+		}
+		reader := m.readers[idx]
+		if start != local {
+			_, err = reader.Seek(start, io.SeekStart)
+			if err != nil {
+				return buf, 0, err
+			}
+		}
+		n, err = io.ReadFull(reader, buf)
+		switch {
+		case err == nil:
+			_ = m.cache.Put(key, buf)
+		case errors.Is(err, io.ErrUnexpectedEOF): // Источник короче заявленного: как и без кэша, переходим к следующему
+			err = io.EOF
+		}
+	}
+
+	skip := int(local - start)
+	if n <= skip {
+		return buf, 0, err
+	}
+	return buf, copy(buf, buf[skip:n]), err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/diskcache"
+)
+
+// newCachedMultiReader создаёт MultiReader над детерминированными сегментами с метрикой чтений из источника
+// и дисковым кэшем в dir. Каждый вызов открывает кэш заново, как после перезапуска процесса.
+func newCachedMultiReader(t *testing.T, dir string, sizes ...int64) *MultiReader {
+	t.Helper()
+	cache, err := diskcache.Open(diskcache.Config{Dir: dir, MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("diskcache.Open: %v", err)
+	}
+	readers := make([]SizedReadSeekCloser, len(sizes))
+	ids := make([]string, len(sizes))
+	for i, size := range sizes {
+		readers[i] = newMockGeneratedReader(uint64(i+1), size)
+		ids[i] = string(rune('a' + i))
+	}
+	m := NewMultiReader(64, 2, readers...)
+	m.EnableMetering(0)
+	if err = m.EnableDiskCache(cache, ids...); err != nil {
+		t.Fatalf("EnableDiskCache: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+// originBytes возвращает число байт, прочитанных из источников.
+func originBytes(m *MultiReader) int64 {
+	var total int64
+	for _, s := range m.SegmentStats() {
+		total += s.Bytes
+	}
+	return total
+}
+
+func TestDiskCache_SecondRunSkipsOrigin(t *testing.T) {
+	dir := t.TempDir()
+	sizes := []int64{1000, 37, 500}
+
+	first := newCachedMultiReader(t, dir, sizes...)
+	want, err := io.ReadAll(first)
+	if err != nil {
+		t.Fatalf("первое чтение: %v", err)
+	}
+	if got := originBytes(first); got != first.Size() {
+		t.Fatalf("первое чтение: из источника %d байт, ожидалось %d", got, first.Size())
+	}
+
+	second := newCachedMultiReader(t, dir, sizes...)
+	got, err := io.ReadAll(second)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("чтение из кэша: %d байт, %v; данные совпадают: %v", len(got), err, bytes.Equal(got, want))
+	}
+	if n := originBytes(second); n != 0 {
+		t.Fatalf("после перезапуска из источника прочитано %d байт, ожидалось 0", n)
+	}
+}
+
+func TestDiskCache_UnalignedSeeks(t *testing.T) {
+	dir := t.TempDir()
+	sizes := []int64{300, 129, 700}
+	plain := NewMultiReader(64, 2, newMockGeneratedReader(1, 300), newMockGeneratedReader(2, 129), newMockGeneratedReader(3, 700))
+	defer plain.Close()
+	want, err := io.ReadAll(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for run := 0; run < 2; run++ { // Второй проход идёт по уже заполненному кэшу
+		m := newCachedMultiReader(t, dir, sizes...)
+		for i := 0; i < 50; i++ {
+			pos := rnd.Int63n(m.Size())
+			n := min(int64(1+rnd.Intn(200)), m.Size()-pos)
+			if _, err := m.Seek(pos, io.SeekStart); err != nil {
+				t.Fatalf("Seek(%d): %v", pos, err)
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(m, buf); err != nil {
+				t.Fatalf("ReadFull с %d: %v", pos, err)
+			}
+			if !bytes.Equal(buf, want[pos:pos+n]) {
+				t.Fatalf("проход %d: данные с позиции %d не совпадают", run, pos)
+			}
+		}
+	}
+}
+
+func TestEnableDiskCache_Validates(t *testing.T) {
+	cache, err := diskcache.Open(diskcache.Config{Dir: t.TempDir(), MaxBytes: 1 << 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMultiReader(8, 2, newMockGeneratedReader(1, 10), newMockGeneratedReader(2, 10))
+	if err = m.EnableDiskCache(cache, "only-one"); err == nil {
+		t.Fatalf("число идентификаторов должно совпадать с числом ридеров")
+	}
+	_ = m.Close()
+	if err = m.EnableDiskCache(cache, "a", "b"); err != io.ErrClosedPipe {
+		t.Fatalf("EnableDiskCache после Close: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// NewMultiReaderFromFiles создаёт MultiReader над файлами paths в указанном порядке. Размеры берутся из Stat
+// при создании, а файлы открываются лениво — при первом чтении или Seek сегмента, поэтому длинный список
+// не держит открытыми дескрипторы ещё не прочитанных частей. Ошибка открытия возвращается из чтения.
+// Быстрый путь EnableZeroCopy для таких сегментов не используется.
+func NewMultiReaderFromFiles(paths []string, opts ...MultiReaderOption) (*MultiReader, error) {
+	readers := make([]SizedReadSeekCloser, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat file %d: %w", i, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("file %d (%s) is not a regular file", i, path)
+		}
+		readers[i] = &lazySegment{name: path, size: info.Size(), open: func() (io.ReadSeekCloser, error) {
+			return os.Open(path)
+		}}
+	}
+	return NewMultiReaderWithOptions(readers, opts...)
+}
+
+// NewMultiReaderFromFS — NewMultiReaderFromFiles для файлов names файловой системы fsys.
+// Файлы fsys должны поддерживать Seek (как у os.DirFS, embed.FS и fstest.MapFS).
+func NewMultiReaderFromFS(fsys fs.FS, names []string, opts ...MultiReaderOption) (*MultiReader, error) {
+	readers := make([]SizedReadSeekCloser, len(names))
+	for i, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("stat file %d: %w", i, err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("file %d (%s) is not a regular file", i, name)
+		}
+		readers[i] = &lazySegment{name: name, size: info.Size(), open: func() (io.ReadSeekCloser, error) {
+			f, err := fsys.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			rs, ok := f.(io.ReadSeekCloser)
+			if !ok {
+				f.Close()
+				return nil, fmt.Errorf("file %s does not support seeking", name)
+			}
+			return rs, nil
+		}}
+	}
+	return NewMultiReaderWithOptions(readers, opts...)
+}
+
+// lazySegment — сегмент с известным размером, который открывает свой источник при первом обращении.
+// MultiReader обращается к сегменту под его замком источника, поэтому отдельная синхронизация не нужна.
+type lazySegment struct {
+	name string
+	size int64
+	open func() (io.ReadSeekCloser, error)
+	f    io.ReadSeekCloser // nil — ещё не открыт
+}
+
+// Проверка, что lazySegment удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*lazySegment)(nil)
+
+func (s *lazySegment) Read(p []byte) (int, error) {
+	f, err := s.file()
+	if err != nil {
+		return 0, err
+	}
+	return f.Read(p)
+}
+
+func (s *lazySegment) Seek(offset int64, whence int) (int64, error) {
+	f, err := s.file()
+	if err != nil {
+		return 0, err
+	}
+	return f.Seek(offset, whence)
+}
+
+// Size возвращает размер файла на момент создания сегмента.
+func (s *lazySegment) Size() int64 {
+	return s.size
+}
+
+// Close закрывает файл, если он был открыт.
+func (s *lazySegment) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// file открывает источник при первом обращении. Неудачное открытие повторяется при следующем.
+func (s *lazySegment) file() (io.ReadSeekCloser, error) {
+	if s.f == nil {
+		f, err := s.open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", s.name, err)
+		}
+		s.f = f
+	}
+	return s.f, nil
+}
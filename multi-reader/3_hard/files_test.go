@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// writeParts записывает части в файлы каталога dir и возвращает их пути.
+func writeParts(t *testing.T, dir string, parts ...string) []string {
+	t.Helper()
+	var paths []string
+	for i, part := range parts {
+		path := filepath.Join(dir, fmt.Sprintf("part%d", i))
+		if err := os.WriteFile(path, []byte(part), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestNewMultiReaderFromFiles(t *testing.T) {
+	paths := writeParts(t, t.TempDir(), "hello, ", "", "multi ", "reader")
+
+	m, err := NewMultiReaderFromFiles(paths, WithBlockSize(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Size() != 19 {
+		t.Fatalf("Size() = %d, ожидалось 19", m.Size())
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "hello, multi reader" {
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+}
+
+func TestNewMultiReaderFromFiles_OpensLazily(t *testing.T) {
+	paths := writeParts(t, t.TempDir(), "0123", "4567")
+
+	m, err := NewMultiReaderFromFiles(paths, WithBlockSize(4), WithoutReadAhead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err = os.Remove(paths[1]); err != nil { // Второй файл ещё не открыт: его отсутствие проявится только при чтении
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4)
+	if _, err = io.ReadFull(m, buf); err != nil || string(buf) != "0123" {
+		t.Fatalf("чтение первого файла: %q, %v", buf, err)
+	}
+	if _, err = m.Read(buf); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("чтение удалённого файла: %v, ожидалась fs.ErrNotExist", err)
+	}
+}
+
+func TestNewMultiReaderFromFiles_StatErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewMultiReaderFromFiles([]string{filepath.Join(dir, "missing")}); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("несуществующий файл: %v, ожидалась fs.ErrNotExist", err)
+	}
+	if _, err := NewMultiReaderFromFiles([]string{dir}); err == nil {
+		t.Fatal("каталог вместо файла: ожидалась ошибка")
+	}
+}
+
+func TestNewMultiReaderFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.bin":     {Data: []byte("abc")},
+		"dir/b.bin": {Data: []byte("defgh")},
+	}
+
+	m, err := NewMultiReaderFromFS(fsys, []string{"dir/b.bin", "a.bin"}, WithBlockSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err = m.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "ghabc" {
+		t.Fatalf("ReadAll после Seek: %q, %v", got, err)
+	}
+
+	if _, err = NewMultiReaderFromFS(fsys, []string{"dir"}); err == nil {
+		t.Fatal("каталог вместо файла: ожидалась ошибка")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+// flattenReaders подставляет вместо вложенных MultiReader их источники, чтобы на всё дерево работал один
+// префетчер, а не горутина и цепочка буферов на каждый уровень. Раскрываются только вложенные ридеры без
+// собственной настройки (см. flattenable); прочие остаются источниками как есть. Раскрытый ридер закрывается
+// без закрытия источников: ими теперь владеет родитель. Срез readers не меняется.
+func flattenReaders(readers []SizedReadSeekCloser) []SizedReadSeekCloser {
+	var flat []SizedReadSeekCloser
+	for i, r := range readers {
+		child, ok := r.(*MultiReader)
+		if !ok || !child.flattenable() {
+			if flat != nil {
+				flat = append(flat, r)
+			}
+			continue
+		}
+		if flat == nil {
+			flat = append(make([]SizedReadSeekCloser, 0, len(readers)), readers[:i]...)
+		}
+		flat = append(flat, child.readers...) // Вложенные в child ридеры раскрыты при его создании
+	}
+	if flat == nil {
+		return readers
+	}
+	return flat
+}
+
+// flattenable сообщает, что источники ридера можно передать родителю, и, если да, закрывает ридер, не трогая
+// источники: Read и Seek такого ридера возвращают io.ErrClosedPipe, а Close — nil. Подходят ридеры NewMultiReader,
+// из которых ещё не читали и для которых не включали метрики, дисковый кэш, проверку дайджестов и арбитр.
+func (m *MultiReader) flattenable() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed || m.windowStart != 0 || len(m.windowBuf) != 0 || m.pfBufCh != nil ||
+		m.meters != nil || m.cache != nil || m.sums != nil || m.arbiter != nil ||
+		m.readAhead != ReadAheadEager || m.minBlock != 0 {
+		return false
+	}
+	// Размеры источников с WithLazySizes узнаются по требованию, а раскрытие заставило бы узнать их сразу
+	if _, ok := m.sizes.known(); !ok {
+		return false
+	}
+	m.closed = true
+	return true
+}
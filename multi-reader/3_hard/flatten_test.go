@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestFlatten_NestedReadersSpliced(t *testing.T) {
+	inner := NewMultiReader(4, 2, testutil.NewStringsReader("bcd"), testutil.NewStringsReader("ef"))
+	middle := NewMultiReader(4, 2, testutil.NewStringsReader("a"), inner)
+	m := NewMultiReader(4, 2, middle, testutil.NewStringsReader("gh"))
+	defer m.Close()
+
+	if len(m.readers) != 4 {
+		t.Fatalf("источников %d, ожидалось 4: вложенные ридеры раскрываются на всю глубину", len(m.readers))
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "abcdefgh" {
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+	if restarts := m.Stats().PrefetchRestarts; restarts != 0 {
+		t.Fatalf("перезапусков префетчера %d, ожидался один запуск на всё дерево", restarts)
+	}
+	if _, err = middle.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Fatalf("Read раскрытого ридера: %v, ожидалась io.ErrClosedPipe", err)
+	}
+	if err = middle.Close(); err != nil {
+		t.Fatalf("Close раскрытого ридера: %v", err)
+	}
+}
+
+func TestFlatten_ConfiguredReaderKept(t *testing.T) {
+	used := NewMultiReader(4, 2, testutil.NewStringsReader("ab"), testutil.NewStringsReader("cd"))
+	if _, err := io.ReadFull(used, make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	lazy, err := NewMultiReaderWithOptions([]SizedReadSeekCloser{testutil.NewStringsReader("ef")}, WithLazySizes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMultiReader(4, 2, used, lazy)
+	defer m.Close()
+	if m.readers[0] != used || m.readers[1] != lazy {
+		t.Fatal("ридер, из которого читали, и ридер с WithLazySizes должны остаться источниками как есть")
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "abcdef" { // Родитель сам позиционирует источники
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+}
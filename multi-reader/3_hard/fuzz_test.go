@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// fuzzScenario — сценарий, декодированный из входа фаззера: содержимое сегментов, параметры префетча и операции.
+type fuzzScenario struct {
+	segments   []string
+	bufferSize int64
+	buffersNum int
+	ops        []fuzzOp
+}
+
+// fuzzOp — операция Read (size > 0) или Seek на абсолютную позицию target относительно whence.
+type fuzzOp struct {
+	seek   bool
+	size   int
+	target int64
+	whence int
+}
+
+// decodeFuzzScenario детерминированно превращает произвольные байты в сценарий.
+// Первые байты задают параметры, data — содержимое сегментов, ops — последовательность операций.
+func decodeFuzzScenario(data, ops []byte) fuzzScenario {
+	next := func(b *[]byte) int {
+		if len(*b) == 0 {
+			return 0
+		}
+		v := int((*b)[0])
+		*b = (*b)[1:]
+		return v
+	}
+
+	sc := fuzzScenario{
+		bufferSize: int64(next(&ops)%16 + 1),
+		buffersNum: next(&ops)%4 + 1,
+	}
+	segCount := next(&data)%5 + 1
+	for range segCount {
+		n := min(next(&data)%32, len(data)) // Пустые сегменты допустимы
+		sc.segments = append(sc.segments, string(data[:n]))
+		data = data[n:]
+	}
+
+	for len(ops) >= 3 {
+		kind, a, b := next(&ops), next(&ops), next(&ops)
+		if kind%3 == 0 {
+			sc.ops = append(sc.ops, fuzzOp{seek: true, target: int64(a) - 2, whence: b % 3})
+			continue
+		}
+		sc.ops = append(sc.ops, fuzzOp{size: a%40 + 1})
+	}
+	return sc
+}
+
+// runFuzzScenario выполняет сценарий на MultiReader и эталонном bytes.Reader и сравнивает результаты побайтно.
+func runFuzzScenario(t *testing.T, sc fuzzScenario) {
+	var all string
+	readers := make([]SizedReadSeekCloser, len(sc.segments))
+	for i, s := range sc.segments {
+		readers[i] = testutil.NewStringsReader(s)
+		all += s
+	}
+	size := int64(len(all))
+	ref := bytes.NewReader([]byte(all))
+	m := NewMultiReader(sc.bufferSize, sc.buffersNum, readers...)
+	defer m.Close()
+
+	if m.Size() != size {
+		t.Fatalf("Size: получено %d, ожидалось %d", m.Size(), size)
+	}
+
+	for i, op := range sc.ops {
+		if op.seek {
+			base := map[int]int64{io.SeekStart: 0, io.SeekCurrent: size - int64(ref.Len()), io.SeekEnd: size}[op.whence]
+			pos, err := m.Seek(op.target-base, op.whence)
+			if op.target < 0 || op.target > size {
+				if err == nil {
+					t.Fatalf("op %d: Seek(%d, %d) за пределы [0, %d] без ошибки", i, op.target-base, op.whence, size)
+				}
+				continue
+			}
+			if err != nil || pos != op.target {
+				t.Fatalf("op %d: Seek(%d, %d) = %d, %v; ожидалось %d", i, op.target-base, op.whence, pos, err, op.target)
+			}
+			_, _ = ref.Seek(op.target, io.SeekStart)
+			continue
+		}
+
+		got := make([]byte, op.size)
+		want := make([]byte, op.size)
+		gotN, gotErr := io.ReadFull(m, got)
+		wantN, wantErr := io.ReadFull(ref, want)
+		if gotN != wantN || !bytes.Equal(got[:gotN], want[:wantN]) {
+			t.Fatalf("op %d: Read(%d) на позиции %d: получено %q, ожидалось %q", i, op.size, size-int64(ref.Len())-int64(wantN), got[:gotN], want[:wantN])
+		}
+		if !errors.Is(gotErr, wantErr) {
+			t.Fatalf("op %d: Read(%d): ошибка %v, ожидалась %v", i, op.size, gotErr, wantErr)
+		}
+	}
+}
+
+func FuzzReadSeek(f *testing.F) {
+	f.Add([]byte("\x03\x03abc\x04defg\x02hi"), []byte("\x02\x01\x01\x05\x00\x00\x06\x00\x01\x0a\x00"))
+	f.Add([]byte("\x02\x00\x05hello"), []byte("\x00\x00\x00\x03\x00\x03\x00\x01\x00\x07\x02\x01\x09\x00"))
+	f.Add([]byte("\x05\x01a\x01b\x01c\x01d\x01e"), []byte("\x01\x03\x01\x02\x00\x00\x04\x00\x01\x03\x00\x01\x02\x00"))
+
+	f.Fuzz(func(t *testing.T, data, ops []byte) {
+		runFuzzScenario(t, decodeFuzzScenario(data, ops))
+	})
+}
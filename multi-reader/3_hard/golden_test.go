@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+var (
+	goldenSize = flag.Int64("golden.size", 256<<20, "суммарный размер сгенерированного потока для golden-тестов, байт")
+	goldenSeed = flag.Int64("golden.seed", 1, "seed генерации содержимого сегментов")
+)
+
+// goldenSegments нарезает поток размера total на сегменты псевдослучайной длины (в том числе пустые)
+// со своим seed содержимого у каждого.
+func goldenSegments(seed int64, total int64) []*mockGeneratedReader {
+	rnd := rand.New(rand.NewSource(seed))
+	var segs []*mockGeneratedReader
+	for rest := total; rest > 0; {
+		var n int64
+		switch rnd.Intn(4) {
+		case 0: // Мелкий сегмент, меньше блока префетча
+			n = rnd.Int63n(4096)
+		case 1: // Пустой сегмент
+			n = 0
+		default:
+			n = rnd.Int63n(total/8 + 1)
+		}
+		n = min(n, rest)
+		segs = append(segs, newMockGeneratedReader(rnd.Uint64(), n))
+		rest -= n
+	}
+	return segs
+}
+
+// goldenDigest считает эталонный sha256 потока, читая сегменты напрямую, без MultiReader.
+func goldenDigest(t *testing.T, segs []*mockGeneratedReader) [sha256.Size]byte {
+	t.Helper()
+	h := sha256.New()
+	for i, s := range segs {
+		_, err := io.Copy(h, io.LimitReader(newMockGeneratedReader(s.seed, s.size), s.size))
+		if err != nil {
+			t.Fatalf("segment %d: %v", i, err)
+		}
+	}
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+func newGoldenMultiReader(segs []*mockGeneratedReader, bufferSize int64, buffersNum int) *MultiReader {
+	readers := make([]SizedReadSeekCloser, len(segs))
+	for i, s := range segs {
+		readers[i] = newMockGeneratedReader(s.seed, s.size)
+	}
+	return NewMultiReader(bufferSize, buffersNum, readers...)
+}
+
+// TestGolden_FullStreamDigest читает сотни мегабайт через MultiReader с разными настройками и сверяет
+// sha256 всего потока с эталоном. Ловит повреждения (потерянные, продублированные, переставленные блоки),
+// которые не проявляются на коротких строковых фикстурах.
+// Размер регулируется флагом: go test -run Golden -golden.size=1073741824
+func TestGolden_FullStreamDigest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("golden-тест на большом объёме данных пропущен в -short")
+	}
+	segs := goldenSegments(*goldenSeed, *goldenSize)
+	want := goldenDigest(t, segs)
+	t.Logf("golden: %d bytes, %d segments, seed %d", *goldenSize, len(segs), *goldenSeed)
+
+	configs := []struct {
+		bufferSize int64
+		buffersNum int
+		readSize   int
+	}{
+		{bufferSize: 1 << 20, buffersNum: 4, readSize: 32 << 10},
+		{bufferSize: 4093, buffersNum: 1, readSize: 1 << 20}, // блок не кратен словам генератора, чтение больше блока
+		{bufferSize: 64 << 10, buffersNum: 16, readSize: 1000},
+		{bufferSize: 8 << 20, buffersNum: 2, readSize: 8<<20 + 7},
+	}
+	for _, cfg := range configs {
+		t.Run(fmt.Sprintf("buf=%d/num=%d/read=%d", cfg.bufferSize, cfg.buffersNum, cfg.readSize), func(t *testing.T) {
+			m := newGoldenMultiReader(segs, cfg.bufferSize, cfg.buffersNum)
+			defer m.Close()
+			if m.Size() != *goldenSize {
+				t.Fatalf("Size: %d, want %d", m.Size(), *goldenSize)
+			}
+
+			h := sha256.New()
+			n, err := io.CopyBuffer(h, struct{ io.Reader }{m}, make([]byte, cfg.readSize))
+			if err != nil {
+				t.Fatalf("copy: %v", err)
+			}
+			if n != *goldenSize {
+				t.Fatalf("read %d bytes, want %d", n, *goldenSize)
+			}
+			if got := [sha256.Size]byte(h.Sum(nil)); got != want {
+				t.Fatalf("digest mismatch: %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+// TestGolden_RandomRanges проверяет произвольные диапазоны большого потока после Seek,
+// сравнивая их с содержимым, вычисленным генератором по абсолютной позиции.
+func TestGolden_RandomRanges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("golden-тест на большом объёме данных пропущен в -short")
+	}
+	segs := goldenSegments(*goldenSeed, *goldenSize)
+	m := newGoldenMultiReader(segs, 256<<10, 4)
+	defer m.Close()
+
+	rnd := rand.New(rand.NewSource(*goldenSeed))
+	for i := 0; i < 200; i++ {
+		off := rnd.Int63n(*goldenSize)
+		size := min(rnd.Int63n(1<<20)+1, *goldenSize-off)
+		if _, err := m.Seek(off, io.SeekStart); err != nil {
+			t.Fatalf("Seek(%d): %v", off, err)
+		}
+		got := make([]byte, size)
+		if _, err := io.ReadFull(m, got); err != nil {
+			t.Fatalf("ReadFull at %d, %d bytes: %v", off, size, err)
+		}
+		if want := goldenRange(segs, off, size); !bytes.Equal(got, want) {
+			t.Fatalf("range [%d, %d): %v", off, off+size, testutil.ExpectBytes("data", got, want))
+		}
+	}
+}
+
+// goldenRange вычисляет эталонное содержимое диапазона [off, off+size) конкатенации сегментов.
+func goldenRange(segs []*mockGeneratedReader, off, size int64) []byte {
+	out := make([]byte, 0, size)
+	var start int64
+	for _, s := range segs {
+		end := start + s.size
+		if lo, hi := max(off, start), min(off+size, end); lo < hi {
+			part := make([]byte, hi-lo)
+			generateAt(s.seed, lo-start, part)
+			out = append(out, part...)
+		}
+		start = end
+	}
+	return out
+}
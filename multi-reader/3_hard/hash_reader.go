@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrChecksumMismatch — дайджест прочитанных данных не совпал с ожидаемым.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumError описывает несовпадение дайджеста; errors.Is(err, ErrChecksumMismatch) == true.
+type ChecksumError struct {
+	Expected []byte
+	Actual   []byte
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%v: expected %x, got %x", ErrChecksumMismatch, e.Expected, e.Actual)
+}
+
+func (e *ChecksumError) Unwrap() error { return ErrChecksumMismatch }
+
+// HashReader хеширует проходящие через него байты. Если задан ожидаемый дайджест, по достижении io.EOF
+// он сверяется с посчитанным, и при несовпадении вместо io.EOF возвращается *ChecksumError.
+// Подходит для проверки целостности любого потока, в том числе всего MultiReader.
+type HashReader struct {
+	r        io.Reader
+	h        hash.Hash
+	expected []byte // nil — только считать дайджест
+}
+
+// NewHashReader оборачивает r хешированием h. expected == nil отключает проверку.
+func NewHashReader(r io.Reader, h hash.Hash, expected []byte) *HashReader {
+	return &HashReader{r: r, h: h, expected: expected}
+}
+
+// Read читает из источника и добавляет прочитанное в дайджест.
+func (hr *HashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		verifyErr := hr.verify()
+		if verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+// Sum возвращает дайджест прочитанных к этому моменту данных.
+func (hr *HashReader) Sum() []byte {
+	return hr.h.Sum(nil)
+}
+
+// verify сверяет дайджест с ожидаемым.
+func (hr *HashReader) verify() error {
+	return verifySum(hr.h, hr.expected)
+}
+
+// HashSegment — HashReader для сегмента MultiReader. MultiReader читает сегмент ровно до Size и io.EOF от него
+// может не получить, поэтому дайджест сверяется, когда прочитан последний байт сегмента.
+// Проверка возможна только для последовательного прохода с начала: Seek на текущую позицию (его делает префетчер)
+// ничего не меняет, Seek в начало сбрасывает дайджест, Seek в другое место отключает проверку до возврата в начало.
+type HashSegment struct {
+	HashReader
+	seg     SizedReadSeekCloser
+	pos     int64 // позиция в сегменте
+	skipped bool  // часть сегмента пропущена через Seek: дайджест не покрывает все данные
+}
+
+// Проверка, что HashSegment удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*HashSegment)(nil)
+
+// NewHashSegment оборачивает сегмент хешированием h. expected == nil отключает проверку.
+func NewHashSegment(seg SizedReadSeekCloser, h hash.Hash, expected []byte) *HashSegment {
+	return &HashSegment{HashReader: HashReader{r: seg, h: h, expected: expected}, seg: seg}
+}
+
+// Read читает из сегмента и, дочитав его до конца, сверяет дайджест.
+func (hs *HashSegment) Read(p []byte) (int, error) {
+	n, err := hs.seg.Read(p)
+	hs.pos += int64(n)
+	if hs.skipped {
+		return n, err
+	}
+	hs.h.Write(p[:n])
+	if hs.pos == hs.seg.Size() {
+		verifyErr := hs.verify()
+		if verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+// Seek перемещает позицию сегмента, сбрасывая или отключая проверку, если проход перестал быть последовательным.
+func (hs *HashSegment) Seek(offset int64, whence int) (int64, error) {
+	pos, err := hs.seg.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	switch pos {
+	case hs.pos:
+	case 0:
+		hs.h.Reset()
+		hs.skipped = false
+	default:
+		hs.skipped = true
+	}
+	hs.pos = pos
+	return pos, nil
+}
+
+// Size возвращает размер сегмента.
+func (hs *HashSegment) Size() int64 {
+	return hs.seg.Size()
+}
+
+// Close закрывает сегмент.
+func (hs *HashSegment) Close() error {
+	return hs.seg.Close()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func sha256Of(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+func TestHashReader_VerifiesAtEOF(t *testing.T) {
+	const content = "integrity matters"
+
+	hr := NewHashReader(strings.NewReader(content), sha256.New(), sha256Of(content))
+	got, err := io.ReadAll(hr)
+	if err != nil || string(got) != content {
+		t.Fatalf("ReadAll с верным дайджестом: %q, %v", got, err)
+	}
+
+	hr = NewHashReader(strings.NewReader(content), sha256.New(), sha256Of("something else"))
+	_, err = io.ReadAll(hr)
+	var ce *ChecksumError
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &ce) || !bytes.Equal(ce.Actual, sha256Of(content)) {
+		t.Fatalf("ReadAll с неверным дайджестом: %v, ожидалась *ChecksumError с фактическим дайджестом", err)
+	}
+
+	hr = NewHashReader(strings.NewReader(content), sha256.New(), nil)
+	if _, err = io.ReadAll(hr); err != nil || !bytes.Equal(hr.Sum(), sha256Of(content)) {
+		t.Fatalf("без ожидаемого дайджеста: err=%v, Sum=%x", err, hr.Sum())
+	}
+}
+
+func TestHashReader_OverMultiReader(t *testing.T) {
+	parts := []string{"first segment, ", "second segment, ", "third"}
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		readers[i] = testutil.NewStringsReader(part)
+	}
+	m := NewMultiReader(8, 2, readers...)
+	defer m.Close()
+
+	hr := NewHashReader(m, sha256.New(), sha256Of(strings.Join(parts, "")))
+	if _, err := io.Copy(io.Discard, hr); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+}
+
+func newHashSegments(parts []string, corrupt int) []SizedReadSeekCloser {
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		data := part
+		if i == corrupt {
+			data = strings.ToUpper(part) // Содержимое испорчено, размер тот же
+		}
+		readers[i] = NewHashSegment(testutil.NewStringsReader(data), sha256.New(), sha256Of(part))
+	}
+	return readers
+}
+
+func TestHashSegment_DetectsCorruptSegment(t *testing.T) {
+	parts := []string{strings.Repeat("a", 40), strings.Repeat("b", 25), strings.Repeat("c", 33)}
+
+	m := NewMultiReader(16, 2, newHashSegments(parts, -1)...)
+	got, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(strings.Join(parts, "")))); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.Close()
+
+	// Ошибка последнего сегмента не должна теряться за io.EOF всего потока
+	for _, corrupt := range []int{1, 2} {
+		m = NewMultiReader(16, 2, newHashSegments(parts, corrupt)...)
+		got, err = io.ReadAll(m)
+		_ = m.Close()
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("ReadAll с испорченным сегментом %d: %v, ожидалась ErrChecksumMismatch", corrupt, err)
+		}
+		if limit := len(strings.Join(parts[:corrupt+1], "")); len(got) > limit {
+			t.Fatalf("после ошибки проверки прочитано %d байт: данные за испорченным сегментом не должны выдаваться", len(got))
+		}
+	}
+}
+
+func TestHashSegment_SeekDisablesAndResetsVerification(t *testing.T) {
+	seg := NewHashSegment(testutil.NewStringsReader("0123456789"), sha256.New(), sha256Of("different"))
+
+	// Частичный проход: дайджест не покрывает все данные, проверка не выполняется
+	if _, err := seg.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if got, err := io.ReadAll(seg); err != nil || string(got) != "456789" {
+		t.Fatalf("ReadAll после Seek: %q, %v", got, err)
+	}
+
+	// Возврат в начало сбрасывает дайджест, полный проход снова проверяется
+	if _, err := seg.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek в начало: %v", err)
+	}
+	if _, err := io.ReadAll(seg); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("полный проход: %v, ожидалась ErrChecksumMismatch", err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+const hookTimeout = 5 * time.Second
+
+// gate — одноразовая точка остановки для планировщика: первая горутина, дошедшая до hook,
+// сообщает о прибытии и ждёт open. Последующие вызовы проходят без остановки.
+type gate struct {
+	armed   atomic.Bool
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func newGate() *gate {
+	g := &gate{arrived: make(chan struct{}), release: make(chan struct{})}
+	g.armed.Store(true)
+	return g
+}
+
+func (g *gate) hook() {
+	if g.armed.CompareAndSwap(true, false) {
+		close(g.arrived)
+		<-g.release
+	}
+}
+
+func (g *gate) waitArrived(t *testing.T) {
+	t.Helper()
+	select {
+	case <-g.arrived:
+	case <-time.After(hookTimeout):
+		t.Fatal("горутина не дошла до точки остановки")
+	}
+}
+
+func (g *gate) open() { close(g.release) }
+
+type readResult struct {
+	data string
+	err  error
+}
+
+// goRead запускает Read в отдельной горутине.
+func goRead(m *MultiReader, size int) <-chan readResult {
+	res := make(chan readResult, 1)
+	go func() {
+		buf := make([]byte, size)
+		n, err := m.Read(buf)
+		res <- readResult{data: string(buf[:n]), err: err}
+	}()
+	return res
+}
+
+func waitRead(t *testing.T, res <-chan readResult) readResult {
+	t.Helper()
+	select {
+	case r := <-res:
+		return r
+	case <-time.After(hookTimeout):
+		t.Fatal("Read завис")
+		return readResult{}
+	}
+}
+
+func TestHooks_SeekBetweenBlockReceiveAndAppend(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	m := NewMultiReader(2, 1, testutil.NewStringsReader("abcdefgh"))
+	defer m.Close()
+	g := newGate()
+	m.hooks = prefetchHooks{hookBlockReceived: g.hook}
+
+	res := goRead(m, 2)
+	g.waitArrived(t) // Read держит блок "ab", но ещё не добавил его в окно
+
+	pos, err := m.Seek(6, io.SeekStart)
+	if err != nil || pos != 6 {
+		t.Fatalf("Seek: %d, %v", pos, err)
+	}
+	g.open()
+
+	r := waitRead(t, res)
+	if r.err != nil || r.data != "gh" {
+		t.Fatalf("Read после Seek: %q, %v; устаревший блок не должен попасть в окно", r.data, r.err)
+	}
+}
+
+func TestHooks_SeekWhilePrefetcherBlockedOnFullChannel(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	const buffersNum = 2
+	m := NewMultiReader(1, buffersNum, testutil.NewStringsReader(strings.Repeat("a", 16)+"z"))
+	defer m.Close()
+	sent := make(chan struct{}, 32)
+	var resetWaited atomic.Bool
+	m.hooks = prefetchHooks{
+		hookBlockSent: func() { sent <- struct{}{} },
+		hookResetWait: func() { resetWaited.Store(true) },
+	}
+
+	r := waitRead(t, goRead(m, 1))
+	if r.err != nil || r.data != "a" {
+		t.Fatalf("первый Read: %q, %v", r.data, r.err)
+	}
+	// Первый блок прочитан, ещё buffersNum заполнили канал: префетчер висит на отправке следующего
+	for i := 0; i < 1+buffersNum; i++ {
+		select {
+		case <-sent:
+		case <-time.After(hookTimeout):
+			t.Fatalf("префетчер отправил только %d блоков", i)
+		}
+	}
+
+	if _, err := m.Seek(-1, io.SeekEnd); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if !resetWaited.Load() {
+		t.Fatal("Seek за пределы окна должен был дождаться остановки префетчера")
+	}
+
+	r = waitRead(t, goRead(m, 1))
+	if r.err != nil || r.data != "z" {
+		t.Fatalf("Read после Seek: %q, %v", r.data, r.err)
+	}
+}
+
+func TestHooks_CloseWhileReadHoldsBlock(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	a := testutil.NewStringsReader("abcdef")
+	m := NewMultiReader(2, 1, a)
+	g := newGate()
+	m.hooks = prefetchHooks{hookBlockReceived: g.hook}
+
+	res := goRead(m, 4)
+	g.waitArrived(t)
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	g.open()
+
+	r := waitRead(t, res)
+	if !errors.Is(r.err, io.ErrClosedPipe) || r.data != "" {
+		t.Fatalf("Read, прерванный Close: %q, %v", r.data, r.err)
+	}
+	if !a.Closed() {
+		t.Fatal("источник не закрыт")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// httpRange — ожидаемый фрагмент ответа: полуинтервал [start, end) исходного содержимого.
+type httpRange struct {
+	start, end int64
+}
+
+// newContentServer отдаёт content через http.ServeContent. На каждый запрос создаётся свой MultiReader
+// поверх сегментов разной длины, маленькие блоки префетча заставляют диапазоны пересекать и блоки, и сегменты.
+// После остановки сервера проверяется, что все созданные ридеры закрыты.
+func newContentServer(t *testing.T, content string) *httptest.Server {
+	t.Helper()
+	var opened atomic.Int64
+	t.Cleanup(func() { // Выполняется после srv.Close, который дожидается завершения всех обработчиков
+		if n := opened.Load(); n != 0 {
+			t.Errorf("незакрытых MultiReader после ответов: %d", n)
+		}
+	})
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var segments []SizedReadSeekCloser
+		for i, rest := 0, content; len(rest) > 0; i++ {
+			n := min(len(rest), 37+i*53%211)
+			segments = append(segments, testutil.NewStringsReader(rest[:n]))
+			rest = rest[n:]
+		}
+		m := NewMultiReader(64, 3, segments...)
+		opened.Add(1)
+		defer func() {
+			if err := m.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+			opened.Add(-1)
+		}()
+		http.ServeContent(w, r, "data.bin", modTime, m)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readParts возвращает фрагменты тела ответа: одиночный для 200/206 и по частям для multipart/byteranges.
+// Для каждого фрагмента разбирается Content-Range.
+func readParts(t *testing.T, resp *http.Response) (parts []string, ranges []httpRange) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Content-Type: %v", err)
+	}
+	if mediaType != "multipart/byteranges" {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("чтение тела: %v", err)
+		}
+		return []string{string(body)}, []httpRange{parseContentRange(t, resp.Header.Get("Content-Range"), int64(len(body)))}
+	}
+
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return parts, ranges
+		}
+		if err != nil {
+			t.Fatalf("multipart: %v", err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("чтение части: %v", err)
+		}
+		parts = append(parts, string(body))
+		ranges = append(ranges, parseContentRange(t, part.Header.Get("Content-Range"), int64(len(body))))
+	}
+}
+
+// parseContentRange разбирает "bytes start-last/size"; пустой заголовок означает всё тело длины n.
+func parseContentRange(t *testing.T, header string, n int64) httpRange {
+	t.Helper()
+	if header == "" {
+		return httpRange{0, n}
+	}
+	var start, last, size int64
+	if _, err := fmt.Sscanf(header, "bytes %d-%d/%d", &start, &last, &size); err != nil {
+		t.Fatalf("Content-Range %q: %v", header, err)
+	}
+	return httpRange{start, last + 1}
+}
+
+func TestHTTP_ServeContentRanges(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	content := stressContent(5000)
+	size := int64(len(content))
+	srv := newContentServer(t, content)
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	for _, tc := range []struct {
+		name       string
+		rangeHdr   string
+		wantStatus int
+		want       []httpRange
+	}{
+		{name: "full", wantStatus: http.StatusOK, want: []httpRange{{0, size}}},
+		{name: "single", rangeHdr: "bytes=100-199", wantStatus: http.StatusPartialContent, want: []httpRange{{100, 200}}},
+		{name: "open-ended", rangeHdr: "bytes=4321-", wantStatus: http.StatusPartialContent, want: []httpRange{{4321, size}}},
+		{name: "suffix", rangeHdr: "bytes=-77", wantStatus: http.StatusPartialContent, want: []httpRange{{size - 77, size}}},
+		{name: "last-byte", rangeHdr: "bytes=4999-4999", wantStatus: http.StatusPartialContent, want: []httpRange{{4999, 5000}}},
+		{
+			name:       "multi",
+			rangeHdr:   "bytes=0-9,1000-1099,-20",
+			wantStatus: http.StatusPartialContent,
+			want:       []httpRange{{0, 10}, {1000, 1100}, {size - 20, size}},
+		},
+		{
+			// Каждая следующая часть начинается раньше конца предыдущей: MultiReader должен отматываться назад
+			name:       "backwards-overlapping",
+			rangeHdr:   "bytes=3000-3499,2900-3100,10-20,0-15",
+			wantStatus: http.StatusPartialContent,
+			want:       []httpRange{{3000, 3500}, {2900, 3101}, {10, 21}, {0, 16}},
+		},
+		{name: "unsatisfiable", rangeHdr: "bytes=5000-", wantStatus: http.StatusRequestedRangeNotSatisfiable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.rangeHdr != "" {
+				req.Header.Set("Range", tc.rangeHdr)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("статус %d, ожидался %d", resp.StatusCode, tc.wantStatus)
+			}
+			if tc.want == nil {
+				return
+			}
+			parts, ranges := readParts(t, resp)
+			if len(parts) != len(tc.want) {
+				t.Fatalf("частей %d, ожидалось %d: %v", len(parts), len(tc.want), ranges)
+			}
+			for i, want := range tc.want {
+				if ranges[i] != want {
+					t.Errorf("часть %d: Content-Range %v, ожидался %v", i, ranges[i], want)
+				}
+				if parts[i] != content[want.start:want.end] {
+					t.Errorf("часть %d [%d, %d): содержимое не совпадает с исходным", i, want.start, want.end)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTP_ServeContentHead(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	content := strings.Repeat("abc", 100)
+	srv := newContentServer(t, content)
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Head(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ContentLength != int64(len(content)) {
+		t.Errorf("Content-Length %d, ожидался %d: размер берётся из Seek(0, io.SeekEnd)", resp.ContentLength, len(content))
+	}
+	if got := resp.Header.Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ManifestVersion — версия формата манифеста, которую пишет Marshal и принимает UnmarshalManifest.
+const ManifestVersion = 1
+
+// manifestBuffersNum — число блоков префетча MultiReader, собранного по манифесту.
+const manifestBuffersNum = 4
+
+// Manifest описывает раскладку составного объекта: упорядоченные сегменты и размер блока чтения.
+// Сериализуется в JSON, чтобы писатель и читатель могли обмениваться раскладкой независимо от хранилища.
+type Manifest struct {
+	Version   int               `json:"version"`
+	BlockSize int64             `json:"block_size"` // размер блока префетча MultiReader
+	Segments  []ManifestSegment `json:"segments"`
+}
+
+// ManifestSegment — запись манифеста об одном сегменте.
+type ManifestSegment struct {
+	Name   string `json:"name"`             // имя сегмента в хранилище, уникально в пределах манифеста
+	Size   int64  `json:"size"`             // размер в байтах
+	Digest string `json:"digest,omitempty"` // sha256 содержимого в hex; пусто — без проверки
+}
+
+// ManifestOpener открывает сегмент манифеста на чтение.
+type ManifestOpener func(seg ManifestSegment) (SizedReadSeekCloser, error)
+
+// Validate проверяет манифест: версию, размер блока, имена, размеры и формат дайджестов.
+func (m *Manifest) Validate() error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version %d, expected %d", m.Version, ManifestVersion)
+	}
+	if m.BlockSize <= 0 {
+		return fmt.Errorf("block size must be positive, got %d", m.BlockSize)
+	}
+	names := make(map[string]bool, len(m.Segments))
+	for i, seg := range m.Segments {
+		switch {
+		case seg.Name == "":
+			return fmt.Errorf("segment %d: empty name", i)
+		case names[seg.Name]:
+			return fmt.Errorf("segment %d: duplicate name %q", i, seg.Name)
+		case seg.Size < 0:
+			return fmt.Errorf("segment %d (%s): negative size %d", i, seg.Name, seg.Size)
+		}
+		names[seg.Name] = true
+		if seg.Digest != "" {
+			_, err := decodeDigest(seg.Digest)
+			if err != nil {
+				return fmt.Errorf("segment %d (%s): %w", i, seg.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Size возвращает суммарный размер сегментов.
+func (m *Manifest) Size() int64 {
+	var size int64
+	for _, seg := range m.Segments {
+		size += seg.Size
+	}
+	return size
+}
+
+// Marshal проверяет манифест и сериализует его в JSON.
+func (m *Manifest) Marshal() ([]byte, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// UnmarshalManifest разбирает и проверяет манифест. Неизвестные поля — ошибка: их смысл читателю неизвестен.
+func UnmarshalManifest(data []byte) (*Manifest, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var m Manifest
+	err := dec.Decode(&m)
+	if err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	err = m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// BuildFromManifest открывает сегменты манифеста через open и возвращает MultiReader над ними.
+// Размер каждого открытого сегмента сверяется с манифестом сразу, дайджест — при чтении:
+// сегмент с дайджестом оборачивается в HashSegment, и испорченное содержимое даёт ErrChecksumMismatch.
+// При ошибке уже открытые сегменты закрываются.
+func BuildFromManifest(m *Manifest, open ManifestOpener) (*MultiReader, error) {
+	err := m.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	readers := make([]SizedReadSeekCloser, 0, len(m.Segments))
+	for _, seg := range m.Segments {
+		r, err := open(seg)
+		if err == nil && r.Size() != seg.Size {
+			_ = r.Close()
+			err = fmt.Errorf("size %d, manifest says %d", r.Size(), seg.Size)
+		}
+		if err != nil {
+			_ = CloseAll(readers, "segment")
+			return nil, fmt.Errorf("open segment %s: %w", seg.Name, err)
+		}
+		if seg.Digest != "" {
+			expected, _ := decodeDigest(seg.Digest) // Формат проверен в Validate
+			r = NewHashSegment(r, sha256.New(), expected)
+		}
+		readers = append(readers, r)
+	}
+
+	return NewMultiReader(m.BlockSize, manifestBuffersNum, readers...), nil
+}
+
+// Manifest строит манифест записанных сегментов; name задаёт имя сегмента по его номеру.
+// Дайджесты SegmentedWriter не считает — их можно дописать в Segments перед Marshal.
+func (w *SegmentedWriter) Manifest(blockSize int64, name func(index int) string) (*Manifest, error) {
+	if !w.closed {
+		return nil, errors.New("segmented writer is not closed")
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	m := &Manifest{Version: ManifestVersion, BlockSize: blockSize, Segments: make([]ManifestSegment, len(w.segments))}
+	for i, seg := range w.segments {
+		m.Segments[i] = ManifestSegment{Name: name(seg.Index), Size: seg.Size}
+	}
+	return m, nil
+}
+
+// decodeDigest разбирает hex-дайджест sha256.
+func decodeDigest(digest string) ([]byte, error) {
+	sum, err := hex.DecodeString(digest)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("digest %q is not a hex sha256", digest)
+	}
+	return sum, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// manifestStore — хранилище сегментов по имени для тестов BuildFromManifest.
+type manifestStore struct {
+	objects map[string]string
+	opened  []*testutil.StringsReader
+}
+
+func (s *manifestStore) open(seg ManifestSegment) (SizedReadSeekCloser, error) {
+	data, ok := s.objects[seg.Name]
+	if !ok {
+		return nil, fmt.Errorf("no object %q", seg.Name)
+	}
+	r := testutil.NewStringsReader(data)
+	s.opened = append(s.opened, r)
+	return r, nil
+}
+
+func newManifest(parts map[string]string, order ...string) *Manifest {
+	m := &Manifest{Version: ManifestVersion, BlockSize: 8}
+	for _, name := range order {
+		m.Segments = append(m.Segments, ManifestSegment{
+			Name:   name,
+			Size:   int64(len(parts[name])),
+			Digest: hex.EncodeToString(sha256Of(parts[name])),
+		})
+	}
+	return m
+}
+
+func TestManifest_MarshalRoundTrip(t *testing.T) {
+	m := newManifest(map[string]string{"a": "first", "b": ""}, "a", "b")
+	m.Segments[1].Digest = ""
+
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got, err := UnmarshalManifest(data)
+	if err != nil {
+		t.Fatalf("UnmarshalManifest: %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Fatalf("манифест после round trip:\n%+v\nожидался\n%+v", got, m)
+	}
+	if got.Size() != 5 {
+		t.Fatalf("Size = %d, ожидалось 5", got.Size())
+	}
+}
+
+func TestUnmarshalManifest_Rejects(t *testing.T) {
+	digest := hex.EncodeToString(sha256Of("x"))
+	for name, data := range map[string]string{
+		"не JSON":              `{"version": 1,`,
+		"неизвестное поле":     `{"version": 1, "block_size": 8, "segments": [], "compression": "zstd"}`,
+		"другая версия":        `{"version": 2, "block_size": 8, "segments": []}`,
+		"нулевой блок":         `{"version": 1, "block_size": 0, "segments": []}`,
+		"пустое имя":           `{"version": 1, "block_size": 8, "segments": [{"name": "", "size": 1}]}`,
+		"повтор имени":         `{"version": 1, "block_size": 8, "segments": [{"name": "a", "size": 1}, {"name": "a", "size": 1}]}`,
+		"отрицательный размер": `{"version": 1, "block_size": 8, "segments": [{"name": "a", "size": -1}]}`,
+		"короткий дайджест":    `{"version": 1, "block_size": 8, "segments": [{"name": "a", "size": 1, "digest": "` + digest[:10] + `"}]}`,
+	} {
+		if _, err := UnmarshalManifest([]byte(data)); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+func TestBuildFromManifest_ReadsAndVerifies(t *testing.T) {
+	parts := map[string]string{"p0": strings.Repeat("a", 20), "p1": strings.Repeat("b", 13), "p2": "tail"}
+	store := &manifestStore{objects: parts}
+
+	m, err := BuildFromManifest(newManifest(parts, "p0", "p1", "p2"), store.open)
+	if err != nil {
+		t.Fatalf("BuildFromManifest: %v", err)
+	}
+	got, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(parts["p0"]+parts["p1"]+parts["p2"]))); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.Close()
+
+	// Содержимое испорчено при том же размере: обнаруживается при чтении
+	store.objects = map[string]string{"p0": parts["p0"], "p1": strings.Repeat("B", 13), "p2": parts["p2"]}
+	m, err = BuildFromManifest(newManifest(parts, "p0", "p1", "p2"), store.open)
+	if err != nil {
+		t.Fatalf("BuildFromManifest: %v", err)
+	}
+	defer m.Close()
+	if _, err = io.ReadAll(m); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("ReadAll испорченного сегмента: %v, ожидалась ErrChecksumMismatch", err)
+	}
+}
+
+func TestBuildFromManifest_SizeMismatchClosesOpened(t *testing.T) {
+	parts := map[string]string{"p0": "abc", "p1": "defg"}
+	manifest := newManifest(parts, "p0", "p1")
+	store := &manifestStore{objects: map[string]string{"p0": "abc", "p1": "de"}}
+
+	_, err := BuildFromManifest(manifest, store.open)
+	if err == nil || !strings.Contains(err.Error(), "p1") {
+		t.Fatalf("ожидалась ошибка размера сегмента p1, получено %v", err)
+	}
+	if len(store.opened) != 2 || !store.opened[0].Closed() || !store.opened[1].Closed() {
+		t.Fatalf("открытые сегменты должны быть закрыты после ошибки")
+	}
+
+	if _, err = BuildFromManifest(&Manifest{Version: ManifestVersion}, store.open); err == nil {
+		t.Fatalf("невалидный манифест должен отклоняться")
+	}
+}
+
+func TestSegmentedWriter_ManifestRoundTrip(t *testing.T) {
+	segs := newMemSegments()
+	w := newTestSegmentedWriter(t, 10, segs)
+	content := strings.Repeat("0123456789", 3) + "xyz"
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Manifest(8, nil); err == nil {
+		t.Fatalf("Manifest до Close должен возвращать ошибку")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	name := func(index int) string { return fmt.Sprintf("part-%03d", index) }
+	manifest, err := w.Manifest(8, name)
+	if err != nil {
+		t.Fatalf("Manifest: %v", err)
+	}
+	data, err := manifest.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	// Читатель знает только сериализованный манифест и хранилище сегментов по имени
+	loaded, err := UnmarshalManifest(data)
+	if err != nil {
+		t.Fatalf("UnmarshalManifest: %v", err)
+	}
+	store := &manifestStore{objects: map[string]string{}}
+	for i, sink := range segs.sinks {
+		store.objects[name(i)] = sink.String()
+	}
+	m, err := BuildFromManifest(loaded, store.open)
+	if err != nil {
+		t.Fatalf("BuildFromManifest: %v", err)
+	}
+	defer m.Close()
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != content {
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+}
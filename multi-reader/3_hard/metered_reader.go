@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// meterBuckets — на сколько корзин делится окно скользящего среднего пропускной способности.
+const meterBuckets = 10
+
+// MeterStats — снимок метрик MeteredReader.
+type MeterStats struct {
+	Bytes      int64         // всего прочитано байт
+	Reads      int64         // вызовов Read
+	Seeks      int64         // вызовов Seek
+	Errors     int64         // ошибок Read и Seek, кроме io.EOF
+	ReadTime   time.Duration // суммарное время внутри Read источника
+	Throughput float64       // байт в секунду за последнее окно
+}
+
+// meterBucket — байты, прочитанные за один интервал окна.
+type meterBucket struct {
+	epoch int64 // номер интервала длиной window/meterBuckets с начала эпохи
+	bytes int64
+}
+
+// MeteredReader считает байты, вызовы и ошибки источника и скользящее среднее пропускной способности за окно.
+// Snapshot безопасно вызывать параллельно с чтением.
+type MeteredReader struct {
+	r      SizedReadSeekCloser
+	window time.Duration
+	now    func() time.Time // источник времени; подменяется в тестах
+
+	mu      sync.Mutex // защищает поля ниже
+	stats   MeterStats
+	buckets [meterBuckets]meterBucket
+	started time.Time // время создания: пока окно не набрано, среднее считается за прошедшее время
+}
+
+// Проверка, что MeteredReader удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*MeteredReader)(nil)
+
+// NewMeteredReader оборачивает r счётчиками; пропускная способность усредняется за window.
+func NewMeteredReader(r SizedReadSeekCloser, window time.Duration) *MeteredReader {
+	return newMeteredReader(r, window, time.Now)
+}
+
+func newMeteredReader(r SizedReadSeekCloser, window time.Duration, now func() time.Time) *MeteredReader {
+	if window < meterBuckets {
+		window = meterBuckets
+	}
+	return &MeteredReader{r: r, window: window, now: now, started: now()}
+}
+
+// Read читает из источника и учитывает прочитанное.
+func (mr *MeteredReader) Read(p []byte) (int, error) {
+	start := mr.now()
+	n, err := mr.r.Read(p)
+	end := mr.now()
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.stats.Reads++
+	mr.stats.Bytes += int64(n)
+	mr.stats.ReadTime += end.Sub(start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		mr.stats.Errors++
+	}
+	if n > 0 {
+		b := mr.bucket(end)
+		b.bytes += int64(n)
+	}
+
+	return n, err
+}
+
+// Seek перемещает позицию источника и учитывает вызов.
+func (mr *MeteredReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := mr.r.Seek(offset, whence)
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.stats.Seeks++
+	if err != nil {
+		mr.stats.Errors++
+	}
+
+	return pos, err
+}
+
+// Size возвращает размер источника.
+func (mr *MeteredReader) Size() int64 {
+	return mr.r.Size()
+}
+
+// Close закрывает источник.
+func (mr *MeteredReader) Close() error {
+	return mr.r.Close()
+}
+
+// Snapshot возвращает текущие метрики.
+func (mr *MeteredReader) Snapshot() MeterStats {
+	now := mr.now()
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	stats := mr.stats
+
+	epoch := mr.epoch(now)
+	var bytes int64
+	for _, b := range mr.buckets {
+		if epoch-b.epoch < meterBuckets {
+			bytes += b.bytes
+		}
+	}
+	span := min(now.Sub(mr.started), mr.window)
+	if span > 0 {
+		stats.Throughput = float64(bytes) / span.Seconds()
+	}
+
+	return stats
+}
+
+// bucket возвращает корзину интервала, в который попадает t, обнуляя её, если она осталась от прошлого круга.
+// Вызывается под mr.mu.
+func (mr *MeteredReader) bucket(t time.Time) *meterBucket {
+	epoch := mr.epoch(t)
+	b := &mr.buckets[epoch%meterBuckets]
+	if b.epoch != epoch {
+		*b = meterBucket{epoch: epoch}
+	}
+	return b
+}
+
+// epoch возвращает номер интервала окна, в который попадает t.
+func (mr *MeteredReader) epoch(t time.Time) int64 {
+	return t.Sub(mr.started).Nanoseconds() / (mr.window.Nanoseconds() / meterBuckets)
+}
+
+// EnableMetering оборачивает каждый сегмент MultiReader в MeteredReader с окном window,
+// чтобы была видна пропускная способность каждого источника. Повторный вызов ничего не делает.
+// Можно вызывать в любой момент: работающий префетчер перезапускается уже поверх обёрток.
+func (m *MultiReader) EnableMetering(window time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed || m.meters != nil {
+		return
+	}
+
+	m.resetPrefetch() // Префетчер читает m.readers без блокировки: подменяем их, только когда он остановлен
+	m.meters = make([]*MeteredReader, len(m.readers))
+	for i, r := range m.readers {
+		m.meters[i] = NewMeteredReader(r, window)
+		m.readers[i] = m.meters[i]
+	}
+}
+
+// SegmentStats возвращает метрики сегментов в порядке их следования; nil, если метрики не включены.
+func (m *MultiReader) SegmentStats() []MeterStats {
+	m.mu.Lock()
+	meters := m.meters
+	m.mu.Unlock()
+	if meters == nil {
+		return nil
+	}
+
+	stats := make([]MeterStats, len(meters))
+	for i, mr := range meters {
+		stats[i] = mr.Snapshot()
+	}
+	return stats
+}
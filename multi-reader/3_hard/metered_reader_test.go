@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// manualNow — ручные часы для MeteredReader.
+type manualNow struct {
+	t time.Time
+}
+
+func (c *manualNow) now() time.Time { return c.t }
+
+func (c *manualNow) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMeteredReader_Counters(t *testing.T) {
+	errDisk := errors.New("disk error")
+	mr := NewMeteredReader(testutil.NewStringsReader("hello, world").FailReadAfter(10, errDisk), time.Second)
+
+	buf := make([]byte, 4)
+	for range 3 {
+		_, _ = mr.Read(buf)
+	}
+	if _, err := mr.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if _, err := mr.Seek(-1, io.SeekStart); err == nil {
+		t.Fatalf("Seek на отрицательную позицию должен возвращать ошибку")
+	}
+
+	got := mr.Snapshot()
+	if got.Bytes != 10 || got.Reads != 3 || got.Seeks != 2 || got.Errors != 1 {
+		t.Fatalf("метрики %+v: ожидалось 10 байт, 3 Read, 2 Seek и 1 ошибка (Read на точке сбоя отдаёт 2 байта без ошибки)", got)
+	}
+
+	mr = NewMeteredReader(testutil.NewStringsReader("ab"), time.Second)
+	if _, err := io.ReadAll(mr); err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if got = mr.Snapshot(); got.Errors != 0 {
+		t.Fatalf("io.EOF не должен считаться ошибкой: %+v", got)
+	}
+}
+
+func TestMeteredReader_ThroughputWindow(t *testing.T) {
+	clock := &manualNow{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	mr := newMeteredReader(testutil.NewStringsReader(strings.Repeat("x", 10000)), time.Second, clock.now)
+	buf := make([]byte, 100)
+
+	// 500 мс по 100 байт каждые 50 мс: пока окно не набрано, среднее считается за прошедшее время
+	for range 10 {
+		clock.advance(50 * time.Millisecond)
+		_, _ = mr.Read(buf)
+	}
+	if got := mr.Snapshot().Throughput; got != 2000 {
+		t.Fatalf("пропускная способность %v байт/с, ожидалось 2000", got)
+	}
+
+	// Источник замолчал: через окно старые корзины перестают учитываться
+	clock.advance(2 * time.Second)
+	if got := mr.Snapshot().Throughput; got != 0 {
+		t.Fatalf("после простоя пропускная способность %v, ожидался 0", got)
+	}
+
+	for range 5 {
+		clock.advance(100 * time.Millisecond)
+		_, _ = mr.Read(buf)
+	}
+	if got := mr.Snapshot().Throughput; got != 500 {
+		t.Fatalf("пропускная способность %v байт/с, ожидалось 500 за окно в 1 с", got)
+	}
+	if got := mr.Snapshot().Bytes; got != 1500 {
+		t.Fatalf("всего прочитано %d байт, ожидалось 1500", got)
+	}
+}
+
+func TestMultiReader_EnableMetering(t *testing.T) {
+	parts := []string{strings.Repeat("a", 100), strings.Repeat("b", 50), strings.Repeat("c", 70)}
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		readers[i] = testutil.NewStringsReader(part)
+	}
+	m := NewMultiReader(16, 2, readers...)
+	defer m.Close()
+	if m.SegmentStats() != nil {
+		t.Fatalf("без EnableMetering метрик быть не должно")
+	}
+
+	// Включение посреди чтения не теряет и не дублирует данные
+	head := make([]byte, 30)
+	if _, err := io.ReadFull(m, head); err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+	m.EnableMetering(time.Second)
+	rest, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", append(head, rest...), []byte(strings.Join(parts, "")))); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := m.SegmentStats()
+	if len(stats) != len(parts) {
+		t.Fatalf("метрик %d, ожидалось %d", len(stats), len(parts))
+	}
+	for i, s := range stats[1:] {
+		if s.Bytes != int64(len(parts[i+1])) || s.Seeks == 0 {
+			t.Errorf("сегмент %d: %+v, ожидалось %d байт и хотя бы один Seek", i+1, s, len(parts[i+1]))
+		}
+	}
+	if stats[0].Bytes == 0 || stats[0].Bytes > int64(len(parts[0])) {
+		t.Errorf("сегмент 0: %d байт после включения метрик, ожидалось от 1 до %d", stats[0].Bytes, len(parts[0]))
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// SizedWriteSeekCloser - интерфейс приёмника заранее известного размера с возможностью seek (например, преаллоцированный файл части).
+type SizedWriteSeekCloser interface {
+	io.WriteSeeker
+	io.Closer
+	Size() int64
+}
+
+// ErrWriteBeyondEnd возвращается при попытке записать за пределы суммарного размера приёмников.
+var ErrWriteBeyondEnd = errors.New("write beyond end of multi writer")
+
+// MultiWriter — зеркало MultiReader для записи: представляет несколько приёмников фиксированного размера
+// единым потоком и направляет запись по абсолютной позиции в нужный приёмник по тем же префиксным суммам.
+// Write и Seek работают с общим курсором; WriteAt не трогает курсор и безопасен для параллельной записи
+// разных диапазонов (например, при загрузке объекта несколькими ranged-запросами). Для приёмников без seek — SequentialWriter.
+type MultiWriter struct {
+	writers     []SizedWriteSeekCloser // приёмники
+	writerMus   []sync.Mutex           // сериализуют пары Seek+Write в каждый приёмник
+	prefixSizes []int64                // абсолютные стартовые позиции приёмников (префиксные суммы)
+	mu          sync.Mutex             // защищает курсор; берётся раньше closeMu
+	pos         int64                  // курсор для Write
+	closeMu     sync.RWMutex           // на чтение держится на время записи, на запись — в Close
+	closed      bool                   // флаг закрытия, защищён closeMu
+}
+
+// Проверка, что MultiWriter удовлетворяет интерфейсам io.WriteSeeker и io.WriterAt
+var (
+	_ io.WriteSeeker = (*MultiWriter)(nil)
+	_ io.WriterAt    = (*MultiWriter)(nil)
+)
+
+// NewMultiWriter создаёт конкатенированный приёмник поверх writers.
+func NewMultiWriter(writers ...SizedWriteSeekCloser) *MultiWriter {
+	prefixSizes := make([]int64, len(writers)+1)
+	for i := 1; i < len(writers)+1; i++ {
+		prefixSizes[i] = prefixSizes[i-1] + writers[i-1].Size()
+	}
+
+	return &MultiWriter{
+		writers:     writers,
+		writerMus:   make([]sync.Mutex, len(writers)),
+		prefixSizes: prefixSizes,
+	}
+}
+
+// Write пишет p с позиции курсора и продвигает его на записанное количество байт.
+func (m *MultiWriter) Write(p []byte) (n int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeMu.RLock()
+	defer m.closeMu.RUnlock()
+	if m.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n, err = m.writeAt(p, m.pos)
+	m.pos += int64(n)
+	return n, err
+}
+
+// WriteAt пишет p с абсолютной позиции off, не меняя курсор. Данные, не помещающиеся в приёмники,
+// не пишутся, а WriteAt возвращает ErrWriteBeyondEnd.
+func (m *MultiWriter) WriteAt(p []byte, off int64) (n int, err error) {
+	m.closeMu.RLock()
+	defer m.closeMu.RUnlock()
+	if m.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	}
+
+	return m.writeAt(p, off)
+}
+
+// Seek перемещает курсор. Позиция должна оставаться в пределах [0, Size].
+func (m *MultiWriter) Seek(offset int64, whence int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeMu.RLock()
+	defer m.closeMu.RUnlock()
+	if m.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	seekPos := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		seekPos += m.pos
+	case io.SeekEnd:
+		seekPos += m.Size()
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+
+	if seekPos < 0 || seekPos > m.Size() {
+		return 0, fmt.Errorf("seek position (%d) should be >= 0 and <= total size (%d)", seekPos, m.Size())
+	}
+	m.pos = seekPos
+
+	return seekPos, nil
+}
+
+// Size возвращает суммарный размер всех приёмников.
+func (m *MultiWriter) Size() int64 {
+	return m.prefixSizes[len(m.writers)]
+}
+
+// Close закрывает все приёмники, агрегируя ошибки. Дожидается завершения идущих Write и WriteAt.
+func (m *MultiWriter) Close() error {
+	m.closeMu.Lock()
+	defer m.closeMu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	return CloseAll(m.writers, "writer")
+}
+
+// writeAt раскладывает p по приёмникам начиная с абсолютной позиции off.
+func (m *MultiWriter) writeAt(p []byte, off int64) (n int, err error) {
+	for n < len(p) {
+		curPos := off + int64(n)
+		if curPos >= m.Size() {
+			return n, ErrWriteBeyondEnd
+		}
+		idx := sort.Search(len(m.writers), func(i int) bool { return m.prefixSizes[i+1] > curPos })
+		chunk := p[n:]
+		if remain := m.prefixSizes[idx+1] - curPos; int64(len(chunk)) > remain {
+			chunk = chunk[:remain]
+		}
+
+		written, err := m.writeSegment(idx, chunk, curPos-m.prefixSizes[idx])
+		n += written
+		if err != nil {
+			return n, fmt.Errorf("write writer %d: %w", idx, err)
+		}
+	}
+
+	return n, nil
+}
+
+// writeSegment пишет chunk в приёмник idx с локальной позиции off.
+func (m *MultiWriter) writeSegment(idx int, chunk []byte, off int64) (int, error) {
+	m.writerMus[idx].Lock()
+	defer m.writerMus[idx].Unlock()
+
+	w := m.writers[idx]
+	_, err := w.Seek(off, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(chunk)
+	if err == nil && n < len(chunk) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// memPart — преаллоцированная часть фиксированного размера в памяти.
+type memPart struct {
+	data     []byte
+	pos      int64
+	closeErr error
+	closed   bool
+}
+
+func newMemParts(sizes ...int) []*memPart {
+	parts := make([]*memPart, len(sizes))
+	for i, size := range sizes {
+		parts[i] = &memPart{data: make([]byte, size)}
+	}
+	return parts
+}
+
+func (p *memPart) Write(b []byte) (int, error) {
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n := copy(p.data[p.pos:], b)
+	p.pos += int64(n)
+	if n < len(b) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+func (p *memPart) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekStart || offset < 0 || offset > int64(len(p.data)) {
+		return 0, fmt.Errorf("unexpected seek %d/%d", offset, whence)
+	}
+	p.pos = offset
+	return offset, nil
+}
+
+func (p *memPart) Close() error {
+	p.closed = true
+	return p.closeErr
+}
+
+func (p *memPart) Size() int64 { return int64(len(p.data)) }
+
+func newTestMultiWriter(parts []*memPart) *MultiWriter {
+	writers := make([]SizedWriteSeekCloser, len(parts))
+	for i, p := range parts {
+		writers[i] = p
+	}
+	return NewMultiWriter(writers...)
+}
+
+func joinParts(parts []*memPart) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		sb.Write(p.data)
+	}
+	return sb.String()
+}
+
+func TestMultiWriter_SequentialWriteAcrossParts(t *testing.T) {
+	parts := newMemParts(3, 0, 4, 2)
+	m := newTestMultiWriter(parts)
+	if m.Size() != 9 {
+		t.Fatalf("Size %d, ожидался 9", m.Size())
+	}
+
+	for _, chunk := range []string{"ab", "cdefg", "hi"} {
+		n, err := m.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q): n=%d, err=%v", chunk, n, err)
+		}
+	}
+	if got := joinParts(parts); got != "abcdefghi" {
+		t.Fatalf("содержимое частей %q, ожидалось %q", got, "abcdefghi")
+	}
+	if got := string(parts[2].data); got != "defg" {
+		t.Fatalf("часть 2: %q, ожидалось %q", got, "defg")
+	}
+}
+
+func TestMultiWriter_SeekAndOverwrite(t *testing.T) {
+	parts := newMemParts(4, 4)
+	m := newTestMultiWriter(parts)
+	if _, err := m.Write([]byte("........")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	steps := []struct {
+		offset int64
+		whence int
+		data   string
+	}{
+		{offset: 3, whence: io.SeekStart, data: "XY"},
+		{offset: -2, whence: io.SeekEnd, data: "Z"},
+		{offset: -6, whence: io.SeekCurrent, data: "W"},
+	}
+	for _, s := range steps {
+		if _, err := m.Seek(s.offset, s.whence); err != nil {
+			t.Fatalf("Seek(%d, %d): %v", s.offset, s.whence, err)
+		}
+		if _, err := m.Write([]byte(s.data)); err != nil {
+			t.Fatalf("Write(%q): %v", s.data, err)
+		}
+	}
+	if got := joinParts(parts); got != ".W.XY.Z." {
+		t.Fatalf("содержимое частей %q, ожидалось %q", got, ".W.XY.Z.")
+	}
+	if _, err := m.Seek(9, io.SeekStart); err == nil {
+		t.Fatalf("Seek за пределы Size должен возвращать ошибку")
+	}
+}
+
+func TestMultiWriter_WriteBeyondEnd(t *testing.T) {
+	parts := newMemParts(2, 2)
+	m := newTestMultiWriter(parts)
+
+	n, err := m.Write([]byte("abcdef"))
+	if !errors.Is(err, ErrWriteBeyondEnd) || n != 4 {
+		t.Fatalf("Write: n=%d, err=%v; ожидались 4 байта и ErrWriteBeyondEnd", n, err)
+	}
+	if _, err = m.WriteAt([]byte("x"), 4); !errors.Is(err, ErrWriteBeyondEnd) {
+		t.Fatalf("WriteAt(4): %v, ожидалась ErrWriteBeyondEnd", err)
+	}
+	if _, err = m.WriteAt([]byte("x"), -1); err == nil {
+		t.Fatalf("WriteAt с отрицательной позицией должен возвращать ошибку")
+	}
+}
+
+func TestMultiWriter_ParallelWriteAt(t *testing.T) {
+	content := strings.Repeat("0123456789abcdefghijklmnopqrstuvwxyz", 30)
+	parts := newMemParts(100, 257, 1, 300, len(content)-658)
+	m := newTestMultiWriter(parts)
+
+	// Имитация загрузки объекта несколькими ranged-запросами: диапазоны пересекают границы частей
+	const rangeSize = 64
+	var wg sync.WaitGroup
+	for off := 0; off < len(content); off += rangeSize {
+		wg.Add(1)
+		go func(off int) {
+			defer wg.Done()
+			end := min(off+rangeSize, len(content))
+			if _, err := m.WriteAt([]byte(content[off:end]), int64(off)); err != nil {
+				t.Errorf("WriteAt(%d): %v", off, err)
+			}
+		}(off)
+	}
+	wg.Wait()
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Записанная раскладка читается обратно MultiReader'ом
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, p := range parts {
+		readers[i] = testutil.NewStringsReader(string(p.data))
+	}
+	r := NewMultiReader(32, 2, readers...)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(content))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMultiWriter_CloseJoinsErrors(t *testing.T) {
+	parts := newMemParts(1, 1, 1)
+	errA, errC := errors.New("flush a"), errors.New("flush c")
+	parts[0].closeErr, parts[2].closeErr = errA, errC
+	m := newTestMultiWriter(parts)
+
+	err := m.Close()
+	if !errors.Is(err, errA) || !errors.Is(err, errC) {
+		t.Fatalf("Close: %v, ожидались обе ошибки закрытия", err)
+	}
+	for i, p := range parts {
+		if !p.closed {
+			t.Errorf("часть %d не закрыта", i)
+		}
+	}
+	if err = m.Close(); err != nil {
+		t.Fatalf("повторный Close: %v", err)
+	}
+	if _, err = m.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Write после Close: %v, ожидалась io.ErrClosedPipe", err)
+	}
+	if _, err = m.WriteAt([]byte("x"), 0); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("WriteAt после Close: %v, ожидалась io.ErrClosedPipe", err)
+	}
+}
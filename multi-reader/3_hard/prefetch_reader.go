@@ -0,0 +1,36 @@
+package main
+
+// PrefetchReader — упреждающее чтение одного источника (файла, HTTP-объекта): пока пользователь обрабатывает
+// текущий блок, фоновая горутина читает следующие depth блоков по blockSize байт.
+// Использует тот же префетчер, что и MultiReader, включая сброс при Seek за пределы окна и сериализацию Read.
+type PrefetchReader struct {
+	m *MultiReader
+}
+
+// Проверка, что PrefetchReader удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*PrefetchReader)(nil)
+
+// NewPrefetchReader оборачивает r упреждающим чтением блоками по blockSize байт с глубиной depth.
+func NewPrefetchReader(r SizedReadSeekCloser, blockSize int64, depth int) *PrefetchReader {
+	return &PrefetchReader{m: NewMultiReader(blockSize, depth, r)}
+}
+
+// Read читает данные, подготовленные префетчером.
+func (pr *PrefetchReader) Read(p []byte) (int, error) {
+	return pr.m.Read(p)
+}
+
+// Seek перемещает позицию; переход за пределы прочитанного окна перезапускает префетч с новой позиции.
+func (pr *PrefetchReader) Seek(offset int64, whence int) (int64, error) {
+	return pr.m.Seek(offset, whence)
+}
+
+// Size возвращает размер источника.
+func (pr *PrefetchReader) Size() int64 {
+	return pr.m.Size()
+}
+
+// Close останавливает префетч и закрывает источник.
+func (pr *PrefetchReader) Close() error {
+	return pr.m.Close()
+}
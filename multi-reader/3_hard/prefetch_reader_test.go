@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/readertest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func TestConformance_PrefetchReader(t *testing.T) {
+	readertest.RunReaderConformance(t, func(_ *testing.T, content []byte) readertest.SizedReadSeekCloser {
+		return NewPrefetchReader(testutil.NewStringsReader(string(content)), 3, 2)
+	})
+}
+
+func TestPrefetchReader_ReadsAhead(t *testing.T) {
+	const blockSize, depth = 16, 3
+	src := NewMeteredReader(newMockGeneratedReader(1, 1<<10), 0)
+	pr := NewPrefetchReader(src, blockSize, depth)
+	defer pr.Close()
+
+	if _, err := io.ReadFull(pr, make([]byte, 1)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	// Окно плюс depth блоков в канале плюс блок, ожидающий отправки
+	want := int64(blockSize * (depth + 2))
+	deadline := time.Now().Add(5 * time.Second)
+	for src.Snapshot().Bytes < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("префетчер прочитал %d байт наперёд, ожидалось %d", src.Snapshot().Bytes, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := src.Snapshot().Bytes; got > want {
+		t.Fatalf("префетчер прочитал %d байт, больше глубины %d", got, want)
+	}
+}
@@ -2,330 +2,342 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strings"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
 )
 
 const bufferSize = 1024 * 1024
 
-var privateTestCases = []TestCase{
+var privateTestCases = []testutil.TestCase{
 	{
-		name: "Seek от конца",
-		run: func() bool {
-			a := newMockStringsReader("abc")
-			b := newMockStringsReader("def")
+		Name: "Seek от конца",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
+			b := testutil.NewStringsReader("def")
 			m := NewMultiReader(bufferSize, 4, a, b)
 
 			pos, err := m.Seek(-2, io.SeekEnd)
-			if err != nil || pos != 4 {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, int64(4))); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 2)
 			n, err := m.Read(buf)
-			if err != nil || n != 2 {
-				return false
-			}
-			return string(buf) == "ef"
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 2),
+				testutil.ExpectBytes("данные", buf, []byte("ef")),
+			)
 		},
 	},
 	{
-		name: "Seek от текущей позиции",
-		run: func() bool {
-			a := newMockStringsReader("abcd")
+		Name: "Seek от текущей позиции",
+		Run: func() error {
+			a := testutil.NewStringsReader("abcd")
 			m := NewMultiReader(bufferSize, 4, a)
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if err != nil || n != 1 || string(buf) != "a" {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("первый Read", err),
+				testutil.ExpectEqual("первый Read n", n, 1),
+				testutil.ExpectBytes("первый байт", buf, []byte("a")),
+			); err != nil {
+				return err
 			}
 
 			pos, err := m.Seek(2, io.SeekCurrent)
-			if err != nil || pos != 3 {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, int64(3))); err != nil {
+				return err
 			}
 
 			n, err = m.Read(buf)
-			if err != nil || n != 1 {
-				return false
-			}
-			return string(buf) == "d"
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectEqual("второй Read n", n, 1),
+				testutil.ExpectBytes("байт после Seek", buf, []byte("d")),
+			)
 		},
 	},
 	{
-		name: "Ошибочные варианты Seek",
-		run: func() bool {
-			a := newMockStringsReader("abc")
+		Name: "Ошибочные варианты Seek",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
 			m := NewMultiReader(bufferSize, 4, a)
 
-			if _, err := m.Seek(0, 99); err == nil {
-				return false
-			}
-			if _, err := m.Seek(-1, io.SeekStart); err == nil {
-				return false
-			}
-			if _, err := m.Seek(5, io.SeekStart); err == nil {
-				return false
-			}
-			return true
+			_, errWhence := m.Seek(0, 99)
+			_, errNegative := m.Seek(-1, io.SeekStart)
+			_, errBeyond := m.Seek(5, io.SeekStart)
+			return testutil.Check(
+				testutil.ExpectError("Seek с неизвестным whence", errWhence),
+				testutil.ExpectError("Seek на отрицательную позицию", errNegative),
+				testutil.ExpectError("Seek за конец потока", errBeyond),
+			)
 		},
 	},
-	//{
-	//	name: "Close агрегирует ошибки",
-	//	run: func() bool {
-	//		errA := errors.New("A")
-	//		errB := errors.New("B")
-	//		a := newMockStringsReader("x")
-	//		b := newMockStringsReader("y")
-	//		c := newMockStringsReader("z")
-	//		a.closeErr = errA
-	//		b.closeErr = errB
-	//
-	//		m := NewMultiReader(bufferSize, 4, a, b, c)
-	//
-	//		err := m.Close()
-	//		if err == nil {
-	//			return false
-	//		}
-	//		if !errors.Is(err, errA) || !errors.Is(err, errB) {
-	//			return false
-	//		}
-	//		return a.closed && b.closed && c.closed
-	//	},
-	//},
 	{
-		name: "Read/Seek после Close",
-		run: func() bool {
-			a := newMockStringsReader("abc")
-			m := NewMultiReader(bufferSize, 4, a)
+		Name: "Close агрегирует ошибки",
+		Run: func() error {
+			errA := errors.New("A")
+			errB := errors.New("B")
+			a := testutil.NewStringsReader("x")
+			b := testutil.NewStringsReader("y")
+			c := testutil.NewStringsReader("z")
+			a.FailClose(errA)
+			b.FailClose(errB)
 
-			err := m.Close()
-			if err != nil {
-				return false
-			}
+			m := NewMultiReader(bufferSize, 4, a, b, c)
 
-			buf := make([]byte, 1)
-			n, err := m.Read(buf)
-			if n != 0 || !errors.Is(err, io.ErrClosedPipe) {
-				return false
-			}
+			err := m.Close()
+			return testutil.Check(
+				testutil.ExpectErrorIs("Close", err, errA),
+				testutil.ExpectErrorIs("Close", err, errB),
+				testutil.ExpectTrue("все ридеры закрыты", a.Closed() && b.Closed() && c.Closed()),
+			)
+		},
+	},
+	{
+		Name: "Read/Seek после Close",
+		Run: func() error {
+			a := testutil.NewStringsReader("abc")
+			m := NewMultiReader(bufferSize, 4, a)
 
-			if _, err = m.Seek(0, io.SeekStart); !errors.Is(err, io.ErrClosedPipe) {
-				return false
+			if err := testutil.ExpectNoError("Close", m.Close()); err != nil {
+				return err
 			}
 
-			err = m.Close()
-			return err == nil
+			buf := make([]byte, 1)
+			n, errRead := m.Read(buf)
+			_, errSeek := m.Seek(0, io.SeekStart)
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 0),
+				testutil.ExpectErrorIs("Read после Close", errRead, io.ErrClosedPipe),
+				testutil.ExpectErrorIs("Seek после Close", errSeek, io.ErrClosedPipe),
+				testutil.ExpectNoError("повторный Close", m.Close()),
+			)
 		},
 	},
 	{
-		name: "Size кэшируется и не пересчитывается",
-		run: func() bool {
-			var calls int
-			tr1 := newMockStringsReader(strings.Repeat("a", 2))
-			tr2 := newMockStringsReader(strings.Repeat("b", 3))
-			tr1.sizeCalls = &calls
-			tr2.sizeCalls = &calls
+		Name: "Size кэшируется и не пересчитывается",
+		Run: func() error {
+			trace := &testutil.CallTrace{}
+			tr1 := testutil.NewStringsReader(strings.Repeat("a", 2)).TraceTo(trace, "tr1")
+			tr2 := testutil.NewStringsReader(strings.Repeat("b", 3)).TraceTo(trace, "tr2")
 
 			m := NewMultiReader(bufferSize, 4, tr1, tr2)
-			if calls != 2 {
-				return false
+			if err := testutil.ExpectEqual("вызовы Size при создании", trace.Count(0, "", testutil.OpSize), 2); err != nil {
+				return err
 			}
+			mark := trace.Mark()
 			_ = m.Size()
 			_ = m.Size()
-			return calls == 2
+			return trace.ExpectNoCalls("m.Size()", mark, "", testutil.OpSize)
 		},
 	},
 	{
-		name: "Ленивый Seek выполняется при первом чтении",
-		run: func() bool {
-			var seekCalls1, seekCalls2 int
-			tr1 := newMockStringsReader("abc")
-			tr2 := newMockStringsReader("def")
-			tr1.seekCalls = &seekCalls1
-			tr2.seekCalls = &seekCalls2
+		Name: "Ленивый Seek выполняется при первом чтении",
+		Run: func() error {
+			trace := &testutil.CallTrace{}
+			tr1 := testutil.NewStringsReader("abc").TraceTo(trace, "tr1")
+			tr2 := testutil.NewStringsReader("def").TraceTo(trace, "tr2")
 
 			m := NewMultiReader(bufferSize, 4, tr1, tr2)
 
 			pos, err := m.Seek(4, io.SeekStart)
-			if err != nil || pos != 4 {
-				return false
-			}
-			if seekCalls1 != 0 || seekCalls2 != 0 {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("Seek", err),
+				testutil.ExpectEqual("позиция", pos, int64(4)),
+				trace.ExpectNoCalls("Seek до Read", 0, "", testutil.OpSeek),
+			); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if err != nil || n != 1 || string(buf) != "e" {
-				return false
-			}
-			if seekCalls1 != 0 {
-				return false
-			}
-			return seekCalls2 > 0
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 1),
+				testutil.ExpectBytes("данные", buf, []byte("e")),
+				trace.ExpectNoCalls("Read после Seek", 0, "tr1", testutil.OpSeek),
+				trace.ExpectCalls("Read после Seek", 0, "tr2", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Seek на EOF допустим и Read возвращает EOF",
-		run: func() bool {
-			a := newMockStringsReader("data")
+		Name: "Seek на EOF допустим и Read возвращает EOF",
+		Run: func() error {
+			a := testutil.NewStringsReader("data")
 			m := NewMultiReader(bufferSize, 4, a)
 
 			size := m.Size()
 			pos, err := m.Seek(0, io.SeekEnd)
-			if err != nil || pos != size {
-				return false
+			if err := testutil.Check(testutil.ExpectNoError("Seek", err), testutil.ExpectEqual("позиция", pos, size)); err != nil {
+				return err
 			}
 
 			buf := make([]byte, 1)
 			n, err := m.Read(buf)
-			if n != 0 {
-				return false
-			}
-			return errors.Is(err, io.EOF)
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 0),
+				testutil.ExpectErrorIs("Read", err, io.EOF),
+			)
 		},
 	},
 	{
-		name: "Read с нулевой длиной возвращает (0, nil)",
-		run: func() bool {
-			a := newMockStringsReader("xy")
+		Name: "Read с нулевой длиной возвращает (0, nil)",
+		Run: func() error {
+			a := testutil.NewStringsReader("xy")
 			m := NewMultiReader(bufferSize, 4, a)
 			n, err := m.Read(nil)
-			return n == 0 && err == nil
+			return testutil.Check(testutil.ExpectEqual("Read n", n, 0), testutil.ExpectNoError("Read", err))
 		},
 	},
 	{
-		name: "Seek внутри буферного окна не вызывает нижний Seek",
-		run: func() bool {
-			var seekCalls int
-			a := newMockStringsReader("hello world")
-			a.seekCalls = &seekCalls
+		Name: "Seek внутри буферного окна не вызывает нижний Seek",
+		Run: func() error {
+			trace := &testutil.CallTrace{}
+			a := testutil.NewStringsReader("hello world").TraceTo(trace, "a")
 			m := NewMultiReader(bufferSize, 4, a)
 			buf := make([]byte, 1)
 			// Старт чтения, префетчер станет активным и сделает первый Seek
-			if n, err := m.Read(buf); err != nil || n != 1 {
-				return false
+			n, err := m.Read(buf)
+			if err := testutil.Check(testutil.ExpectNoError("первый Read", err), testutil.ExpectEqual("первый Read n", n, 1)); err != nil {
+				return err
 			}
-			before := seekCalls
+			mark := trace.Mark()
 			// Переход вперёд на 1 байт — должен быть внутри уже буферизованного окна
 			if _, err := m.Seek(1, io.SeekCurrent); err != nil {
-				return false
+				return testutil.ExpectNoError("Seek", err)
 			}
 			// Следующее чтение должно прийти из буфера, без новых Seek в источнике
-			if n, err := m.Read(buf); err != nil || n != 1 {
-				return false
-			}
-			return seekCalls == before
+			n, err = m.Read(buf)
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectEqual("второй Read n", n, 1),
+				trace.ExpectNoCalls("чтение внутри окна", mark, "", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Seek назад внутри head-буфера и сразу Read — буфер сбрасывается",
-		run: func() bool {
+		Name: "Seek назад внутри head-буфера и сразу Read — буфер сбрасывается",
+		Run: func() error {
 			// Сценарий: внутри одного большого head-буфера (bufferSize >> данных) читаем часть,
 			// откатываемся на 1 байт внутри головы, читаем снова — нижний Seek прибавляется.
-			var seeks int
-			r := newMockStringsReader("abcdef")
-			r.seekCalls = &seeks
+			trace := &testutil.CallTrace{}
+			r := testutil.NewStringsReader("abcdef").TraceTo(trace, "r")
 			m := NewMultiReader(bufferSize, 2, r)
 			buf := make([]byte, 4)
-			if n, err := m.Read(buf); err != nil || n != 4 || string(buf) != "abcd" {
-				return false
-			}
-			before := seeks
+			n, err := m.Read(buf)
+			if err := testutil.Check(
+				testutil.ExpectNoError("первый Read", err),
+				testutil.ExpectEqual("первый Read n", n, 4),
+				testutil.ExpectBytes("данные", buf, []byte("abcd")),
+			); err != nil {
+				return err
+			}
+			mark := trace.Mark()
 			if _, err := m.Seek(-1, io.SeekCurrent); err != nil { // позиция на 'd' (внутри head)
-				return false
+				return testutil.ExpectNoError("Seek", err)
 			}
 			b2 := make([]byte, 1)
-			n, err := m.Read(b2)
-			if err != nil || n != 1 || string(b2) != "d" {
-				return false
-			}
-			return seeks != before
+			n, err = m.Read(b2)
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectEqual("второй Read n", n, 1),
+				testutil.ExpectBytes("байт после Seek", b2, []byte("d")),
+				trace.ExpectCalls("выполнен новый нижний Seek", mark, "", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Seek назад за пределы окна (после смены head) инициирует новый нижний Seek",
-		run: func() bool {
+		Name: "Seek назад за пределы окна (после смены head) инициирует новый нижний Seek",
+		Run: func() error {
 			// Схема: два ридера. Полностью исчерпываем первый, чтобы сдвинуть bufferStart,
 			// затем откатываемся на 0 (левее окна) и проверяем, что требуется новый нижний Seek.
-			var seeks int
-			r1 := newMockStringsReader("hello") // 5 байт
-			r2 := newMockStringsReader("world!")
-			r1.seekCalls = &seeks
-			r2.seekCalls = &seeks
+			trace := &testutil.CallTrace{}
+			r1 := testutil.NewStringsReader("hello").TraceTo(trace, "r1") // 5 байт
+			r2 := testutil.NewStringsReader("world!").TraceTo(trace, "r2")
 			m := NewMultiReader(bufferSize, 2, r1, r2)
 			buf := make([]byte, 5)
-			if n, err := m.Read(buf); err != nil || n != 5 { // полностью съели r1 → head переедет на r2
-				return false
+			n, err := m.Read(buf) // полностью съели r1 → head переедет на r2
+			if err := testutil.Check(testutil.ExpectNoError("первый Read", err), testutil.ExpectEqual("первый Read n", n, 5)); err != nil {
+				return err
 			}
-			before := seeks
+			mark := trace.Mark()
 			if _, err := m.Seek(0, io.SeekStart); err != nil {
-				return false
+				return testutil.ExpectNoError("Seek", err)
 			}
 			b := make([]byte, 1)
-			if n, err := m.Read(b); err != nil || n != 1 {
-				return false
-			}
-			return seeks > before
+			n, err = m.Read(b)
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectEqual("второй Read n", n, 1),
+				trace.ExpectCalls("выполнен новый нижний Seek", mark, "", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Дальний Seek вперёд за окно и немедленный Read — новый Seek",
-		run: func() bool {
+		Name: "Дальний Seek вперёд за окно и немедленный Read — новый Seek",
+		Run: func() error {
 			// С одним буфером окно = [bufferStart, bufferStart+bufferSize).
 			// Длина данных > bufferSize, поэтому Seek далеко вперёд выйдет за текущий буфер и потребует нового нижнего Seek.
-			var seeks int
-			r := newMockStringsReader(strings.Repeat("x", bufferSize+100))
-			r.seekCalls = &seeks
+			trace := &testutil.CallTrace{}
+			r := testutil.NewStringsReader(strings.Repeat("x", bufferSize+100)).TraceTo(trace, "r")
 			m := NewMultiReader(bufferSize, 1, r)
 			buf := make([]byte, 8)
 			_, _ = m.Read(buf) // прогреем окно, префетчер сделает первый Seek
-			before := seeks
+			mark := trace.Mark()
 			if _, err := m.Seek(int64(bufferSize+50), io.SeekStart); err != nil {
-				return false
+				return testutil.ExpectNoError("Seek", err)
 			}
 			b2 := make([]byte, 1)
 			n, err := m.Read(b2)
-			if err != nil || n != 1 || string(b2) != "x" {
-				return false
-			}
-			return seeks > before
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, 1),
+				testutil.ExpectBytes("данные", b2, []byte("x")),
+				trace.ExpectCalls("выполнен новый нижний Seek", mark, "", testutil.OpSeek),
+			)
 		},
 	},
 	{
-		name: "Маленькие ридеры, большие буферы",
-		run: func() bool {
-			a := newMockStringsReader("aaaaa")
-			b := newMockStringsReader("bbb")
-			c := newMockStringsReader("cccccccc")
+		Name: "Маленькие ридеры, большие буферы",
+		Run: func() error {
+			a := testutil.NewStringsReader("aaaaa")
+			b := testutil.NewStringsReader("bbb")
+			c := testutil.NewStringsReader("cccccccc")
 			m := NewMultiReader(bufferSize, 2, a, b, c)
 			buf := make([]byte, int(m.Size()))
 			n, err := m.Read(buf)
-			if err != nil || n != len(buf) {
-				return false
-			}
-			return string(buf) == "aaaaabbbcccccccc"
+			return testutil.Check(
+				testutil.ExpectNoError("Read", err),
+				testutil.ExpectEqual("Read n", n, len(buf)),
+				testutil.ExpectBytes("данные", buf, []byte("aaaaabbbcccccccc")),
+			)
 		},
 	},
 	{
-		name: "EOF при достижении конца общего потока",
-		run: func() bool {
-			r := newMockStringsReader("z")
+		Name: "EOF при достижении конца общего потока",
+		Run: func() error {
+			r := testutil.NewStringsReader("z")
 			m := NewMultiReader(bufferSize, 1, r)
 			b := make([]byte, 10)
 			n, err := m.Read(b)
-			if n != 1 || string(b[:n]) != "z" || !errors.Is(err, io.EOF) {
-				return false
-			}
-			return true
+			return testutil.Check(
+				testutil.ExpectEqual("Read n", n, 1),
+				testutil.ExpectBytes("данные", b[:n], []byte("z")),
+				testutil.ExpectErrorIs("Read", err, io.EOF),
+			)
 		},
 	},
 	{
-		name: "Close во время фонового чтения не падает",
-		run: func() bool {
-			r := newMockStringsReader(strings.Repeat("a", 1<<16))
+		Name: "Close во время фонового чтения не падает",
+		Run: func() error {
+			r := testutil.NewStringsReader(strings.Repeat("a", 1<<16))
 			m := NewMultiReader(bufferSize, 2, r)
 			done := make(chan struct{})
 			go func() {
@@ -335,56 +347,246 @@ var privateTestCases = []TestCase{
 			}()
 			_ = m.Close()
 			<-done
-			return true
+			return nil
 		},
 	},
 	{
-		name: "Большие данные: полное чтение и чтение через границы",
-		run: func() bool {
+		Name: "Большие данные: полное чтение и чтение через границы",
+		Run: func() error {
 			// Сгенерируем несколько «больших» источников по ~1–2KB суммарно
 			s1 := strings.Repeat("A", 1024)
 			s2 := strings.Repeat("B", 768)
 			s3 := strings.Repeat("C", 512)
-			a := newMockStringsReader(s1)
-			b := newMockStringsReader(s2)
-			c := newMockStringsReader(s3)
+			a := testutil.NewStringsReader(s1)
+			b := testutil.NewStringsReader(s2)
+			c := testutil.NewStringsReader(s3)
 			m := NewMultiReader(bufferSize, 4, a, b, c)
 
 			// Полное чтение и сравнение
 			expected := s1 + s2 + s3
 			buf := make([]byte, len(expected))
 			n, err := m.Read(buf)
-			if err != nil || n != len(expected) || string(buf) != expected {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("полное чтение", err),
+				testutil.ExpectEqual("полное чтение n", n, len(expected)),
+				testutil.ExpectBytes("полное чтение", string(buf), expected),
+			); err != nil {
+				return err
 			}
 
 			// Seek в конце первого ридера минус 10, прочитать 20 байт — пересекаем границу A->B
 			if _, err := m.Seek(int64(len(s1)-10), io.SeekStart); err != nil {
-				return false
+				return testutil.ExpectNoError("Seek к границе A->B", err)
 			}
 			buf2 := make([]byte, 20)
 			n, err = m.Read(buf2)
-			if err != nil || n != 20 {
-				return false
-			}
-			if string(buf2) != strings.Repeat("A", 10)+strings.Repeat("B", 10) {
-				return false
+			if err := testutil.Check(
+				testutil.ExpectNoError("чтение через A->B", err),
+				testutil.ExpectEqual("чтение через A->B n", n, 20),
+				testutil.ExpectBytes("чтение через A->B", string(buf2), strings.Repeat("A", 10)+strings.Repeat("B", 10)),
+			); err != nil {
+				return err
 			}
 
 			// Seek на конец второго ридера минус 5, прочитать 15 — пересекаем границу B->C
 			offset := int64(len(s1) + len(s2) - 5)
 			if _, err := m.Seek(offset, io.SeekStart); err != nil {
-				return false
+				return testutil.ExpectNoError("Seek к границе B->C", err)
 			}
 			buf3 := make([]byte, 15)
 			n, err = m.Read(buf3)
-			if err != nil || n != 15 {
-				return false
+			return testutil.Check(
+				testutil.ExpectNoError("чтение через B->C", err),
+				testutil.ExpectEqual("чтение через B->C n", n, 15),
+				testutil.ExpectBytes("чтение через B->C", string(buf3), strings.Repeat("B", 5)+strings.Repeat("C", 10)),
+			)
+		},
+	},
+	{
+		Name: "Ошибка источника посреди потока: сначала данные, затем ошибка",
+		Run: func() error {
+			errBroken := errors.New("broken source")
+			a := testutil.NewStringsReader("abcdefgh").FailReadAfter(5, errBroken)
+			m := NewMultiReader(2, 4, a)
+
+			buf := make([]byte, 8)
+			n, err := m.Read(buf)
+			return testutil.Check(
+				testutil.ExpectErrorIs("Read", err, errBroken),
+				testutil.ExpectBytes("данные до сбоя", buf[:n], []byte("abcde")),
+			)
+		},
+	},
+	{
+		Name: "После ошибки источника следующий Read продолжает с текущей позиции",
+		Run: func() error {
+			errTransient := errors.New("transient")
+			a := testutil.NewStringsReader("abcdef").FailReadAfter(3, errTransient)
+			m := NewMultiReader(bufferSize, 2, a)
+
+			buf := make([]byte, 6)
+			n, err := m.Read(buf)
+			if err := testutil.Check(testutil.ExpectErrorIs("первый Read", err, errTransient), testutil.ExpectEqual("первый Read n", n, 3)); err != nil {
+				return err
+			}
+
+			a.FailReadAfter(0, nil) // Сбой устранён
+			n, err = m.Read(buf[:3])
+			return testutil.Check(
+				testutil.ExpectNoError("второй Read", err),
+				testutil.ExpectBytes("данные после сбоя", buf[:n], []byte("def")),
+			)
+		},
+	},
+	{
+		Name: "Короткие чтения источников не искажают данные",
+		Run: func() error {
+			a := testutil.NewStringsReader("hello ").ShortReads(1)
+			b := testutil.NewStringsReader("short ").ShortReads(2)
+			c := testutil.NewStringsReader("reads").ShortReads(3)
+			m := NewMultiReader(4, 2, a, b, c)
+
+			data, err := io.ReadAll(m)
+			return testutil.Check(
+				testutil.ExpectNoError("ReadAll", err),
+				testutil.ExpectBytes("данные", string(data), "hello short reads"),
+			)
+		},
+	},
+	{
+		Name: "Ошибка Seek источника возвращается из Read",
+		Run: func() error {
+			errSeek := errors.New("seek failed")
+			a := testutil.NewStringsReader("abc")
+			b := testutil.NewStringsReader("def").FailSeek(func(int64, int) error { return errSeek })
+			m := NewMultiReader(bufferSize, 2, a, b)
+
+			buf := make([]byte, 6)
+			n, err := m.Read(buf)
+			return testutil.Check(
+				testutil.ExpectErrorIs("Read", err, errSeek),
+				testutil.ExpectBytes("данные первого ридера", buf[:n], []byte("abc")),
+			)
+		},
+	},
+	{
+		Name: "Выборочная ошибка Seek при переходе назад",
+		Run: func() error {
+			errSeek := errors.New("rewind not supported")
+			a := testutil.NewStringsReader("abcdef").FailSeek(func(offset int64, _ int) error {
+				if offset < 3 {
+					return errSeek
+				}
+				return nil
+			})
+			m := NewMultiReader(bufferSize, 2, a)
+
+			if _, err := m.Seek(3, io.SeekStart); err != nil {
+				return testutil.ExpectNoError("Seek вперёд", err)
+			}
+			buf := make([]byte, 3)
+			n, err := m.Read(buf)
+			if err := testutil.Check(testutil.ExpectNoError("Read после Seek вперёд", err), testutil.ExpectBytes("данные", buf[:n], []byte("def"))); err != nil {
+				return err
 			}
-			if string(buf3) != strings.Repeat("B", 5)+strings.Repeat("C", 10) {
-				return false
+
+			if _, err := m.Seek(0, io.SeekStart); err != nil {
+				return testutil.ExpectNoError("Seek назад", err)
 			}
-			return true
+			n, err = m.Read(buf)
+			return testutil.Check(
+				testutil.ExpectEqual("Read после Seek назад n", n, 0),
+				testutil.ExpectErrorIs("Read после Seek назад", err, errSeek),
+			)
+		},
+	},
+	{
+		Name: "Ошибка Close одного источника не мешает закрыть остальные",
+		Run: func() error {
+			errClose := errors.New("close failed")
+			a := testutil.NewStringsReader("x").FailClose(errClose)
+			b := testutil.NewStringsReader("y")
+			m := NewMultiReader(bufferSize, 2, a, b)
+
+			err := m.Close()
+			return testutil.Check(
+				testutil.ExpectErrorIs("Close", err, errClose),
+				testutil.ExpectTrue("второй ридер закрыт", b.Closed()),
+			)
+		},
+	},
+	{
+		Name: "Префетч скрывает задержку источника",
+		Run: func() error {
+			const (
+				blocks  = 6
+				block   = 4
+				latency = 30 * time.Millisecond
+			)
+			r := newMockLatencyReader(strings.Repeat("x", blocks*block), latency, 0, 0)
+			m := NewMultiReader(block, blocks, r)
+
+			// Потребитель тратит на блок столько же, сколько источник: без префетча вышло бы 2*blocks*latency
+			start := time.Now()
+			buf := make([]byte, block)
+			for range blocks {
+				if _, err := io.ReadFull(m, buf); err != nil {
+					return testutil.ExpectNoError("ReadFull", err)
+				}
+				time.Sleep(latency)
+			}
+			elapsed := time.Since(start)
+
+			sequential := 2 * blocks * latency
+			return testutil.ExpectTrue(fmt.Sprintf("чтение заняло %v, последовательно было бы %v", elapsed, sequential), elapsed < sequential*4/5)
+		},
+	},
+	{
+		Name: "Close прерывает медленный префетч за время одного чтения",
+		Run: func() error {
+			const latency = 100 * time.Millisecond
+			r := newMockLatencyReader(strings.Repeat("x", 64), latency, 0, 10*time.Millisecond)
+			m := NewMultiReader(1, 2, r)
+
+			buf := make([]byte, 1)
+			if _, err := m.Read(buf); err != nil { // Префетчер запущен и висит в медленном Read
+				return testutil.ExpectNoError("Read", err)
+			}
+
+			start := time.Now()
+			err := m.Close()
+			elapsed := time.Since(start)
+			return testutil.Check(
+				testutil.ExpectNoError("Close", err),
+				testutil.ExpectTrue(fmt.Sprintf("Close занял %v", elapsed), elapsed < 3*latency),
+			)
+		},
+	},
+	{
+		Name: "Seek далеко вперёд прерывает медленный префетч за время одного чтения",
+		Run: func() error {
+			const latency = 100 * time.Millisecond
+			r := newMockLatencyReader(strings.Repeat("ab", 32), latency, 0, 0)
+			m := NewMultiReader(1, 2, r)
+
+			buf := make([]byte, 1)
+			if _, err := m.Read(buf); err != nil {
+				return testutil.ExpectNoError("Read", err)
+			}
+
+			start := time.Now()
+			pos, err := m.Seek(-1, io.SeekEnd)
+			elapsed := time.Since(start)
+			if err := testutil.Check(
+				testutil.ExpectNoError("Seek", err),
+				testutil.ExpectEqual("позиция", pos, int64(63)),
+				testutil.ExpectTrue(fmt.Sprintf("Seek занял %v", elapsed), elapsed < 3*latency),
+			); err != nil {
+				return err
+			}
+
+			n, err := m.Read(buf)
+			return testutil.Check(testutil.ExpectEqual("Read n", n, 1), testutil.ExpectBytes("данные", buf[:n], []byte("b")), testutil.ExpectNoError("Read", err))
 		},
 	},
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+const propertyChecks = 500
+
+// segmentSet — случайный набор сегментов для property-тестов (включая пустые сегменты и пустой набор).
+type segmentSet []string
+
+func (segmentSet) Generate(r *rand.Rand, size int) reflect.Value {
+	segs := make(segmentSet, r.Intn(6))
+	for i := range segs {
+		b := make([]byte, r.Intn(size+1))
+		r.Read(b)
+		segs[i] = string(b)
+	}
+	return reflect.ValueOf(segs)
+}
+
+// prefetchParams — случайные параметры префетча.
+type prefetchParams struct {
+	bufferSize int64
+	buffersNum int
+}
+
+func (prefetchParams) Generate(r *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(prefetchParams{bufferSize: int64(r.Intn(16) + 1), buffersNum: r.Intn(4) + 1})
+}
+
+func (segs segmentSet) newMultiReader(pp prefetchParams) *MultiReader {
+	readers := make([]SizedReadSeekCloser, len(segs))
+	for i, s := range segs {
+		readers[i] = testutil.NewStringsReader(s)
+	}
+	return NewMultiReader(pp.bufferSize, pp.buffersNum, readers...)
+}
+
+// readChunked читает r до конца кусками размера chunk.
+func readChunked(r io.Reader, chunk int) ([]byte, error) {
+	var out []byte
+	buf := make([]byte, chunk)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
+
+func quickConfig() *quick.Config {
+	return &quick.Config{MaxCount: propertyChecks, Rand: rand.New(rand.NewSource(1))}
+}
+
+func TestProperty_SequentialReadMatchesIOMultiReader(t *testing.T) {
+	property := func(segs segmentSet, pp prefetchParams, chunk uint8) bool {
+		m := segs.newMultiReader(pp)
+		defer m.Close()
+
+		refReaders := make([]io.Reader, len(segs))
+		for i, s := range segs {
+			refReaders[i] = strings.NewReader(s)
+		}
+		want, _ := io.ReadAll(io.MultiReader(refReaders...))
+
+		got, err := readChunked(m, int(chunk)+1)
+		return err == nil && bytes.Equal(got, want)
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProperty_SizeIsSumOfSegments(t *testing.T) {
+	property := func(segs segmentSet, pp prefetchParams) bool {
+		m := segs.newMultiReader(pp)
+		defer m.Close()
+		return m.Size() == int64(len(strings.Join(segs, "")))
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProperty_SeekInvariants(t *testing.T) {
+	property := func(segs segmentSet, pp prefetchParams, k uint16, chunk uint8) bool {
+		m := segs.newMultiReader(pp)
+		defer m.Close()
+		all := strings.Join(segs, "")
+		size := m.Size()
+		pos := int64(k) % (size + 1)
+
+		// Позиции, возвращаемые Seek, согласованы между собой для всех whence
+		if p, err := m.Seek(pos, io.SeekStart); err != nil || p != pos {
+			return false
+		}
+		if p, err := m.Seek(0, io.SeekCurrent); err != nil || p != pos {
+			return false
+		}
+		if p, err := m.Seek(pos-size, io.SeekEnd); err != nil || p != pos {
+			return false
+		}
+
+		// Позиции за пределами [0, size] отклоняются и не сдвигают курсор
+		if _, err := m.Seek(-1, io.SeekStart); err == nil {
+			return false
+		}
+		if _, err := m.Seek(1, io.SeekEnd); err == nil {
+			return false
+		}
+
+		got, err := readChunked(m, int(chunk)+1)
+		return err == nil && string(got) == all[pos:]
+	}
+	if err := quick.Check(property, quickConfig()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Range возвращает независимый ридер диапазона [off, off+length) конкатенированного потока: у него свой курсор
+// и свой префетчер, поэтому несколько Range (например, HTTP Range-запросы) читают параллельно друг с другом
+// и с самим MultiReader, не сдвигая его позицию. Ридер также реализует Seek и Size в пределах диапазона.
+//
+// Источники с io.ReaderAt (в том числе FileSegment) читаются через ReadAt. Остальные — через их Seek и Read
+// под общим с MultiReader замком источника; обёртки, которые следят за последовательностью чтения
+// (HashSegment), при этом теряют проверку. Close ридера диапазона не закрывает источники;
+// сам MultiReader нужно закрывать после всех своих Range.
+func (m *MultiReader) Range(off, length int64) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, io.ErrClosedPipe
+	}
+	if off < 0 || length < 0 || !m.sizes.contains(off+length) {
+		return nil, fmt.Errorf("range [%d, %d) is out of stream [0, %d)", off, off+length, m.Size())
+	}
+
+	var views []SizedReadSeekCloser
+	end := off + length
+	for idx := m.readerIndex(off); idx < len(m.readers) && m.sizes.start(idx) < end; idx++ {
+		start := max(off, m.sizes.start(idx))
+		views = append(views, &sourceView{
+			src:  m.readers[idx],
+			mu:   &m.srcMu[idx],
+			base: start - m.sizes.start(idx),
+			size: min(end, m.sizes.end(idx)) - start,
+		})
+	}
+
+	r := NewMultiReader(m.bufferSize, m.buffersNum, views...)
+	r.pool = m.pool
+	r.arbiter = m.arbiter // Диапазоны делят с родителем и его место в очереди на общий канал
+	return r, nil
+}
+
+// sourceView — окно [base, base+size) источника MultiReader со своей позицией для ридеров Range.
+type sourceView struct {
+	src  SizedReadSeekCloser
+	mu   *sync.Mutex // замок источника из MultiReader.srcMu; не нужен для io.ReaderAt
+	base int64
+	size int64
+	pos  int64
+}
+
+// Проверка, что sourceView удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*sourceView)(nil)
+
+func (v *sourceView) Read(p []byte) (n int, err error) {
+	if v.pos >= v.size {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), v.size-v.pos)]
+	if ra, ok := v.src.(io.ReaderAt); ok {
+		n, err = ra.ReadAt(p, v.base+v.pos)
+	} else {
+		n, err = v.readLocked(p)
+	}
+	v.pos += int64(n)
+	switch {
+	case errors.Is(err, io.EOF) && v.pos < v.size:
+		err = io.ErrUnexpectedEOF // Источник короче заявленного Size
+	case errors.Is(err, io.EOF):
+		err = nil // io.EOF вернёт следующий Read
+	}
+	return n, err
+}
+
+// readLocked читает источник с позиции окна, удерживая его замок между Seek и Read.
+func (v *sourceView) readLocked(p []byte) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, err := v.src.Seek(v.base+v.pos, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	return v.src.Read(p)
+}
+
+func (v *sourceView) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		pos += v.pos
+	case io.SeekEnd:
+		pos += v.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("negative position %d", pos)
+	}
+	v.pos = pos
+	return pos, nil
+}
+
+// Size возвращает размер окна.
+func (v *sourceView) Size() int64 {
+	return v.size
+}
+
+// Close ничего не делает: источник принадлежит MultiReader.
+func (v *sourceView) Close() error {
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// rangeContent возвращает псевдослучайное содержимое и его разбиение на сегменты указанных размеров.
+func rangeContent(sizes ...int) ([]byte, [][]byte) {
+	var parts [][]byte
+	var all []byte
+	rnd := rand.New(rand.NewSource(7))
+	for _, size := range sizes {
+		part := make([]byte, size)
+		rnd.Read(part)
+		parts = append(parts, part)
+		all = append(all, part...)
+	}
+	return all, parts
+}
+
+// readRanges параллельно читает диапазоны ranges из m и сверяет их с want, одновременно читая сам m.
+func readRanges(t *testing.T, m *MultiReader, want []byte, ranges [][2]int64) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for _, rg := range ranges {
+		r, err := m.Range(rg[0], rg[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, want[rg[0]:rg[0]+rg[1]]) {
+				t.Errorf("Range(%d, %d): %d байт, %v", rg[0], rg[1], len(got), err)
+			}
+		}()
+	}
+	got, err := io.ReadAll(m) // Родитель читает те же источники параллельно с диапазонами
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("ReadAll родителя: %d байт, %v", len(got), err)
+	}
+	wg.Wait()
+}
+
+func TestRange_ConcurrentWithSeekReadSources(t *testing.T) {
+	want, parts := rangeContent(5000, 1, 0, 7000, 3000)
+	segs := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		segs[i] = testutil.NewStringsReader(string(part))
+	}
+	m := NewMultiReader(256, 3, segs...)
+	defer m.Close()
+
+	readRanges(t, m, want, [][2]int64{{0, 15001}, {4990, 20}, {5001, 7000}, {12000, 3001}, {100, 0}, {15001, 0}})
+}
+
+func TestRange_ConcurrentWithFileSegments(t *testing.T) {
+	want, parts := rangeContent(4000, 6000)
+	segs := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		path := filepath.Join(t.TempDir(), fmt.Sprint(i))
+		if err := os.WriteFile(path, part, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if segs[i], err = NewFileSegment(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewMultiReader(512, 2, segs...)
+	defer m.Close()
+
+	readRanges(t, m, want, [][2]int64{{0, 10000}, {3500, 1000}, {9999, 1}})
+}
+
+func TestRange_FileSegmentReadAtKeepsOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seg")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg, err := NewFileSegment(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMultiReader(4, 2, seg)
+	defer m.Close()
+
+	r, err := m.Range(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != "34567" {
+		t.Fatalf("Range(3, 5): %q, %v", got, err)
+	}
+	if pos, _ := f.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Fatalf("позиция файла %d: Range должен читать FileSegment через ReadAt, не сдвигая её", pos)
+	}
+}
+
+func TestRange_SeekWithinRange(t *testing.T) {
+	m := NewMultiReader(4, 2, testutil.NewStringsReader("0123456789"), testutil.NewStringsReader("abcdefghij"))
+	defer m.Close()
+
+	r, err := m.Range(8, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	rs := r.(io.ReadSeeker)
+	if _, err := rs.Seek(-3, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rs)
+	if err != nil || string(got) != "bcd" {
+		t.Fatalf("хвост диапазона: %q, %v", got, err)
+	}
+	if pos, _ := m.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Fatalf("Range сдвинул позицию родителя на %d", pos)
+	}
+}
+
+func TestRange_InvalidAndClosed(t *testing.T) {
+	m := NewMultiReader(4, 2, testutil.NewStringsReader("0123456789"))
+	for _, rg := range [][2]int64{{-1, 2}, {0, -1}, {5, 6}, {11, 0}} {
+		if _, err := m.Range(rg[0], rg[1]); err == nil {
+			t.Errorf("Range(%d, %d): ожидалась ошибка", rg[0], rg[1])
+		}
+	}
+	_ = m.Close()
+	if _, err := m.Range(0, 1); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Range после Close: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// TestReadContext_CancelWaitingBlock: ctx прерывает Read, ждущий блок от зависшего источника,
+// а после его оживления чтение продолжается с той же позиции без потери данных.
+func TestReadContext_CancelWaitingBlock(t *testing.T) {
+	stuck := newStuckReader("0123456789")
+	m := NewMultiReader(4, 2, stuck)
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testOpTimeout)
+	defer cancel()
+	n, err := m.ReadContext(ctx, make([]byte, 4))
+	if n != 0 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReadContext: %d байт, %v; ожидался DeadlineExceeded", n, err)
+	}
+
+	stuck.release()
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "0123456789" {
+		t.Fatalf("ReadAll после отмены: %q, %v", got, err)
+	}
+}
+
+// TestReadContext_CancelWaitingQueue: ctx прерывает ожидание очереди за другим, зависшим Read.
+func TestReadContext_CancelWaitingQueue(t *testing.T) {
+	stuck := newStuckReader("0123456789")
+	m := NewMultiReader(4, 2, stuck)
+	defer m.Close()
+
+	res := goRead(m, 4)
+	waitFor(t, func() bool { return len(m.readSem) == 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.ReadContext(ctx, make([]byte, 4)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ReadContext в очереди: %v; ожидался Canceled", err)
+	}
+
+	stuck.release()
+	if r := waitRead(t, res); r.err != nil || r.data != "0123" {
+		t.Fatalf("зависший Read: %q, %v", r.data, r.err)
+	}
+}
+
+// TestSeekContext_CancelWaitingPrefetcher: Seek вне окна ждёт остановки префетчера, застрявшего в источнике;
+// ctx прерывает ожидание, не меняя позицию, и следующее чтение дочитывает поток с прежнего места.
+func TestSeekContext_CancelWaitingPrefetcher(t *testing.T) {
+	stuck := newStuckReader("ABCDEFGHIJ")
+	m := NewMultiReader(4, 2, testutil.NewStringsReader("0123456789"), stuck)
+	defer m.Close()
+
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(m, buf); err != nil || string(buf) != "0123" {
+		t.Fatalf("первое чтение: %q, %v", buf, err)
+	}
+
+	// Блоки «4567» и «89» заполнили канал, префетчер застрял в Seek второго сегмента
+	ctx, cancel := context.WithTimeout(context.Background(), testOpTimeout)
+	defer cancel()
+	start := time.Now()
+	if _, err := m.SeekContext(ctx, 0, io.SeekStart); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SeekContext: %v; ожидался DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("SeekContext прерван через %v", elapsed)
+	}
+	if _, err := m.SeekContext(ctx, 0, io.SeekCurrent); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SeekContext с завершённым ctx: %v", err)
+	}
+
+	stuck.release()
+	if pos, err := m.Seek(0, io.SeekCurrent); err != nil || pos != 4 {
+		t.Fatalf("позиция после прерванного Seek: %d, %v; ожидалась 4", pos, err)
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != "456789ABCDEFGHIJ" {
+		t.Fatalf("ReadAll после прерванного Seek: %q, %v", got, err)
+	}
+	if _, err := m.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = io.ReadAll(m); err != nil || string(got) != "23456789ABCDEFGHIJ" {
+		t.Fatalf("ReadAll после Seek: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ReadAhead — стратегия префетча MultiReader.
+type ReadAhead int
+
+const (
+	ReadAheadEager ReadAhead = iota // префетч с первого Read (по умолчанию, как у NewMultiReader)
+	ReadAheadLazy                   // префетч только после нескольких последовательных Read подряд
+	ReadAheadNone                   // без префетча: Read читает источники напрямую в буфер вызывающего
+)
+
+// Значения по умолчанию NewMultiReaderWithOptions.
+const (
+	defaultBlockSize  = 256 << 10
+	defaultBuffersNum = 4
+)
+
+// MultiReaderOption — функциональная опция NewMultiReaderWithOptions.
+type MultiReaderOption func(*multiReaderOptions)
+
+// multiReaderOptions — итоговая конфигурация NewMultiReaderWithOptions.
+type multiReaderOptions struct {
+	blockSize  int64
+	buffersNum int
+	readAhead  ReadAhead
+	lazyAfter  int // число последовательных Read до запуска префетча в ReadAheadLazy
+
+	adaptive     bool  // адаптивный размер блока, см. WithAdaptiveBlockSize
+	minBlockSize int64 // начальный блок адаптивного префетча
+	lazySizes    bool  // размеры источников узнаются по мере чтения, см. WithLazySizes
+
+	streamHash  hash.Hash        // дайджест всего потока, см. WithChecksum
+	streamSum   []byte           // ожидаемый дайджест потока
+	segmentHash func() hash.Hash // дайджест источника, см. WithSegmentChecksums
+	segmentSums [][]byte         // ожидаемые дайджесты источников
+}
+
+// WithBlockSize задаёт размер блока префетча; по умолчанию 256 KiB.
+func WithBlockSize(n int64) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.blockSize = n
+	}
+}
+
+// WithBuffersNum задаёт глубину префетча — число блоков, прочитанных впрок; по умолчанию 4.
+func WithBuffersNum(n int) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.buffersNum = n
+	}
+}
+
+// WithEagerReadAhead запускает префетч с первого Read (по умолчанию).
+func WithEagerReadAhead() MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.readAhead = ReadAheadEager
+	}
+}
+
+// WithLazyReadAhead читает источники напрямую, пока не наберётся n последовательных Read подряд, и только
+// затем запускает префетч. Seek за пределы прочитанного окна сбрасывает счётчик: случайный доступ
+// не платит за блоки, которые не понадобятся, а последовательный проход быстро переходит на префетч.
+func WithLazyReadAhead(n int) MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.readAhead = ReadAheadLazy
+		o.lazyAfter = n
+	}
+}
+
+// WithoutReadAhead отключает префетч: каждый Read читает источники напрямую.
+// WriteTo и дисковый кэш работают поблочно и по-прежнему используют префетч.
+func WithoutReadAhead() MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.readAhead = ReadAheadNone
+	}
+}
+
+// NewMultiReaderWithOptions создаёт конкатенированный ридер, настроенный опциями opts.
+// Без опций он ведёт себя как NewMultiReader с блоком 256 KiB и глубиной 4.
+func NewMultiReaderWithOptions(readers []SizedReadSeekCloser, opts ...MultiReaderOption) (*MultiReader, error) {
+	o := multiReaderOptions{blockSize: defaultBlockSize, buffersNum: defaultBuffersNum}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch {
+	case o.blockSize <= 0:
+		return nil, fmt.Errorf("block size must be positive, got %d", o.blockSize)
+	case o.adaptive && (o.minBlockSize <= 0 || o.minBlockSize > o.blockSize):
+		return nil, fmt.Errorf("min block size must be in [1, %d], got %d", o.blockSize, o.minBlockSize)
+	case o.buffersNum <= 0:
+		return nil, fmt.Errorf("buffers number must be positive, got %d", o.buffersNum)
+	case o.readAhead == ReadAheadLazy && o.lazyAfter <= 0:
+		return nil, fmt.Errorf("lazy read-ahead threshold must be positive, got %d", o.lazyAfter)
+	}
+
+	var err error
+	m := newMultiReader(o.blockSize, o.buffersNum, newLayout(readers, o.lazySizes), readers...)
+	m.readAhead = o.readAhead
+	m.lazyAfter = o.lazyAfter
+	m.minBlock = o.minBlockSize
+	m.sums, err = newChecksums(o, m.sizes)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// directRead сообщает, что очередной Read должен читать источники напрямую, минуя префетч. Вызывается под m.mu.
+func (m *MultiReader) directRead() bool {
+	if len(m.windowBuf) != 0 || m.pfBufCh != nil || m.cache != nil { // Окно или работающий префетчер вычитываются первыми
+		return false
+	}
+	switch m.readAhead {
+	case ReadAheadNone:
+		return true
+	case ReadAheadLazy:
+		return m.seqReads < m.lazyAfter
+	}
+	return false
+}
+
+// readDirect читает в p прямо из источников с текущей позиции. Вызывается под m.mu и в очереди чтения;
+// на время чтения источников отпускает m.mu. Как и префетчер, учитывается в m.pfWg:
+// Seek вне окна и Close дожидаются окончания чтения, прежде чем трогать источники.
+func (m *MultiReader) readDirect(ctx context.Context, p []byte) (int, error) {
+	pos, gen := m.windowStart, m.pfGen
+	if m.sizes.atEnd(pos) {
+		return 0, m.sums.eof()
+	}
+	m.seqReads++
+	m.pfWg.Add(1)
+	m.mu.Unlock()
+
+	n, err := m.readSources(ctx, pos, p)
+
+	m.pfWg.Done()
+	m.mu.Lock()
+	if m.pfGen == gen && !m.closed { // Seek во время чтения задаёт позицию сам
+		m.windowStart = pos + int64(n)
+	}
+	m.counters.read.Add(int64(n))
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil // io.EOF вернёт следующий Read
+	}
+	sumErr := m.sums.update(pos, p[:n])
+	if err == nil {
+		err = sumErr
+	}
+	return n, err
+}
+
+// readSources заполняет p данными с абсолютной позиции pos, переходя между источниками. Короткое чтение
+// источника продолжается, пока p не заполнен или поток не кончился.
+func (m *MultiReader) readSources(ctx context.Context, pos int64, p []byte) (n int, err error) {
+	for n < len(p) && !m.sizes.atEnd(pos) {
+		idx := m.readerIndex(pos)
+		start, end := m.sizes.start(idx), m.sizes.end(idx)
+		chunk := p[n:min(int64(len(p)), int64(n)+end-pos)]
+		err = m.arbiter.acquireBlock(ctx, len(chunk))
+		if err != nil {
+			return n, err
+		}
+		k, err := m.readSourceAt(idx, pos-start, chunk)
+		n += k
+		pos += int64(k)
+		switch {
+		case errors.Is(err, io.EOF) && pos < end:
+			return n, fmt.Errorf("reader %d: %w", idx, io.ErrUnexpectedEOF) // Источник короче заявленного Size
+		case err != nil && !errors.Is(err, io.EOF):
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readSourceAt читает в p из источника idx с локальной позиции local, не разрывая Seek и Read (см. m.srcMu).
+func (m *MultiReader) readSourceAt(idx int, local int64, p []byte) (int, error) {
+	m.srcMu[idx].Lock()
+	defer m.srcMu[idx].Unlock()
+	_, err := m.readers[idx].Seek(local, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	return m.readers[idx].Read(p)
+}
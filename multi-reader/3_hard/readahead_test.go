@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// countingSegment считает вызовы Read источника.
+type countingSegment struct {
+	*testutil.StringsReader
+	reads *atomic.Int32
+}
+
+func (s countingSegment) Read(p []byte) (int, error) {
+	s.reads.Add(1)
+	return s.StringsReader.Read(p)
+}
+
+func countingSegments(reads *atomic.Int32, parts ...string) []SizedReadSeekCloser {
+	segs := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		segs[i] = countingSegment{StringsReader: testutil.NewStringsReader(part), reads: reads}
+	}
+	return segs
+}
+
+func prefetching(m *MultiReader) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pfBufCh != nil
+}
+
+func TestReadAhead_NoneReadsSourcesDirectly(t *testing.T) {
+	var reads atomic.Int32
+	parts := []string{"0123456789", "abcdefghij", "KLMNOPQRST"}
+	m, err := NewMultiReaderWithOptions(countingSegments(&reads, parts...), WithBlockSize(4), WithoutReadAhead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	buf := make([]byte, 6)
+	for _, tc := range []struct {
+		pos  int64
+		want string
+	}{{7, "789abc"}, {0, "012345"}, {27, "RST"}, {15, "fghijK"}} {
+		if _, err := m.Seek(tc.pos, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err := m.Read(buf)
+		if err != nil || string(buf[:n]) != tc.want {
+			t.Fatalf("Read с %d: %q, %v; ожидалось %q", tc.pos, buf[:n], err, tc.want)
+		}
+		if prefetching(m) {
+			t.Fatalf("Read с %d запустил префетч", tc.pos)
+		}
+	}
+	if got := reads.Load(); got != 6 { // Чтение через границу сегмента — по Read на каждый сегмент
+		t.Fatalf("%d чтений источников, ожидалось 6", got)
+	}
+	if _, err := m.Seek(0, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Read(buf); n != 0 || err != io.EOF {
+		t.Fatalf("Read в конце: %d, %v", n, err)
+	}
+
+	if _, err := m.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || string(got) != strings.Join(parts, "") {
+		t.Fatalf("ReadAll: %q, %v", got, err)
+	}
+}
+
+func TestReadAhead_LazyStartsAfterSequentialReads(t *testing.T) {
+	var reads atomic.Int32
+	content := strings.Repeat("0123456789", 10)
+	m, err := NewMultiReaderWithOptions(countingSegments(&reads, content[:50], content[50:]), WithBlockSize(8), WithBuffersNum(2), WithLazyReadAhead(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	buf := make([]byte, 4)
+	read := func(want string) {
+		t.Helper()
+		if _, err := io.ReadFull(m, buf); err != nil || string(buf) != want {
+			t.Fatalf("Read: %q, %v; ожидалось %q", buf, err, want)
+		}
+	}
+	for i := range 3 {
+		read(content[i*4 : i*4+4])
+		if prefetching(m) {
+			t.Fatalf("префетч запущен после %d последовательных Read из 3", i+1)
+		}
+	}
+	read(content[12:16])
+	if !prefetching(m) {
+		t.Fatalf("префетч не запущен после 3 последовательных Read")
+	}
+
+	// Seek за пределы окна возвращает к прямому чтению
+	if _, err := m.Seek(60, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	read(content[60:64])
+	if prefetching(m) {
+		t.Fatalf("префетч запущен сразу после Seek")
+	}
+	rest, err := io.ReadAll(m)
+	if err != nil || string(rest) != content[64:] {
+		t.Fatalf("ReadAll: %q, %v", rest, err)
+	}
+}
+
+func TestReadAhead_DirectReadShortSource(t *testing.T) {
+	m, err := NewMultiReaderWithOptions([]SizedReadSeekCloser{lyingSegment{StringsReader: testutil.NewStringsReader("abc"), size: 5}}, WithoutReadAhead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if _, err := io.ReadAll(m); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("источник короче Size: %v; ожидался ErrUnexpectedEOF", err)
+	}
+}
+
+func TestNewMultiReaderWithOptions_InvalidConfig(t *testing.T) {
+	for name, opts := range map[string][]MultiReaderOption{
+		"блок":    {WithBlockSize(0)},
+		"буферы":  {WithBuffersNum(-1)},
+		"ленивый": {WithLazyReadAhead(0)},
+	} {
+		if _, err := NewMultiReaderWithOptions(nil, opts...); err == nil {
+			t.Errorf("%s: ожидалась ошибка конфигурации", name)
+		}
+	}
+	m, err := NewMultiReaderWithOptions(countingSegments(new(atomic.Int32), "abc"))
+	if err != nil || m.bufferSize != defaultBlockSize || m.buffersNum != defaultBuffersNum || m.readAhead != ReadAheadEager {
+		t.Fatalf("значения по умолчанию: %+v, %v", m, err)
+	}
+	_ = m.Close()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/zlatoivan/go-advanced/retry"
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+// UploadSink — приёмник репликации: пишет данные по абсолютному смещению. MultiWriter подходит напрямую,
+// как и файл или клиент multipart-загрузки. Запись по одному смещению должна быть идемпотентной: при повторе
+// и при возобновлении тот же диапазон может быть записан снова.
+type UploadSink interface {
+	WriteAt(p []byte, off int64) (int, error)
+}
+
+// ResumeToken — точка, с которой репликацию можно продолжить: всё до Offset записано в приёмник.
+// HashState — сериализованное состояние хэша на Offset (если хэш задан); токен можно сохранить как JSON.
+type ResumeToken struct {
+	Offset    int64  `json:"offset"`
+	HashState []byte `json:"hash_state,omitempty"`
+}
+
+// ReplicationProgress передаётся в ReplicatorConfig.Progress после каждой записанной пачки.
+type ReplicationProgress struct {
+	Written int64       // записано байт с начала объекта (включая пропущенное при возобновлении)
+	Total   int64       // размер объекта
+	Token   ResumeToken // сохранив токен, репликацию можно продолжить с этого места
+}
+
+// ReplicatorConfig — настройки Replicator. Нулевые значения — значения по умолчанию.
+type ReplicatorConfig struct {
+	BlockSize  int64 // блок префетча MultiReader; 0 — 256 KiB
+	BuffersNum int   // глубина префетча; 0 — 4
+	BatchSize  int   // размер одной записи в приёмник; 0 — BlockSize
+
+	NewHash  func() hash.Hash // хэш всего объекта; nil — без хэша. Для возобновления нужен encoding.BinaryMarshaler
+	Expected []byte           // ожидаемый дайджест; nil — не проверять
+
+	Bucket *throttle.Bucket // ограничение суммарной скорости чтения источников; nil — без ограничения
+	Retry  retry.Policy     // повторы записи пачки в приёмник
+
+	Progress func(ReplicationProgress) // вызывается после каждой записанной пачки; nil — не нужно
+}
+
+const (
+	defaultReplicatorBlockSize  = 256 << 10
+	defaultReplicatorBuffersNum = 4
+)
+
+// Replicator копирует объект из нескольких частей в приёмник: MultiReader с префетчем над источниками,
+// необязательные ограничение скорости и хэш, запись пачками с повторами, прогресс и возобновление по ResumeToken.
+type Replicator struct {
+	cfg ReplicatorConfig
+}
+
+// NewReplicator проверяет конфигурацию и создаёт Replicator.
+func NewReplicator(cfg ReplicatorConfig) (*Replicator, error) {
+	if cfg.BlockSize < 0 || cfg.BuffersNum < 0 || cfg.BatchSize < 0 {
+		return nil, fmt.Errorf("replicator sizes must not be negative: block %d, buffers %d, batch %d",
+			cfg.BlockSize, cfg.BuffersNum, cfg.BatchSize)
+	}
+	if cfg.Expected != nil && cfg.NewHash == nil {
+		return nil, errors.New("expected digest requires a hash")
+	}
+	if cfg.BlockSize == 0 {
+		cfg.BlockSize = defaultReplicatorBlockSize
+	}
+	if cfg.BuffersNum == 0 {
+		cfg.BuffersNum = defaultReplicatorBuffersNum
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = int(cfg.BlockSize)
+	}
+	return &Replicator{cfg: cfg}, nil
+}
+
+// Run копирует конкатенацию sources в sink, начиная с resume (nil — с начала), и возвращает дайджест объекта
+// (nil без хэша). Источники закрываются по завершении. При ошибке последний токен из Progress указывает,
+// откуда продолжить.
+func (r *Replicator) Run(ctx context.Context, sources []SizedReadSeekCloser, sink UploadSink, resume *ResumeToken) ([]byte, error) {
+	segs := sources
+	if r.cfg.Bucket != nil {
+		segs = make([]SizedReadSeekCloser, len(sources))
+		for i, src := range sources {
+			segs[i] = NewThrottledSegment(ctx, src, r.cfg.Bucket)
+		}
+	}
+	m := NewMultiReader(r.cfg.BlockSize, r.cfg.BuffersNum, segs...)
+	defer m.Close()
+
+	var h hash.Hash
+	if r.cfg.NewHash != nil {
+		h = r.cfg.NewHash()
+	}
+	var off int64
+	if resume != nil {
+		err := restoreReplication(m, h, *resume)
+		if err != nil {
+			return nil, fmt.Errorf("resume at %d: %w", resume.Offset, err)
+		}
+		off = resume.Offset
+	}
+
+	batch := make([]byte, r.cfg.BatchSize)
+	for off < m.Size() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(m, batch[:min(int64(len(batch)), m.Size()-off)])
+		if err != nil {
+			return nil, fmt.Errorf("read at %d: %w", off, err)
+		}
+		err = r.cfg.Retry.Do(ctx, func() error {
+			_, err := sink.WriteAt(batch[:n], off)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("write at %d: %w", off, err)
+		}
+		if h != nil {
+			h.Write(batch[:n])
+		}
+		off += int64(n)
+
+		if r.cfg.Progress != nil {
+			token, err := replicationToken(h, off)
+			if err != nil {
+				return nil, err
+			}
+			r.cfg.Progress(ReplicationProgress{Written: off, Total: m.Size(), Token: token})
+		}
+	}
+
+	if h == nil {
+		return nil, nil
+	}
+	sum := h.Sum(nil)
+	if r.cfg.Expected != nil && !bytes.Equal(sum, r.cfg.Expected) {
+		return sum, &ChecksumError{Expected: r.cfg.Expected, Actual: sum}
+	}
+	return sum, nil
+}
+
+// restoreReplication ставит MultiReader и хэш в состояние токена.
+func restoreReplication(m *MultiReader, h hash.Hash, token ResumeToken) error {
+	if token.Offset < 0 || token.Offset > m.Size() {
+		return fmt.Errorf("offset out of range [0, %d]", m.Size())
+	}
+	if h != nil && token.Offset > 0 {
+		u, ok := h.(encoding.BinaryUnmarshaler)
+		if !ok || token.HashState == nil {
+			return errors.New("hash state cannot be restored")
+		}
+		if err := u.UnmarshalBinary(token.HashState); err != nil {
+			return fmt.Errorf("restore hash state: %w", err)
+		}
+	}
+	_, err := m.Seek(token.Offset, io.SeekStart)
+	return err
+}
+
+// replicationToken снимает токен на позиции off.
+func replicationToken(h hash.Hash, off int64) (ResumeToken, error) {
+	token := ResumeToken{Offset: off}
+	if h == nil {
+		return token, nil
+	}
+	mh, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return token, nil // Хэш без сериализации: токен годится только для репликации без хэша
+	}
+	state, err := mh.MarshalBinary()
+	if err != nil {
+		return token, fmt.Errorf("save hash state: %w", err)
+	}
+	token.HashState = state
+	return token, nil
+}
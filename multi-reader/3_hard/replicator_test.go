@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/retry"
+	"github.com/zlatoivan/go-advanced/testutil"
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+// replicationSource возвращает содержимое объекта и фабрику его частей: при возобновлении источники открываются заново.
+func replicationSource(sizes ...int) ([]byte, func() []SizedReadSeekCloser) {
+	rnd := rand.New(rand.NewSource(7))
+	var data []byte
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		p := make([]byte, size)
+		rnd.Read(p)
+		parts[i] = string(p)
+		data = append(data, p...)
+	}
+	return data, func() []SizedReadSeekCloser {
+		segs := make([]SizedReadSeekCloser, len(parts))
+		for i, p := range parts {
+			segs[i] = testutil.NewStringsReader(p)
+		}
+		return segs
+	}
+}
+
+func newTestReplicator(t *testing.T, cfg ReplicatorConfig) *Replicator {
+	t.Helper()
+	r, err := NewReplicator(cfg)
+	if err != nil {
+		t.Fatalf("NewReplicator: %v", err)
+	}
+	return r
+}
+
+func TestReplicator_CopiesIntoMultiWriter(t *testing.T) {
+	data, open := replicationSource(3000, 0, 5000, 1234)
+	parts := newMemParts(4000, 4000, 1234)
+	sink := newTestMultiWriter(parts)
+	want := sha256.Sum256(data)
+	bucket, err := throttle.NewBucket(1<<30, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []ReplicationProgress
+	r := newTestReplicator(t, ReplicatorConfig{
+		BlockSize: 512,
+		BatchSize: 1000,
+		NewHash:   sha256.New,
+		Expected:  want[:],
+		Bucket:    bucket,
+		Progress:  func(p ReplicationProgress) { progress = append(progress, p) },
+	})
+	sum, err := r.Run(context.Background(), open(), sink, nil)
+	if err != nil || !bytes.Equal(sum, want[:]) {
+		t.Fatalf("Run: %x, %v", sum, err)
+	}
+	if joinParts(parts) != string(data) {
+		t.Fatalf("данные в приёмнике не совпадают")
+	}
+	if len(progress) != 10 || progress[9].Written != int64(len(data)) || progress[9].Total != int64(len(data)) {
+		t.Fatalf("прогресс: %d вызовов, последний %+v", len(progress), progress[len(progress)-1])
+	}
+}
+
+// flakySink — приёмник в памяти, отказывающий на записи с позиции failAt failures раз подряд.
+type flakySink struct {
+	data     []byte
+	failAt   int64
+	failures int
+	writes   int
+}
+
+var errSinkDown = errors.New("sink unavailable")
+
+func (s *flakySink) WriteAt(p []byte, off int64) (int, error) {
+	s.writes++
+	if off == s.failAt && s.failures > 0 {
+		s.failures--
+		return 0, errSinkDown
+	}
+	return copy(s.data[off:], p), nil
+}
+
+func TestReplicator_ResumesFromToken(t *testing.T) {
+	data, open := replicationSource(2500, 2500)
+	want := sha256.Sum256(data)
+	sink := &flakySink{data: make([]byte, len(data)), failAt: 3000, failures: 1}
+
+	var last ResumeToken
+	cfg := ReplicatorConfig{
+		BlockSize: 256,
+		BatchSize: 1000,
+		NewHash:   sha256.New,
+		Expected:  want[:],
+		Progress:  func(p ReplicationProgress) { last = p.Token },
+	}
+	if _, err := newTestReplicator(t, cfg).Run(context.Background(), open(), sink, nil); !errors.Is(err, errSinkDown) {
+		t.Fatalf("первый запуск: %v, ожидалась ошибка приёмника", err)
+	}
+	if last.Offset != 3000 {
+		t.Fatalf("токен после сбоя: %d, ожидалось 3000", last.Offset)
+	}
+
+	writes := sink.writes
+	sum, err := newTestReplicator(t, cfg).Run(context.Background(), open(), sink, &last)
+	if err != nil || !bytes.Equal(sum, want[:]) {
+		t.Fatalf("возобновление: %x, %v", sum, err)
+	}
+	if !bytes.Equal(sink.data, data) {
+		t.Fatalf("данные в приёмнике не совпадают")
+	}
+	if sink.writes-writes != 2 {
+		t.Fatalf("после возобновления записано пачек: %d, ожидалось 2", sink.writes-writes)
+	}
+}
+
+func TestReplicator_RetriesSinkAndChecksDigest(t *testing.T) {
+	data, open := replicationSource(4096)
+	sink := &flakySink{data: make([]byte, len(data)), failAt: 1024, failures: 2}
+	r := newTestReplicator(t, ReplicatorConfig{
+		BatchSize: 1024,
+		Retry:     retry.Policy{MaxAttempts: 3, Backoff: time.Millisecond},
+	})
+	if _, err := r.Run(context.Background(), open(), sink, nil); err != nil || !bytes.Equal(sink.data, data) {
+		t.Fatalf("Run с повторами: %v", err)
+	}
+
+	r = newTestReplicator(t, ReplicatorConfig{NewHash: sha256.New, Expected: make([]byte, sha256.Size)})
+	_, err := r.Run(context.Background(), open(), &flakySink{data: make([]byte, len(data))}, nil)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("неверный дайджест: %v", err)
+	}
+}
+
+func TestReplicator_Validates(t *testing.T) {
+	for _, cfg := range []ReplicatorConfig{{BlockSize: -1}, {BatchSize: -1}, {Expected: []byte{1}}} {
+		if _, err := NewReplicator(cfg); err == nil {
+			t.Errorf("NewReplicator(%+v): ожидалась ошибка", cfg)
+		}
+	}
+
+	_, open := replicationSource(100)
+	r := newTestReplicator(t, ReplicatorConfig{NewHash: sha256.New})
+	_, err := r.Run(context.Background(), open(), &flakySink{data: make([]byte, 100)}, &ResumeToken{Offset: 50})
+	if err == nil {
+		t.Fatalf("возобновление с хэшем без его состояния: ожидалась ошибка")
+	}
+}
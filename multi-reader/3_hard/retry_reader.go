@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/retry"
+)
+
+// RetryPolicy описывает повторы Read/Seek в RetryReader.
+type RetryPolicy struct {
+	MaxAttempts int                   // максимум попыток одной операции, включая первую; <= 0 — одна попытка
+	Backoff     time.Duration         // пауза перед первым повтором, далее удваивается
+	MaxBackoff  time.Duration         // верхняя граница паузы; 0 — без ограничения
+	Jitter      float64               // доля паузы [0, 1], на которую она случайно уменьшается
+	Retryable   func(err error) bool  // классификатор ошибок; nil — любая ошибка, кроме io.EOF, считается повторяемой
+	Sleep       func(d time.Duration) // ожидание паузы; nil — time.Sleep
+}
+
+// RetryReader повторяет неудавшиеся Read и Seek источника с экспоненциальной паузой.
+// Перед повтором источник заново позиционируется на последнюю успешно прочитанную позицию,
+// поэтому после обрыва (например, сетевого соединения) чтение продолжается без пропусков и повторов данных.
+// Подходит как самостоятельная обёртка и как сегмент MultiReader. Не предназначен для конкурентного использования.
+type RetryReader struct {
+	r        SizedReadSeekCloser
+	retry    retry.Policy
+	pos      int64 // позиция после последнего успешного Read или Seek
+	needSeek bool  // после сбоя позиция источника не определена: перед чтением нужно вернуться на pos
+}
+
+// Проверка, что RetryReader удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*RetryReader)(nil)
+
+// NewRetryReader оборачивает r повторами по политике policy. Источник должен стоять в начале.
+func NewRetryReader(r SizedReadSeekCloser, policy RetryPolicy) *RetryReader {
+	if policy.Sleep == nil {
+		policy.Sleep = time.Sleep
+	}
+	return &RetryReader{r: r, retry: retry.Policy{
+		MaxAttempts: policy.MaxAttempts,
+		Backoff:     policy.Backoff,
+		MaxBackoff:  policy.MaxBackoff,
+		Jitter:      policy.Jitter,
+		Retryable: func(err error) bool {
+			return !errors.Is(err, io.EOF) && (policy.Retryable == nil || policy.Retryable(err))
+		},
+		Sleep: func(_ context.Context, d time.Duration) error {
+			policy.Sleep(d)
+			return nil
+		},
+	}}
+}
+
+// Read читает из источника, повторяя сбои. Если часть данных прочитана до сбоя, она возвращается без ошибки,
+// а повтор выполняется при следующем вызове. io.EOF не повторяется.
+func (rr *RetryReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	err = rr.do("read", func() error {
+		if rr.needSeek {
+			_, err := rr.r.Seek(rr.pos, io.SeekStart)
+			if err != nil {
+				return err
+			}
+			rr.needSeek = false
+		}
+		var readErr error
+		n, readErr = rr.r.Read(p)
+		rr.pos += int64(n)
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			rr.needSeek = true
+			if n > 0 { // Отдаём прочитанное сейчас, повтор — при следующем Read
+				return nil
+			}
+		}
+		return readErr
+	})
+
+	return n, err
+}
+
+// Seek перемещает позицию, повторяя сбои источника.
+func (rr *RetryReader) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		target += rr.pos
+	case io.SeekEnd:
+		target += rr.r.Size()
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("negative seek position: %d", target)
+	}
+
+	err := rr.do("seek", func() error {
+		_, err := rr.r.Seek(target, io.SeekStart)
+		if err != nil {
+			rr.needSeek = true
+			return err
+		}
+		rr.pos = target
+		rr.needSeek = false
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return target, nil
+}
+
+// Size возвращает размер источника.
+func (rr *RetryReader) Size() int64 {
+	return rr.r.Size()
+}
+
+// Close закрывает источник без повторов.
+func (rr *RetryReader) Close() error {
+	return rr.r.Close()
+}
+
+// do выполняет op, повторяя её по политике. Неповторяемые ошибки и io.EOF возвращаются сразу.
+func (rr *RetryReader) do(name string, op func() error) error {
+	err := rr.retry.Do(context.Background(), op)
+	if err != nil && rr.retry.IsRetryable(err) { // Попытки исчерпаны
+		return fmt.Errorf("%s %w", name, err)
+	}
+	return err
+}
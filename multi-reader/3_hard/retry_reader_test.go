@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+var errConnReset = errors.New("connection reset")
+
+// flakyReader имитирует обрывающееся соединение: на вызовах Read из failCalls возвращает errConnReset,
+// предварительно отдав половину запрошенного (если partial), и теряет позицию — источник «переподключается» в начало.
+type flakyReader struct {
+	*testutil.StringsReader
+	failCalls map[int]bool
+	partial   bool
+	failSeeks int // сколько первых Seek завершатся ошибкой
+	calls     int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	f.calls++
+	if !f.failCalls[f.calls] {
+		return f.StringsReader.Read(p)
+	}
+	n := 0
+	if f.partial {
+		n, _ = f.StringsReader.Read(p[:max(len(p)/2, 1)])
+	}
+	_, _ = f.StringsReader.Seek(0, io.SeekStart)
+	return n, errConnReset
+}
+
+func (f *flakyReader) Seek(offset int64, whence int) (int64, error) {
+	if f.failSeeks > 0 {
+		f.failSeeks--
+		return 0, errConnReset
+	}
+	return f.StringsReader.Seek(offset, whence)
+}
+
+// recordSleeps возвращает функцию Sleep для RetryPolicy, запоминающую запрошенные паузы.
+func recordSleeps(sleeps *[]time.Duration) func(time.Duration) {
+	return func(d time.Duration) { *sleeps = append(*sleeps, d) }
+}
+
+func TestRetryReader_ResumesAfterTransientFailures(t *testing.T) {
+	// При частичном чтении до сбоя данные отдаются сразу, и пауза не нужна: повтор начинается со следующего Read
+	wantSleeps := map[bool][]time.Duration{
+		false: {time.Millisecond, time.Millisecond, 2 * time.Millisecond},
+		true:  nil,
+	}
+	for _, partial := range []bool{false, true} {
+		content := strings.Repeat("0123456789", 20)
+		src := &flakyReader{StringsReader: testutil.NewStringsReader(content), failCalls: map[int]bool{3: true, 7: true, 8: true}, partial: partial}
+		var sleeps []time.Duration
+		rr := NewRetryReader(src, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Sleep: recordSleeps(&sleeps)})
+
+		var got []byte
+		buf := make([]byte, 16)
+		for {
+			n, err := rr.Read(buf)
+			got = append(got, buf[:n]...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("partial=%v: Read: %v", partial, err)
+			}
+		}
+		if err := testutil.ExpectBytes("данные", got, []byte(content)); err != nil {
+			t.Fatalf("partial=%v: %v", partial, err)
+		}
+		if !reflect.DeepEqual(sleeps, wantSleeps[partial]) {
+			t.Fatalf("partial=%v: паузы %v, ожидались %v", partial, sleeps, wantSleeps[partial])
+		}
+	}
+}
+
+func TestRetryReader_GivesUpAfterMaxAttempts(t *testing.T) {
+	src := &flakyReader{StringsReader: testutil.NewStringsReader("abc"), failCalls: map[int]bool{1: true, 2: true, 3: true}}
+	var sleeps []time.Duration
+	rr := NewRetryReader(src, RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond, MaxBackoff: 15 * time.Millisecond, Sleep: recordSleeps(&sleeps)})
+
+	_, err := rr.Read(make([]byte, 3))
+	if !errors.Is(err, errConnReset) {
+		t.Fatalf("Read: %v, ожидалась ошибка источника", err)
+	}
+	if want := []time.Duration{10 * time.Millisecond, 15 * time.Millisecond}; !reflect.DeepEqual(sleeps, want) {
+		t.Fatalf("паузы %v, ожидались %v", sleeps, want)
+	}
+
+	// Следующий вызов начинает новую серию попыток и дочитывает данные
+	buf := make([]byte, 3)
+	n, err := rr.Read(buf)
+	if err != nil || string(buf[:n]) != "abc" {
+		t.Fatalf("Read после восстановления: %q, %v", buf[:n], err)
+	}
+}
+
+func TestRetryReader_NonRetryableError(t *testing.T) {
+	errFatal := errors.New("access denied")
+	src := testutil.NewStringsReader("abc").FailReadAfter(0, errFatal)
+	var sleeps []time.Duration
+	rr := NewRetryReader(src, RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return !errors.Is(err, errFatal) },
+		Sleep:       recordSleeps(&sleeps),
+	})
+
+	if _, err := rr.Read(make([]byte, 3)); !errors.Is(err, errFatal) || len(sleeps) != 0 {
+		t.Fatalf("Read: %v, пауз %d; ожидалась немедленная ошибка без повторов", err, len(sleeps))
+	}
+}
+
+func TestRetryReader_SeekRetries(t *testing.T) {
+	src := &flakyReader{StringsReader: testutil.NewStringsReader("hello, world"), failSeeks: 2}
+	rr := NewRetryReader(src, RetryPolicy{MaxAttempts: 3, Sleep: func(time.Duration) {}})
+
+	pos, err := rr.Seek(-5, io.SeekEnd)
+	if err != nil || pos != 7 {
+		t.Fatalf("Seek: pos=%d, err=%v", pos, err)
+	}
+	got, err := io.ReadAll(rr)
+	if err != nil || string(got) != "world" {
+		t.Fatalf("ReadAll после Seek: %q, %v", got, err)
+	}
+
+	src.failSeeks = 3
+	if _, err = rr.Seek(0, io.SeekStart); !errors.Is(err, errConnReset) {
+		t.Fatalf("Seek при постоянном сбое: %v, ожидалась ошибка источника", err)
+	}
+}
+
+func TestRetryReader_AsMultiReaderSegment(t *testing.T) {
+	parts := []string{strings.Repeat("a", 100), strings.Repeat("b", 70), strings.Repeat("c", 130)}
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		src := &flakyReader{StringsReader: testutil.NewStringsReader(part), failCalls: map[int]bool{2: true, 4: true}, partial: true}
+		readers[i] = NewRetryReader(src, RetryPolicy{MaxAttempts: 2, Sleep: func(time.Duration) {}})
+	}
+	m := NewMultiReader(16, 2, readers...)
+	defer m.Close()
+
+	got, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(strings.Join(parts, "")))); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Формат потока SeekableWriter повторяет раскладку seekable-формата zstd: независимо сжатые кадры,
+// за ними таблица кадров (сжатый и исходный размер каждого, uint32 LE) и футер из числа кадров и магии.
+// Кадр можно распаковать отдельно, поэтому чтение с произвольной позиции распаковывает только нужный кадр.
+const (
+	seekableMagic      = 0x8F92EAB1
+	seekableEntrySize  = 8
+	seekableFooterSize = 8
+	maxSeekableFrame   = 1<<32 - 1
+)
+
+// ErrSeekableFormat — повреждённая или чужая таблица кадров.
+var ErrSeekableFormat = errors.New("invalid seekable stream")
+
+// FrameCodec сжимает и распаковывает отдельные кадры. Если писатель умеет Reset(io.Writer), а читатель —
+// Reset(io.Reader, []byte) error (как у compress/flate), они переиспользуются между кадрами.
+type FrameCodec interface {
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// FlateCodec — FrameCodec на compress/flate. Level — уровень сжатия flate; 0 — flate.DefaultCompression.
+type FlateCodec struct {
+	Level int
+}
+
+func (c FlateCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return flate.NewWriter(w, level)
+}
+
+func (FlateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+// seekableFrame — запись таблицы кадров.
+type seekableFrame struct {
+	compressed   uint32
+	decompressed uint32
+}
+
+// SeekableWriter сжимает поток кадрами по frameSize байт исходных данных и на Close дописывает таблицу кадров.
+// Результат открывается SeekableSegment для чтения с произвольной позиции. Не предназначен для конкурентного использования.
+type SeekableWriter struct {
+	w         io.Writer
+	codec     FrameCodec
+	frameSize int
+	buf       []byte         // исходные данные текущего кадра
+	cw        io.WriteCloser // писатель кодека, если его можно переиспользовать через Reset
+	out       bytes.Buffer   // сжатый текущий кадр
+	frames    []seekableFrame
+	err       error // первая ошибка записи; после неё Write и Close её возвращают
+	closed    bool
+}
+
+// NewSeekableWriter создаёт писатель поверх w. Close дописывает таблицу кадров, но не закрывает w.
+func NewSeekableWriter(w io.Writer, frameSize int, codec FrameCodec) (*SeekableWriter, error) {
+	if frameSize <= 0 || frameSize > maxSeekableFrame {
+		return nil, fmt.Errorf("frame size must be in (0, %d], got %d", maxSeekableFrame, frameSize)
+	}
+	if codec == nil {
+		codec = FlateCodec{}
+	}
+	return &SeekableWriter{w: w, codec: codec, frameSize: frameSize, buf: make([]byte, 0, frameSize)}, nil
+}
+
+// Write накапливает данные и сжимает каждый заполненный кадр.
+func (sw *SeekableWriter) Write(p []byte) (n int, err error) {
+	if sw.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	for len(p) > 0 {
+		k := min(len(p), sw.frameSize-len(sw.buf))
+		sw.buf = append(sw.buf, p[:k]...)
+		p = p[k:]
+		n += k
+		if len(sw.buf) == sw.frameSize {
+			if err := sw.flushFrame(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close сжимает неполный последний кадр и пишет таблицу кадров с футером.
+func (sw *SeekableWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	if sw.err != nil {
+		return sw.err
+	}
+	if len(sw.buf) > 0 {
+		if err := sw.flushFrame(); err != nil {
+			return err
+		}
+	}
+
+	index := make([]byte, 0, len(sw.frames)*seekableEntrySize+seekableFooterSize)
+	for _, f := range sw.frames {
+		index = binary.LittleEndian.AppendUint32(index, f.compressed)
+		index = binary.LittleEndian.AppendUint32(index, f.decompressed)
+	}
+	index = binary.LittleEndian.AppendUint32(index, uint32(len(sw.frames)))
+	index = binary.LittleEndian.AppendUint32(index, seekableMagic)
+	if _, err := sw.w.Write(index); err != nil {
+		sw.err = fmt.Errorf("write seek index: %w", err)
+		return sw.err
+	}
+	return nil
+}
+
+// flushFrame сжимает накопленный кадр в w и добавляет его в таблицу.
+func (sw *SeekableWriter) flushFrame() error {
+	compressed := &sw.out
+	compressed.Reset()
+	cw, err := sw.frameWriter()
+	if err == nil {
+		_, err = cw.Write(sw.buf)
+		if closeErr := cw.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err == nil && compressed.Len() > maxSeekableFrame {
+		err = fmt.Errorf("compressed frame of %d bytes does not fit the index", compressed.Len())
+	}
+	if err == nil {
+		_, err = sw.w.Write(compressed.Bytes())
+	}
+	if err != nil {
+		sw.err = fmt.Errorf("write frame %d: %w", len(sw.frames), err)
+		return sw.err
+	}
+	sw.frames = append(sw.frames, seekableFrame{compressed: uint32(compressed.Len()), decompressed: uint32(len(sw.buf))})
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+// frameWriter возвращает писатель кодека в sw.out, переиспользуя прежний, если он умеет Reset.
+func (sw *SeekableWriter) frameWriter() (io.WriteCloser, error) {
+	if r, ok := sw.cw.(interface{ Reset(io.Writer) }); ok {
+		r.Reset(&sw.out)
+		return sw.cw, nil
+	}
+	cw, err := sw.codec.NewWriter(&sw.out)
+	if err == nil {
+		sw.cw = cw
+	}
+	return cw, err
+}
+
+// SeekableSegment читает поток SeekableWriter как обычный сегмент MultiReader: Size — размер исходных данных,
+// Seek не распаковывает ничего, Read распаковывает только кадр с текущей позицией. Последний кадр кэшируется.
+// Не предназначен для конкурентного использования.
+type SeekableSegment struct {
+	src    SizedReadSeekCloser
+	codec  FrameCodec
+	offs   []int64 // offs[i] — смещение кадра i в src
+	starts []int64 // starts[i] — позиция первого байта кадра i в исходных данных; starts[len] — размер
+	pos    int64
+
+	frame    int           // номер распакованного кадра в data; -1 — нет
+	data     []byte        // распакованный кадр
+	frameBuf []byte        // сжатый кадр, переиспользуется между чтениями
+	fr       io.ReadCloser // читатель кодека, если его можно переиспользовать через Reset
+}
+
+// Проверка, что SeekableSegment удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*SeekableSegment)(nil)
+
+// NewSeekableSegment читает таблицу кадров из конца src. src закрывается в Close.
+func NewSeekableSegment(src SizedReadSeekCloser, codec FrameCodec) (*SeekableSegment, error) {
+	if codec == nil {
+		codec = FlateCodec{}
+	}
+	size := src.Size()
+	if size < seekableFooterSize {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrSeekableFormat, size)
+	}
+	footer := make([]byte, seekableFooterSize)
+	if err := readAt(src, footer, size-seekableFooterSize); err != nil {
+		return nil, fmt.Errorf("read seek footer: %w", err)
+	}
+	if binary.LittleEndian.Uint32(footer[4:]) != seekableMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrSeekableFormat)
+	}
+	n := int64(binary.LittleEndian.Uint32(footer))
+	indexSize := n*seekableEntrySize + seekableFooterSize
+	if indexSize > size {
+		return nil, fmt.Errorf("%w: index of %d frames does not fit %d bytes", ErrSeekableFormat, n, size)
+	}
+	index := make([]byte, n*seekableEntrySize)
+	if err := readAt(src, index, size-indexSize); err != nil {
+		return nil, fmt.Errorf("read seek index: %w", err)
+	}
+
+	s := &SeekableSegment{src: src, codec: codec, offs: make([]int64, n+1), starts: make([]int64, n+1), frame: -1}
+	for i := range n {
+		entry := index[i*seekableEntrySize:]
+		s.offs[i+1] = s.offs[i] + int64(binary.LittleEndian.Uint32(entry))
+		s.starts[i+1] = s.starts[i] + int64(binary.LittleEndian.Uint32(entry[4:]))
+	}
+	if s.offs[n] != size-indexSize {
+		return nil, fmt.Errorf("%w: frames take %d bytes, stream has %d", ErrSeekableFormat, s.offs[n], size-indexSize)
+	}
+	return s, nil
+}
+
+// readAt читает len(p) байт src с позиции off.
+func readAt(src io.ReadSeeker, p []byte, off int64) error {
+	if _, err := src.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(src, p)
+	return err
+}
+
+func (s *SeekableSegment) Read(p []byte) (int, error) {
+	if s.pos >= s.Size() {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	i := sort.Search(len(s.starts)-1, func(i int) bool { return s.starts[i+1] > s.pos })
+	if i != s.frame {
+		if err := s.loadFrame(i); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.data[s.pos-s.starts[i]:])
+	s.pos += int64(n)
+	return n, nil
+}
+
+// loadFrame распаковывает кадр i в data.
+func (s *SeekableSegment) loadFrame(i int) error {
+	s.frame = -1
+	compSize := s.offs[i+1] - s.offs[i]
+	if int64(cap(s.frameBuf)) < compSize {
+		s.frameBuf = make([]byte, compSize)
+	}
+	s.frameBuf = s.frameBuf[:compSize]
+	if err := readAt(s.src, s.frameBuf, s.offs[i]); err != nil {
+		return fmt.Errorf("read frame %d: %w", i, err)
+	}
+	r, err := s.frameReader(bytes.NewReader(s.frameBuf))
+	if err != nil {
+		return fmt.Errorf("decompress frame %d: %w", i, err)
+	}
+
+	want := s.starts[i+1] - s.starts[i]
+	if int64(cap(s.data)) < want {
+		s.data = make([]byte, want)
+	}
+	s.data = s.data[:want]
+	if _, err := io.ReadFull(r, s.data); err != nil {
+		return fmt.Errorf("decompress frame %d: %w", i, err)
+	}
+	s.frame = i
+	return nil
+}
+
+// frameReader возвращает читатель кодека над src, переиспользуя прежний, если он умеет Reset.
+func (s *SeekableSegment) frameReader(src io.Reader) (io.ReadCloser, error) {
+	if r, ok := s.fr.(interface {
+		Reset(r io.Reader, dict []byte) error
+	}); ok {
+		return s.fr, r.Reset(src, nil)
+	}
+	if s.fr != nil {
+		_ = s.fr.Close()
+	}
+	fr, err := s.codec.NewReader(src)
+	if err == nil {
+		s.fr = fr
+	}
+	return fr, err
+}
+
+// Seek перемещает позицию в исходных данных без обращения к источнику.
+func (s *SeekableSegment) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.Size()
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative seek position: %d", offset)
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+// Size возвращает размер исходных (распакованных) данных.
+func (s *SeekableSegment) Size() int64 {
+	return s.starts[len(s.starts)-1]
+}
+
+// Close закрывает источник.
+func (s *SeekableSegment) Close() error {
+	if s.fr != nil {
+		_ = s.fr.Close()
+	}
+	return s.src.Close()
+}
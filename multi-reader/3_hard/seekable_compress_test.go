@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/readertest"
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// compressSeekable сжимает data писателем SeekableWriter, записывая порциями случайной длины.
+func compressSeekable(t *testing.T, data []byte, frameSize int) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	sw, err := NewSeekableWriter(&out, frameSize, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(int64(len(data))))
+	for rest := data; len(rest) > 0; {
+		n := min(len(rest), 1+rnd.Intn(3*frameSize))
+		if _, err := sw.Write(rest[:n]); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.Bytes()
+}
+
+func openSeekable(t *testing.T, compressed []byte) *SeekableSegment {
+	t.Helper()
+	s, err := NewSeekableSegment(testutil.NewStringsReader(string(compressed)), nil)
+	if err != nil {
+		t.Fatalf("NewSeekableSegment: %v", err)
+	}
+	return s
+}
+
+func TestConformance_SeekableSegment(t *testing.T) {
+	readertest.RunReaderConformance(t, func(t *testing.T, content []byte) readertest.SizedReadSeekCloser {
+		return openSeekable(t, compressSeekable(t, content, 64))
+	})
+}
+
+// TestSeekable_RandomAccessThroughMultiReader: сжатые сегменты читаются MultiReader с произвольных позиций.
+func TestSeekable_RandomAccessThroughMultiReader(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var want []byte
+	segments := make([]SizedReadSeekCloser, 3)
+	for i := range segments {
+		data := bytes.Repeat([]byte{byte('a' + i)}, 10_000+rnd.Intn(10_000)) // Сжимаемые данные
+		rnd.Read(data[:500])
+		compressed := compressSeekable(t, data, 1024)
+		if len(compressed) >= len(data) {
+			t.Fatalf("сегмент %d не сжат: %d байт из %d", i, len(compressed), len(data))
+		}
+		segments[i] = openSeekable(t, compressed)
+		want = append(want, data...)
+	}
+
+	m := NewMultiReader(700, 3, segments...)
+	defer m.Close()
+	for range 50 {
+		off := rnd.Int63n(int64(len(want)))
+		n := 1 + rnd.Intn(3000)
+		if _, err := m.Seek(off, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, n)
+		k, err := io.ReadFull(m, got)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("Read с %d: %v", off, err)
+		}
+		if !bytes.Equal(got[:k], want[off:off+int64(k)]) {
+			t.Fatalf("данные с позиции %d не совпадают", off)
+		}
+	}
+}
+
+func TestSeekable_EmptyAndCorruptStreams(t *testing.T) {
+	empty := openSeekable(t, compressSeekable(t, nil, 16))
+	if empty.Size() != 0 {
+		t.Fatalf("Size пустого потока: %d", empty.Size())
+	}
+	if _, err := empty.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Read пустого потока: %v", err)
+	}
+
+	valid := compressSeekable(t, []byte("hello, seekable world"), 4)
+	corrupt := map[string][]byte{
+		"короткий":     valid[:3],
+		"магия":        append(append([]byte(nil), valid[:len(valid)-1]...), 0),
+		"лишние байты": append([]byte("xx"), valid...),
+	}
+	for name, data := range corrupt {
+		_, err := NewSeekableSegment(testutil.NewStringsReader(string(data)), nil)
+		if !errors.Is(err, ErrSeekableFormat) {
+			t.Errorf("%s: %v, ожидалась ErrSeekableFormat", name, err)
+		}
+	}
+}
+
+// failingWriter принимает limit байт и затем возвращает ошибку.
+type failingWriter struct {
+	limit int
+	err   error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, w.err
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestSeekableWriter_Errors(t *testing.T) {
+	if _, err := NewSeekableWriter(io.Discard, 0, nil); err == nil {
+		t.Fatalf("NewSeekableWriter с нулевым кадром: ожидалась ошибка")
+	}
+
+	sinkErr := errors.New("disk full")
+	sw, err := NewSeekableWriter(&failingWriter{err: sinkErr}, 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sw.Write([]byte("12345678")); !errors.Is(err, sinkErr) {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := sw.Write([]byte("9")); !errors.Is(err, sinkErr) {
+		t.Fatalf("повторный Write после ошибки: %v", err)
+	}
+	if err := sw.Close(); !errors.Is(err, sinkErr) {
+		t.Fatalf("Close после ошибки: %v", err)
+	}
+}
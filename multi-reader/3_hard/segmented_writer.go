@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SegmentInfo — запись манифеста о сегменте, записанном SegmentedWriter.
+type SegmentInfo struct {
+	Index  int   // порядковый номер сегмента, с которым вызывалась фабрика
+	Offset int64 // абсолютная позиция начала сегмента в общем потоке
+	Size   int64 // количество байт в сегменте
+}
+
+// SegmentFactory создаёт приёмник для сегмента с порядковым номером index.
+type SegmentFactory func(index int) (io.WriteCloser, error)
+
+// SegmentOpener открывает записанный сегмент на чтение.
+type SegmentOpener func(seg SegmentInfo) (SizedReadSeekCloser, error)
+
+// SegmentedWriter — зеркало MultiReader на стороне записи: режет поток на сегменты по segmentSize байт,
+// открывая каждый следующий приёмник через фабрику, и ведёт манифест записанных сегментов.
+// Не предназначен для конкурентного использования.
+type SegmentedWriter struct {
+	segmentSize int64          // порог ротации: максимальный размер одного сегмента
+	factory     SegmentFactory // открывает приёмник очередного сегмента
+	sink        io.WriteCloser // приёмник текущего сегмента; nil — сегмент ещё не открыт
+	segments    []SegmentInfo  // манифест; последний элемент — текущий сегмент, если sink != nil
+	size        int64          // всего записано байт
+	err         error          // первая ошибка приёмника или фабрики, возвращается из всех последующих Write
+	closed      bool           // флаг закрытия
+}
+
+// Проверка, что SegmentedWriter удовлетворяет интерфейсу io.WriteCloser
+var _ io.WriteCloser = (*SegmentedWriter)(nil)
+
+// NewSegmentedWriter создаёт писатель, открывающий новый сегмент через factory каждые segmentSize байт.
+// Приёмник открывается только когда есть что в него записать, поэтому пустых сегментов не бывает.
+func NewSegmentedWriter(segmentSize int64, factory SegmentFactory) (*SegmentedWriter, error) {
+	if segmentSize <= 0 || factory == nil {
+		return nil, errors.New("segmented writer: positive segment size and factory are required")
+	}
+	return &SegmentedWriter{
+		segmentSize: segmentSize,
+		factory:     factory,
+	}, nil
+}
+
+// Write пишет p, переходя на следующий сегмент при достижении порога. Заполненный сегмент закрывается сразу.
+// После ошибки приёмника Write продолжает возвращать её: часть данных могла не дойти до сегмента.
+func (w *SegmentedWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	for n < len(p) {
+		if w.sink == nil {
+			err = w.openSegment()
+			if err != nil {
+				return n, err
+			}
+		}
+
+		cur := &w.segments[len(w.segments)-1]
+		chunk := p[n:]
+		if rest := w.segmentSize - cur.Size; int64(len(chunk)) > rest {
+			chunk = chunk[:rest]
+		}
+		written, err := w.sink.Write(chunk)
+		if err == nil && written < len(chunk) {
+			err = io.ErrShortWrite
+		}
+		n += written
+		cur.Size += int64(written)
+		w.size += int64(written)
+		if err != nil {
+			w.err = fmt.Errorf("write segment %d: %w", cur.Index, err)
+			return n, w.err
+		}
+
+		if cur.Size == w.segmentSize {
+			err = w.closeSegment()
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Close закрывает текущий сегмент. Повторный вызов ничего не делает.
+func (w *SegmentedWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.sink == nil {
+		return nil
+	}
+	return w.closeSegment()
+}
+
+// Size возвращает общее количество записанных байт.
+func (w *SegmentedWriter) Size() int64 {
+	return w.size
+}
+
+// Segments возвращает копию манифеста: сегменты в порядке записи, включая текущий незакрытый.
+func (w *SegmentedWriter) Segments() []SegmentInfo {
+	return append([]SegmentInfo(nil), w.segments...)
+}
+
+// MultiReader открывает записанные сегменты через open и возвращает MultiReader над ними.
+// Доступен только после Close, когда все приёмники закрыты. Размер каждого открытого сегмента сверяется с манифестом;
+// при ошибке уже открытые сегменты закрываются.
+func (w *SegmentedWriter) MultiReader(bufferSize int64, buffersNum int, open SegmentOpener) (*MultiReader, error) {
+	if !w.closed {
+		return nil, errors.New("segmented writer is not closed")
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	readers := make([]SizedReadSeekCloser, 0, len(w.segments))
+	for _, seg := range w.segments {
+		r, err := open(seg)
+		if err == nil && r.Size() != seg.Size {
+			_ = r.Close()
+			err = fmt.Errorf("size %d, manifest says %d", r.Size(), seg.Size)
+		}
+		if err != nil {
+			_ = CloseAll(readers, "segment")
+			return nil, fmt.Errorf("open segment %d: %w", seg.Index, err)
+		}
+		readers = append(readers, r)
+	}
+
+	return NewMultiReader(bufferSize, buffersNum, readers...), nil
+}
+
+// openSegment открывает приёмник следующего сегмента и добавляет его в манифест.
+func (w *SegmentedWriter) openSegment() error {
+	index := len(w.segments)
+	sink, err := w.factory(index)
+	if err != nil {
+		w.err = fmt.Errorf("open segment %d: %w", index, err)
+		return w.err
+	}
+	w.sink = sink
+	w.segments = append(w.segments, SegmentInfo{Index: index, Offset: w.size})
+	return nil
+}
+
+// closeSegment закрывает приёмник текущего сегмента.
+func (w *SegmentedWriter) closeSegment() error {
+	index := len(w.segments) - 1
+	err := w.sink.Close()
+	w.sink = nil
+	if err != nil {
+		err = fmt.Errorf("close segment %d: %w", index, err)
+		if w.err == nil {
+			w.err = err
+		}
+		return err
+	}
+	return nil
+}
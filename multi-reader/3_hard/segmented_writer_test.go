@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// memSink — приёмник сегмента в памяти с внедряемыми ошибками.
+type memSink struct {
+	strings.Builder
+	writeErr error
+	closed   bool
+}
+
+func (s *memSink) Write(p []byte) (int, error) {
+	if s.writeErr != nil {
+		return 0, s.writeErr
+	}
+	return s.Builder.Write(p)
+}
+
+func (s *memSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+// memSegments — фабрика сегментов в памяти и парный ей SegmentOpener.
+type memSegments struct {
+	sinks   []*memSink
+	opened  []*testutil.StringsReader
+	failAt  int // номер сегмента, запись в который завершается ошибкой; -1 — без сбоев
+	failErr error
+}
+
+func newMemSegments() *memSegments {
+	return &memSegments{failAt: -1}
+}
+
+func (m *memSegments) create(index int) (io.WriteCloser, error) {
+	s := &memSink{}
+	if index == m.failAt {
+		s.writeErr = m.failErr
+	}
+	m.sinks = append(m.sinks, s)
+	return s, nil
+}
+
+func (m *memSegments) open(seg SegmentInfo) (SizedReadSeekCloser, error) {
+	r := testutil.NewStringsReader(m.sinks[seg.Index].String())
+	m.opened = append(m.opened, r)
+	return r, nil
+}
+
+func newTestSegmentedWriter(t *testing.T, segmentSize int64, segs *memSegments) *SegmentedWriter {
+	t.Helper()
+	w, err := NewSegmentedWriter(segmentSize, segs.create)
+	if err != nil {
+		t.Fatalf("NewSegmentedWriter: %v", err)
+	}
+	return w
+}
+
+func TestSegmentedWriter_RotatesBySize(t *testing.T) {
+	segs := newMemSegments()
+	w := newTestSegmentedWriter(t, 4, segs)
+
+	for _, chunk := range []string{"abc", "defgh", "ij"} {
+		n, err := w.Write([]byte(chunk))
+		if err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q): n=%d, err=%v", chunk, n, err)
+		}
+	}
+	if !segs.sinks[0].closed || !segs.sinks[1].closed || segs.sinks[2].closed {
+		t.Fatalf("заполненные сегменты должны закрываться сразу, текущий — оставаться открытым")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := []SegmentInfo{{Index: 0, Offset: 0, Size: 4}, {Index: 1, Offset: 4, Size: 4}, {Index: 2, Offset: 8, Size: 2}}
+	if got := w.Segments(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("манифест %+v, ожидался %+v", got, want)
+	}
+	for i, wantData := range []string{"abcd", "efgh", "ij"} {
+		if got := segs.sinks[i].String(); got != wantData {
+			t.Errorf("сегмент %d: %q, ожидалось %q", i, got, wantData)
+		}
+	}
+	if !segs.sinks[2].closed || w.Size() != 10 {
+		t.Fatalf("после Close: последний сегмент закрыт=%v, Size=%d", segs.sinks[2].closed, w.Size())
+	}
+}
+
+func TestSegmentedWriter_NoEmptyTrailingSegment(t *testing.T) {
+	segs := newMemSegments()
+	w := newTestSegmentedWriter(t, 4, segs)
+
+	if _, err := w.Write([]byte("12345678")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if len(segs.sinks) != 2 || len(w.Segments()) != 2 {
+		t.Fatalf("открыто приёмников %d, в манифесте %d, ожидалось 2", len(segs.sinks), len(w.Segments()))
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Write после Close: %v, ожидалась io.ErrClosedPipe", err)
+	}
+}
+
+func TestSegmentedWriter_MultiReaderRoundTrip(t *testing.T) {
+	content := strings.Repeat("0123456789abcdef", 40) + "tail"
+	segs := newMemSegments()
+	w := newTestSegmentedWriter(t, 100, segs)
+
+	if _, err := w.MultiReader(16, 2, segs.open); err == nil {
+		t.Fatalf("MultiReader до Close должен возвращать ошибку")
+	}
+	if _, err := io.Copy(w, strings.NewReader(content)); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	m, err := w.MultiReader(16, 2, segs.open)
+	if err != nil {
+		t.Fatalf("MultiReader: %v", err)
+	}
+	defer m.Close()
+	if m.Size() != int64(len(content)) {
+		t.Fatalf("Size %d, ожидался %d", m.Size(), len(content))
+	}
+	got, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(content))); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = m.Seek(250, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	buf := make([]byte, 10)
+	if _, err = io.ReadFull(m, buf); err != nil || string(buf) != content[250:260] {
+		t.Fatalf("после Seek прочитано %q (%v), ожидалось %q", buf, err, content[250:260])
+	}
+}
+
+func TestSegmentedWriter_SinkErrorIsSticky(t *testing.T) {
+	errDisk := errors.New("disk full")
+	segs := newMemSegments()
+	segs.failAt, segs.failErr = 1, errDisk
+	w := newTestSegmentedWriter(t, 4, segs)
+
+	n, err := w.Write([]byte("abcdef"))
+	if !errors.Is(err, errDisk) || n != 4 {
+		t.Fatalf("Write: n=%d, err=%v; ожидались 4 байта и ошибка приёмника", n, err)
+	}
+	if _, err = w.Write([]byte("g")); !errors.Is(err, errDisk) {
+		t.Fatalf("повторный Write: %v, ожидалась прежняя ошибка", err)
+	}
+	_ = w.Close()
+	if _, err = w.MultiReader(16, 2, segs.open); !errors.Is(err, errDisk) {
+		t.Fatalf("MultiReader после сбоя: %v, ожидалась ошибка приёмника", err)
+	}
+}
+
+func TestSegmentedWriter_SizeMismatchClosesOpened(t *testing.T) {
+	segs := newMemSegments()
+	w := newTestSegmentedWriter(t, 4, segs)
+	if _, err := w.Write([]byte("abcdefgh")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	_ = w.Close()
+	segs.sinks[1].WriteString("!") // Сегмент изменился после записи манифеста
+
+	if _, err := w.MultiReader(16, 2, segs.open); err == nil || !strings.Contains(err.Error(), "segment 1") {
+		t.Fatalf("ожидалась ошибка несоответствия размера сегмента 1, получено %v", err)
+	}
+	for i, r := range segs.opened {
+		if !r.Closed() {
+			t.Errorf("сегмент %d остался открытым", i)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// SizedWriteCloser - интерфейс приёмника заранее известного размера без seek (поток загрузки, pipe, сокет).
+type SizedWriteCloser interface {
+	io.WriteCloser
+	Size() int64
+}
+
+// SequentialWriter — MultiWriter для приёмников без seek: раскладывает записываемый поток по writers
+// строго по порядку. Как только приёмник получил заявленные Size байт, он закрывается, и запись продолжается
+// в следующий; приёмники нулевого размера закрываются, когда до них доходит запись. Данные за пределами
+// суммарного размера не пишутся, а Write возвращает ErrWriteBeyondEnd.
+type SequentialWriter struct {
+	writers     []SizedWriteCloser // приёмники
+	prefixSizes []int64            // абсолютные стартовые позиции приёмников (префиксные суммы)
+	mu          sync.Mutex         // мьютекс, блокирует все нижние поля
+	idx         int                // текущий приёмник; приёмники до него уже закрыты
+	pos         int64              // сколько байт записано
+	closed      bool               // флаг закрытия
+}
+
+// Проверка, что SequentialWriter удовлетворяет интерфейсу io.WriteCloser
+var _ io.WriteCloser = (*SequentialWriter)(nil)
+
+// NewSequentialWriter создаёт последовательный конкатенированный приёмник поверх writers.
+func NewSequentialWriter(writers ...SizedWriteCloser) *SequentialWriter {
+	prefixSizes := make([]int64, len(writers)+1)
+	for i := 1; i < len(writers)+1; i++ {
+		prefixSizes[i] = prefixSizes[i-1] + writers[i-1].Size()
+	}
+
+	return &SequentialWriter{
+		writers:     writers,
+		prefixSizes: prefixSizes,
+	}
+}
+
+// Write пишет p в текущий приёмник, переходя к следующим по мере их заполнения.
+// После ошибки приёмника запись можно продолжить с позиции, до которой дошли данные.
+func (s *SequentialWriter) Write(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	for {
+		err = s.closeFilled()
+		if err != nil || n == len(p) {
+			return n, err
+		}
+		if s.idx == len(s.writers) {
+			return n, ErrWriteBeyondEnd
+		}
+
+		chunk := p[n:]
+		if remain := s.prefixSizes[s.idx+1] - s.pos; int64(len(chunk)) > remain {
+			chunk = chunk[:remain]
+		}
+		written, err := s.writers[s.idx].Write(chunk)
+		if err == nil && written < len(chunk) {
+			err = io.ErrShortWrite
+		}
+		n += written
+		s.pos += int64(written)
+		if err != nil {
+			return n, fmt.Errorf("write writer %d: %w", s.idx, err)
+		}
+	}
+}
+
+// Size возвращает суммарный размер всех приёмников.
+func (s *SequentialWriter) Size() int64 {
+	return s.prefixSizes[len(s.writers)]
+}
+
+// Close закрывает ещё не закрытые приёмники, агрегируя ошибки. Если записано меньше Size байт,
+// к ошибкам добавляется io.ErrShortWrite: недописанный приёмник, скорее всего, неполон.
+func (s *SequentialWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	if s.pos < s.Size() {
+		errs = append(errs, fmt.Errorf("closed after %d of %d bytes: %w", s.pos, s.Size(), io.ErrShortWrite))
+	}
+	for ; s.idx < len(s.writers); s.idx++ { // Закрываем все, даже если какой-то вернул ошибку
+		err := s.writers[s.idx].Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("close writer %d: %w", s.idx, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeFilled закрывает заполненные приёмники начиная с текущего. Вызывается под s.mu.
+func (s *SequentialWriter) closeFilled() error {
+	for s.idx < len(s.writers) && s.pos == s.prefixSizes[s.idx+1] {
+		idx := s.idx
+		s.idx++ // Закрытый с ошибкой приёмник не закрывается повторно
+		err := s.writers[idx].Close()
+		if err != nil {
+			return fmt.Errorf("close writer %d: %w", idx, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// streamSink — приёмник без seek с заявленным размером (как поток загрузки части).
+type streamSink struct {
+	strings.Builder
+	size     int64
+	closeErr error
+	closed   bool
+}
+
+func (s *streamSink) Write(b []byte) (int, error) {
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return s.Builder.Write(b)
+}
+
+func (s *streamSink) Close() error {
+	s.closed = true
+	return s.closeErr
+}
+
+func (s *streamSink) Size() int64 { return s.size }
+
+func newStreamSinks(sizes ...int64) ([]*streamSink, []SizedWriteCloser) {
+	sinks := make([]*streamSink, len(sizes))
+	writers := make([]SizedWriteCloser, len(sizes))
+	for i, size := range sizes {
+		sinks[i] = &streamSink{size: size}
+		writers[i] = sinks[i]
+	}
+	return sinks, writers
+}
+
+func TestSequentialWriter_SplitsAndClosesFilledSinks(t *testing.T) {
+	sinks, writers := newStreamSinks(4, 0, 3, 5)
+	w := NewSequentialWriter(writers...)
+
+	for _, chunk := range []string{"ab", "cdef"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q): %d, %v", chunk, n, err)
+		}
+	}
+	if !sinks[0].closed || !sinks[1].closed || sinks[2].closed {
+		t.Fatal("закрыты должны быть ровно заполненные приёмники, включая пустой между ними")
+	}
+	if n, err := w.Write([]byte("ghijkl")); err != nil || n != 6 {
+		t.Fatalf("Write до конца: %d, %v", n, err)
+	}
+	for i, want := range []string{"abcd", "", "efg", "hijkl"} {
+		if got := sinks[i].String(); got != want {
+			t.Fatalf("приёмник %d: %q, ожидалось %q", i, got, want)
+		}
+		if !sinks[i].closed {
+			t.Fatalf("приёмник %d не закрыт после заполнения", i)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close после полной записи: %v", err)
+	}
+}
+
+func TestSequentialWriter_WriteBeyondEnd(t *testing.T) {
+	sinks, writers := newStreamSinks(3, 2)
+	w := NewSequentialWriter(writers...)
+
+	n, err := w.Write([]byte("abcdefg"))
+	if !errors.Is(err, ErrWriteBeyondEnd) || n != 5 {
+		t.Fatalf("Write: %d, %v; ожидалось 5 байт и ErrWriteBeyondEnd", n, err)
+	}
+	if sinks[1].String() != "de" || !sinks[1].closed {
+		t.Fatalf("последний приёмник: %q, закрыт %v", sinks[1].String(), sinks[1].closed)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close после полной записи: %v", err)
+	}
+}
+
+func TestSequentialWriter_CloseEarlyReportsShortWrite(t *testing.T) {
+	sinks, writers := newStreamSinks(2, 2, 2)
+	sinks[2].closeErr = errors.New("upload aborted")
+	w := NewSequentialWriter(writers...)
+
+	if _, err := w.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	err := w.Close()
+	if !errors.Is(err, io.ErrShortWrite) || !errors.Is(err, sinks[2].closeErr) || !strings.Contains(err.Error(), "close writer 2:") {
+		t.Fatalf("Close: %v; ожидались io.ErrShortWrite и ошибка приёмника 2", err)
+	}
+	for i, s := range sinks {
+		if !s.closed {
+			t.Fatalf("приёмник %d не закрыт", i)
+		}
+	}
+	if _, err = w.Write([]byte("x")); err != io.ErrClosedPipe {
+		t.Fatalf("Write после Close: %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("повторный Close: %v", err)
+	}
+}
+
+func TestSequentialWriter_CloseErrorOnFill(t *testing.T) {
+	sinks, writers := newStreamSinks(2, 2)
+	sinks[0].closeErr = errors.New("commit part failed")
+	w := NewSequentialWriter(writers...)
+
+	n, err := w.Write([]byte("abcd"))
+	if !errors.Is(err, sinks[0].closeErr) || n != 2 {
+		t.Fatalf("Write: %d, %v; ожидалась ошибка закрытия приёмника 0 после 2 байт", n, err)
+	}
+	if n, err = w.Write([]byte("cd")); err != nil || n != 2 || sinks[1].String() != "cd" {
+		t.Fatalf("Write после ошибки: %d, %v, %q", n, err, sinks[1].String())
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrSizeUnresolved возвращается Seek от конца потока, пока размеры источников MultiReader с WithLazySizes
+// известны не все (см. MultiReader.SizeResolved).
+var ErrSizeUnresolved = errors.New("total size is not resolved yet")
+
+// WithLazySizes откладывает вызовы Size источников до первого обращения к их данным: границы источников
+// вычисляются по мере продвижения чтения или Seek, а не все при создании. Подходит для источников, чей
+// размер дорог (например, HEAD-запрос к удалённому объекту). Seek от конца потока возвращает
+// ErrSizeUnresolved, пока размеры известны не все; Size и Boundaries узнают размеры всех источников.
+func WithLazySizes() MultiReaderOption {
+	return func(o *multiReaderOptions) {
+		o.lazySizes = true
+	}
+}
+
+// layout — границы источников MultiReader (префиксные суммы их размеров), которые можно узнавать лениво.
+// Безопасен для конкурентного использования: к нему обращаются и префетчер, и Read, и ридеры Range.
+type layout struct {
+	readers []SizedReadSeekCloser // источники; Size вызывается под mu
+
+	mu       sync.Mutex
+	prefix   []int64 // prefix[i] — абсолютная позиция начала источника i; известны prefix[:resolved+1]
+	resolved int     // число источников с известным размером
+}
+
+// newLayout создаёт границы источников readers; lazy == false сразу узнаёт размеры всех.
+func newLayout(readers []SizedReadSeekCloser, lazy bool) *layout {
+	l := &layout{readers: readers, prefix: make([]int64, len(readers)+1)}
+	if !lazy {
+		l.resolve(len(readers))
+	}
+	return l
+}
+
+// resolve узнаёт размеры первых n источников. Вызывается под l.mu или до публикации layout.
+func (l *layout) resolve(n int) {
+	for ; l.resolved < n; l.resolved++ {
+		l.prefix[l.resolved+1] = l.prefix[l.resolved] + l.readers[l.resolved].Size()
+	}
+}
+
+// resolveTo узнаёт размеры, пока известная часть потока не выйдет за pos или не кончатся источники.
+// Вызывается под l.mu.
+func (l *layout) resolveTo(pos int64) {
+	for l.resolved < len(l.readers) && l.prefix[l.resolved] <= pos {
+		l.resolve(l.resolved + 1)
+	}
+}
+
+// start возвращает абсолютную позицию начала источника i (i == len(readers) — конец потока).
+func (l *layout) start(i int) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolve(i)
+	return l.prefix[i]
+}
+
+// end возвращает абсолютную позицию конца источника i.
+func (l *layout) end(i int) int64 {
+	return l.start(i + 1)
+}
+
+// index возвращает индекс источника, содержащего абсолютную позицию pos, или len(readers), если поток кончается
+// не дальше pos. Пустые источники пропускаются.
+func (l *layout) index(pos int64) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolveTo(pos)
+	return sort.Search(l.resolved, func(i int) bool { return l.prefix[i+1] > pos })
+}
+
+// atEnd сообщает, что поток кончается не дальше pos.
+func (l *layout) atEnd(pos int64) bool {
+	return l.index(pos) == len(l.readers)
+}
+
+// contains сообщает, что pos не дальше конца потока: 0 <= pos <= Size.
+func (l *layout) contains(pos int64) bool {
+	if pos < 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolveTo(pos - 1)
+	return pos <= l.prefix[l.resolved]
+}
+
+// total возвращает размер потока, узнавая размеры всех источников.
+func (l *layout) total() int64 {
+	return l.start(len(l.readers))
+}
+
+// known возвращает размер потока, если размеры всех источников уже известны.
+func (l *layout) known() (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.prefix[l.resolved], l.resolved == len(l.readers)
+}
+
+// bounds возвращает копию всех границ, узнавая размеры всех источников.
+func (l *layout) bounds() []int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolve(len(l.readers))
+	return append([]int64(nil), l.prefix...)
+}
+
+// SizeResolved сообщает, известны ли уже размеры всех источников. Без WithLazySizes — всегда true.
+func (m *MultiReader) SizeResolved() bool {
+	_, ok := m.sizes.known()
+	return ok
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// sizeCountingSegment считает вызовы Size источника — например, HEAD-запросы к удалённому объекту.
+type sizeCountingSegment struct {
+	*testutil.StringsReader
+	sizes *atomic.Int32
+}
+
+func (s sizeCountingSegment) Size() int64 {
+	s.sizes.Add(1)
+	return s.StringsReader.Size()
+}
+
+func sizeCountingSegments(sizes *atomic.Int32, parts ...string) []SizedReadSeekCloser {
+	segs := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		segs[i] = sizeCountingSegment{StringsReader: testutil.NewStringsReader(part), sizes: sizes}
+	}
+	return segs
+}
+
+func TestLazySizes_ResolvedOnDemand(t *testing.T) {
+	var sizes atomic.Int32
+	m, err := NewMultiReaderWithOptions(sizeCountingSegments(&sizes, "0123456789", "abcdefghij", "KLMNOPQRST"),
+		WithBlockSize(4), WithoutReadAhead(), WithLazySizes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if n := sizes.Load(); n != 0 {
+		t.Fatalf("при создании вызвано Size: %d, ожидалось 0", n)
+	}
+
+	buf := make([]byte, 4)
+	if _, err = io.ReadFull(m, buf); err != nil || string(buf) != "0123" {
+		t.Fatalf("Read: %q, %v", buf, err)
+	}
+	if n := sizes.Load(); n != 1 {
+		t.Fatalf("после чтения первого источника вызвано Size: %d, ожидалось 1", n)
+	}
+
+	if _, err = m.Seek(-1, io.SeekEnd); !errors.Is(err, ErrSizeUnresolved) {
+		t.Fatalf("Seek от конца: %v, ожидалась ErrSizeUnresolved", err)
+	}
+	if _, err = m.Seek(15, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = io.ReadFull(m, buf); err != nil || string(buf) != "fghi" {
+		t.Fatalf("Read после Seek: %q, %v", buf, err)
+	}
+	if n := sizes.Load(); n != 2 || m.SizeResolved() {
+		t.Fatalf("после Seek во второй источник вызвано Size: %d, SizeResolved %v; ожидалось 2 и false", n, m.SizeResolved())
+	}
+
+	rest, err := io.ReadAll(m)
+	if err != nil || string(rest) != "jKLMNOPQRST" {
+		t.Fatalf("ReadAll: %q, %v", rest, err)
+	}
+	if !m.SizeResolved() {
+		t.Fatal("после чтения до конца размеры должны быть известны")
+	}
+	if pos, err := m.Seek(-3, io.SeekEnd); err != nil || pos != 27 {
+		t.Fatalf("Seek от конца после чтения: %d, %v", pos, err)
+	}
+	if n := sizes.Load(); n != 3 {
+		t.Fatalf("Size каждого источника должен вызываться один раз, вызвано %d", n)
+	}
+}
+
+func TestLazySizes_SizeResolvesAll(t *testing.T) {
+	var sizes atomic.Int32
+	m, err := NewMultiReaderWithOptions(sizeCountingSegments(&sizes, "01", "", "abc"), WithLazySizes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if m.SizeResolved() {
+		t.Fatal("до обращений размеры не должны быть известны")
+	}
+	if size := m.Size(); size != 5 || !m.SizeResolved() {
+		t.Fatalf("Size() = %d, SizeResolved %v; ожидалось 5 и true", size, m.SizeResolved())
+	}
+	if _, err = m.Seek(6, io.SeekStart); err == nil {
+		t.Fatal("Seek за конец потока: ожидалась ошибка")
+	}
+}
+
+func TestLazySizes_EagerByDefault(t *testing.T) {
+	var sizes atomic.Int32
+	m := NewMultiReader(4, 2, sizeCountingSegments(&sizes, "01", "abc")...)
+	defer m.Close()
+	if !m.SizeResolved() || sizes.Load() != 2 {
+		t.Fatalf("NewMultiReader: SizeResolved %v, вызовов Size %d; ожидалось true и 2", m.SizeResolved(), sizes.Load())
+	}
+}
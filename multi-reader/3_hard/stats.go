@@ -0,0 +1,50 @@
+package main
+
+import "sync/atomic"
+
+// ReaderStats — снимок счётчиков MultiReader для подбора bufferSize и buffersNum.
+type ReaderStats struct {
+	BytesRead        int64 // отдано вызывающему через Read и WriteTo
+	BytesPrefetched  int64 // прочитано префетчером впрок (из источников или дискового кэша)
+	BytesDiscarded   int64 // прочитано впрок, но выброшено: Seek за пределы окна, Close
+	PrefetchRestarts int64 // перезапуски префетчера после первого запуска: Seek вне окна, ошибки, EnableMetering и т. п.
+	SeeksInWindow    int64 // Seek, обслуженные без сброса префетча
+	SeeksOutOfWindow int64 // Seek, сбросившие окно и префетч
+	WindowBytes      int   // непрочитанные байты текущего окна
+	QueuedBlocks     int   // блоки, которые префетчер уже прочитал и ждут Read
+}
+
+// readerCounters — накопительные счётчики ReaderStats. Атомарные: префетчер обновляет их без m.mu.
+type readerCounters struct {
+	read         atomic.Int64
+	prefetched   atomic.Int64
+	discarded    atomic.Int64
+	starts       atomic.Int64
+	seeksInside  atomic.Int64
+	seeksOutside atomic.Int64
+}
+
+// Stats возвращает снимок счётчиков ридера. Безопасен для вызова параллельно с Read и Seek.
+func (m *MultiReader) Stats() ReaderStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := ReaderStats{
+		BytesRead:        m.counters.read.Load(),
+		BytesPrefetched:  m.counters.prefetched.Load(),
+		BytesDiscarded:   m.counters.discarded.Load(),
+		PrefetchRestarts: max(m.counters.starts.Load()-1, 0),
+		SeeksInWindow:    m.counters.seeksInside.Load(),
+		SeeksOutOfWindow: m.counters.seeksOutside.Load(),
+		WindowBytes:      len(m.windowBuf),
+	}
+	if m.pfBufCh != nil {
+		s.QueuedBlocks = len(m.pfBufCh)
+	}
+	return s
+}
+
+// discard возвращает в пул блок, часть которого (n байт) так и не была прочитана.
+func (m *MultiReader) discard(block []byte, n int) {
+	m.counters.discarded.Add(int64(n))
+	m.pool.Put(block)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+func newStatsReader() *MultiReader {
+	return NewMultiReader(4, 2,
+		testutil.NewStringsReader("0123456789"), testutil.NewStringsReader("abcdefghij"), testutil.NewStringsReader("KLMNOPQRST"))
+}
+
+func TestStats_SequentialRead(t *testing.T) {
+	m := newStatsReader()
+	defer m.Close()
+
+	if _, err := io.ReadAll(m); err != nil {
+		t.Fatal(err)
+	}
+	got := m.Stats()
+	want := ReaderStats{BytesRead: 30, BytesPrefetched: 30}
+	if got != want {
+		t.Fatalf("Stats() = %+v, ожидалось %+v", got, want)
+	}
+}
+
+func TestStats_Seeks(t *testing.T) {
+	m := newStatsReader()
+	defer m.Close()
+
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(m, buf); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.WindowBytes != 3 || s.BytesRead != 1 {
+		t.Fatalf("после чтения байта: %+v, ожидалось 3 байта в окне и 1 прочитанный", s)
+	}
+
+	if _, err := m.Seek(2, io.SeekCurrent); err != nil { // Внутри окна: байты 1 и 2 пропущены
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.SeeksInWindow != 1 || s.BytesDiscarded != 2 || s.WindowBytes != 1 {
+		t.Fatalf("после Seek внутри окна: %+v", s)
+	}
+
+	if _, err := m.Seek(20, io.SeekStart); err != nil { // Вне окна: окно и прочитанные впрок блоки выброшены
+		t.Fatal(err)
+	}
+	s := m.Stats()
+	if s.SeeksOutOfWindow != 1 || s.WindowBytes != 0 || s.QueuedBlocks != 0 {
+		t.Fatalf("после Seek вне окна: %+v", s)
+	}
+	if s.BytesPrefetched != s.BytesRead+s.BytesDiscarded {
+		t.Fatalf("после сброса префетча всё прочитанное впрок должно быть отдано или выброшено: %+v", s)
+	}
+
+	rest, err := io.ReadAll(m)
+	if err != nil || string(rest) != "KLMNOPQRST" {
+		t.Fatalf("ReadAll после Seek: %q, %v", rest, err)
+	}
+	s = m.Stats()
+	if s.PrefetchRestarts != 1 || s.BytesRead != 11 || s.BytesPrefetched != s.BytesRead+s.BytesDiscarded {
+		t.Fatalf("в конце: %+v, ожидался один перезапуск и 11 отданных байт", s)
+	}
+}
+
+func TestStats_DirectReadAndWriteTo(t *testing.T) {
+	m, err := NewMultiReaderWithOptions([]SizedReadSeekCloser{testutil.NewStringsReader("0123456789")}, WithBlockSize(4), WithoutReadAhead())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	buf := make([]byte, 3)
+	if _, err = io.ReadFull(m, buf); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.BytesRead != 3 || s.BytesPrefetched != 0 {
+		t.Fatalf("чтение без префетча: %+v", s)
+	}
+	if _, err = m.WriteTo(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if s := m.Stats(); s.BytesRead != 10 || s.BytesPrefetched != 7 {
+		t.Fatalf("после WriteTo: %+v, ожидалось 10 отданных и 7 прочитанных впрок", s)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+var (
+	stressDuration = flag.Duration("stress.duration", 300*time.Millisecond, "длительность стресс-теста MultiReader")
+	stressSeed     = flag.Int64("stress.seed", 0, "seed стресс-теста; 0 — текущее время")
+	stressWorkers  = flag.Int("stress.workers", 8, "число горутин стресс-теста")
+)
+
+// stressContent возвращает данные, где каждый байт кодирует свою позицию, чтобы ошибки смещения были заметны.
+func stressContent(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return string(b)
+}
+
+// TestStress_ConcurrentReadSeekCloseSize нагружает один MultiReader случайными конкурентными вызовами.
+// Запуск с воспроизведением: go test -race -run Stress -stress.seed=<seed> -stress.duration=10s
+func TestStress_ConcurrentReadSeekCloseSize(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	seed := *stressSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("stress seed: %d", seed)
+
+	content := stressContent(4096)
+	segRnd := rand.New(rand.NewSource(seed))
+	var segments []SizedReadSeekCloser
+	for rest := content; len(rest) > 0; {
+		n := min(len(rest), segRnd.Intn(700)+1)
+		segments = append(segments, testutil.NewStringsReader(rest[:n]))
+		rest = rest[n:]
+	}
+	m := NewMultiReader(64, 3, segments...)
+	size := m.Size()
+
+	deadline := time.Now().Add(*stressDuration)
+	errCh := make(chan error, *stressWorkers)
+	var wg sync.WaitGroup
+	for w := range *stressWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed + int64(w)))
+			buf := make([]byte, 512)
+			for time.Now().Before(deadline) {
+				var err error
+				switch op := rnd.Intn(100); {
+				case op < 50:
+					var n int
+					n, err = m.Read(buf[:rnd.Intn(len(buf))+1])
+					for _, b := range buf[:n] {
+						if b >= 251 { // Такого байта нет в содержимом: прочитан мусор из чужого буфера
+							err = errors.New("read returned byte outside of content")
+						}
+					}
+				case op < 85:
+					_, err = m.Seek(rnd.Int63n(size+1), io.SeekStart)
+				case op < 99:
+					if m.Size() != size {
+						err = errors.New("size changed")
+					}
+				default:
+					if rnd.Intn(20) == 0 { // Close редко и ближе к концу, чтобы большая часть прогона шла на открытом ридере
+						err = m.Close()
+					}
+				}
+				if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+					errCh <- err
+					return
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(*stressDuration + 5*time.Second):
+		stack := make([]byte, 1<<20)
+		stack = stack[:runtime.Stack(stack, true)]
+		t.Fatalf("стресс-тест завис (seed %d):\n%s", seed, stack)
+	}
+	close(errCh)
+	for err := range errCh {
+		t.Errorf("seed %d: %v", seed, err)
+	}
+
+	// После Close все операции должны завершаться ErrClosedPipe, а не блокироваться
+	_ = m.Close()
+	_, err := m.Read(make([]byte, 1))
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Read после Close: %v", err)
+	}
+}
+
+// TestStress_SequentialReaderWithConcurrentSize проверяет корректность данных при параллельных вызовах Size.
+func TestStress_SequentialReaderWithConcurrentSize(t *testing.T) {
+	content := stressContent(8192)
+	var segments []SizedReadSeekCloser
+	for i := 0; i < len(content); i += 1000 {
+		segments = append(segments, testutil.NewStringsReader(content[i:min(i+1000, len(content))]))
+	}
+	m := NewMultiReader(128, 2, segments...)
+	defer m.Close()
+
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = m.Size()
+			}
+		}
+	}()
+	got, err := io.ReadAll(m)
+	close(stop)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if err = testutil.ExpectBytes("данные", string(got), content); err != nil {
+		t.Fatal(err)
+	}
+}
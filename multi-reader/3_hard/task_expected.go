@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"sort"
 	"sync"
+
+	"github.com/zlatoivan/go-advanced/bufpool"
+	"github.com/zlatoivan/go-advanced/diskcache"
 )
 
 // SizedReadSeekCloser - интерфейс ридера с возможностью seek и знанием своего размера.
@@ -17,96 +20,195 @@ type SizedReadSeekCloser interface {
 // MultiReader объединяет несколько SizedReadSeekCloser в единый конкатенированный поток и поддерживает асинхронный префетч
 type MultiReader struct {
 	readers     []SizedReadSeekCloser // исходные ридеры
-	prefixSizes []int64               // абсолютные стартовые позиции ридеров (префиксные суммы)
-	bufferSize  int64                 // размер одного блока префетча
+	srcMu       []sync.Mutex          // srcMu[i] связывает Seek и Read источника i, который читают и ридеры Range
+	sizes       *layout               // абсолютные стартовые позиции ридеров (префиксные суммы), см. WithLazySizes
+	bufferSize  int64                 // размер одного блока префетча (наибольший в адаптивном режиме)
+	minBlock    int64                 // первый блок адаптивного префетча, см. WithAdaptiveBlockSize; 0 — блок постоянный
 	buffersNum  int                   // количество буферов
+	readSem     chan struct{}         // семафор на одно место: сериализует конкурентные вызовы Read
 	mu          sync.Mutex            // мьютекс для блокировок, блокирует все нижние поля:
 	windowBuf   []byte                // текущее окно данных
+	windowBlock []byte                // блок префетча, на который указывает окно; возвращается в пул после вычитывания
 	windowStart int64                 // абсолютная позиция начала окна
 	pfBufCh     chan []byte           // буферизированный канал блоков, наполняется префетчером
 	pfErrCh     chan error            // канал для ошибки/EOF от префетчера (ёмкость 1)
 	pfCancel    context.CancelFunc    // отмена контекста префетчера
 	pfWg        sync.WaitGroup        // ожидание завершения горутины префетчера
+	pfDone      chan struct{}         // закрывается при завершении текущего префетчера
+	pfStopped   bool                  // префетчер отменён прерванным SeekContext, но ещё не сброшен
+	pfGen       uint64                // поколение префетчера, увеличивается при каждом сбросе
+	pool        *bufpool.Pool         // пул блоков префетча; по умолчанию bufpool.Default
+	meters      []*MeteredReader      // счётчики сегментов, см. EnableMetering; nil — метрики выключены
+	cache       *diskcache.Cache      // дисковый кэш блоков, см. EnableDiskCache; nil — без кэша
+	cacheIDs    []string              // идентификаторы источников для ключей кэша
+	zeroCopy    bool                  // WriteTo передаёт файловые сегменты в ядре, см. EnableZeroCopy
+	readAhead   ReadAhead             // стратегия префетча, см. NewMultiReaderWithOptions
+	lazyAfter   int                   // порог последовательных Read для ReadAheadLazy
+	seqReads    int                   // Read подряд без Seek за пределы окна
+	sums        *checksums            // проверка дайджестов, см. WithChecksum; nil — без проверки
+	arbiter     *ArbiterStream        // очередь на общий канал для чтений префетча, см. Arbiter; nil — без арбитра
+	counters    readerCounters        // счётчики Stats
+	hooks       prefetchHooks         // точки внедрения для тестов; nil в рабочем коде
 	closed      bool                  // флаг закрытия мультиридера
 }
 
 // Проверка, что MultiReader удовлетворяет интерфейсу SizedReadSeekCloser
 var _ SizedReadSeekCloser = (*MultiReader)(nil)
 
-// NewMultiReader создаёт конкатенированный ридер с поддержкой асинхронного префетча
+// NewMultiReader создаёт конкатенированный ридер с поддержкой асинхронного префетча.
+// Вложенные MultiReader без собственной настройки раскрываются в свои источники (см. flattenReaders).
 func NewMultiReader(buffersSize int64, buffersNum int, readers ...SizedReadSeekCloser) *MultiReader {
-	prefixSizes := make([]int64, len(readers)+1)
-	for i := 1; i < len(readers)+1; i++ {
-		prefixSizes[i] = prefixSizes[i-1] + readers[i-1].Size()
-	}
+	readers = flattenReaders(readers)
+	return newMultiReader(buffersSize, buffersNum, newLayout(readers, false), readers...)
+}
 
+// newMultiReader — NewMultiReader с заданными границами источников.
+func newMultiReader(buffersSize int64, buffersNum int, sizes *layout, readers ...SizedReadSeekCloser) *MultiReader {
 	return &MultiReader{
-		readers:     readers,
-		prefixSizes: prefixSizes,
-		buffersNum:  buffersNum,
-		bufferSize:  buffersSize,
+		readers:    readers,
+		srcMu:      make([]sync.Mutex, len(readers)),
+		sizes:      sizes,
+		buffersNum: buffersNum,
+		bufferSize: buffersSize,
+		readSem:    make(chan struct{}, 1),
+		pool:       bufpool.Default,
 	}
 }
 
 // Read читает данные из внутреннего окна, пополняемого префетчером.
+// Конкурентные вызовы Read сериализуются; Seek, Size и Close можно вызывать параллельно с ожидающим Read.
 func (m *MultiReader) Read(p []byte) (n int, err error) {
+	return m.ReadContext(context.Background(), p)
+}
+
+// ReadContext — Read, ожидание которого прерывает ctx: и очередь за конкурентным Read, и ожидание блока
+// от префетчера. Прерывание не трогает префетчер и позицию: уже скопированные в p байты учтены в n,
+// блок, которого не дождались, достанется следующему чтению. Возвращает ctx.Err().
+func (m *MultiReader) ReadContext(ctx context.Context, p []byte) (n int, err error) {
+	err = m.lockRead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlockRead()
+	return m.read(ctx, p)
+}
+
+// lockRead занимает очередь чтения; ctx прерывает ожидание.
+func (m *MultiReader) lockRead(ctx context.Context) error {
+	select {
+	case m.readSem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unlockRead освобождает очередь чтения.
+func (m *MultiReader) unlockRead() {
+	<-m.readSem
+}
+
+// read — Read без сериализации. Вызывается в очереди чтения (lockRead).
+func (m *MultiReader) read(ctx context.Context, p []byte) (n int, err error) {
 	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.closed {
-		m.mu.Unlock()
 		return 0, io.ErrClosedPipe
 	}
-	if m.windowStart == m.Size() {
-		m.mu.Unlock()
-		return 0, io.EOF
+	if len(p) == 0 {
+		return 0, nil
 	}
-	if m.pfBufCh == nil { // Если префетч не начат, запускаем его
-		m.pfBufCh = make(chan []byte, m.buffersNum)
-		m.pfErrCh = make(chan error, 1)
-		ctx, cancel := context.WithCancel(context.Background())
-		m.pfCancel = cancel
-		m.pfWg.Add(1)
-		go m.prefetchLoop(ctx, m.windowStart)
+	if m.directRead() {
+		return m.readDirect(ctx, p)
 	}
-	m.mu.Unlock()
 
 	for {
-		m.mu.Lock()
-		if len(m.windowBuf) != 0 { // Если данные в окне есть, то копируем их и продвигаем курсоры
-			dst := p[n:]
-			toCopy := min(len(dst), len(m.windowBuf))
-			copy(dst[:toCopy], m.windowBuf[:toCopy])
-			m.windowBuf = m.windowBuf[toCopy:]
-			m.windowStart += int64(toCopy)
-			n += toCopy
-			if n == len(p) {
-				m.mu.Unlock()
-				return n, nil
-			}
+		err = m.fillWindow(ctx)
+		if err != nil {
+			return n, err
+		}
+		// Копируем данные окна и продвигаем курсоры
+		toCopy := copy(p[n:], m.windowBuf)
+		sumErr := m.sums.update(m.windowStart, p[n:n+toCopy])
+		m.windowBuf = m.windowBuf[toCopy:]
+		m.windowStart += int64(toCopy)
+		m.counters.read.Add(int64(toCopy))
+		n += toCopy
+		if len(m.windowBuf) == 0 {
+			m.releaseWindow()
 		}
+		if n == len(p) || sumErr != nil {
+			return n, sumErr
+		}
+	}
+}
+
+// fillWindow дожидается непустого окна, при необходимости запуская префетчер. Вызывается под m.mu;
+// на время ожидания блока отпускает его. Возвращает io.EOF в конце потока (или несовпадение дайджеста, см. WithChecksum),
+// io.ErrClosedPipe после Close и ctx.Err(), если ctx завершился раньше, чем пришёл блок.
+func (m *MultiReader) fillWindow(ctx context.Context) error {
+	for len(m.windowBuf) == 0 {
+		if m.closed { // Close во время ожидания блока
+			return io.ErrClosedPipe
+		}
+		if m.pfBufCh == nil && m.sizes.atEnd(m.windowStart) { // Префетчер, дошедший до конца, может ещё прислать ошибку последнего сегмента
+			return m.sums.eof()
+		}
+		if m.pfBufCh == nil { // Если префетч не начат, запускаем его
+			m.startPrefetch()
+		}
+		bufCh, errCh, gen := m.pfBufCh, m.pfErrCh, m.pfGen
 		m.mu.Unlock()
 
-		buf, okPf := <-m.pfBufCh // Окно пусто - ждём новый блок от префетчера
-		if !okPf {               // Канал данных закрыт - считываем итоговую ошибку/EOF
-			select {
-			case err = <-m.pfErrCh:
-			default:
-				err = io.EOF
-			}
-			return n, err
+		var buf []byte
+		var okPf bool
+		select { // Окно пусто - ждём новый блок от префетчера
+		case buf, okPf = <-bufCh:
+		case <-ctx.Done():
+			m.mu.Lock()
+			return ctx.Err()
 		}
+		m.hooks.run(hookBlockReceived)
+
 		m.mu.Lock()
-		m.windowBuf = append(m.windowBuf, buf...)
-		m.mu.Unlock()
+		if m.pfGen != gen || m.closed { // Пока ждали, Seek перезапустил префетч или Close закрыл ридер: блок не нужен
+			if okPf {
+				m.discard(buf, len(buf))
+			}
+			continue
+		}
+		if !okPf { // Канал данных закрыт - считываем итоговую ошибку/EOF
+			err := <-errCh
+			stopped := m.pfStopped // Префетчер отменён прерванным SeekContext: его ошибка — лишь отмена
+			m.resetPrefetch()      // Префетчер завершён: следующая итерация или Read перезапустит его с текущей позиции
+			if err != nil && !errors.Is(err, io.EOF) && !m.closed && !stopped {
+				return err
+			}
+			continue
+		}
+		m.windowBuf = buf // Окно к этому моменту всегда вычитано: блок становится окном без копирования
+		m.windowBlock = buf
 	}
+	return nil
 }
 
 // Seek перемещает курсор
 func (m *MultiReader) Seek(offset int64, whence int) (int64, error) {
+	return m.SeekContext(context.Background(), offset, whence)
+}
+
+// SeekContext — Seek, который ctx прерывает, пока тот ждёт остановки префетчера (например, застрявшего
+// в медленном источнике). Прерванный Seek позицию не меняет и возвращает ctx.Err(); префетчер при этом
+// остаётся отменённым, и следующее чтение перезапустит его с прежней позиции.
+func (m *MultiReader) SeekContext(ctx context.Context, offset int64, whence int) (int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.closed {
 		return 0, io.ErrClosedPipe
 	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
 
 	seekPos := offset
 	switch whence {
@@ -114,28 +216,37 @@ func (m *MultiReader) Seek(offset int64, whence int) (int64, error) {
 	case io.SeekCurrent:
 		seekPos += m.windowStart
 	case io.SeekEnd:
-		seekPos += m.Size()
+		size, ok := m.sizes.known()
+		if !ok {
+			return 0, ErrSizeUnresolved
+		}
+		seekPos += size
 	default:
 		return 0, fmt.Errorf("invalid whence: %d", whence)
 	}
 
-	if seekPos < 0 || seekPos > m.Size() {
+	if !m.sizes.contains(seekPos) {
 		return 0, fmt.Errorf("seek position (%d) should be >= 0 and <= total size (%d)", seekPos, m.Size())
 	}
 
 	delta := seekPos - m.windowStart
 	switch {
-	case 0 <= delta && delta < int64(len(m.windowBuf)): // Быстрый путь: позиция внутри текущего окна - только сдвигаем смещение
+	case 0 <= delta && delta <= int64(len(m.windowBuf)): // Быстрый путь: позиция внутри окна или сразу за ним - префетчер уже читает с неё
+		m.counters.seeksInside.Add(1)
+		m.counters.discarded.Add(delta) // Перескоченная часть окна
 		m.windowBuf = m.windowBuf[delta:]
+		if len(m.windowBuf) == 0 {
+			m.releaseWindow()
+		}
 	default: // Вне окна: сбрасываем окно и перезапускаем префетч при следующем чтении
-		m.windowBuf = nil
-		if m.pfCancel != nil {
-			m.pfCancel()
+		err := m.stopPrefetch(ctx)
+		if err != nil {
+			return 0, err
 		}
-		m.pfWg.Wait()   // Дождаться завершения старого префетчера, чтобы исключить параллельный доступ
-		m.pfBufCh = nil // Останавливаем текущий префетч и сбрасываем его поля
-		m.pfErrCh = nil
-		m.pfCancel = nil
+		m.counters.seeksOutside.Add(1)
+		m.releaseWindow()
+		m.resetPrefetch()
+		m.seqReads = 0
 	}
 
 	m.windowStart = seekPos
@@ -158,19 +269,61 @@ func (m *MultiReader) Close() error {
 
 	m.pfWg.Wait()
 
-	for _, r := range m.readers {
-		err := r.Close()
-		if err != nil {
-			return fmt.Errorf("r.Close: %w", err)
-		}
+	m.mu.Lock() // Возвращаем в пул окно и блоки, которые префетчер успел отправить
+	m.releaseWindow()
+	m.drainPrefetch()
+	m.mu.Unlock()
+
+	return CloseAll(m.readers, "reader") // Закрываем все источники, даже если какой-то вернул ошибку
+}
+
+// startPrefetch запускает префетчер с текущей позиции окна. Вызывается под m.mu.
+func (m *MultiReader) startPrefetch() {
+	m.pfBufCh = make(chan []byte, m.buffersNum)
+	m.pfErrCh = make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.pfCancel = cancel
+	m.pfDone = make(chan struct{})
+	m.counters.starts.Add(1)
+	m.pfWg.Add(1)
+	go m.prefetchLoop(ctx, m.windowStart+int64(len(m.windowBuf)))
+}
+
+// resetPrefetch останавливает префетчер и сбрасывает его поля. Вызывается под m.mu.
+func (m *MultiReader) resetPrefetch() {
+	if m.pfCancel != nil {
+		m.pfCancel()
 	}
+	m.hooks.run(hookResetWait)
+	m.pfWg.Wait() // Дождаться завершения старого префетчера, чтобы исключить параллельный доступ
+	m.drainPrefetch()
+	m.pfBufCh = nil
+	m.pfErrCh = nil
+	m.pfCancel = nil
+	m.pfDone = nil
+	m.pfStopped = false
+	m.pfGen++
+}
 
-	return nil
+// stopPrefetch отменяет префетчер и ждёт его завершения, пока не завершится ctx. Вызывается под m.mu.
+// Если ctx завершился раньше, префетчер остаётся отменённым, но не сброшенным: его сбросит resetPrefetch.
+func (m *MultiReader) stopPrefetch(ctx context.Context) error {
+	if m.pfCancel == nil {
+		return nil
+	}
+	m.pfCancel()
+	select {
+	case <-m.pfDone:
+		return nil
+	case <-ctx.Done():
+		m.pfStopped = true
+		return ctx.Err()
+	}
 }
 
-// Size возвращает суммарный размер всех ридеров.
+// Size возвращает суммарный размер всех ридеров. С WithLazySizes узнаёт размеры всех ещё не опрошенных источников.
 func (m *MultiReader) Size() int64 {
-	return m.prefixSizes[len(m.readers)]
+	return m.sizes.total()
 }
 
 // prefetchLoop - горутина префетча. Наполняет pfBufCh блоками, по завершении шлёт ошибку в pfErrCh.
@@ -178,41 +331,66 @@ func (m *MultiReader) prefetchLoop(ctx context.Context, startPos int64) {
 	defer func() {
 		close(m.pfBufCh)
 		close(m.pfErrCh)
+		close(m.pfDone)
 		m.pfWg.Done()
 	}()
 
 	curPos := startPos
+	blockSize := m.firstBlockSize()
 
-	for curPos < m.Size() {
-		curReaderIdx := sort.Search(len(m.readers), func(i int) bool { return m.prefixSizes[i+1] > curPos })
+	for !m.sizes.atEnd(curPos) {
+		if ctx.Err() != nil { // Не начинаем новое (возможно, медленное) чтение после отмены
+			m.sendErr(ctx.Err())
+			return
+		}
+		curReaderIdx := m.readerIndex(curPos)
 		reader := m.readers[curReaderIdx]
 
-		_, err := reader.Seek(curPos-m.prefixSizes[curReaderIdx], io.SeekStart)
+		m.srcMu[curReaderIdx].Lock() // Источник читают и ридеры Range: Seek и Read не должны разрываться
+		readerStart, readerEnd := m.sizes.start(curReaderIdx), m.sizes.end(curReaderIdx)
+		_, err := reader.Seek(curPos-readerStart, io.SeekStart)
 		if err != nil {
+			m.srcMu[curReaderIdx].Unlock()
 			m.sendErr(err)
 			return
 		}
 
-		remainInReader := m.prefixSizes[curReaderIdx+1] - curPos
+		remainInReader := readerEnd - curPos
 		if remainInReader == 0 { // Достигли границы ридеров
-			curPos = m.prefixSizes[curReaderIdx+1]
+			m.srcMu[curReaderIdx].Unlock()
+			curPos = readerEnd
 			continue
 		}
-		toRead := min(remainInReader, m.bufferSize)
-		buf := make([]byte, toRead)
-		n, err := reader.Read(buf)
-		if n > 0 {
+		var buf []byte
+		var n int
+		if m.cache != nil {
+			buf, n, err = m.readCached(ctx, curReaderIdx, curPos-readerStart)
+		} else {
+			buf = m.pool.Get(int(min(remainInReader, blockSize)))
+			err = m.arbiter.acquireBlock(ctx, len(buf))
+			if err == nil {
+				n, err = reader.Read(buf)
+			}
+		}
+		m.srcMu[curReaderIdx].Unlock()
+		if n == 0 {
+			m.pool.Put(buf)
+		} else {
 			select {
 			case <-ctx.Done():
+				m.pool.Put(buf)
 				m.sendErr(ctx.Err())
 				return
 			case m.pfBufCh <- buf[:n]: // Ждем, пока окно освободиться, чтобы записать следующий блок
+				m.counters.prefetched.Add(int64(n))
 				curPos += int64(n) // Обновляем глобальную позицию на фактически прочитанные байты
+				blockSize = m.nextBlockSize(blockSize)
+				m.hooks.run(hookBlockSent)
 			}
 		}
 		switch {
 		case err == io.EOF:
-			curPos = m.prefixSizes[curReaderIdx+1]
+			curPos = readerEnd
 		case err != nil:
 			m.sendErr(err)
 			return
@@ -222,6 +400,28 @@ func (m *MultiReader) prefetchLoop(ctx context.Context, startPos int64) {
 	m.sendErr(io.EOF)
 }
 
+// releaseWindow сбрасывает окно и возвращает его блок в пул. Вызывается под m.mu.
+// Блок больше не читается ни окном, ни префетчером, поэтому его можно перезаписывать.
+func (m *MultiReader) releaseWindow() {
+	unread := len(m.windowBuf)
+	m.windowBuf = nil
+	if m.windowBlock == nil {
+		return
+	}
+	m.discard(m.windowBlock, unread)
+	m.windowBlock = nil
+}
+
+// drainPrefetch возвращает в пул блоки, оставшиеся в канале завершённого префетчера. Вызывается под m.mu.
+func (m *MultiReader) drainPrefetch() {
+	if m.pfBufCh == nil {
+		return
+	}
+	for buf := range m.pfBufCh { // Канал закрыт префетчером; блоки мог забрать и ожидающий Read — он вернёт их сам
+		m.discard(buf, len(buf))
+	}
+}
+
 // sendErr отправляет ошибку в канал, если есть место
 func (m *MultiReader) sendErr(err error) {
 	select {
@@ -229,3 +429,24 @@ func (m *MultiReader) sendErr(err error) {
 	default:
 	}
 }
+
+// hookPoint — точка внедрения в префетч для тестов.
+type hookPoint int
+
+const (
+	hookBlockSent     hookPoint = iota // префетчер отправил блок в канал
+	hookBlockReceived                  // Read получил блок из канала и ещё не взял мьютекс
+	hookResetWait                      // сброс префетча отменил контекст и ждёт завершения горутины (под m.mu)
+	hookZeroCopy                       // WriteTo передал сегмент в ядре, минуя префетч
+)
+
+// prefetchHooks — точки внедрения для тестов. Тестовый планировщик блокирует в них горутины,
+// чтобы детерминированно воспроизвести нужное чередование Seek/Close и доставки блоков префетчером.
+type prefetchHooks map[hookPoint]func()
+
+// run вызывает обработчик точки p, если он задан.
+func (h prefetchHooks) run(p hookPoint) {
+	if hook := h[p]; hook != nil {
+		hook()
+	}
+}
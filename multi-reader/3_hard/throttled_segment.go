@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+// throttledSegment — сегмент, чтение которого ограничено корзиной токенов; Seek, Size и Close идут напрямую в источник.
+type throttledSegment struct {
+	SizedReadSeekCloser
+	tr *throttle.Reader
+}
+
+// NewThrottledSegment ограничивает скорость чтения r корзиной bucket. Если одна корзина передана нескольким сегментам,
+// лимит действует на их суммарный поток. ctx прерывает ожидание токенов.
+func NewThrottledSegment(ctx context.Context, r SizedReadSeekCloser, bucket *throttle.Bucket) SizedReadSeekCloser {
+	return throttledSegment{SizedReadSeekCloser: r, tr: throttle.NewReader(ctx, r, bucket)}
+}
+
+func (s throttledSegment) Read(p []byte) (int, error) {
+	return s.tr.Read(p)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+	"github.com/zlatoivan/go-advanced/throttle"
+)
+
+func TestThrottledSegment_SharedBucketLimitsMultiReader(t *testing.T) {
+	bucket, err := throttle.NewBucket(40_000, 1024)
+	if err != nil {
+		t.Fatalf("NewBucket: %v", err)
+	}
+	parts := []string{strings.Repeat("a", 3000), strings.Repeat("b", 3000), strings.Repeat("c", 3000)}
+	readers := make([]SizedReadSeekCloser, len(parts))
+	for i, part := range parts {
+		readers[i] = NewThrottledSegment(context.Background(), testutil.NewStringsReader(part), bucket)
+	}
+	m := NewMultiReader(4096, 2, readers...)
+	defer m.Close()
+
+	start := time.Now()
+	got, err := io.ReadAll(m)
+	if err := testutil.Check(testutil.ExpectNoError("ReadAll", err), testutil.ExpectBytes("данные", got, []byte(strings.Join(parts, "")))); err != nil {
+		t.Fatal(err)
+	}
+	// 9000 байт: 1024 из полной корзины, остальные — по 40000 байт/с на все сегменты вместе
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Fatalf("чтение заняло %v, лимит не соблюдён", elapsed)
+	}
+}
+
+func TestThrottledSegment_CanceledContext(t *testing.T) {
+	bucket, err := throttle.NewBucket(1, 4)
+	if err != nil {
+		t.Fatalf("NewBucket: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	seg := NewThrottledSegment(ctx, testutil.NewStringsReader("abcdefgh"), bucket)
+
+	buf := make([]byte, 8)
+	n, err := seg.Read(buf)
+	if err != nil || n != 4 {
+		t.Fatalf("первое чтение из полной корзины: n=%d, err=%v", n, err)
+	}
+	cancel()
+	if _, err = seg.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Read после отмены: %v, ожидалась context.Canceled", err)
+	}
+	if seg.Size() != 8 {
+		t.Fatalf("Size %d, ожидался 8", seg.Size())
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// TimeoutError возвращается TimeoutReader, если операция источника не уложилась в отведённое время.
+// Совместима с net.Error (Timeout) и errors.Is(err, os.ErrDeadlineExceeded).
+type TimeoutError struct {
+	Op    string        // операция, не уложившаяся в таймаут: "read" или "seek"
+	After time.Duration // истёкший таймаут
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v", e.Op, e.After)
+}
+
+// Timeout сообщает, что ошибка — таймаут.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// Temporary сообщает, что операцию можно повторить.
+func (e *TimeoutError) Temporary() bool { return true }
+
+// Is делает ошибку сопоставимой с os.ErrDeadlineExceeded.
+func (e *TimeoutError) Is(target error) bool { return target == os.ErrDeadlineExceeded }
+
+// readDeadliner — источник с поддержкой дедлайнов чтения (net.Conn, *os.File поверх pipe или сокета).
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// timeoutResult — результат операции источника, выполненной сторожевой горутиной.
+type timeoutResult struct {
+	n   int
+	err error
+}
+
+// TimeoutReader ограничивает каждую операцию Read и Seek источника таймаутом и возвращает *TimeoutError,
+// чтобы один зависший источник не останавливал весь MultiReader.
+// Если источник поддерживает SetReadDeadline, Read ограничивается дедлайном. Иначе операция выполняется в отдельной
+// горутине: по таймауту она бросается, читая во внутренний буфер, а следующая операция сначала дожидается её
+// завершения и возвращает источник на последнюю известную позицию. Не предназначен для конкурентного использования;
+// Close можно вызывать в любой момент, в том числе для прерывания зависшей операции.
+type TimeoutReader struct {
+	r         SizedReadSeekCloser
+	timeout   time.Duration
+	deadliner readDeadliner      // nil — дедлайны не поддерживаются, используется сторожевая горутина
+	buf       []byte             // буфер чтения сторожевой горутины
+	pos       int64              // позиция после последней завершившейся в срок операции
+	needSeek  bool               // брошенная операция сдвинула источник: перед чтением нужно вернуться на pos
+	pending   chan timeoutResult // результат брошенной по таймауту операции; nil — таких нет
+}
+
+// Проверка, что TimeoutReader удовлетворяет интерфейсу SizedReadSeekCloser
+var _ SizedReadSeekCloser = (*TimeoutReader)(nil)
+
+// NewTimeoutReader оборачивает r таймаутом timeout на каждую операцию. Источник должен стоять в начале.
+func NewTimeoutReader(r SizedReadSeekCloser, timeout time.Duration) *TimeoutReader {
+	tr := &TimeoutReader{r: r, timeout: timeout}
+	// Сброс дедлайна заодно проверяет поддержку: обычный файл, например, возвращает os.ErrNoDeadline
+	if d, ok := r.(readDeadliner); ok && d.SetReadDeadline(time.Time{}) == nil {
+		tr.deadliner = d
+	}
+	return tr
+}
+
+// Read читает из источника не дольше таймаута.
+func (tr *TimeoutReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if tr.deadliner != nil {
+		return tr.readWithDeadline(p)
+	}
+
+	err := tr.settle("read")
+	if err != nil {
+		return 0, err
+	}
+	if tr.needSeek {
+		_, err = tr.seek(tr.pos)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if cap(tr.buf) < len(p) {
+		tr.buf = make([]byte, len(p))
+	}
+	buf := tr.buf[:len(p)]
+	res, ok := tr.run("read", func() (int, error) { return tr.r.Read(buf) })
+	if !ok {
+		tr.buf = nil // Буфер остаётся у брошенной горутины
+		return 0, res.err
+	}
+
+	n := copy(p, buf[:res.n])
+	tr.pos += int64(n)
+	return n, res.err
+}
+
+// Seek перемещает позицию источника не дольше таймаута.
+func (tr *TimeoutReader) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		target += tr.pos
+	case io.SeekEnd:
+		target += tr.r.Size()
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("negative seek position: %d", target)
+	}
+
+	err := tr.settle("seek")
+	if err != nil {
+		return 0, err
+	}
+	return tr.seek(target)
+}
+
+// Size возвращает размер источника.
+func (tr *TimeoutReader) Size() int64 {
+	return tr.r.Size()
+}
+
+// Close закрывает источник, не дожидаясь брошенной операции: закрытие обычно и прерывает её.
+func (tr *TimeoutReader) Close() error {
+	return tr.r.Close()
+}
+
+// readWithDeadline читает, ограничивая Read дедлайном источника.
+func (tr *TimeoutReader) readWithDeadline(p []byte) (int, error) {
+	err := tr.deadliner.SetReadDeadline(time.Now().Add(tr.timeout))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tr.deadliner.SetReadDeadline(time.Time{}) }()
+
+	n, err := tr.r.Read(p)
+	tr.pos += int64(n)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = &TimeoutError{Op: "read", After: tr.timeout}
+	}
+	return n, err
+}
+
+// seek позиционирует источник на target через сторожевую горутину.
+func (tr *TimeoutReader) seek(target int64) (int64, error) {
+	res, ok := tr.run("seek", func() (int, error) {
+		_, err := tr.r.Seek(target, io.SeekStart)
+		return 0, err
+	})
+	if !ok || res.err != nil {
+		tr.needSeek = true
+		return 0, res.err
+	}
+	tr.pos = target
+	tr.needSeek = false
+	return target, nil
+}
+
+// settle дожидается брошенной операции не дольше таймаута. Её результат отбрасывается.
+// Если она всё ещё не завершилась, операция name тоже считается не уложившейся в таймаут.
+func (tr *TimeoutReader) settle(name string) error {
+	if tr.pending == nil {
+		return nil
+	}
+	_, ok := tr.wait(tr.pending)
+	if !ok {
+		return &TimeoutError{Op: name, After: tr.timeout}
+	}
+	tr.pending = nil
+	return nil
+}
+
+// run выполняет op в сторожевой горутине. ok == false, если op не завершилась за таймаут;
+// тогда res.err содержит *TimeoutError, а операция запоминается как брошенная.
+func (tr *TimeoutReader) run(name string, op func() (int, error)) (res timeoutResult, ok bool) {
+	ch := make(chan timeoutResult, 1)
+	go func() {
+		n, err := op()
+		ch <- timeoutResult{n: n, err: err}
+	}()
+
+	res, ok = tr.wait(ch)
+	if !ok {
+		tr.pending = ch
+		tr.needSeek = true
+		return timeoutResult{err: &TimeoutError{Op: name, After: tr.timeout}}, false
+	}
+	return res, true
+}
+
+// wait ждёт результат из ch не дольше таймаута.
+func (tr *TimeoutReader) wait(ch <-chan timeoutResult) (timeoutResult, bool) {
+	t := time.NewTimer(tr.timeout)
+	defer t.Stop()
+	select {
+	case res := <-ch:
+		return res, true
+	case <-t.C:
+		return timeoutResult{}, false
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+const testOpTimeout = 20 * time.Millisecond
+
+// stuckReader зависает в Read и Seek, пока не вызван release. Зависшая операция всё же выполняется,
+// сдвигая позицию источника, как это сделал бы медленный сетевой ответ.
+type stuckReader struct {
+	*testutil.StringsReader
+	mu      sync.Mutex
+	blocked chan struct{} // nil — источник отвечает сразу
+}
+
+func newStuckReader(s string) *stuckReader {
+	return &stuckReader{StringsReader: testutil.NewStringsReader(s), blocked: make(chan struct{})}
+}
+
+func (s *stuckReader) gate() {
+	s.mu.Lock()
+	ch := s.blocked
+	s.mu.Unlock()
+	if ch != nil {
+		<-ch
+	}
+}
+
+func (s *stuckReader) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.blocked != nil {
+		close(s.blocked)
+		s.blocked = nil
+	}
+}
+
+func (s *stuckReader) Read(p []byte) (int, error) {
+	s.gate()
+	return s.StringsReader.Read(p)
+}
+
+func (s *stuckReader) Seek(offset int64, whence int) (int64, error) {
+	s.gate()
+	return s.StringsReader.Seek(offset, whence)
+}
+
+func expectTimeout(t *testing.T, what string, err error) {
+	t.Helper()
+	var te *TimeoutError
+	if !errors.As(err, &te) || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("%s: %v, ожидалась *TimeoutError", what, err)
+	}
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() {
+		t.Fatalf("%s: ошибка должна быть net.Error с Timeout() == true", what)
+	}
+}
+
+func TestTimeoutReader_AbandonsStuckRead(t *testing.T) {
+	src := newStuckReader("hello, world")
+	tr := NewTimeoutReader(src, testOpTimeout)
+	defer src.release()
+
+	buf := make([]byte, 5)
+	_, err := tr.Read(buf)
+	expectTimeout(t, "Read", err)
+	_, err = tr.Read(buf)
+	expectTimeout(t, "Read при всё ещё зависшей операции", err)
+
+	// Брошенное чтение завершается и сдвигает источник, но TimeoutReader возвращается на последнюю известную позицию
+	src.release()
+	got, err := io.ReadAll(tr)
+	if err != nil || string(got) != "hello, world" {
+		t.Fatalf("ReadAll после восстановления: %q, %v", got, err)
+	}
+}
+
+func TestTimeoutReader_SeekTimeout(t *testing.T) {
+	src := newStuckReader("hello, world")
+	tr := NewTimeoutReader(src, testOpTimeout)
+	defer src.release()
+
+	_, err := tr.Seek(7, io.SeekStart)
+	expectTimeout(t, "Seek", err)
+
+	src.release()
+	pos, err := tr.Seek(-5, io.SeekEnd)
+	if err != nil || pos != 7 {
+		t.Fatalf("Seek после восстановления: pos=%d, err=%v", pos, err)
+	}
+	got, err := io.ReadAll(tr)
+	if err != nil || string(got) != "world" {
+		t.Fatalf("ReadAll после Seek: %q, %v", got, err)
+	}
+}
+
+// pipeSegment — сегмент поверх os.Pipe: поддерживает дедлайны чтения, но не Seek.
+type pipeSegment struct {
+	*os.File
+	size int64
+}
+
+func (p pipeSegment) Size() int64 { return p.size }
+
+func TestTimeoutReader_UsesReadDeadline(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	defer w.Close()
+	tr := NewTimeoutReader(pipeSegment{File: r, size: 4}, testOpTimeout)
+	defer tr.Close()
+	if tr.deadliner == nil {
+		t.Skip("pipe на этой платформе не поддерживает дедлайны")
+	}
+
+	buf := make([]byte, 4)
+	_, err = tr.Read(buf)
+	expectTimeout(t, "Read из пустого pipe", err)
+
+	if _, err = w.WriteString("data"); err != nil {
+		t.Fatalf("запись в pipe: %v", err)
+	}
+	n, err := io.ReadFull(tr, buf)
+	if err != nil || string(buf[:n]) != "data" {
+		t.Fatalf("Read после записи: %q, %v", buf[:n], err)
+	}
+}
+
+func TestTimeoutReader_StuckSegmentDoesNotStallMultiReader(t *testing.T) {
+	stuck := newStuckReader(strings.Repeat("b", 10))
+	defer stuck.release()
+	m := NewMultiReader(4, 2,
+		NewTimeoutReader(testutil.NewStringsReader("aaaa"), testOpTimeout),
+		NewTimeoutReader(stuck, testOpTimeout),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(m)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		expectTimeout(t, "ReadAll", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("MultiReader завис на зависшем сегменте")
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FileSegment — сегмент MultiReader поверх файла. Для таких сегментов WriteTo с EnableZeroCopy
+// передаёт данные в сокет или pipe без копирования в пространство пользователя.
+type FileSegment struct {
+	f    *os.File
+	size int64
+}
+
+// Проверка, что FileSegment удовлетворяет интерфейсам SizedReadSeekCloser и io.ReaderAt
+var (
+	_ SizedReadSeekCloser = (*FileSegment)(nil)
+	_ io.ReaderAt         = (*FileSegment)(nil)
+)
+
+// NewFileSegment оборачивает открытый файл; размер берётся из Stat на момент вызова.
+func NewFileSegment(f *os.File) (*FileSegment, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat segment file: %w", err)
+	}
+	return &FileSegment{f: f, size: info.Size()}, nil
+}
+
+func (fs *FileSegment) Read(p []byte) (int, error) {
+	return fs.f.Read(p)
+}
+
+func (fs *FileSegment) Seek(offset int64, whence int) (int64, error) {
+	return fs.f.Seek(offset, whence)
+}
+
+// ReadAt читает файл с позиции off, не сдвигая его текущую позицию: так сегмент читают ридеры Range.
+func (fs *FileSegment) ReadAt(p []byte, off int64) (int, error) {
+	return fs.f.ReadAt(p, off)
+}
+
+// Size возвращает размер файла на момент создания сегмента.
+func (fs *FileSegment) Size() int64 {
+	return fs.size
+}
+
+// Close закрывает файл.
+func (fs *FileSegment) Close() error {
+	return fs.f.Close()
+}
+
+// EnableZeroCopy включает быстрый путь WriteTo: файловые сегменты (FileSegment) передаются в TCP- или
+// Unix-сокет либо в pipe через sendfile/splice, минуя префетч и дисковый кэш. Работает только на Linux;
+// на других системах, для прочих сегментов и приёмников WriteTo читает данные обычным путём.
+func (m *MultiReader) EnableZeroCopy() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.zeroCopy = true
+}
+
+// WriteTo пишет в w данные от текущей позиции до конца (io.WriterTo, используется io.Copy).
+// Блоки префетча передаются в w напрямую, без копирования через промежуточный буфер.
+// Позиция продвигается на записанное, поэтому после ошибки чтение можно продолжить.
+func (m *MultiReader) WriteTo(w io.Writer) (written int64, err error) {
+	err = m.lockRead(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlockRead()
+
+	for {
+		n, ok, err := m.copySegment(w)
+		if !ok {
+			n, err = m.writeWindow(w)
+		}
+		written += n
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// writeWindow дожидается окна и пишет его в w целиком. На время записи блок изымается из окна,
+// чтобы Seek и Close не вернули его в пул; недописанный остаток становится окном снова. Вызывается в очереди чтения.
+func (m *MultiReader) writeWindow(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	err := m.fillWindow(context.Background())
+	if err != nil {
+		m.mu.Unlock()
+		return 0, err
+	}
+	chunk, block, pos, gen := m.windowBuf, m.windowBlock, m.windowStart, m.pfGen
+	m.windowBuf, m.windowBlock = nil, nil
+	m.mu.Unlock()
+
+	nw, err := w.Write(chunk)
+	if err == nil && nw < len(chunk) {
+		err = io.ErrShortWrite
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sumErr := m.sums.update(pos, chunk[:nw])
+	if err == nil {
+		err = sumErr
+	}
+	m.counters.read.Add(int64(nw))
+	if m.pfGen != gen || m.closed || m.windowStart != pos { // Seek или Close во время записи задают позицию сами
+		m.discard(block, len(chunk)-nw)
+		return int64(nw), err
+	}
+	m.windowStart = pos + int64(nw)
+	if nw < len(chunk) {
+		m.windowBuf, m.windowBlock = chunk[nw:], block
+	} else {
+		m.pool.Put(block)
+	}
+	return int64(nw), err
+}
+
+// copySegment передаёт остаток текущего сегмента в w в ядре, если это возможно: окно пусто,
+// сегмент файловый, а w принимает данные из файла. ok == false — быстрый путь неприменим. Вызывается в очереди чтения.
+func (m *MultiReader) copySegment(w io.Writer) (n int64, ok bool, err error) {
+	m.mu.Lock()
+	pos := m.windowStart
+	if !m.zeroCopy || m.closed || m.cache != nil || m.sums != nil || len(m.windowBuf) != 0 || m.sizes.atEnd(pos) || !zeroCopyTarget(w) {
+		m.mu.Unlock()
+		return 0, false, nil
+	}
+	idx := m.readerIndex(pos)
+	seg, isFile := m.readers[idx].(*FileSegment)
+	if !isFile {
+		m.mu.Unlock()
+		return 0, false, nil
+	}
+	m.resetPrefetch() // Префетчер двигает позицию того же файла: останавливаем его, Read перезапустит
+	gen := m.pfGen
+	m.mu.Unlock()
+
+	off := pos - m.sizes.start(idx)
+	m.srcMu[idx].Lock() // Позицию того же файла двигают и ридеры Range: Seek и передача не должны разрываться
+	_, err = seg.f.Seek(off, io.SeekStart)
+	if err == nil {
+		// *net.TCPConn, *net.UnixConn и *os.File реализуют ReadFrom через sendfile/splice для *io.LimitedReader над файлом
+		n, err = io.Copy(w, io.LimitReader(seg.f, seg.size-off))
+		if err == nil && n < seg.size-off {
+			err = io.ErrUnexpectedEOF // Файл укоротился после NewFileSegment
+		}
+	}
+	m.srcMu[idx].Unlock()
+
+	m.mu.Lock()
+	if m.pfGen == gen && !m.closed { // Seek во время передачи задаёт позицию сам
+		m.windowStart = pos + n
+	}
+	m.counters.read.Add(n)
+	m.mu.Unlock()
+	m.hooks.run(hookZeroCopy)
+	return n, true, err
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+// zeroCopyTarget сообщает, примет ли w данные из файла в ядре: sendfile для сокетов, splice для pipe.
+func zeroCopyTarget(w io.Writer) bool {
+	switch w.(type) {
+	case *net.TCPConn, *net.UnixConn, *os.File:
+		return true
+	}
+	return false
+}
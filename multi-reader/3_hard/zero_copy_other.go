@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "io"
+
+// zeroCopyTarget: вне Linux быстрого пути нет, WriteTo читает обычным путём.
+func zeroCopyTarget(io.Writer) bool {
+	return false
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zlatoivan/go-advanced/testutil"
+)
+
+// newZeroCopyMultiReader собирает MultiReader из двух файловых сегментов с памятью между ними
+// и возвращает его вместе с ожидаемым содержимым и счётчиком переданных в ядре сегментов.
+func newZeroCopyMultiReader(t *testing.T) (*MultiReader, []byte, *atomic.Int32) {
+	t.Helper()
+	rnd := rand.New(rand.NewSource(1))
+	parts := [][]byte{make([]byte, 300_000), make([]byte, 1000), make([]byte, 200_000)}
+	for _, p := range parts {
+		rnd.Read(p)
+	}
+
+	fileSegment := func(name string, data []byte) *FileSegment {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seg, err := NewFileSegment(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return seg
+	}
+	m := NewMultiReader(4096, 4,
+		fileSegment("a", parts[0]),
+		testutil.NewStringsReader(string(parts[1])),
+		fileSegment("c", parts[2]),
+	)
+	t.Cleanup(func() { _ = m.Close() })
+
+	var spliced atomic.Int32
+	m.hooks = prefetchHooks{hookZeroCopy: func() { spliced.Add(1) }}
+	m.EnableZeroCopy()
+	return m, bytes.Join(parts, nil), &spliced
+}
+
+// checkSpliced проверяет число сегментов, переданных в ядре: на Linux — want, на прочих системах — ни одного.
+func checkSpliced(t *testing.T, spliced *atomic.Int32, want int32) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		want = 0
+	}
+	if got := spliced.Load(); got != want {
+		t.Fatalf("в ядре передано сегментов: %d, ожидалось %d", got, want)
+	}
+}
+
+func TestWriteTo_ZeroCopyToTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("нет локальной сети: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	m, want, spliced := newZeroCopyMultiReader(t)
+	// Начинаем с середины первого файла после обычного Read: окно и префетч уже заполнены
+	if _, err := m.Seek(100_000, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	head := make([]byte, 10)
+	if _, err := io.ReadFull(m, head); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := io.Copy(conn, m)
+	_ = conn.Close()
+	if err != nil || n != int64(len(want))-100_010 {
+		t.Fatalf("io.Copy: %d байт, %v", n, err)
+	}
+	if got := <-received; !bytes.Equal(got, want[100_010:]) {
+		t.Fatalf("получено %d байт, данные не совпадают", len(got))
+	}
+	// Первый файл: хвост окна читается обычным путём, остаток сегмента — в ядре; средний сегмент — обычным путём
+	checkSpliced(t, spliced, 2)
+}
+
+func TestWriteTo_ZeroCopyToPipe(t *testing.T) {
+	m, want, spliced := newZeroCopyMultiReader(t)
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(pr)
+		received <- data
+	}()
+
+	n, err := m.WriteTo(pw)
+	_ = pw.Close()
+	if err != nil || n != int64(len(want)) {
+		t.Fatalf("WriteTo: %d байт, %v", n, err)
+	}
+	if got := <-received; !bytes.Equal(got, want) {
+		t.Fatalf("получено %d байт, данные не совпадают", len(got))
+	}
+	checkSpliced(t, spliced, 2)
+
+	// Позиция в конце, следующий Read — io.EOF
+	if _, err := m.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Read после WriteTo: %v", err)
+	}
+}
+
+// TestWriteTo_FallbackAndResume: приёмник без быстрого пути получает данные обычным путём,
+// а после WriteTo чтение и Seek продолжают работать с правильной позиции.
+func TestWriteTo_FallbackAndResume(t *testing.T) {
+	m, want, spliced := newZeroCopyMultiReader(t)
+	if _, err := m.Seek(-50, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	var dst bytes.Buffer
+	n, err := m.WriteTo(&dst)
+	if err != nil || n != 50 || !bytes.Equal(dst.Bytes(), want[len(want)-50:]) {
+		t.Fatalf("WriteTo: %d байт, %v", n, err)
+	}
+	checkSpliced(t, spliced, 0)
+
+	if _, err := m.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(m)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("ReadAll после WriteTo: %d байт, %v", len(got), err)
+	}
+}
+
+// blockWriter запоминает размеры вызовов Write; после limit байт принимает часть записи и возвращает errStop.
+type blockWriter struct {
+	buf    bytes.Buffer
+	writes []int
+	limit  int // <= 0 — без ограничения
+}
+
+var errStop = errors.New("stop")
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	if w.limit > 0 && w.buf.Len()+len(p) > w.limit {
+		n, _ := w.buf.Write(p[:w.limit-w.buf.Len()])
+		return n, errStop
+	}
+	return w.buf.Write(p)
+}
+
+// TestWriteTo_WritesPrefetchBlocks: io.Copy выбирает WriteTo, и в приёмник уходят сами блоки префетча —
+// по одному Write на блок, не длиннее размера блока и не пересекая границы сегментов.
+func TestWriteTo_WritesPrefetchBlocks(t *testing.T) {
+	parts := []string{strings.Repeat("a", 150), strings.Repeat("b", 10), strings.Repeat("c", 100)}
+	m := NewMultiReader(64, 2, testutil.NewStringsReader(parts[0]), testutil.NewStringsReader(parts[1]), testutil.NewStringsReader(parts[2]))
+	defer m.Close()
+
+	var dst blockWriter
+	n, err := io.Copy(&dst, m)
+	if err != nil || n != 260 || dst.buf.String() != strings.Join(parts, "") {
+		t.Fatalf("io.Copy: %d байт, %v", n, err)
+	}
+	if want := []int{64, 64, 22, 10, 64, 36}; !slices.Equal(dst.writes, want) {
+		t.Fatalf("размеры записей %v, ожидались %v", dst.writes, want)
+	}
+}
+
+// TestWriteTo_ShortWriteResumes: после ошибки приёмника позиция стоит сразу за записанными байтами,
+// и недописанный остаток блока читается следующим Read.
+func TestWriteTo_ShortWriteResumes(t *testing.T) {
+	content := strings.Repeat("0123456789", 30)
+	m := NewMultiReader(64, 2, testutil.NewStringsReader(content[:100]), testutil.NewStringsReader(content[100:]))
+	defer m.Close()
+
+	dst := blockWriter{limit: 90}
+	n, err := m.WriteTo(&dst)
+	if !errors.Is(err, errStop) || n != 90 || dst.buf.String() != content[:90] {
+		t.Fatalf("WriteTo: %d байт, %v", n, err)
+	}
+	if pos, _ := m.Seek(0, io.SeekCurrent); pos != 90 {
+		t.Fatalf("позиция после ошибки %d, ожидалась 90", pos)
+	}
+	rest, err := io.ReadAll(m)
+	if err != nil || string(rest) != content[90:] {
+		t.Fatalf("ReadAll после ошибки: %q, %v", rest, err)
+	}
+}
+
+// TestWriteTo_ZeroCopyWithConcurrentRange: передача файла в ядре и ридер Range над тем же файлом
+// не сдвигают позицию файла друг другу.
+func TestWriteTo_ZeroCopyWithConcurrentRange(t *testing.T) {
+	m, want, _ := newZeroCopyMultiReader(t)
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(pr)
+		received <- data
+	}()
+
+	rangeErr := make(chan error, 1)
+	go func() {
+		for i := range 20 {
+			off := int64(i * 10_000)
+			r, err := m.Range(off, 50_000)
+			if err != nil {
+				rangeErr <- err
+				return
+			}
+			got, err := io.ReadAll(r)
+			_ = r.Close()
+			if err != nil || !bytes.Equal(got, want[off:off+50_000]) {
+				rangeErr <- errors.New("данные Range не совпадают")
+				return
+			}
+		}
+		rangeErr <- nil
+	}()
+
+	n, err := m.WriteTo(pw)
+	_ = pw.Close()
+	if err != nil || n != int64(len(want)) {
+		t.Fatalf("WriteTo: %d байт, %v", n, err)
+	}
+	if got := <-received; !bytes.Equal(got, want) {
+		t.Fatalf("получено %d байт, данные не совпадают", len(got))
+	}
+	if err = <-rangeErr; err != nil {
+		t.Fatal(err)
+	}
+}
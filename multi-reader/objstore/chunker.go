@@ -0,0 +1,178 @@
+package objstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SHA256Digest — дайджест чанка по умолчанию: sha256 в hex.
+func SHA256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chunk — очередной чанк потока. Data принадлежит получателю: Chunker не переиспользует её.
+type Chunk struct {
+	Index  int    // порядковый номер чанка
+	Offset int64  // позиция чанка в исходном потоке
+	Data   []byte // содержимое
+	Digest string // ключ чанка в ChunkStore
+}
+
+// ChunkRef — ссылка на сохранённый чанк: по списку ссылок поток собирается обратно из ChunkStore.
+type ChunkRef struct {
+	Digest string
+	Size   int64
+}
+
+// ChunkerConfig — настройки Chunker.
+type ChunkerConfig struct {
+	Size           int                      // размер чанка; при ContentDefined — средний размер
+	ContentDefined bool                     // границы по содержимому (rolling hash) вместо фиксированных
+	Digest         func(data []byte) string // дайджест чанка; nil — SHA256Digest
+}
+
+// Chunker нарезает поток на чанки с дайджестами для дедуплицирующего бэкапа в ChunkStore.
+// Фиксированные чанки дешевле, но вставка байта в начало потока сдвигает все границы;
+// при ContentDefined граница ставится там, где rolling hash последних байт удовлетворяет маске,
+// поэтому после локальной правки границы восстанавливаются и остальные чанки совпадают с уже сохранёнными.
+// Размер чанка по содержимому ограничен диапазоном [Size/4, Size*4].
+type Chunker struct {
+	r       io.Reader
+	cfg     ChunkerConfig
+	minSize int
+	maxSize int
+	mask    uint64 // граница — когда старшие биты gear-хеша под маской нулевые
+
+	buf    []byte // прочитанные, но ещё не выданные данные
+	eof    bool   // источник исчерпан
+	index  int
+	offset int64
+}
+
+// NewChunker создаёт нарезчик потока r.
+func NewChunker(r io.Reader, cfg ChunkerConfig) (*Chunker, error) {
+	if cfg.Size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", cfg.Size)
+	}
+	if cfg.Digest == nil {
+		cfg.Digest = SHA256Digest
+	}
+	c := &Chunker{r: r, cfg: cfg, minSize: cfg.Size, maxSize: cfg.Size}
+	if cfg.ContentDefined {
+		c.minSize = max(cfg.Size/4, 1)
+		c.maxSize = cfg.Size * 4
+		bits := 0
+		for 1<<bits < cfg.Size { // Граница в среднем через 2^bits байт после minSize
+			bits++
+		}
+		if bits > 0 {
+			c.mask = ^uint64(0) << (64 - bits)
+		}
+	}
+	return c, nil
+}
+
+// Next возвращает следующий чанк или io.EOF, когда поток исчерпан.
+func (c *Chunker) Next() (Chunk, error) {
+	err := c.fill()
+	if err != nil {
+		return Chunk{}, err
+	}
+	if len(c.buf) == 0 {
+		return Chunk{}, io.EOF
+	}
+
+	cut := min(len(c.buf), c.maxSize)
+	if c.cfg.ContentDefined {
+		cut = c.cutPoint(c.buf[:cut])
+	}
+	data := make([]byte, cut)
+	copy(data, c.buf)
+	c.buf = c.buf[:copy(c.buf, c.buf[cut:])]
+
+	chunk := Chunk{Index: c.index, Offset: c.offset, Data: data, Digest: c.cfg.Digest(data)}
+	c.index++
+	c.offset += int64(cut)
+	return chunk, nil
+}
+
+// StoreAll нарезает оставшийся поток и сохраняет чанки в store, пропуская уже сохранённые.
+// Возвращает ссылки на все чанки по порядку, в том числе на пропущенные.
+func (c *Chunker) StoreAll(ctx context.Context, store ChunkStore) ([]ChunkRef, error) {
+	var refs []ChunkRef
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return refs, nil
+		}
+		if err != nil {
+			return refs, err
+		}
+		has, err := store.HasChunk(ctx, chunk.Digest)
+		if err != nil {
+			return refs, fmt.Errorf("check chunk %d (%s): %w", chunk.Index, chunk.Digest, err)
+		}
+		if !has {
+			err = store.PutChunk(ctx, chunk.Digest, chunk.Data)
+			if err != nil {
+				return refs, fmt.Errorf("put chunk %d (%s): %w", chunk.Index, chunk.Digest, err)
+			}
+		}
+		refs = append(refs, ChunkRef{Digest: chunk.Digest, Size: int64(len(chunk.Data))})
+	}
+}
+
+// fill дочитывает буфер до maxSize байт или до конца источника.
+func (c *Chunker) fill() error {
+	if c.eof || len(c.buf) >= c.maxSize {
+		return nil
+	}
+	if cap(c.buf) < c.maxSize {
+		buf := make([]byte, len(c.buf), c.maxSize)
+		copy(buf, c.buf)
+		c.buf = buf
+	}
+	n, err := io.ReadFull(c.r, c.buf[len(c.buf):c.maxSize])
+	c.buf = c.buf[:len(c.buf)+n]
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		c.eof = true
+	case err != nil:
+		return fmt.Errorf("read chunk %d: %w", c.index, err)
+	}
+	return nil
+}
+
+// cutPoint ищет границу чанка по gear-хешу: первый байт после minSize, на котором старшие биты хеша под маской нулевые.
+// Если границы нет, чанк занимает весь data (maxSize байт или остаток потока).
+func (c *Chunker) cutPoint(data []byte) int {
+	if len(data) <= c.minSize {
+		return len(data)
+	}
+	var h uint64
+	for i, b := range data {
+		h = h<<1 + gearTable[b]
+		if i+1 >= c.minSize && h&c.mask == 0 {
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// gearTable — случайные 64-битные значения для gear-хеша; фиксированный seed делает границы воспроизводимыми.
+var gearTable = func() (table [256]uint64) {
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range table { // splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+		z = (z ^ z>>27) * 0x94d049bb133111eb
+		table[i] = z ^ z>>31
+	}
+	return table
+}()
@@ -0,0 +1,137 @@
+package objstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore/objstoretest"
+)
+
+func randomData(seed int64, n int) []byte {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// readChunks нарезает data до конца и проверяет, что чанки покрывают поток без пропусков.
+func readChunks(t *testing.T, data []byte, cfg objstore.ChunkerConfig) []objstore.Chunk {
+	t.Helper()
+	c, err := objstore.NewChunker(bytes.NewReader(data), cfg)
+	require.NoError(t, err)
+
+	var chunks []objstore.Chunk
+	var joined []byte
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, len(chunks), chunk.Index)
+		require.Equal(t, int64(len(joined)), chunk.Offset)
+		require.Equal(t, objstore.SHA256Digest(chunk.Data), chunk.Digest)
+		chunks = append(chunks, chunk)
+		joined = append(joined, chunk.Data...)
+	}
+	require.Equal(t, data, joined, "чанки должны собираться в исходный поток")
+	return chunks
+}
+
+func digests(chunks []objstore.Chunk) map[string]bool {
+	set := make(map[string]bool, len(chunks))
+	for _, c := range chunks {
+		set[c.Digest] = true
+	}
+	return set
+}
+
+func TestChunker_FixedSize(t *testing.T) {
+	chunks := readChunks(t, []byte(strings.Repeat("x", 25)), objstore.ChunkerConfig{Size: 10})
+	require.Len(t, chunks, 3)
+	assert.Equal(t, []int{10, 10, 5}, []int{len(chunks[0].Data), len(chunks[1].Data), len(chunks[2].Data)})
+	assert.Equal(t, chunks[0].Digest, chunks[1].Digest, "одинаковое содержимое — одинаковый дайджест")
+
+	assert.Empty(t, readChunks(t, nil, objstore.ChunkerConfig{Size: 10}))
+
+	_, err := objstore.NewChunker(bytes.NewReader(nil), objstore.ChunkerConfig{})
+	assert.Error(t, err)
+}
+
+func TestChunker_ContentDefinedSizes(t *testing.T) {
+	const size = 1 << 10
+	chunks := readChunks(t, randomData(1, 256<<10), objstore.ChunkerConfig{Size: size, ContentDefined: true})
+	for _, c := range chunks[:len(chunks)-1] {
+		assert.GreaterOrEqual(t, len(c.Data), size/4)
+		assert.LessOrEqual(t, len(c.Data), size*4)
+	}
+	avg := (256 << 10) / len(chunks)
+	assert.InDelta(t, size, avg, size, "средний размер чанка должен быть порядка Size, получено %d", avg)
+}
+
+// TestChunker_ContentDefinedSurvivesInsertion проверяет главное свойство для дедупликации:
+// вставка в начало потока сдвигает все фиксированные границы, а границы по содержимому восстанавливаются.
+func TestChunker_ContentDefinedSurvivesInsertion(t *testing.T) {
+	original := randomData(2, 128<<10)
+	edited := append([]byte("inserted prefix"), original...)
+
+	shared := func(cfg objstore.ChunkerConfig) float64 {
+		before := digests(readChunks(t, original, cfg))
+		after := readChunks(t, edited, cfg)
+		n := 0
+		for _, c := range after {
+			if before[c.Digest] {
+				n++
+			}
+		}
+		return float64(n) / float64(len(after))
+	}
+
+	assert.Zero(t, shared(objstore.ChunkerConfig{Size: 1 << 10}), "фиксированные чанки после вставки не совпадают")
+	assert.Greater(t, shared(objstore.ChunkerConfig{Size: 1 << 10, ContentDefined: true}), 0.9)
+}
+
+func TestChunker_StoreAllDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	store := objstoretest.New(objstoretest.Config{Digest: objstore.SHA256Digest})
+	data := append(randomData(3, 8<<10), randomData(3, 8<<10)...) // Вторая половина повторяет первую
+
+	c, err := objstore.NewChunker(bytes.NewReader(data), objstore.ChunkerConfig{Size: 1 << 10})
+	require.NoError(t, err)
+	refs, err := c.StoreAll(ctx, store)
+	require.NoError(t, err)
+	require.Len(t, refs, 16)
+
+	puts := func() (n int) {
+		for _, req := range store.Requests() {
+			if req.Op == "put" {
+				n++
+			}
+		}
+		return n
+	}
+	assert.Equal(t, 8, puts(), "повторяющиеся чанки сохраняются один раз")
+
+	var restored []byte
+	for _, ref := range refs {
+		chunk, err := store.GetChunk(ctx, ref.Digest)
+		require.NoError(t, err)
+		require.EqualValues(t, ref.Size, len(chunk))
+		restored = append(restored, chunk...)
+	}
+	assert.Equal(t, data, restored)
+
+	store.FailNext(objstoretest.ErrInjected)
+	c, err = objstore.NewChunker(bytes.NewReader(data), objstore.ChunkerConfig{Size: 1 << 10})
+	require.NoError(t, err)
+	_, err = c.StoreAll(ctx, store)
+	assert.ErrorIs(t, err, objstoretest.ErrInjected)
+}
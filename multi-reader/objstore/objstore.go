@@ -0,0 +1,34 @@
+// Package objstore описывает интерфейсы удалённых хранилищ объектов, поверх которых строятся сегменты MultiReader,
+// и адаптер Segment, читающий объект диапазонными запросами.
+package objstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound возвращается, если объекта или чанка нет в хранилище.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidRange возвращается для диапазона за пределами объекта (аналог HTTP 416).
+	ErrInvalidRange = errors.New("invalid range")
+)
+
+// ObjectGetter — хранилище объектов с диапазонными запросами (HTTP Range, S3 GetObject с Range).
+type ObjectGetter interface {
+	// Size returns the object size in bytes
+	Size(ctx context.Context, key string) (int64, error)
+	// GetRange returns length bytes of the object starting at offset; length < 0 means up to the end
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
+
+// ChunkStore — контентно-адресуемое хранилище чанков: ключ чанка — его дайджест.
+type ChunkStore interface {
+	// PutChunk stores data under its digest; storing an existing digest is a no-op
+	PutChunk(ctx context.Context, digest string, data []byte) error
+	// GetChunk returns the chunk data or ErrNotFound
+	GetChunk(ctx context.Context, digest string) ([]byte, error)
+	// HasChunk reports whether the chunk is stored
+	HasChunk(ctx context.Context, digest string) (bool, error)
+}
@@ -0,0 +1,256 @@
+// Package objstoretest содержит in-memory реализацию хранилищ из objstore для герметичных тестов адаптеров.
+package objstoretest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+)
+
+// ErrInjected — ошибка, внедряемая Fake по FailureRate или FailNext.
+var ErrInjected = errors.New("injected failure")
+
+// Config — поведение Fake.
+type Config struct {
+	Latency      time.Duration            // задержка каждого запроса
+	Jitter       time.Duration            // случайная добавка к задержке в диапазоне [0, Jitter)
+	FailureRate  float64                  // доля запросов, завершающихся ErrInjected, от 0 до 1
+	Seed         int64                    // seed для jitter и FailureRate; 0 — 1
+	StrictRanges bool                     // отклонять диапазоны, выходящие за конец объекта, вместо усечения
+	Digest       func(data []byte) string // проверка дайджеста в PutChunk; nil — без проверки
+}
+
+// Request — запись об одном запросе к Fake.
+type Request struct {
+	Op     string // "size", "get", "put", "get-chunk", "has-chunk"
+	Key    string
+	Offset int64
+	Length int64
+	Err    error
+}
+
+// Fake — in-memory хранилище объектов и чанков, реализующее objstore.ObjectGetter и objstore.ChunkStore,
+// с внедряемой задержкой, случайными и заданными сбоями и проверкой диапазонов.
+// Записывает все запросы и следит за незакрытыми телами ответов.
+type Fake struct {
+	cfg Config
+
+	mu         sync.Mutex
+	rnd        *rand.Rand
+	objects    map[string][]byte
+	chunks     map[string][]byte
+	failNext   []error
+	requests   []Request
+	openBodies int
+}
+
+// Проверка, что Fake удовлетворяет интерфейсам objstore
+var (
+	_ objstore.ObjectGetter = (*Fake)(nil)
+	_ objstore.ChunkStore   = (*Fake)(nil)
+)
+
+// New создаёт пустое хранилище.
+func New(cfg Config) *Fake {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	return &Fake{
+		cfg:     cfg,
+		rnd:     rand.New(rand.NewSource(seed)),
+		objects: make(map[string][]byte),
+		chunks:  make(map[string][]byte),
+	}
+}
+
+// Put сохраняет объект key (копию data).
+func (f *Fake) Put(key string, data []byte) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key] = bytes.Clone(data)
+	return f
+}
+
+// FailNext заставляет следующие len(errs) запросов вернуть errs по порядку (nil в списке — запрос без сбоя).
+func (f *Fake) FailNext(errs ...error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = append(f.failNext, errs...)
+	return f
+}
+
+func (f *Fake) Size(ctx context.Context, key string) (int64, error) {
+	req := Request{Op: "size", Key: key}
+	err := f.begin(ctx, &req)
+	if err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	if !ok {
+		return 0, f.finish(req, fmt.Errorf("%w: %q", objstore.ErrNotFound, key))
+	}
+	return int64(len(data)), f.finish(req, nil)
+}
+
+func (f *Fake) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	req := Request{Op: "get", Key: key, Offset: offset, Length: length}
+	err := f.begin(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, f.finish(req, fmt.Errorf("%w: %q", objstore.ErrNotFound, key))
+	}
+
+	size := int64(len(data))
+	end := size
+	if length >= 0 {
+		end = offset + length
+	}
+	switch {
+	case offset < 0 || offset > size || (offset == size && size > 0):
+		return nil, f.finish(req, fmt.Errorf("%w: offset %d of %d-byte object %q", objstore.ErrInvalidRange, offset, size, key))
+	case end > size && f.cfg.StrictRanges:
+		return nil, f.finish(req, fmt.Errorf("%w: range [%d, %d) beyond %d-byte object %q", objstore.ErrInvalidRange, offset, end, size, key))
+	}
+	end = min(end, size)
+
+	f.openBodies++
+	return &body{Reader: bytes.NewReader(data[offset:end]), f: f}, f.finish(req, nil)
+}
+
+func (f *Fake) PutChunk(ctx context.Context, digest string, data []byte) error {
+	req := Request{Op: "put", Key: digest, Length: int64(len(data))}
+	err := f.begin(ctx, &req)
+	if err != nil {
+		return err
+	}
+	if f.cfg.Digest != nil {
+		if got := f.cfg.Digest(data); got != digest {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.finish(req, fmt.Errorf("digest mismatch: chunk %q hashes to %q", digest, got))
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.chunks[digest]; !ok {
+		f.chunks[digest] = bytes.Clone(data)
+	}
+	return f.finish(req, nil)
+}
+
+func (f *Fake) GetChunk(ctx context.Context, digest string) ([]byte, error) {
+	req := Request{Op: "get-chunk", Key: digest}
+	err := f.begin(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.chunks[digest]
+	if !ok {
+		return nil, f.finish(req, fmt.Errorf("%w: chunk %q", objstore.ErrNotFound, digest))
+	}
+	return bytes.Clone(data), f.finish(req, nil)
+}
+
+func (f *Fake) HasChunk(ctx context.Context, digest string) (bool, error) {
+	req := Request{Op: "has-chunk", Key: digest}
+	err := f.begin(ctx, &req)
+	if err != nil {
+		return false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.chunks[digest]
+	return ok, f.finish(req, nil)
+}
+
+// Requests возвращает все запросы в порядке поступления.
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
+
+// OpenBodies возвращает число тел ответов GetRange, которые ещё не закрыты.
+func (f *Fake) OpenBodies() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.openBodies
+}
+
+// begin выдерживает задержку и решает, внедрять ли сбой. При сбое запрос записывается сразу.
+func (f *Fake) begin(ctx context.Context, req *Request) error {
+	f.mu.Lock()
+	delay := f.cfg.Latency
+	if f.cfg.Jitter > 0 {
+		delay += time.Duration(f.rnd.Int63n(int64(f.cfg.Jitter)))
+	}
+	var err error
+	switch {
+	case len(f.failNext) > 0:
+		err = f.failNext[0]
+		f.failNext = f.failNext[1:]
+	case f.cfg.FailureRate > 0 && f.rnd.Float64() < f.cfg.FailureRate:
+		err = ErrInjected
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+		case <-timer.C:
+		}
+	} else if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.finish(*req, err)
+	}
+	return nil
+}
+
+// finish записывает запрос с его результатом. Вызывается под f.mu.
+func (f *Fake) finish(req Request, err error) error {
+	req.Err = err
+	f.requests = append(f.requests, req)
+	return err
+}
+
+// body — тело ответа GetRange, отслеживающее закрытие.
+type body struct {
+	*bytes.Reader
+	f      *Fake
+	closed bool
+}
+
+func (b *body) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.f.mu.Lock()
+	defer b.f.mu.Unlock()
+	b.f.openBodies--
+	return nil
+}
@@ -0,0 +1,122 @@
+package objstoretest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+)
+
+func readBody(t *testing.T, rc io.ReadCloser) string {
+	t.Helper()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	return string(data)
+}
+
+func TestFake_GetRange(t *testing.T) {
+	ctx := context.Background()
+	f := New(Config{}).Put("obj", []byte("hello world"))
+
+	size, err := f.Size(ctx, "obj")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+
+	rc, err := f.GetRange(ctx, "obj", 6, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "world", readBody(t, rc))
+
+	rc, err = f.GetRange(ctx, "obj", 6, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "world", readBody(t, rc), "без StrictRanges хвост усекается, как в S3")
+
+	_, err = f.GetRange(ctx, "obj", 11, 1)
+	assert.ErrorIs(t, err, objstore.ErrInvalidRange)
+	_, err = f.GetRange(ctx, "missing", 0, 1)
+	assert.ErrorIs(t, err, objstore.ErrNotFound)
+	assert.Zero(t, f.OpenBodies())
+}
+
+func TestFake_StrictRanges(t *testing.T) {
+	f := New(Config{StrictRanges: true}).Put("obj", []byte("abc"))
+	_, err := f.GetRange(context.Background(), "obj", 1, 3)
+	assert.ErrorIs(t, err, objstore.ErrInvalidRange)
+
+	rc, err := f.GetRange(context.Background(), "obj", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bc", readBody(t, rc))
+}
+
+func TestFake_TracksOpenBodiesAndRequests(t *testing.T) {
+	ctx := context.Background()
+	f := New(Config{}).Put("obj", []byte("abc"))
+	rc, err := f.GetRange(ctx, "obj", 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.OpenBodies())
+	require.NoError(t, rc.Close())
+	require.NoError(t, rc.Close())
+	assert.Zero(t, f.OpenBodies(), "повторный Close не должен уводить счётчик в минус")
+
+	assert.Equal(t, []Request{{Op: "get", Key: "obj", Offset: 0, Length: 2}}, f.Requests())
+}
+
+func TestFake_FailureInjection(t *testing.T) {
+	ctx := context.Background()
+	f := New(Config{}).Put("obj", []byte("abc")).FailNext(ErrInjected, nil)
+	_, err := f.Size(ctx, "obj")
+	assert.ErrorIs(t, err, ErrInjected)
+	_, err = f.Size(ctx, "obj")
+	assert.NoError(t, err)
+
+	f = New(Config{FailureRate: 0.3, Seed: 42}).Put("obj", []byte("abc"))
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		if _, err = f.Size(ctx, "obj"); err != nil {
+			require.ErrorIs(t, err, ErrInjected)
+			failures++
+		}
+	}
+	assert.InDelta(t, 300, failures, 60)
+}
+
+func TestFake_LatencyHonoursContext(t *testing.T) {
+	f := New(Config{Latency: time.Hour}).Put("obj", []byte("abc"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := f.GetRange(ctx, "obj", 0, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestFake_Chunks(t *testing.T) {
+	ctx := context.Background()
+	digest := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	f := New(Config{Digest: digest})
+	d := digest([]byte("chunk"))
+
+	ok, err := f.HasChunk(ctx, d)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, f.PutChunk(ctx, d, []byte("chunk")))
+	require.NoError(t, f.PutChunk(ctx, d, []byte("chunk")), "повторная запись того же чанка")
+	assert.Error(t, f.PutChunk(ctx, d, []byte("other")), "дайджест не совпадает с данными")
+
+	data, err := f.GetChunk(ctx, d)
+	require.NoError(t, err)
+	assert.Equal(t, "chunk", string(data))
+	_, err = f.GetChunk(ctx, "missing")
+	assert.ErrorIs(t, err, objstore.ErrNotFound)
+}
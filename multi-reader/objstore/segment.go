@@ -0,0 +1,104 @@
+package objstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Segment — сегмент MultiReader поверх объекта в ObjectGetter. Размер запрашивается один раз при создании,
+// данные читаются одним открытым диапазонным запросом от текущей позиции до конца объекта;
+// Seek закрывает его, и следующий Read открывает новый с новой позиции.
+type Segment struct {
+	ctx     context.Context
+	getter  ObjectGetter
+	key     string
+	size    int64
+	pos     int64
+	body    io.ReadCloser // открытый диапазонный запрос; nil — не открыт
+	bodyPos int64         // позиция, с которой продолжит читать body
+}
+
+// NewSegment создаёт сегмент объекта key. Все запросы выполняются с контекстом ctx.
+func NewSegment(ctx context.Context, getter ObjectGetter, key string) (*Segment, error) {
+	size, err := getter.Size(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("size of %q: %w", key, err)
+	}
+	return &Segment{ctx: ctx, getter: getter, key: key, size: size}, nil
+}
+
+func (s *Segment) Read(p []byte) (int, error) {
+	if s.pos >= s.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if s.body != nil && s.bodyPos != s.pos { // После Seek открытый запрос указывает не туда
+		s.closeBody()
+	}
+	if s.body == nil {
+		body, err := s.getter.GetRange(s.ctx, s.key, s.pos, s.size-s.pos)
+		if err != nil {
+			return 0, fmt.Errorf("get %q at %d: %w", s.key, s.pos, err)
+		}
+		s.body, s.bodyPos = body, s.pos
+	}
+
+	n, err := s.body.Read(p[:min(int64(len(p)), s.size-s.pos)])
+	s.pos += int64(n)
+	s.bodyPos = s.pos
+	switch {
+	case err == io.EOF && s.pos < s.size: // Ответ оборвался раньше объекта: следующий Read переоткроет запрос
+		s.closeBody()
+		if n == 0 {
+			return 0, fmt.Errorf("get %q at %d: %w", s.key, s.pos, io.ErrUnexpectedEOF)
+		}
+		return n, nil
+	case err == io.EOF:
+		s.closeBody()
+		return n, io.EOF
+	case err != nil:
+		s.closeBody()
+		return n, fmt.Errorf("read %q at %d: %w", s.key, s.pos, err)
+	}
+	return n, nil
+}
+
+func (s *Segment) Seek(offset int64, whence int) (int64, error) {
+	pos := offset
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		pos += s.pos
+	case io.SeekEnd:
+		pos += s.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("negative position: %d", pos)
+	}
+	s.pos = pos
+	return pos, nil
+}
+
+// Close закрывает открытый диапазонный запрос.
+func (s *Segment) Close() error {
+	if s.body == nil {
+		return nil
+	}
+	err := s.body.Close()
+	s.body = nil
+	return err
+}
+
+func (s *Segment) Size() int64 {
+	return s.size
+}
+
+// closeBody закрывает открытый запрос, игнорируя ошибку: данные из него больше не нужны.
+func (s *Segment) closeBody() {
+	_ = s.Close()
+}
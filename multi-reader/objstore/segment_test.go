@@ -0,0 +1,81 @@
+package objstore_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore"
+	"github.com/zlatoivan/go-advanced/multi-reader/objstore/objstoretest"
+	"github.com/zlatoivan/go-advanced/multi-reader/readertest"
+)
+
+func TestSegment_Conformance(t *testing.T) {
+	readertest.RunReaderConformance(t, func(t *testing.T, content []byte) readertest.SizedReadSeekCloser {
+		f := objstoretest.New(objstoretest.Config{StrictRanges: true}).Put("obj", content)
+		seg, err := objstore.NewSegment(context.Background(), f, "obj")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = seg.Close()
+			assert.Zero(t, f.OpenBodies(), "сегмент оставил незакрытый ответ")
+			for _, req := range f.Requests() {
+				assert.NotErrorIs(t, req.Err, objstore.ErrInvalidRange, "сегмент запросил диапазон за пределами объекта")
+			}
+		})
+		return seg
+	})
+}
+
+func TestSegment_SequentialReadUsesSingleRequest(t *testing.T) {
+	f := objstoretest.New(objstoretest.Config{}).Put("obj", []byte("0123456789"))
+	seg, err := objstore.NewSegment(context.Background(), f, "obj")
+	require.NoError(t, err)
+	defer seg.Close()
+
+	buf := make([]byte, 3)
+	for i := 0; i < 3; i++ {
+		_, err = io.ReadFull(seg, buf)
+		require.NoError(t, err)
+	}
+	_, err = seg.Seek(1, io.SeekStart)
+	require.NoError(t, err)
+	_, err = io.ReadFull(seg, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "123", string(buf))
+
+	var gets []objstoretest.Request
+	for _, req := range f.Requests() {
+		if req.Op == "get" {
+			gets = append(gets, req)
+		}
+	}
+	assert.Equal(t, []objstoretest.Request{
+		{Op: "get", Key: "obj", Offset: 0, Length: 10},
+		{Op: "get", Key: "obj", Offset: 1, Length: 9},
+	}, gets, "последовательное чтение — один запрос, Seek — новый")
+	assert.Equal(t, 1, f.OpenBodies())
+}
+
+func TestSegment_FailedRequestIsRetriedOnNextRead(t *testing.T) {
+	f := objstoretest.New(objstoretest.Config{}).Put("obj", []byte("abc"))
+	seg, err := objstore.NewSegment(context.Background(), f, "obj")
+	require.NoError(t, err)
+	defer seg.Close()
+
+	f.FailNext(objstoretest.ErrInjected)
+	_, err = seg.Read(make([]byte, 3))
+	require.ErrorIs(t, err, objstoretest.ErrInjected)
+
+	data, err := io.ReadAll(seg)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+}
+
+func TestNewSegment_MissingObject(t *testing.T) {
+	_, err := objstore.NewSegment(context.Background(), objstoretest.New(objstoretest.Config{}), "missing")
+	assert.True(t, errors.Is(err, objstore.ErrNotFound), "получено %v", err)
+}
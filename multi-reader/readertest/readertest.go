@@ -0,0 +1,302 @@
+// Package readertest содержит набор проверок соответствия для сегментов MultiReader.
+//
+// Любой адаптер (HTTP Range, S3, mmap и т.п.) можно прогнать через RunReaderConformance и убедиться,
+// что он соблюдает предположения MultiReader о Read/Seek/Size/Close: короткие чтения, EOF на границе,
+// абсолютные позиции Seek и неизменный Size. Интерфейсы объявлены структурно,
+// поэтому пакет не зависит от пакетов с MultiReader.
+package readertest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// SizedReadCloser — сегмент без поддержки Seek.
+type SizedReadCloser interface {
+	io.ReadCloser
+	Size() int64
+}
+
+// SizedReadSeekCloser — сегмент с поддержкой Seek.
+type SizedReadSeekCloser interface {
+	io.ReadSeekCloser
+	Size() int64
+}
+
+// ReadCloserFactory создаёт новый экземпляр проверяемого сегмента с содержимым content.
+type ReadCloserFactory func(t *testing.T, content []byte) SizedReadCloser
+
+// ReaderFactory создаёт новый экземпляр проверяемого сегмента с поддержкой Seek и содержимым content.
+type ReaderFactory func(t *testing.T, content []byte) SizedReadSeekCloser
+
+// conformanceContents — содержимое сегментов, на которых выполняются проверки.
+func conformanceContents() map[string][]byte {
+	rnd := rand.New(rand.NewSource(1))
+	random := make([]byte, 10_000)
+	rnd.Read(random)
+	return map[string][]byte{
+		"empty":  {},
+		"byte":   {'x'},
+		"short":  []byte("hello, world"),
+		"random": random,
+	}
+}
+
+// RunReadCloserConformance проверяет семантику Read/Size/Close сегмента без Seek.
+func RunReadCloserConformance(t *testing.T, factory ReadCloserFactory) {
+	t.Helper()
+	for name, content := range conformanceContents() {
+		t.Run(name, func(t *testing.T) {
+			runReadChecks(t, content, func(t *testing.T) SizedReadCloser { return factory(t, content) })
+		})
+	}
+}
+
+// RunReaderConformance проверяет семантику Read/Seek/Size/Close сегмента.
+// Каждая проверка получает новый экземпляр из factory.
+func RunReaderConformance(t *testing.T, factory ReaderFactory) {
+	t.Helper()
+	for name, content := range conformanceContents() {
+		t.Run(name, func(t *testing.T) {
+			newReader := func(t *testing.T) SizedReadSeekCloser { return factory(t, content) }
+			runReadChecks(t, content, func(t *testing.T) SizedReadCloser { return newReader(t) })
+			runSeekChecks(t, content, newReader)
+		})
+	}
+}
+
+// runReadChecks — проверки, общие для сегментов с Seek и без.
+func runReadChecks(t *testing.T, content []byte, newReader func(t *testing.T) SizedReadCloser) {
+	t.Run("Size", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		for i := 0; i < 2; i++ {
+			if got := r.Size(); got != int64(len(content)) {
+				t.Fatalf("Size() = %d, want %d", got, len(content))
+			}
+		}
+		_, _ = r.Read(make([]byte, 3))
+		if got := r.Size(); got != int64(len(content)) {
+			t.Fatalf("Size() after Read = %d, want %d: size must not depend on position", got, len(content))
+		}
+	})
+
+	for _, bufSize := range []int{1, 7, 4096, len(content) + 1} {
+		t.Run(fmt.Sprintf("SequentialRead/buf=%d", bufSize), func(t *testing.T) {
+			r := newReader(t)
+			defer closeReader(t, r)
+			got, err := readAll(r, bufSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = compare(got, content); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	t.Run("ZeroLengthRead", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		n, err := r.Read(nil)
+		if n != 0 {
+			t.Fatalf("Read(nil) = %d bytes, want 0", n)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.Fatalf("Read(nil) error: %v", err)
+		}
+		got, err := readAll(r, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = compare(got, content); err != nil {
+			t.Fatalf("after zero-length read: %v", err)
+		}
+	})
+
+	t.Run("EOFIsSticky", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		if _, err := readAll(r, 512); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			n, err := r.Read(make([]byte, 8))
+			if n != 0 || !errors.Is(err, io.EOF) {
+				t.Fatalf("Read after EOF #%d = (%d, %v), want (0, EOF)", i, n, err)
+			}
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		r := newReader(t)
+		if _, err := r.Read(make([]byte, 4)); err != nil && !errors.Is(err, io.EOF) {
+			t.Fatal(err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Close() = %v", err)
+		}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Fatalf("Read after Close panicked: %v", p)
+				}
+			}()
+			if n, err := r.Read(make([]byte, 4)); err == nil && n > 0 {
+				t.Logf("Read after Close returned %d bytes without error; MultiReader never relies on it", n)
+			}
+		}()
+	})
+}
+
+// runSeekChecks — проверки Seek.
+func runSeekChecks(t *testing.T, content []byte, newReader func(t *testing.T) SizedReadSeekCloser) {
+	size := int64(len(content))
+
+	t.Run("SeekWhence", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		mid := size / 2
+		cases := []struct {
+			offset int64
+			whence int
+			want   int64
+		}{
+			{offset: mid, whence: io.SeekStart, want: mid},
+			{offset: 0, whence: io.SeekCurrent, want: mid},
+			{offset: -mid, whence: io.SeekCurrent, want: 0},
+			{offset: 0, whence: io.SeekEnd, want: size},
+			{offset: -size, whence: io.SeekEnd, want: 0},
+			{offset: size, whence: io.SeekStart, want: size},
+		}
+		for _, c := range cases {
+			pos, err := r.Seek(c.offset, c.whence)
+			if err != nil || pos != c.want {
+				t.Fatalf("Seek(%d, %d) = (%d, %v), want (%d, nil)", c.offset, c.whence, pos, err, c.want)
+			}
+		}
+	})
+
+	t.Run("SeekToEndThenRead", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		if _, err := r.Seek(0, io.SeekEnd); err != nil {
+			t.Fatal(err)
+		}
+		n, err := r.Read(make([]byte, 8))
+		if n != 0 || !errors.Is(err, io.EOF) {
+			t.Fatalf("Read at end = (%d, %v), want (0, EOF)", n, err)
+		}
+	})
+
+	t.Run("SeekAfterEOF", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		if _, err := readAll(r, 512); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readAll(r, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = compare(got, content); err != nil {
+			t.Fatalf("re-read after EOF and Seek(0): %v", err)
+		}
+	})
+
+	t.Run("NegativeSeekFails", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		if _, err := r.Seek(-1, io.SeekStart); err == nil {
+			t.Fatal("Seek(-1, SeekStart) succeeded, want error")
+		}
+		if _, err := r.Seek(-size-1, io.SeekEnd); err == nil {
+			t.Fatal("Seek before start via SeekEnd succeeded, want error")
+		}
+	})
+
+	t.Run("InvalidWhenceFails", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		if _, err := r.Seek(0, 42); err == nil {
+			t.Fatal("Seek with invalid whence succeeded, want error")
+		}
+	})
+
+	t.Run("RandomSeekRead", func(t *testing.T) {
+		r := newReader(t)
+		defer closeReader(t, r)
+		rnd := rand.New(rand.NewSource(size + 1))
+		for i := 0; i < 100; i++ {
+			off := rnd.Int63n(size + 1)
+			want := content[off:min(size, off+rnd.Int63n(300))]
+			pos, err := r.Seek(off, io.SeekStart)
+			if err != nil || pos != off {
+				t.Fatalf("Seek(%d) = (%d, %v)", off, pos, err)
+			}
+			got := make([]byte, len(want))
+			if _, err = io.ReadFull(r, got); err != nil {
+				t.Fatalf("ReadFull at %d, %d bytes: %v", off, len(want), err)
+			}
+			if err = compare(got, want); err != nil {
+				t.Fatalf("read at %d: %v", off, err)
+			}
+			pos, err = r.Seek(0, io.SeekCurrent)
+			if err != nil || pos != off+int64(len(want)) {
+				t.Fatalf("position after read = (%d, %v), want %d", pos, err, off+int64(len(want)))
+			}
+		}
+	})
+}
+
+// readAll читает r до io.EOF буфером размера bufSize, проверяя контракт io.Reader для каждого вызова.
+func readAll(r io.Reader, bufSize int) ([]byte, error) {
+	var out []byte
+	buf := make([]byte, max(bufSize, 1))
+	for zeroReads := 0; zeroReads < 100; {
+		n, err := r.Read(buf)
+		if n < 0 || n > len(buf) {
+			return out, fmt.Errorf("Read returned n=%d for buffer of %d bytes", n, len(buf))
+		}
+		out = append(out, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, fmt.Errorf("read at %d: %w", len(out), err)
+		}
+		if n == 0 {
+			zeroReads++
+		} else {
+			zeroReads = 0
+		}
+	}
+	return out, fmt.Errorf("read at %d: %w", len(out), io.ErrNoProgress)
+}
+
+// compare описывает первое расхождение got и want.
+func compare(got, want []byte) error {
+	if bytes.Equal(got, want) {
+		return nil
+	}
+	i := 0
+	for i < len(got) && i < len(want) && got[i] == want[i] {
+		i++
+	}
+	return fmt.Errorf("content mismatch at offset %d (got %d bytes, want %d)", i, len(got), len(want))
+}
+
+// closeReader закрывает сегмент в конце проверки.
+func closeReader(t *testing.T, r io.Closer) {
+	t.Helper()
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+}
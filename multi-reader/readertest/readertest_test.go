@@ -0,0 +1,49 @@
+package readertest
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// bytesSegment — эталонный сегмент поверх bytes.Reader.
+type bytesSegment struct {
+	*bytes.Reader
+	size int64
+}
+
+func (s *bytesSegment) Close() error { return nil }
+func (s *bytesSegment) Size() int64  { return s.size }
+
+func newBytesSegment(_ *testing.T, content []byte) SizedReadSeekCloser {
+	return &bytesSegment{Reader: bytes.NewReader(content), size: int64(len(content))}
+}
+
+func TestRunReaderConformance_BytesReader(t *testing.T) {
+	RunReaderConformance(t, newBytesSegment)
+}
+
+func TestRunReadCloserConformance_BytesReader(t *testing.T) {
+	RunReadCloserConformance(t, func(t *testing.T, content []byte) SizedReadCloser {
+		return newBytesSegment(t, content)
+	})
+}
+
+func TestReadAll_DetectsContractViolations(t *testing.T) {
+	if _, err := readAll(stuckReader{}, 8); err == nil {
+		t.Error("ожидалась ошибка для ридера без прогресса")
+	}
+	if _, err := readAll(overflowReader{}, 8); err == nil {
+		t.Error("ожидалась ошибка для n > len(p)")
+	}
+}
+
+// stuckReader всегда возвращает (0, nil).
+type stuckReader struct{}
+
+func (stuckReader) Read([]byte) (int, error) { return 0, nil }
+
+// overflowReader возвращает больше байт, чем вмещает буфер.
+type overflowReader struct{}
+
+func (overflowReader) Read(p []byte) (int, error) { return len(p) + 1, io.EOF }
@@ -0,0 +1,96 @@
+// Package retry — общая политика повторов: экспоненциальная пауза со случайным разбросом (jitter),
+// лимит попыток, классификатор ошибок и прерывание ожидания по контексту.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy описывает повторы одной операции. Нулевое значение — одна попытка без повторов.
+type Policy struct {
+	MaxAttempts int                  // максимум попыток, включая первую; <= 0 — одна попытка
+	Backoff     time.Duration        // пауза перед первым повтором, далее удваивается
+	MaxBackoff  time.Duration        // верхняя граница паузы; 0 — без ограничения
+	Jitter      float64              // доля паузы [0, 1], на которую она случайно уменьшается; 0 — без разброса
+	Retryable   func(err error) bool // классификатор ошибок; nil — любая ошибка считается повторяемой
+
+	// Sleep ждёт паузу и возвращает ошибку, если ожидание прервано; nil — таймер с отменой по ctx.
+	Sleep func(ctx context.Context, d time.Duration) error
+	// Rand возвращает случайное число из [0, 1) для Jitter; nil — math/rand/v2.
+	Rand func() float64
+	// OnRetry вызывается в Do перед паузой перед повтором: номер неудачной попытки, пауза и её ошибка; nil — не нужен.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// Delay возвращает паузу перед повтором номер n (с единицы): Backoff << (n-1), не больше MaxBackoff,
+// уменьшенную на случайную долю до Jitter. При переполнении пауза насыщается на math.MaxInt64.
+func (p Policy) Delay(n int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < n && delay > 0; i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 && delay > 0 {
+		rnd := p.Rand
+		if rnd == nil {
+			rnd = rand.Float64
+		}
+		delay -= time.Duration(float64(delay) * min(p.Jitter, 1) * rnd())
+	}
+	return delay
+}
+
+// IsRetryable применяет классификатор политики к err.
+func (p Policy) IsRetryable(err error) bool {
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// Wait выдерживает паузу d. Возвращает ctx.Err(), если контекст отменён раньше.
+func (p Policy) Wait(ctx context.Context, d time.Duration) error {
+	if p.Sleep != nil {
+		return p.Sleep(ctx, d)
+	}
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Do выполняет op, повторяя повторяемые ошибки до MaxAttempts попыток с паузами Delay.
+// Неповторяемая ошибка возвращается как есть; после исчерпания попыток — обёрнутой с их числом.
+// Если ctx отменён во время паузы, возвращается последняя ошибка op вместе с ошибкой контекста.
+func (p Policy) Do(ctx context.Context, op func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !p.IsRetryable(err) {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+		delay := p.Delay(attempt)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, delay, err)
+		}
+		if waitErr := p.Wait(ctx, delay); waitErr != nil {
+			return fmt.Errorf("retry interrupted after %d attempts: %w: %w", attempt, waitErr, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recordSleeps возвращает Sleep, запоминающий паузы без ожидания.
+func recordSleeps(sleeps *[]time.Duration) func(context.Context, time.Duration) error {
+	return func(_ context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	var got []time.Duration
+	for n := 1; n <= 4; n++ {
+		got = append(got, p.Delay(n))
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond}
+	if !slices.Equal(got, want) {
+		t.Fatalf("паузы %v, ожидались %v", got, want)
+	}
+	if d := p.Delay(100); d != p.MaxBackoff {
+		t.Fatalf("пауза при переполнении сдвига: %v, ожидалась MaxBackoff", d)
+	}
+	unbounded := Policy{Backoff: 10 * time.Millisecond}
+	for _, n := range []int{50, 64, 100, 1000} {
+		if d := unbounded.Delay(n); d != math.MaxInt64 {
+			t.Fatalf("пауза повтора %d без MaxBackoff: %v, ожидалось насыщение на math.MaxInt64", n, d)
+		}
+	}
+
+	p.Jitter = 0.5
+	p.Rand = func() float64 { return 0.5 }
+	if d := p.Delay(1); d != 7500*time.Microsecond {
+		t.Fatalf("пауза с jitter: %v, ожидалось 7.5ms", d)
+	}
+}
+
+func TestPolicy_DoRetriesUntilSuccess(t *testing.T) {
+	var sleeps []time.Duration
+	p := Policy{MaxAttempts: 5, Backoff: time.Millisecond, Sleep: recordSleeps(&sleeps)}
+	calls := 0
+	err := p.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Do: %v, вызовов %d", err, calls)
+	}
+	if want := []time.Duration{time.Millisecond, 2 * time.Millisecond}; !slices.Equal(sleeps, want) {
+		t.Fatalf("паузы %v, ожидались %v", sleeps, want)
+	}
+}
+
+func TestPolicy_DoStopsOnFatalAndExhaustion(t *testing.T) {
+	errFatal := errors.New("fatal")
+	errTransient := errors.New("transient")
+	var sleeps []time.Duration
+	p := Policy{
+		MaxAttempts: 3,
+		Retryable:   func(err error) bool { return !errors.Is(err, errFatal) },
+		Sleep:       recordSleeps(&sleeps),
+	}
+
+	if err := p.Do(context.Background(), func() error { return errFatal }); err != errFatal || len(sleeps) != 0 {
+		t.Fatalf("неповторяемая ошибка: %v, пауз %d", err, len(sleeps))
+	}
+
+	calls := 0
+	err := p.Do(context.Background(), func() error { calls++; return errTransient })
+	if !errors.Is(err, errTransient) || calls != 3 || len(sleeps) != 2 {
+		t.Fatalf("исчерпание попыток: %v, вызовов %d, пауз %d", err, calls, len(sleeps))
+	}
+
+	calls = 0
+	if err := (Policy{}).Do(context.Background(), func() error { calls++; return errTransient }); calls != 1 || err == nil {
+		t.Fatalf("нулевая политика: %v, вызовов %d", err, calls)
+	}
+}
+
+func TestPolicy_DoInterruptedByContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errTransient := errors.New("transient")
+	p := Policy{MaxAttempts: 10, Backoff: time.Hour}
+
+	calls := 0
+	start := time.Now()
+	err := p.Do(ctx, func() error {
+		calls++
+		cancel()
+		return errTransient
+	})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errTransient) || calls != 1 {
+		t.Fatalf("Do: %v, вызовов %d", err, calls)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("ожидание паузы не прервано отменой контекста")
+	}
+}
+
+func TestPolicy_DoReportsRetries(t *testing.T) {
+	type retryCall struct {
+		attempt int
+		delay   time.Duration
+	}
+	var calls []retryCall
+	errTransient := errors.New("transient")
+	p := Policy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		Sleep:       recordSleeps(new([]time.Duration)),
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			if err != errTransient {
+				t.Errorf("OnRetry с ошибкой %v", err)
+			}
+			calls = append(calls, retryCall{attempt, delay})
+		},
+	}
+	_ = p.Do(context.Background(), func() error { return errTransient })
+	if want := []retryCall{{1, time.Millisecond}, {2, 2 * time.Millisecond}}; !slices.Equal(calls, want) {
+		t.Fatalf("OnRetry %v, ожидалось %v: перед каждой паузой, но не после последней попытки", calls, want)
+	}
+}
@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"runtime"
+	"testing"
+)
+
+// allocRuns — число прогонов fn в AllocBudget: среднее по нескольким сотням вызовов сглаживает разовые
+// аллокации прогрева (запуск горутин, первое наполнение пулов).
+const allocRuns = 500
+
+// AllocBudget проверяет, что fn в среднем выполняет не больше budget аллокаций за вызов (testing.AllocsPerRun).
+// Под детектором гонок проверка пропускается: его инструментирование само выделяет память.
+// Аллокации считаются по всему процессу, включая фоновые горутины тестируемого кода.
+func AllocBudget(t testing.TB, budget float64, fn func()) {
+	t.Helper()
+	if RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	if got := testing.AllocsPerRun(allocRuns, fn); got > budget {
+		t.Errorf("аллокаций за вызов: %v, бюджет: %v", got, budget)
+	}
+}
+
+// BenchAllocBudget начинает отсчёт аллокаций бенчмарка и возвращает функцию проверки, которую нужно вызвать
+// после цикла по b.N (обычно через defer). Она валит бенчмарк, если аллокаций на итерацию больше budget.
+// В отличие от b.ReportAllocs, превышение бюджета видно как ошибка, а не только как число в отчёте.
+func BenchAllocBudget(b *testing.B, budget float64) (check func()) {
+	b.Helper()
+	b.ReportAllocs()
+	if RaceEnabled {
+		return func() {}
+	}
+	var before runtime.MemStats
+	check = func() {
+		b.Helper()
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		if got := float64(after.Mallocs-before.Mallocs) / float64(b.N); got > budget {
+			b.Errorf("аллокаций на итерацию: %.2f, бюджет: %v", got, budget)
+		}
+	}
+	runtime.ReadMemStats(&before) // Замер после создания check, чтобы не учитывать её собственную аллокацию
+	return check
+}
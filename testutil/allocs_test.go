@@ -0,0 +1,28 @@
+package testutil
+
+import "testing"
+
+var allocSink []byte
+
+func TestAllocBudget(t *testing.T) {
+	if RaceEnabled {
+		t.Skip("бюджет аллокаций не проверяется под -race")
+	}
+	rec := &recordingTB{TB: t}
+	AllocBudget(rec, 0, func() {})
+	if len(rec.errs) != 0 {
+		t.Fatalf("ложное срабатывание: %v", rec.errs)
+	}
+
+	AllocBudget(rec, 0, func() { allocSink = make([]byte, 64) })
+	if len(rec.errs) != 1 {
+		t.Fatalf("превышение бюджета не обнаружено: %v", rec.errs)
+	}
+}
+
+func BenchmarkBenchAllocBudget(b *testing.B) {
+	defer BenchAllocBudget(b, 0)()
+	for range b.N {
+		allocSink = allocSink[:0]
+	}
+}
@@ -0,0 +1,101 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Операции, записываемые в трассу вызовов.
+const (
+	OpRead  = "Read"
+	OpSeek  = "Seek"
+	OpClose = "Close"
+	OpSize  = "Size"
+)
+
+// TraceCall — один вызов метода источника.
+type TraceCall struct {
+	Reader string // имя источника, заданное в StringsReader.TraceTo
+	Op     string
+	Offset int64 // смещение Seek
+	Whence int   // whence Seek
+	Len    int   // длина буфера Read
+	N      int64 // результат: прочитано байт (Read) или новая позиция (Seek)
+	Err    error
+	At     time.Time
+}
+
+func (c TraceCall) String() string {
+	switch c.Op {
+	case OpRead:
+		return fmt.Sprintf("%s.Read(len=%d) = %d, %v", c.Reader, c.Len, c.N, c.Err)
+	case OpSeek:
+		return fmt.Sprintf("%s.Seek(%d, %d) = %d, %v", c.Reader, c.Offset, c.Whence, c.N, c.Err)
+	default:
+		return fmt.Sprintf("%s.%s()", c.Reader, c.Op)
+	}
+}
+
+// CallTrace — потокобезопасная упорядоченная запись вызовов одного или нескольких источников.
+// Заменяет разрозненные счётчики вызовов и позволяет проверять порядок и отсутствие вызовов на участке теста.
+type CallTrace struct {
+	mu    sync.Mutex
+	calls []TraceCall
+}
+
+// Record добавляет вызов в трассу.
+func (t *CallTrace) Record(c TraceCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c.At = time.Now()
+	t.calls = append(t.calls, c)
+}
+
+// Mark возвращает текущую длину трассы — отметку для Since.
+func (t *CallTrace) Mark() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.calls)
+}
+
+// Since возвращает копию вызовов после отметки mark.
+func (t *CallTrace) Since(mark int) []TraceCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceCall(nil), t.calls[mark:]...)
+}
+
+// Count возвращает число вызовов op источника reader ("" — любого источника) после отметки mark.
+func (t *CallTrace) Count(mark int, reader, op string) int {
+	cnt := 0
+	for _, c := range t.Since(mark) {
+		if c.Op == op && (reader == "" || c.Reader == reader) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// ExpectNoCalls проверяет, что после отметки mark не было вызовов op источника reader ("" — любого).
+func (t *CallTrace) ExpectNoCalls(what string, mark int, reader, op string) error {
+	var found []string
+	for _, c := range t.Since(mark) {
+		if c.Op == op && (reader == "" || c.Reader == reader) {
+			found = append(found, c.String())
+		}
+	}
+	if len(found) > 0 {
+		return fmt.Errorf("%s: неожиданные вызовы %s:\n\t\t%s", what, op, strings.Join(found, "\n\t\t"))
+	}
+	return nil
+}
+
+// ExpectCalls проверяет, что после отметки mark был хотя бы один вызов op источника reader ("" — любого).
+func (t *CallTrace) ExpectCalls(what string, mark int, reader, op string) error {
+	if t.Count(mark, reader, op) == 0 {
+		return fmt.Errorf("%s: ожидался вызов %s, трасса: %v", what, op, t.Since(mark))
+	}
+	return nil
+}
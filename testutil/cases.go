@@ -0,0 +1,162 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+)
+
+// DefaultCaseTimeout — таймаут одного тест кейса по умолчанию.
+const DefaultCaseTimeout = time.Second * 10
+
+// TestCase — именованный тест кейс: Run возвращает причину провала или nil.
+type TestCase struct {
+	Name string
+	Run  func() error
+}
+
+// CaseStatus — итог выполнения одного тест кейса.
+type CaseStatus int
+
+const (
+	CasePassed CaseStatus = iota
+	CaseFailed
+	CaseTimeout
+	CasePanic
+)
+
+func (s CaseStatus) String() string {
+	switch s {
+	case CasePassed:
+		return "успех"
+	case CaseFailed:
+		return "провал"
+	case CaseTimeout:
+		return "таймаут"
+	case CasePanic:
+		return "паника"
+	default:
+		return fmt.Sprintf("CaseStatus(%d)", int(s))
+	}
+}
+
+// CaseResult — результат выполнения тест кейса.
+type CaseResult struct {
+	Name     string
+	Status   CaseStatus
+	Err      error  // причина провала при CaseFailed
+	Panic    any    // значение recover() при CasePanic
+	Stack    []byte // стек паники
+	Duration time.Duration
+}
+
+// RunConfig — настройки запуска тест кейсов.
+type RunConfig struct {
+	Timeout  time.Duration // таймаут одного кейса; 0 — DefaultCaseTimeout
+	Parallel int           // число одновременно выполняемых кейсов; <= 1 — последовательно
+}
+
+// RunCase выполняет один кейс в отдельной горутине, перехватывая панику и ограничивая время выполнения.
+// Зависший кейс (например, заблокированная горутина префетча) получает CaseTimeout и остаётся висеть в фоне,
+// не блокируя остальные кейсы.
+func RunCase(tc TestCase, timeout time.Duration) CaseResult {
+	if timeout <= 0 {
+		timeout = DefaultCaseTimeout
+	}
+
+	start := time.Now()
+	resCh := make(chan CaseResult, 1)
+	go func() {
+		res := CaseResult{Name: tc.Name, Status: CaseFailed}
+		defer func() {
+			if r := recover(); r != nil {
+				res.Status = CasePanic
+				res.Panic = r
+				res.Stack = debug.Stack()
+			}
+			resCh <- res
+		}()
+		res.Err = tc.Run()
+		if res.Err == nil {
+			res.Status = CasePassed
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var res CaseResult
+	select {
+	case res = <-resCh:
+	case <-timer.C:
+		res = CaseResult{Name: tc.Name, Status: CaseTimeout}
+	}
+	res.Duration = time.Since(start)
+	return res
+}
+
+// RunTestCases выполняет кейсы согласно cfg и возвращает результаты в исходном порядке.
+func RunTestCases(cases []TestCase, cfg RunConfig) []CaseResult {
+	parallel := max(cfg.Parallel, 1)
+	results := make([]CaseResult, len(cases))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, tc := range cases {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = RunCase(tc, cfg.Timeout)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// RunTestCasesT запускает каждый кейс как подтест t.Run. При cfg.Parallel > 1 подтесты выполняются параллельно
+// (степень параллелизма ограничивает флаг go test -parallel).
+func RunTestCasesT(t *testing.T, cases []TestCase, cfg RunConfig) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if cfg.Parallel > 1 {
+				t.Parallel()
+			}
+			res := RunCase(tc, cfg.Timeout)
+			switch res.Status {
+			case CasePassed:
+			case CasePanic:
+				t.Fatalf("паника: %v\n%s", res.Panic, res.Stack)
+			case CaseFailed:
+				t.Fatal(res.Err)
+			default:
+				t.Fatalf("%s за %v", res.Status, res.Duration)
+			}
+		})
+	}
+}
+
+// ReportResults печатает результаты в stderr и возвращает true, если все кейсы прошли.
+func ReportResults(results []CaseResult) bool {
+	ok := true
+	for _, res := range results {
+		switch res.Status {
+		case CasePassed:
+			_, _ = fmt.Fprintf(os.Stderr, "Тест кейс %q - успех\n", res.Name)
+		case CasePanic:
+			ok = false
+			_, _ = fmt.Fprintf(os.Stderr, "Тест кейс %q - Паника: %v\n", res.Name, res.Panic)
+		case CaseFailed:
+			ok = false
+			_, _ = fmt.Fprintf(os.Stderr, "Тест кейс %q - провал\n\t%v\n", res.Name, res.Err)
+		default:
+			ok = false
+			_, _ = fmt.Fprintf(os.Stderr, "Тест кейс %q - %s\n", res.Name, res.Status)
+		}
+	}
+	return ok
+}
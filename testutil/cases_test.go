@@ -0,0 +1,44 @@
+package testutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunCase_Statuses(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	cases := []TestCase{
+		{Name: "pass", Run: func() error { return nil }},
+		{Name: "fail", Run: func() error { return errors.New("mismatch") }},
+		{Name: "panic", Run: func() error { panic("boom") }},
+		{Name: "hang", Run: func() error { <-block; return nil }},
+	}
+	want := []CaseStatus{CasePassed, CaseFailed, CasePanic, CaseTimeout}
+
+	results := RunTestCases(cases, RunConfig{Timeout: 50 * time.Millisecond, Parallel: len(cases)})
+	for i, res := range results {
+		if res.Name != cases[i].Name || res.Status != want[i] {
+			t.Errorf("кейс %q: статус %v, ожидался %v", cases[i].Name, res.Status, want[i])
+		}
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "mismatch" {
+		t.Errorf("ожидалась причина провала %q, получено %v", "mismatch", results[1].Err)
+	}
+	if results[2].Panic != "boom" {
+		t.Errorf("ожидалось значение паники %q, получено %v", "boom", results[2].Panic)
+	}
+}
+
+func TestExpectBytes_ReportsOffset(t *testing.T) {
+	err := ExpectBytes("данные", "abcXef", "abcdef")
+	want := `данные: расхождение на смещении 3 (длина 6, ожидалась 6): получено "Xef", ожидалось "def"`
+	if err == nil || err.Error() != want {
+		t.Errorf("получено %v, ожидалось %q", err, want)
+	}
+	if err = Check(nil, ExpectEqual("n", 1, 1), ExpectBytes("b", []byte("x"), []byte("x"))); err != nil {
+		t.Errorf("неожиданная ошибка: %v", err)
+	}
+}
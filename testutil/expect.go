@@ -0,0 +1,79 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Check возвращает первую ненулевую ошибку проверки. Позволяет записать несколько проверок одним выражением.
+func Check(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpectNoError проверяет отсутствие ошибки.
+func ExpectNoError(what string, err error) error {
+	if err != nil {
+		return fmt.Errorf("%s: неожиданная ошибка: %w", what, err)
+	}
+	return nil
+}
+
+// ExpectError проверяет наличие любой ошибки.
+func ExpectError(what string, err error) error {
+	if err == nil {
+		return fmt.Errorf("%s: ожидалась ошибка, получено nil", what)
+	}
+	return nil
+}
+
+// ExpectErrorIs проверяет, что err содержит target в цепочке.
+func ExpectErrorIs(what string, err, target error) error {
+	if !errors.Is(err, target) {
+		return fmt.Errorf("%s: получена ошибка %v, ожидалась %v", what, err, target)
+	}
+	return nil
+}
+
+// ExpectEqual сравнивает значения.
+func ExpectEqual[T comparable](what string, got, want T) error {
+	if got != want {
+		return fmt.Errorf("%s: получено %v, ожидалось %v", what, got, want)
+	}
+	return nil
+}
+
+// ExpectTrue проверяет условие, описанное в what.
+func ExpectTrue(what string, cond bool) error {
+	if !cond {
+		return fmt.Errorf("%s: условие не выполнено", what)
+	}
+	return nil
+}
+
+// ExpectBytes сравнивает данные и указывает смещение первого расхождения.
+func ExpectBytes[B []byte | string](what string, got, want B) error {
+	g, w := string(got), string(want)
+	if g == w {
+		return nil
+	}
+	off := 0
+	for off < len(g) && off < len(w) && g[off] == w[off] {
+		off++
+	}
+	return fmt.Errorf("%s: расхождение на смещении %d (длина %d, ожидалась %d): получено %q, ожидалось %q",
+		what, off, len(g), len(w), excerpt(g, off), excerpt(w, off))
+}
+
+// excerpt возвращает короткий фрагмент s начиная с off для сообщений об ошибках.
+func excerpt(s string, off int) string {
+	const maxExcerpt = 16
+	if off >= len(s) {
+		return ""
+	}
+	return s[off:min(len(s), off+maxExcerpt)]
+}
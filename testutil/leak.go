@@ -0,0 +1,109 @@
+// Package testutil содержит общие помощники для тестов заданий репозитория.
+package testutil
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakWait — сколько ждать завершения горутин, прежде чем считать их утёкшими:
+// после Close и отмены контекста фоновым горутинам нужно время, чтобы выйти.
+const leakWait = 2 * time.Second
+
+// ignoredGoroutines — фрагменты стека служебных горутин рантайма и пакета testing, которые не считаются утечкой.
+var ignoredGoroutines = []string{
+	"testing.(*T).Run(",
+	"testing.(*T).Parallel(",
+	"testing.tRunner(",
+	"testing.runTests(",
+	"testing.(*M).",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+}
+
+// VerifyNoLeaks запоминает горутины, существующие в момент вызова, и по завершении теста (t.Cleanup)
+// проверяет, что все горутины, появившиеся после, завершились. Утёкшие горутины выводятся со стеком.
+// Вызывайте первой строкой теста, чтобы Cleanup выполнился после всех остальных.
+// Параллельные тесты в одном процессе видят горутины друг друга, поэтому вызывайте VerifyNoLeaks
+// в тесте верхнего уровня, а не в параллельных подтестах.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := make(map[int]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	t.Cleanup(func() {
+		var leaked []goroutine
+		deadline := time.Now().Add(leakWait)
+		for delay := time.Millisecond; ; delay = min(2*delay, 100*time.Millisecond) {
+			leaked = leaked[:0]
+			for _, g := range goroutines() {
+				if !before[g.id] && !g.ignored() {
+					leaked = append(leaked, g)
+				}
+			}
+			if len(leaked) == 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(delay)
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		var b strings.Builder
+		for _, g := range leaked {
+			b.WriteString("\n\n")
+			b.WriteString(g.stack)
+		}
+		t.Errorf("утечка горутин: %d не завершились за %v после теста:%s", len(leaked), leakWait, b.String())
+	})
+}
+
+// goroutine — одна горутина из дампа runtime.Stack.
+type goroutine struct {
+	id    int
+	stack string
+}
+
+// ignored сообщает, является ли горутина служебной.
+func (g goroutine) ignored() bool {
+	for _, s := range ignoredGoroutines {
+		if strings.Contains(g.stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines возвращает все горутины процесса, кроме текущей.
+func goroutines() []goroutine {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var res []goroutine
+	for i, block := range bytes.Split(buf, []byte("\n\n")) {
+		if i == 0 { // Первой в дампе идёт текущая горутина
+			continue
+		}
+		header, _, _ := strings.Cut(string(block), "\n")
+		idStr, _, _ := strings.Cut(strings.TrimPrefix(header, "goroutine "), " ")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			continue
+		}
+		res = append(res, goroutine{id: id, stack: string(block)})
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+package testutil
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordingTB перехватывает ошибки VerifyNoLeaks и выполняет Cleanup по запросу.
+type recordingTB struct {
+	testing.TB
+	cleanups []func()
+	errs     []string
+}
+
+func (r *recordingTB) Helper()               {}
+func (r *recordingTB) Cleanup(f func())      { r.cleanups = append(r.cleanups, f) }
+func (r *recordingTB) Errorf(string, ...any) { r.errs = append(r.errs, "leak") }
+
+func (r *recordingTB) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestVerifyNoLeaks_FinishedGoroutine(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	VerifyNoLeaks(rec)
+	done := make(chan struct{})
+	go func() { close(done) }()
+	<-done
+	rec.finish()
+	if len(rec.errs) != 0 {
+		t.Fatalf("ложное срабатывание: %v", rec.errs)
+	}
+}
+
+func TestVerifyNoLeaks_DetectsBlockedGoroutine(t *testing.T) {
+	if testing.Short() {
+		t.Skip("ожидание утечки занимает leakWait")
+	}
+	rec := &recordingTB{TB: t}
+	VerifyNoLeaks(rec)
+	block := make(chan struct{})
+	defer close(block)
+	go func() { <-block }()
+	rec.finish()
+	if len(rec.errs) != 1 {
+		t.Fatalf("утечка не обнаружена: %v", rec.errs)
+	}
+}
+
+func TestGoroutines_ExcludesCurrent(t *testing.T) {
+	for _, g := range goroutines() {
+		if strings.Contains(g.stack, "TestGoroutines_ExcludesCurrent") {
+			t.Fatalf("текущая горутина в списке:\n%s", g.stack)
+		}
+	}
+}
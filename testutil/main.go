@@ -0,0 +1,57 @@
+package testutil
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// RunMain — точка входа исполняемого файла с тест кейсами задания (make t): разбирает флаги, выполняет выбранные
+// из public и private кейсы и завершает процесс с кодом 1 при провалах и 2 при ошибках использования или вывода.
+func RunMain(public, private []TestCase) {
+	timeout := flag.Duration("case-timeout", DefaultCaseTimeout, "таймаут одного тест кейса")
+	parallel := flag.Int("parallel", 1, "число одновременно выполняемых тест кейсов")
+	set := flag.String("set", SetAll, "набор тест кейсов: all, public или private")
+	pattern := flag.String("run", "", "регулярное выражение для отбора кейсов по имени")
+	list := flag.Bool("list", false, "только вывести имена выбранных кейсов")
+	format := flag.String("format", FormatText, "формат результатов: text (stderr), json или tap (stdout)")
+	flag.Parse()
+
+	tests, err := SelectCases(public, private, *set, *pattern)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *list {
+		err = ListCases(os.Stdout, tests)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	results := RunTestCases(tests, RunConfig{Timeout: *timeout, Parallel: *parallel})
+
+	ok := true
+	for _, res := range results {
+		ok = ok && res.Status == CasePassed
+	}
+	switch *format {
+	case FormatText:
+		ReportResults(results)
+	case FormatJSON:
+		err = WriteJSON(os.Stdout, results)
+	case FormatTAP:
+		err = WriteTAP(os.Stdout, results)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
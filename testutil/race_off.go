@@ -0,0 +1,6 @@
+//go:build !race
+
+package testutil
+
+// RaceEnabled сообщает, собраны ли тесты с детектором гонок.
+const RaceEnabled = false
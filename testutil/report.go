@@ -0,0 +1,161 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Форматы вывода результатов.
+const (
+	FormatText = "text" // человекочитаемый вывод в stderr (ReportResults)
+	FormatJSON = "json" // один JSON-документ со сводкой и всеми кейсами
+	FormatTAP  = "tap"  // Test Anything Protocol, версия 13
+)
+
+// Наборы тест кейсов.
+const (
+	SetAll     = "all"
+	SetPublic  = "public"
+	SetPrivate = "private"
+)
+
+// SelectCases возвращает кейсы набора set из публичных public и приватных private кейсов,
+// имена которых соответствуют регулярному выражению pattern. Пустой pattern выбирает все кейсы набора.
+func SelectCases(public, private []TestCase, set, pattern string) ([]TestCase, error) {
+	var cases []TestCase
+	switch set {
+	case SetAll, "":
+		cases = append(append(cases, public...), private...)
+	case SetPublic:
+		cases = append(cases, public...)
+	case SetPrivate:
+		cases = append(cases, private...)
+	default:
+		return nil, fmt.Errorf("unknown case set %q", set)
+	}
+	return FilterCases(cases, pattern)
+}
+
+// FilterCases оставляет кейсы, имена которых соответствуют регулярному выражению pattern.
+func FilterCases(cases []TestCase, pattern string) ([]TestCase, error) {
+	if pattern == "" {
+		return cases, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid case filter: %w", err)
+	}
+	var out []TestCase
+	for _, tc := range cases {
+		if re.MatchString(tc.Name) {
+			out = append(out, tc)
+		}
+	}
+	return out, nil
+}
+
+// ListCases печатает имена кейсов, по одному в строке.
+func ListCases(w io.Writer, cases []TestCase) error {
+	for _, tc := range cases {
+		_, err := fmt.Fprintln(w, tc.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusCode — машиночитаемое обозначение статуса для JSON и TAP.
+func statusCode(s CaseStatus) string {
+	switch s {
+	case CasePassed:
+		return "pass"
+	case CaseFailed:
+		return "fail"
+	case CaseTimeout:
+		return "timeout"
+	case CasePanic:
+		return "panic"
+	default:
+		return "unknown"
+	}
+}
+
+// jsonCase — запись о кейсе в JSON-отчёте.
+type jsonCase struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Message    string  `json:"message,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// jsonReport — JSON-отчёт о запуске.
+type jsonReport struct {
+	Total  int        `json:"total"`
+	Passed int        `json:"passed"`
+	Failed int        `json:"failed"`
+	Cases  []jsonCase `json:"cases"`
+}
+
+// caseMessage возвращает причину неуспеха кейса.
+func caseMessage(res CaseResult) string {
+	switch res.Status {
+	case CaseFailed:
+		if res.Err != nil {
+			return res.Err.Error()
+		}
+	case CasePanic:
+		return fmt.Sprintf("panic: %v", res.Panic)
+	case CaseTimeout:
+		return fmt.Sprintf("timeout after %v", res.Duration)
+	}
+	return ""
+}
+
+// WriteJSON пишет результаты в w одним JSON-документом.
+func WriteJSON(w io.Writer, results []CaseResult) error {
+	rep := jsonReport{Total: len(results), Cases: make([]jsonCase, 0, len(results))}
+	for _, res := range results {
+		if res.Status == CasePassed {
+			rep.Passed++
+		} else {
+			rep.Failed++
+		}
+		rep.Cases = append(rep.Cases, jsonCase{
+			Name:       res.Name,
+			Status:     statusCode(res.Status),
+			Message:    caseMessage(res),
+			DurationMS: float64(res.Duration.Microseconds()) / 1000,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// WriteTAP пишет результаты в w в формате TAP 13. Причина неуспеха выводится YAML-блоком.
+func WriteTAP(w io.Writer, results []CaseResult) error {
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+	for i, res := range results {
+		// '#' начинает директиву TAP, поэтому экранируем его в описании
+		name := strings.ReplaceAll(res.Name, "#", `\#`)
+		if res.Status == CasePassed {
+			fmt.Fprintf(&b, "ok %d - %s\n", i+1, name)
+			continue
+		}
+		fmt.Fprintf(&b, "not ok %d - %s\n", i+1, name)
+		b.WriteString("  ---\n")
+		fmt.Fprintf(&b, "  status: %s\n", statusCode(res.Status))
+		msg, _ := json.Marshal(caseMessage(res)) // JSON-строка — валидный YAML-скаляр
+		fmt.Fprintf(&b, "  message: %s\n", msg)
+		fmt.Fprintf(&b, "  duration_ms: %.3f\n", float64(res.Duration.Microseconds())/1000)
+		b.WriteString("  ...\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
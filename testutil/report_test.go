@@ -0,0 +1,113 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectCases(t *testing.T) {
+	noop := func() error { return nil }
+	public := []TestCase{{Name: "a", Run: noop}, {Name: "b", Run: noop}}
+	private := []TestCase{{Name: "secret a", Run: noop}}
+
+	for _, tc := range []struct {
+		set, pattern string
+		want         []string
+	}{
+		{set: SetAll, want: []string{"a", "b", "secret a"}},
+		{set: "", want: []string{"a", "b", "secret a"}},
+		{set: SetPublic, want: []string{"a", "b"}},
+		{set: SetPrivate, want: []string{"secret a"}},
+		{set: SetAll, pattern: "^" + regexp.QuoteMeta("a") + "$", want: []string{"a"}},
+		{set: SetAll, pattern: "a$", want: []string{"a", "secret a"}},
+	} {
+		got, err := SelectCases(public, private, tc.set, tc.pattern)
+		if err != nil {
+			t.Fatalf("%s/%q: %v", tc.set, tc.pattern, err)
+		}
+		var names []string
+		for _, c := range got {
+			names = append(names, c.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s/%q: получено %v, ожидалось %v", tc.set, tc.pattern, names, tc.want)
+		}
+	}
+
+	if _, err := SelectCases(public, private, "secret", ""); err == nil {
+		t.Error("ожидалась ошибка для неизвестного набора")
+	}
+	if _, err := SelectCases(public, private, SetAll, "("); err == nil {
+		t.Error("ожидалась ошибка для некорректного регулярного выражения")
+	}
+}
+
+var reportResults = []CaseResult{
+	{Name: "ok case", Status: CasePassed, Duration: 1500 * time.Microsecond},
+	{Name: "bad # case", Status: CaseFailed, Err: errors.New("want \"a\"\ngot \"b\""), Duration: time.Millisecond},
+	{Name: "boom", Status: CasePanic, Panic: "nil map"},
+	{Name: "slow", Status: CaseTimeout, Duration: time.Second},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+	var rep jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatalf("некорректный JSON: %v\n%s", err, buf.String())
+	}
+	if rep.Total != 4 || rep.Passed != 1 || rep.Failed != 3 {
+		t.Errorf("сводка: %+v", rep)
+	}
+	wantStatus := []string{"pass", "fail", "panic", "timeout"}
+	for i, c := range rep.Cases {
+		if c.Name != reportResults[i].Name || c.Status != wantStatus[i] {
+			t.Errorf("кейс %d: %+v", i, c)
+		}
+	}
+	if rep.Cases[0].DurationMS != 1.5 || rep.Cases[0].Message != "" {
+		t.Errorf("успешный кейс: %+v", rep.Cases[0])
+	}
+	if rep.Cases[1].Message != "want \"a\"\ngot \"b\"" || rep.Cases[2].Message != "panic: nil map" {
+		t.Errorf("причины: %q, %q", rep.Cases[1].Message, rep.Cases[2].Message)
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTAP(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+	want := `TAP version 13
+1..4
+ok 1 - ok case
+not ok 2 - bad \# case
+  ---
+  status: fail
+  message: "want \"a\"\ngot \"b\""
+  duration_ms: 1.000
+  ...
+not ok 3 - boom
+  ---
+  status: panic
+  message: "panic: nil map"
+  duration_ms: 0.000
+  ...
+not ok 4 - slow
+  ---
+  status: timeout
+  message: "timeout after 1s"
+  duration_ms: 1000.000
+  ...
+`
+	if err := ExpectBytes("TAP", buf.String(), want); err != nil {
+		t.Error(err)
+	}
+}
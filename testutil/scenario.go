@@ -0,0 +1,231 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Scenario — построитель тест кейса из последовательности операций и проверок:
+//
+//	NewScenario("Seek и чтение").Segments("abc", "def").Read(4).SeekTo(2, io.SeekStart).Read(2).ExpectBytes("cd").TestCase()
+//
+// Операции (Read, ReadAll, Seek, Close) запоминают свой результат, проверки (Expect*) сравнивают с ним.
+// Ошибка операции, отличная от io.EOF, считается провалом, если следующим шагом не идёт ExpectErr.
+// В конце сценария ридер закрывается. Один и тот же сценарий собирается под любой вариант задания:
+// ридер создаёт фабрика, переданная в NewScenario.
+type Scenario struct {
+	name        string
+	newReader   ScenarioReaderFunc
+	segments    []string
+	bufferSize  int64
+	buffersNum  int
+	steps       []scenarioStep
+	prepareSegs func(segs []*StringsReader) // настройка моков перед созданием ридера; nil — без настройки
+}
+
+// scenarioStep — один шаг сценария.
+type scenarioStep struct {
+	desc   string
+	expect bool // шаг-проверка, а не операция
+	run    func(st *scenarioState) error
+}
+
+// scenarioState — состояние выполнения сценария: ридер и результат последней операции.
+type scenarioState struct {
+	r       ScenarioReader
+	segs    []*StringsReader
+	data    []byte // данные последнего Read/ReadAll
+	n       int
+	pos     int64 // результат последнего Seek
+	err     error // ошибка последней операции
+	checked bool  // ошибка последней операции проверена ExpectErr
+	closed  bool
+}
+
+// ScenarioReaderFunc собирает MultiReader варианта задания поверх моков сегментов.
+// Варианты без префетча игнорируют bufferSize и buffersNum.
+type ScenarioReaderFunc func(bufferSize int64, buffersNum int, segs []*StringsReader) ScenarioReader
+
+// NewScenario начинает сценарий с именем тест кейса name; ридер создаётся через newReader.
+func NewScenario(name string, newReader ScenarioReaderFunc) *Scenario {
+	return &Scenario{name: name, newReader: newReader, bufferSize: 4, buffersNum: 2}
+}
+
+// Segments задаёт содержимое исходных ридеров.
+func (s *Scenario) Segments(contents ...string) *Scenario {
+	s.segments = contents
+	return s
+}
+
+// Buffers задаёт размер и число блоков префетча (для вариантов без префетча игнорируется).
+func (s *Scenario) Buffers(size int64, num int) *Scenario {
+	s.bufferSize, s.buffersNum = size, num
+	return s
+}
+
+// Prepare настраивает моки сегментов (ошибки, короткие чтения, трассировку) перед созданием ридера.
+func (s *Scenario) Prepare(fn func(segs []*StringsReader)) *Scenario {
+	s.prepareSegs = fn
+	return s
+}
+
+// Read выполняет один вызов Read с буфером размера n.
+func (s *Scenario) Read(n int) *Scenario {
+	return s.op(fmt.Sprintf("Read(%d)", n), func(st *scenarioState) {
+		buf := make([]byte, n)
+		st.n, st.err = st.r.Read(buf)
+		if st.n >= 0 && st.n <= n {
+			st.data = buf[:st.n]
+		}
+	})
+}
+
+// ReadAll читает ридер до конца; io.EOF ошибкой не считается.
+func (s *Scenario) ReadAll() *Scenario {
+	return s.op("ReadAll", func(st *scenarioState) {
+		st.data, st.err = io.ReadAll(st.r)
+		st.n = len(st.data)
+	})
+}
+
+// SeekTo выполняет Seek; для ридеров без поддержки Seek шаг завершается ошибкой.
+// Имя Seek не используется, чтобы не путать построитель с io.Seeker.
+func (s *Scenario) SeekTo(offset int64, whence int) *Scenario {
+	return s.op(fmt.Sprintf("Seek(%d, %d)", offset, whence), func(st *scenarioState) {
+		seeker, ok := st.r.(io.Seeker)
+		if !ok {
+			st.err = ErrSeekNotSupported
+			return
+		}
+		st.pos, st.err = seeker.Seek(offset, whence)
+	})
+}
+
+// Close закрывает ридер.
+func (s *Scenario) Close() *Scenario {
+	return s.op("Close", func(st *scenarioState) {
+		st.err = st.r.Close()
+		st.closed = true
+	})
+}
+
+// ExpectBytes проверяет данные последнего Read/ReadAll.
+func (s *Scenario) ExpectBytes(want string) *Scenario {
+	return s.expect(fmt.Sprintf("ExpectBytes(%q)", excerpt(want, 0)), func(st *scenarioState) error {
+		return ExpectBytes("данные", string(st.data), want)
+	})
+}
+
+// ExpectN проверяет число байт, возвращённое последним Read.
+func (s *Scenario) ExpectN(want int) *Scenario {
+	return s.expect(fmt.Sprintf("ExpectN(%d)", want), func(st *scenarioState) error {
+		return ExpectEqual("n", st.n, want)
+	})
+}
+
+// ExpectPos проверяет позицию, возвращённую последним Seek.
+func (s *Scenario) ExpectPos(want int64) *Scenario {
+	return s.expect(fmt.Sprintf("ExpectPos(%d)", want), func(st *scenarioState) error {
+		return ExpectEqual("позиция", st.pos, want)
+	})
+}
+
+// ExpectSize проверяет Size ридера.
+func (s *Scenario) ExpectSize(want int64) *Scenario {
+	return s.expect(fmt.Sprintf("ExpectSize(%d)", want), func(st *scenarioState) error {
+		return ExpectEqual("Size", st.r.Size(), want)
+	})
+}
+
+// ExpectErr проверяет, что последняя операция вернула ошибку target (errors.Is); nil target — любую ошибку.
+func (s *Scenario) ExpectErr(target error) *Scenario {
+	return s.expect(fmt.Sprintf("ExpectErr(%v)", target), func(st *scenarioState) error {
+		st.checked = true
+		if target == nil {
+			return ExpectError("ошибка", st.err)
+		}
+		return ExpectErrorIs("ошибка", st.err, target)
+	})
+}
+
+// ExpectNoErr проверяет, что последняя операция завершилась без ошибки.
+func (s *Scenario) ExpectNoErr() *Scenario {
+	return s.expect("ExpectNoErr", func(st *scenarioState) error {
+		st.checked = true
+		return ExpectNoError("ошибка", st.err)
+	})
+}
+
+// Expect добавляет произвольную проверку над моками сегментов.
+func (s *Scenario) Expect(desc string, fn func(segs []*StringsReader) error) *Scenario {
+	return s.expect(desc, func(st *scenarioState) error { return fn(st.segs) })
+}
+
+func (s *Scenario) op(desc string, fn func(st *scenarioState)) *Scenario {
+	s.steps = append(s.steps, scenarioStep{desc: desc, run: func(st *scenarioState) error {
+		st.data, st.n, st.pos, st.err, st.checked = nil, 0, 0, nil, false
+		fn(st)
+		return nil
+	}})
+	return s
+}
+
+func (s *Scenario) expect(desc string, fn func(st *scenarioState) error) *Scenario {
+	s.steps = append(s.steps, scenarioStep{desc: desc, expect: true, run: fn})
+	return s
+}
+
+// ScenarioReader — общий для всех вариантов задания интерфейс ридера в сценарии. Seek вызывается,
+// если ридер реализует io.Seeker.
+type ScenarioReader interface {
+	io.ReadCloser
+	Size() int64
+}
+
+// ErrSeekNotSupported возвращается шагом Seek для ридеров без io.Seeker.
+var ErrSeekNotSupported = errors.New("seek is not supported")
+
+// TestCase собирает сценарий в тест кейс.
+func (s *Scenario) TestCase() TestCase {
+	return TestCase{Name: s.name, Run: s.run}
+}
+
+func (s *Scenario) run() (err error) {
+	st := &scenarioState{}
+	for _, content := range s.segments {
+		st.segs = append(st.segs, NewStringsReader(content))
+	}
+	if s.prepareSegs != nil {
+		s.prepareSegs(st.segs)
+	}
+	st.r = s.newReader(s.bufferSize, s.buffersNum, st.segs)
+	defer func() {
+		if !st.closed {
+			_ = st.r.Close()
+		}
+	}()
+
+	for i, step := range s.steps {
+		if !step.expect { // Перед новой операцией убеждаемся, что ошибка предыдущей не осталась незамеченной
+			if err = uncheckedError(st); err != nil {
+				return fmt.Errorf("шаг %d %s: %w", i, s.steps[i-1].desc, err)
+			}
+		}
+		if err = step.run(st); err != nil {
+			return fmt.Errorf("шаг %d %s: %w", i+1, step.desc, err)
+		}
+	}
+	if err = uncheckedError(st); err != nil && len(s.steps) > 0 {
+		return fmt.Errorf("шаг %d %s: %w", len(s.steps), s.steps[len(s.steps)-1].desc, err)
+	}
+	return nil
+}
+
+// uncheckedError возвращает ошибку последней операции, если она не io.EOF и не была проверена.
+func uncheckedError(st *scenarioState) error {
+	if st.checked || st.err == nil || errors.Is(st.err, io.EOF) {
+		return nil
+	}
+	return fmt.Errorf("неожиданная ошибка: %w", st.err)
+}
@@ -0,0 +1,118 @@
+package testutil
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// concatReader — простейший ридер для проверки Scenario: склеивает содержимое сегментов и закрывает их в Close.
+type concatReader struct {
+	io.Reader
+	size int64
+	segs []*StringsReader
+}
+
+func (c *concatReader) Size() int64 { return c.size }
+
+func (c *concatReader) Close() error {
+	var errs []error
+	for _, s := range c.segs {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// seekableConcatReader дополняет concatReader поддержкой Seek.
+type seekableConcatReader struct {
+	*concatReader
+	section *io.SectionReader
+}
+
+func (c *seekableConcatReader) Read(p []byte) (int, error) { return c.section.Read(p) }
+
+func (c *seekableConcatReader) Seek(offset int64, whence int) (int64, error) {
+	return c.section.Seek(offset, whence)
+}
+
+func concatContent(segs []*StringsReader) string {
+	var b strings.Builder
+	for _, s := range segs {
+		_, _ = s.WriteTo(&b)
+		_, _ = s.Seek(0, io.SeekStart)
+	}
+	return b.String()
+}
+
+func newConcatReader(_ int64, _ int, segs []*StringsReader) ScenarioReader {
+	content := concatContent(segs)
+	return &concatReader{Reader: strings.NewReader(content), size: int64(len(content)), segs: segs}
+}
+
+func newSeekableConcatReader(_ int64, _ int, segs []*StringsReader) ScenarioReader {
+	content := concatContent(segs)
+	return &seekableConcatReader{
+		concatReader: &concatReader{size: int64(len(content)), segs: segs},
+		section:      io.NewSectionReader(strings.NewReader(content), 0, int64(len(content))),
+	}
+}
+
+func TestScenario_Passes(t *testing.T) {
+	err := NewScenario("ok", newSeekableConcatReader).Segments("ab", "", "cd").
+		ExpectSize(4).
+		Read(3).ExpectNoErr().ExpectBytes("abc").
+		SeekTo(1, io.SeekStart).ExpectNoErr().ExpectPos(1).
+		ReadAll().ExpectNoErr().ExpectBytes("bcd").
+		TestCase().Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScenario_ReportsFailedStep(t *testing.T) {
+	err := NewScenario("mismatch", newConcatReader).Segments("abc").Read(3).ExpectBytes("abd").TestCase().Run()
+	if err == nil || !strings.HasPrefix(err.Error(), `шаг 2 ExpectBytes("abd"): данные: расхождение на смещении 2`) {
+		t.Fatalf("получено %v", err)
+	}
+}
+
+func TestScenario_UncheckedErrorFails(t *testing.T) {
+	err := NewScenario("unchecked", newSeekableConcatReader).Segments("abc").SeekTo(-1, io.SeekStart).Read(1).TestCase().Run()
+	if err == nil || !strings.Contains(err.Error(), "шаг 1 Seek(-1, 0): неожиданная ошибка") {
+		t.Fatalf("получено %v", err)
+	}
+
+	err = NewScenario("checked", newSeekableConcatReader).Segments("abc").SeekTo(-1, io.SeekStart).ExpectErr(nil).Read(1).TestCase().Run()
+	if err != nil {
+		t.Fatalf("проверенная ошибка не должна проваливать сценарий: %v", err)
+	}
+}
+
+func TestScenario_SeekNotSupported(t *testing.T) {
+	err := NewScenario("unsupported", newConcatReader).Segments("abc").SeekTo(0, io.SeekStart).Read(1).TestCase().Run()
+	if err == nil || !strings.Contains(err.Error(), "шаг 1 Seek(0, 0): неожиданная ошибка: seek is not supported") {
+		t.Fatalf("получено %v", err)
+	}
+
+	err = NewScenario("checked", newConcatReader).Segments("abc").SeekTo(0, io.SeekStart).ExpectErr(ErrSeekNotSupported).Read(1).TestCase().Run()
+	if err != nil {
+		t.Fatalf("проверенная ошибка не должна проваливать сценарий: %v", err)
+	}
+}
+
+func TestScenario_ClosesReader(t *testing.T) {
+	var segs []*StringsReader
+	err := NewScenario("close", newConcatReader).Segments("a", "b").
+		Prepare(func(s []*StringsReader) { segs = s }).
+		Read(1).
+		TestCase().Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range segs {
+		if !s.Closed() {
+			t.Errorf("сегмент %d не закрыт", i)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package testutil
+
+import "strings"
+
+// StringsReader — адаптер поверх strings.Reader, реализующий SizedReadSeekCloser и SizedReadCloser всех вариантов
+// MultiReader и записывающий вызовы в трассу.
+// Дополнительно умеет внедрять сбои: ошибку Read после N байт, короткие чтения, выборочные ошибки Seek и ошибку Close.
+type StringsReader struct {
+	*strings.Reader
+	size     int64
+	closed   bool
+	closeErr error
+	trace    *CallTrace // трасса вызовов; nil — не записывать
+	name     string     // имя источника в трассе
+
+	readErr      error                                // ошибка Read после readErrAfter байт; nil — без сбоя
+	readErrAfter int64                                // смещение, начиная с которого Read возвращает readErr
+	maxRead      int                                  // максимум байт за один Read (короткие чтения); 0 — без ограничения
+	seekErrFn    func(offset int64, whence int) error // ошибка для конкретного Seek; nil — без сбоя
+}
+
+// NewStringsReader создаёт источник с содержимым s.
+func NewStringsReader(s string) *StringsReader {
+	r := strings.NewReader(s)
+	return &StringsReader{
+		Reader: r,
+		size:   int64(r.Len()),
+	}
+}
+
+// TraceTo включает запись вызовов в трассу t под именем name. Одну трассу можно разделять между источниками.
+func (c *StringsReader) TraceTo(t *CallTrace, name string) *StringsReader {
+	c.trace = t
+	c.name = name
+	return c
+}
+
+// record добавляет вызов в трассу, если она включена.
+func (c *StringsReader) record(call TraceCall) {
+	if c.trace != nil {
+		call.Reader = c.name
+		c.trace.Record(call)
+	}
+}
+
+// FailReadAfter настраивает Read: первые n байт отдаются нормально, затем возвращается err.
+func (c *StringsReader) FailReadAfter(n int64, err error) *StringsReader {
+	c.readErrAfter = n
+	c.readErr = err
+	return c
+}
+
+// ShortReads ограничивает число байт, возвращаемых одним Read.
+func (c *StringsReader) ShortReads(maxRead int) *StringsReader {
+	c.maxRead = maxRead
+	return c
+}
+
+// FailSeek настраивает выборочные ошибки Seek: fn возвращает ошибку для нужных вызовов и nil для остальных.
+func (c *StringsReader) FailSeek(fn func(offset int64, whence int) error) *StringsReader {
+	c.seekErrFn = fn
+	return c
+}
+
+// FailClose настраивает ошибку Close.
+func (c *StringsReader) FailClose(err error) *StringsReader {
+	c.closeErr = err
+	return c
+}
+
+func (c *StringsReader) Read(p []byte) (n int, err error) {
+	defer func(l int) { c.record(TraceCall{Op: OpRead, Len: l, N: int64(n), Err: err}) }(len(p))
+	if c.maxRead > 0 && len(p) > c.maxRead {
+		p = p[:c.maxRead]
+	}
+	if c.readErr != nil {
+		pos := c.size - int64(c.Reader.Len())
+		if pos >= c.readErrAfter {
+			return 0, c.readErr
+		}
+		if rest := c.readErrAfter - pos; int64(len(p)) > rest { // Отдаём данные ровно до точки сбоя
+			p = p[:rest]
+		}
+	}
+	return c.Reader.Read(p)
+}
+
+func (c *StringsReader) Seek(offset int64, whence int) (pos int64, err error) {
+	defer func() { c.record(TraceCall{Op: OpSeek, Offset: offset, Whence: whence, N: pos, Err: err}) }()
+	if c.seekErrFn != nil {
+		if err := c.seekErrFn(offset, whence); err != nil {
+			return 0, err
+		}
+	}
+	return c.Reader.Seek(offset, whence)
+}
+
+func (c *StringsReader) Close() error {
+	c.record(TraceCall{Op: OpClose})
+	c.closed = true
+	return c.closeErr
+}
+
+func (c *StringsReader) Size() int64 {
+	c.record(TraceCall{Op: OpSize})
+	return c.size
+}
+
+// Closed сообщает, был ли вызван Close.
+func (c *StringsReader) Closed() bool {
+	return c.closed
+}
@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStringsReader_FaultInjection(t *testing.T) {
+	errRead := errors.New("read failed")
+	r := NewStringsReader("abcdef").FailReadAfter(4, errRead).ShortReads(3)
+
+	buf := make([]byte, 10)
+	n, err := r.Read(buf)
+	if n != 3 || err != nil {
+		t.Fatalf("короткое чтение: %d, %v", n, err)
+	}
+	n, err = r.Read(buf)
+	if n != 1 || err != nil {
+		t.Fatalf("данные до точки сбоя: %d, %v", n, err)
+	}
+	if _, err = r.Read(buf); !errors.Is(err, errRead) {
+		t.Fatalf("ожидалась ошибка чтения, получено %v", err)
+	}
+
+	errSeek := errors.New("seek failed")
+	r.FailSeek(func(offset int64, _ int) error {
+		if offset == 5 {
+			return errSeek
+		}
+		return nil
+	})
+	if _, err = r.Seek(5, io.SeekStart); !errors.Is(err, errSeek) {
+		t.Fatalf("ожидалась ошибка Seek, получено %v", err)
+	}
+	if pos, err := r.Seek(1, io.SeekStart); pos != 1 || err != nil {
+		t.Fatalf("Seek: %d, %v", pos, err)
+	}
+
+	errClose := errors.New("close failed")
+	if err = r.FailClose(errClose).Close(); !errors.Is(err, errClose) || !r.Closed() {
+		t.Fatalf("Close: %v, closed=%v", err, r.Closed())
+	}
+}
+
+func TestStringsReader_Trace(t *testing.T) {
+	trace := &CallTrace{}
+	a := NewStringsReader("ab").TraceTo(trace, "a")
+	b := NewStringsReader("c").TraceTo(trace, "b")
+
+	_ = a.Size()
+	mark := trace.Mark()
+	_, _ = a.Read(make([]byte, 4))
+	_, _ = b.Seek(0, io.SeekStart)
+
+	if got := trace.Count(mark, "", OpRead); got != 1 {
+		t.Errorf("Read: %d вызовов", got)
+	}
+	if err := trace.ExpectCalls("Seek b", mark, "b", OpSeek); err != nil {
+		t.Error(err)
+	}
+	if err := trace.ExpectNoCalls("Size после отметки", mark, "", OpSize); err != nil {
+		t.Error(err)
+	}
+	if err := trace.ExpectNoCalls("Read b", mark, "b", OpRead); err != nil {
+		t.Error(err)
+	}
+	want := []string{"a.Read(len=4) = 2, <nil>", "b.Seek(0, 0) = 0, <nil>"}
+	for i, c := range trace.Since(mark) {
+		if c.String() != want[i] {
+			t.Errorf("вызов %d: %s, ожидался %s", i, c, want[i])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package throttle ограничивает скорость потоков байт по алгоритму token bucket.
+// Одна корзина может быть общей для нескольких ридеров и писателей — тогда лимит действует на их суммарный поток.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Bucket — корзина токенов: пополняется со скоростью rate байт в секунду до burst байт.
+// Безопасна для конкурентного использования.
+type Bucket struct {
+	rate  float64 // байт в секунду
+	burst int64   // ёмкость корзины и максимальный размер одной порции
+
+	mu     sync.Mutex // защищает поля ниже
+	tokens float64    // доступные токены; отрицательное значение — долг уже выданных порций
+	last   time.Time  // время последнего пополнения
+
+	now   func() time.Time                       // источник времени; подменяется в тестах
+	after func(d time.Duration) <-chan time.Time // ожидание; подменяется в тестах
+}
+
+// NewBucket создаёт полную корзину с лимитом bytesPerSec и ёмкостью burst байт.
+func NewBucket(bytesPerSec, burst int64) (*Bucket, error) {
+	if bytesPerSec <= 0 || burst <= 0 {
+		return nil, errors.New("throttle: rate and burst must be positive")
+	}
+	return &Bucket{
+		rate:   float64(bytesPerSec),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+		after:  time.After,
+	}, nil
+}
+
+// Burst возвращает ёмкость корзины — максимальную порцию, которую можно получить за один WaitN.
+func (b *Bucket) Burst() int64 {
+	return b.burst
+}
+
+// WaitN ждёт, пока в корзине наберётся n токенов, и забирает их. n не может превышать Burst.
+// При отмене ctx ожидание прерывается, а зарезервированные токены возвращаются в корзину.
+func (b *Bucket) WaitN(ctx context.Context, n int64) error {
+	if n > b.burst {
+		return fmt.Errorf("throttle: wait for %d bytes exceeds burst %d", n, b.burst)
+	}
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	now := b.now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, float64(b.burst))
+	b.last = now
+	b.tokens -= float64(n)
+	deficit := -b.tokens
+	b.mu.Unlock()
+	if deficit <= 0 {
+		return nil
+	}
+
+	select {
+	case <-b.after(time.Duration(deficit / b.rate * float64(time.Second))):
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens += float64(n)
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Reader ограничивает скорость чтения из r корзиной bucket.
+type Reader struct {
+	ctx    context.Context
+	r      io.Reader
+	bucket *Bucket
+}
+
+// NewReader создаёт ридер, который после каждого чтения ждёт токены на прочитанные байты.
+// ctx прерывает ожидание: Read возвращает уже прочитанные байты вместе с ошибкой контекста.
+func NewReader(ctx context.Context, r io.Reader, bucket *Bucket) *Reader {
+	return &Reader{ctx: ctx, r: r, bucket: bucket}
+}
+
+// Read читает не больше Burst байт и выдерживает паузу, если корзина пуста.
+func (tr *Reader) Read(p []byte) (int, error) {
+	if int64(len(p)) > tr.bucket.burst {
+		p = p[:tr.bucket.burst]
+	}
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		waitErr := tr.bucket.WaitN(tr.ctx, int64(n))
+		if waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// Writer ограничивает скорость записи в w корзиной bucket.
+type Writer struct {
+	ctx    context.Context
+	w      io.Writer
+	bucket *Bucket
+}
+
+// NewWriter создаёт писатель, который перед записью каждой порции ждёт токены на неё.
+func NewWriter(ctx context.Context, w io.Writer, bucket *Bucket) *Writer {
+	return &Writer{ctx: ctx, w: w, bucket: bucket}
+}
+
+// Write пишет p порциями не больше Burst байт.
+func (tw *Writer) Write(p []byte) (n int, err error) {
+	for n < len(p) {
+		chunk := p[n:min(len(p), n+int(tw.bucket.burst))]
+		err = tw.bucket.WaitN(tw.ctx, int64(len(chunk)))
+		if err != nil {
+			return n, err
+		}
+		written, err := tw.w.Write(chunk)
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Flush сбрасывает буфер приёмника, если он его поддерживает: обёртка не должна скрывать Flush от Pipe.
+func (tw *Writer) Flush() error {
+	if f, ok := tw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Sync гарантирует запись приёмника на диск, если он это поддерживает.
+func (tw *Writer) Sync() error {
+	if s, ok := tw.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close закрывает приёмник, если он это поддерживает.
+func (tw *Writer) Close() error {
+	if c, ok := tw.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// virtualTime — виртуальные часы: ожидание мгновенно сдвигает время на запрошенную паузу.
+type virtualTime struct {
+	t     time.Time
+	start time.Time
+}
+
+func newTestBucket(t *testing.T, rate, burst int64) (*Bucket, *virtualTime) {
+	t.Helper()
+	b, err := NewBucket(rate, burst)
+	if err != nil {
+		t.Fatalf("NewBucket: %v", err)
+	}
+	vt := &virtualTime{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	vt.start = vt.t
+	b.now = func() time.Time { return vt.t }
+	b.last = vt.t
+	b.after = func(d time.Duration) <-chan time.Time {
+		vt.t = vt.t.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- vt.t
+		return ch
+	}
+	return b, vt
+}
+
+func (vt *virtualTime) elapsed() time.Duration { return vt.t.Sub(vt.start) }
+
+func TestNewBucket_Validates(t *testing.T) {
+	if _, err := NewBucket(0, 10); err == nil {
+		t.Fatalf("нулевая скорость должна отклоняться")
+	}
+	if _, err := NewBucket(10, 0); err == nil {
+		t.Fatalf("нулевой burst должен отклоняться")
+	}
+}
+
+func TestReader_LimitsRate(t *testing.T) {
+	b, vt := newTestBucket(t, 100, 50)
+	r := NewReader(context.Background(), strings.NewReader(strings.Repeat("x", 250)), b)
+
+	got, err := io.ReadAll(r)
+	if err != nil || len(got) != 250 {
+		t.Fatalf("ReadAll: %d байт, %v", len(got), err)
+	}
+	// Первые 50 байт — из полной корзины, остальные 200 — по 100 байт/с
+	if vt.elapsed() != 2*time.Second {
+		t.Fatalf("чтение заняло %v, ожидалось 2s", vt.elapsed())
+	}
+}
+
+func TestReader_SharedBucket(t *testing.T) {
+	b, vt := newTestBucket(t, 100, 10)
+	ctx := context.Background()
+	r1 := NewReader(ctx, strings.NewReader(strings.Repeat("a", 100)), b)
+	r2 := NewReader(ctx, strings.NewReader(strings.Repeat("b", 100)), b)
+
+	buf := make([]byte, 64)
+	for _, r := range []io.Reader{r1, r2, r1, r2} {
+		for range 5 {
+			n, err := r.Read(buf)
+			if n > 10 || err != nil {
+				t.Fatalf("Read: n=%d, err=%v; порция не должна превышать burst", n, err)
+			}
+		}
+	}
+	// 200 байт суммарно: 10 из полной корзины, остальные 190 — по общему лимиту 100 байт/с
+	if vt.elapsed() != 1900*time.Millisecond {
+		t.Fatalf("чтение заняло %v, ожидалось 1.9s", vt.elapsed())
+	}
+}
+
+func TestBucket_WaitNCanceled(t *testing.T) {
+	b, err := NewBucket(1, 10)
+	if err != nil {
+		t.Fatalf("NewBucket: %v", err)
+	}
+	if err = b.WaitN(context.Background(), 11); err == nil {
+		t.Fatalf("запрос больше burst должен возвращать ошибку")
+	}
+	if err = b.WaitN(context.Background(), 10); err != nil {
+		t.Fatalf("WaitN из полной корзины: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = b.WaitN(ctx, 5); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitN при пустой корзине: %v, ожидалась ошибка контекста", err)
+	}
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < 0 {
+		t.Fatalf("после отмены токены не вернулись в корзину: %v", tokens)
+	}
+}
+
+// flushBuffer — приёмник, считающий вызовы Flush.
+type flushBuffer struct {
+	bytes.Buffer
+	writes, flushes int
+}
+
+func (f *flushBuffer) Write(p []byte) (int, error) {
+	f.writes++
+	return f.Buffer.Write(p)
+}
+
+func (f *flushBuffer) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestWriter_LimitsRateAndForwardsFlush(t *testing.T) {
+	b, vt := newTestBucket(t, 1000, 100)
+	sink := &flushBuffer{}
+	w := NewWriter(context.Background(), sink, b)
+
+	data := strings.Repeat("y", 1100)
+	n, err := w.Write([]byte(data))
+	if err != nil || n != len(data) || sink.String() != data {
+		t.Fatalf("Write: n=%d, err=%v", n, err)
+	}
+	if sink.writes != 11 {
+		t.Fatalf("записей в приёмник %d, ожидалось 11 порций по burst", sink.writes)
+	}
+	if vt.elapsed() != time.Second {
+		t.Fatalf("запись заняла %v, ожидалось 1s", vt.elapsed())
+	}
+	if err = w.Flush(); err != nil || sink.flushes != 1 {
+		t.Fatalf("Flush должен доходить до приёмника: err=%v, вызовов %d", err, sink.flushes)
+	}
+}
@@ -0,0 +1,139 @@
+// Package workerpool выполняет задания параллельно и выдаёт результаты строго в порядке отправки,
+// ограничивая число заданий, отправленных, но ещё не выданных получателю.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrClosed — Submit после Close.
+var ErrClosed = errors.New("workerpool: pool is closed")
+
+// Config — настройки Pool.
+type Config struct {
+	Workers     int // число параллельно выполняемых заданий
+	MaxInFlight int // предел отправленных и ещё не выданных заданий; 0 — Workers
+}
+
+// Result — результат одного задания.
+type Result[Out any] struct {
+	Value Out
+	Err   error
+}
+
+// Pool — пул из Workers горутин с упорядоченной выдачей результатов.
+// Пока получатель не забрал результат самого раннего задания, более поздние копятся, но не выдаются;
+// когда незабранных заданий MaxInFlight, Submit ждёт.
+type Pool[In, Out any] struct {
+	ctx context.Context
+	fn  func(context.Context, In) (Out, error)
+
+	sem     chan struct{}         // слоты заданий в обороте
+	jobs    chan job[In, Out]     // задания для воркеров
+	order   chan chan Result[Out] // каналы результатов в порядке отправки
+	results chan Result[Out]      // упорядоченные результаты для получателя
+	wg      sync.WaitGroup        // воркеры
+
+	mu     sync.Mutex // защищает closed и отправку в jobs/order
+	closed bool
+}
+
+type job[In, Out any] struct {
+	in  In
+	res chan Result[Out]
+}
+
+// New запускает пул, выполняющий fn. Отмена ctx прерывает ожидание в Submit, передаётся в fn
+// и прекращает выдачу результатов: оставшиеся отбрасываются. Close нужно вызывать всегда.
+func New[In, Out any](ctx context.Context, cfg Config, fn func(context.Context, In) (Out, error)) (*Pool[In, Out], error) {
+	if cfg.Workers <= 0 {
+		return nil, fmt.Errorf("workerpool: workers must be positive, got %d", cfg.Workers)
+	}
+	if cfg.MaxInFlight < 0 {
+		return nil, fmt.Errorf("workerpool: max in flight must not be negative, got %d", cfg.MaxInFlight)
+	}
+	if cfg.MaxInFlight == 0 {
+		cfg.MaxInFlight = cfg.Workers
+	}
+
+	p := &Pool[In, Out]{
+		ctx:     ctx,
+		fn:      fn,
+		sem:     make(chan struct{}, cfg.MaxInFlight),
+		jobs:    make(chan job[In, Out], cfg.MaxInFlight), // Слотов sem не больше ёмкости: отправка не блокируется
+		order:   make(chan chan Result[Out], cfg.MaxInFlight),
+		results: make(chan Result[Out]),
+	}
+	p.wg.Add(cfg.Workers)
+	for range cfg.Workers {
+		go p.work()
+	}
+	go p.deliver()
+	return p, nil
+}
+
+// Submit отправляет задание, ожидая свободного слота. Результат появится в Results в порядке вызовов Submit.
+func (p *Pool[In, Out]) Submit(ctx context.Context, in In) error {
+	select {
+	case p.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		<-p.sem
+		return ErrClosed
+	}
+	res := make(chan Result[Out], 1)
+	p.order <- res
+	p.jobs <- job[In, Out]{in: in, res: res}
+	return nil
+}
+
+// Results возвращает канал упорядоченных результатов. Он закрывается после Close, когда выданы все результаты.
+func (p *Pool[In, Out]) Results() <-chan Result[Out] {
+	return p.results
+}
+
+// Close прекращает приём заданий. Уже отправленные задания выполняются, их результаты выдаются в Results.
+func (p *Pool[In, Out]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	close(p.order)
+}
+
+// work выполняет задания до закрытия jobs.
+func (p *Pool[In, Out]) work() {
+	defer p.wg.Done()
+	for j := range p.jobs {
+		out, err := p.fn(p.ctx, j.in)
+		j.res <- Result[Out]{Value: out, Err: err}
+	}
+}
+
+// deliver выдаёт результаты в порядке отправки, освобождая слот, когда получатель забрал результат.
+// После отмены ctx дожидается оставшихся заданий, не выдавая их, чтобы ни одна горутина пула не пережила Results.
+func (p *Pool[In, Out]) deliver() {
+	defer close(p.results)
+	defer p.wg.Wait()
+	for res := range p.order {
+		r := <-res
+		select {
+		case p.results <- r:
+		case <-p.ctx.Done():
+		}
+		<-p.sem
+	}
+}
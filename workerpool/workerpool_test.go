@@ -0,0 +1,131 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestPool[In, Out any](t *testing.T, ctx context.Context, cfg Config, fn func(context.Context, In) (Out, error)) *Pool[In, Out] {
+	t.Helper()
+	p, err := New(ctx, cfg, fn)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestNew_Validates(t *testing.T) {
+	fn := func(context.Context, int) (int, error) { return 0, nil }
+	for _, cfg := range []Config{{}, {Workers: -1}, {Workers: 1, MaxInFlight: -1}} {
+		if _, err := New(context.Background(), cfg, fn); err == nil {
+			t.Errorf("New(%+v): ожидалась ошибка", cfg)
+		}
+	}
+}
+
+func TestPool_ResultsInSubmissionOrder(t *testing.T) {
+	const n = 200
+	var running, peak atomic.Int32
+	p := newTestPool(t, context.Background(), Config{Workers: 4, MaxInFlight: 8}, func(_ context.Context, i int) (int, error) {
+		cur := running.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(rand.Intn(300)) * time.Microsecond) // Задания завершаются не по порядку
+		running.Add(-1)
+		if i%10 == 7 {
+			return 0, errors.New("odd job")
+		}
+		return i * i, nil
+	})
+
+	go func() {
+		for i := range n {
+			if err := p.Submit(context.Background(), i); err != nil {
+				t.Errorf("Submit(%d): %v", i, err)
+			}
+		}
+		p.Close()
+	}()
+
+	i := 0
+	for r := range p.Results() {
+		if i%10 == 7 {
+			if r.Err == nil {
+				t.Fatalf("результат %d: ожидалась ошибка задания", i)
+			}
+		} else if r.Err != nil || r.Value != i*i {
+			t.Fatalf("результат %d: %v, %v — порядок нарушен", i, r.Value, r.Err)
+		}
+		i++
+	}
+	if i != n {
+		t.Fatalf("получено %d результатов из %d", i, n)
+	}
+	if peak.Load() < 2 || peak.Load() > 4 {
+		t.Fatalf("одновременно выполнялось до %d заданий, ожидалось от 2 до 4", peak.Load())
+	}
+}
+
+// TestPool_BoundsInFlight проверяет, что Submit ждёт, пока получатель не заберёт результаты.
+func TestPool_BoundsInFlight(t *testing.T) {
+	p := newTestPool(t, context.Background(), Config{Workers: 2, MaxInFlight: 3}, func(_ context.Context, i int) (int, error) {
+		return i, nil
+	})
+	defer p.Close()
+
+	for i := range 3 {
+		if err := p.Submit(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit сверх MaxInFlight без получателя: %v, ожидалось ожидание до дедлайна", err)
+	}
+
+	<-p.Results()
+	if err := p.Submit(context.Background(), 3); err != nil {
+		t.Fatalf("Submit после освобождения слота: %v", err)
+	}
+}
+
+func TestPool_CloseAndCancel(t *testing.T) {
+	p := newTestPool(t, context.Background(), Config{Workers: 1}, func(_ context.Context, i int) (int, error) { return i, nil })
+	p.Close()
+	if err := p.Submit(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit после Close: %v", err)
+	}
+	if _, ok := <-p.Results(); ok {
+		t.Fatalf("Results должен закрыться после Close")
+	}
+
+	// Отмена контекста: результаты отбрасываются, Results закрывается, когда все задания завершены
+	ctx, cancel := context.WithCancel(context.Background())
+	var finished atomic.Int32
+	p = newTestPool(t, ctx, Config{Workers: 2, MaxInFlight: 4}, func(ctx context.Context, i int) (int, error) {
+		<-ctx.Done()
+		finished.Add(1)
+		return i, ctx.Err()
+	})
+	for i := range 4 {
+		if err := p.Submit(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	p.Close()
+	for range p.Results() {
+	}
+	if finished.Load() != 4 {
+		t.Fatalf("Results закрыт до завершения заданий: завершено %d из 4", finished.Load())
+	}
+}